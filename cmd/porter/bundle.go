@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"get.porter.sh/porter/pkg/porter"
+	"get.porter.sh/porter/pkg/sbom"
 	"github.com/spf13/cobra"
 )
 
@@ -26,6 +27,8 @@ func buildBundleCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildBundleExplainCommand(p))
 	cmd.AddCommand(buildBundleCopyCommand(p))
 	cmd.AddCommand(buildBundleInspectCommand(p))
+	cmd.AddCommand(buildBundleVerifyCommand(p))
+	cmd.AddCommand(buildBundleProvenanceCommand(p))
 
 	return cmd
 }
@@ -55,13 +58,28 @@ func buildBundleBuildCommand(p *porter.Porter) *cobra.Command {
 		Short: "Build a bundle",
 		Long: `Builds the bundle in the current directory by generating a Dockerfile and a CNAB bundle.json, and then building the bundle image.
 
-The docker driver builds the bundle image using the local Docker host. To use a remote Docker host, set the following environment variables:
+The buildkit driver builds the bundle image using the local Docker host. To use a remote Docker host, set the following environment variables:
   DOCKER_HOST (required)
   DOCKER_TLS_VERIFY (optional)
   DOCKER_CERT_PATH (optional)
+
+The oci driver builds the bundle image without Docker by adding the bundle files to the base image, and saves it to an OCI image layout in .cnab/image. The oci driver cannot run commands, so it only supports template Dockerfiles without RUN instructions, and mixins that do not add RUN instructions to the Dockerfile. Use the buildkit driver when the bundle image needs tools installed with RUN. The image is pushed directly to the registry when the bundle is published.
+
+Use --platform, or platforms in porter.yaml, to build a multi-platform bundle image for each of the specified platforms. The runtime of each mixin used by the bundle must be installed for those platforms with porter mixins install --platform.
+
+The versions and checksums of the mixins used by the bundle are written to porter.lock in the build context directory. Commit porter.lock and build with --locked to fail the build when the installed mixins are different from the locked mixins.
+
+Use --watch to rebuild the bundle each time that porter.yaml, the Dockerfile template or the files in the build context are saved. When only the steps in porter.yaml change, the manifest is validated and linted and bundle.json is regenerated, without building the bundle image. The bundle image is rebuilt automatically the next time the bundle is run or published.
+
+Use --cache-from and --cache-to, or buildCache in porter.yaml, to import and export the build cache of the bundle image, so that builds on a new machine do not start with an empty cache. The number of build steps that were cached is printed after the bundle image is built.
 '
 `,
 		Example: `  porter build
+  porter build --locked
+  porter build --watch
+  porter build --platform linux/amd64,linux/arm64
+  porter build --driver oci
+  porter build --cache-from type=registry,ref=example.com/mybuns:buildcache --cache-to type=registry,ref=example.com/mybuns:buildcache,mode=max
   porter build --name newbuns
   porter build --version 0.1.0
   porter build --file path/to/porter.yaml
@@ -72,12 +90,19 @@ The docker driver builds the bundle image using the local Docker host. To use a
 			return opts.Validate(p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Watch {
+				return p.BuildWatch(cmd.Context(), opts)
+			}
 			return p.Build(cmd.Context(), opts)
 		},
 	}
 
 	f := cmd.Flags()
 	f.BoolVar(&opts.NoLint, "no-lint", false, "Do not run the linter")
+	f.BoolVar(&opts.Watch, "watch", false,
+		"Rebuild the bundle each time that porter.yaml, the Dockerfile template or the files in the build context change.")
+	f.BoolVar(&opts.Locked, "locked", false,
+		"Fail the build when the installed mixins are different from the mixins in porter.lock, instead of updating porter.lock.")
 	f.StringVar(&opts.Name, "name", "", "Override the bundle name")
 	f.StringVar(&opts.Version, "version", "", "Override the bundle version")
 	f.StringVarP(&opts.File, "file", "f", "",
@@ -85,8 +110,7 @@ The docker driver builds the bundle image using the local Docker host. To use a
 	f.StringVarP(&opts.Dir, "dir", "d", "",
 		"Path to the build context directory where all bundle assets are located. Defaults to the current directory.")
 	f.StringVar(&opts.Driver, "driver", porter.BuildDriverDefault,
-		fmt.Sprintf("Driver for building the bundle image. Allowed values are: %s", strings.Join(porter.BuildDriverAllowedValues, ", ")))
-	_ = f.MarkHidden("driver") // Hide the driver flag since there aren't any choices to make right now
+		fmt.Sprintf("Driver for building the bundle image. Allowed values are: %s. The oci driver only supports template Dockerfiles and mixins without RUN instructions.", strings.Join(porter.BuildDriverAllowedValues, ", ")))
 	f.StringArrayVar(&opts.BuildArgs, "build-arg", nil,
 		"Set build arguments in the template Dockerfile (format: NAME=VALUE). May be specified multiple times. Max length is 5,000 characters.")
 	f.StringArrayVar(&opts.BuildContexts, "build-context", nil,
@@ -97,11 +121,19 @@ The docker driver builds the bundle image using the local Docker host. To use a
 		"Secret file to expose to the build (format: id=mysecret,src=/local/secret). Custom values are accessible as build arguments in the template Dockerfile and in the manifest using template variables. May be specified multiple times.")
 	f.BoolVar(&opts.NoCache, "no-cache", false,
 		"Do not use the Docker cache when building the bundle image.")
+	f.StringArrayVar(&opts.CacheFrom, "cache-from", nil,
+		"External cache source to import the build cache from (format: type=registry,ref=IMAGE or type=local,src=PATH). Overrides buildCache.from in porter.yaml. May be specified multiple times.")
+	f.StringArrayVar(&opts.CacheTo, "cache-to", nil,
+		"Cache destination to export the build cache to (format: type=registry,ref=IMAGE, type=local,dest=PATH or type=inline). Overrides buildCache.to in porter.yaml. May be specified multiple times.")
+	f.StringSliceVar(&opts.Platforms, "platform", nil,
+		"Platforms to build the bundle image for, such as linux/amd64 and linux/arm64. Overrides platforms in porter.yaml. Defaults to linux/amd64. May be specified multiple times.")
 	f.StringArrayVar(&opts.Customs, "custom", nil,
 		"Define an individual key-value pair for the custom section in the form of NAME=VALUE. Use dot notation to specify a nested custom field. May be specified multiple times. Max length is 5,000 characters when used as a build argument.")
 	f.BoolVar(&opts.InsecureRegistry, "insecure-registry", false,
 		"Don't require TLS when pulling referenced images")
 	f.BoolVar(&opts.PreserveTags, "preserve-tags", false, "Preserve the original tag name on referenced images")
+	f.StringVar(&opts.SBOMFormat, "sbom-format", sbom.DefaultFormat,
+		fmt.Sprintf("Format of the SBOM that lists the base images, Porter runtime, mixins and images used by the bundle, saved to .cnab/sbom.json. It does not list the packages in the bundle image. Allowed values are: %s", strings.Join(sbom.Formats, ", ")))
 
 	// Allow configuring the --driver flag with build-driver, to avoid conflicts with other commands
 	cmd.Flag("driver").Annotations = map[string][]string{
@@ -182,6 +214,8 @@ Note: if overrides for registry/tag/reference are provided, this command only re
 	f.BoolVar(&opts.AutoBuildDisabled, "autobuild-disabled", false, "Do not automatically build the bundle from source when the last build is out-of-date.")
 	f.BoolVar(&opts.SignBundle, "sign-bundle", false, "Sign the bundle using the configured signing plugin")
 	f.BoolVar(&opts.PreserveTags, "preserve-tags", false, "Preserve the original tag name on referenced images")
+	f.StringVar(&opts.SBOMFormat, "sbom-format", sbom.DefaultFormat,
+		fmt.Sprintf("Format of the SBOM that lists the base images, Porter runtime, mixins and images used by the bundle, attached to the published bundle. It does not list the packages in the bundle image. Allowed values are: %s", strings.Join(sbom.Formats, ", ")))
 
 	return &cmd
 }
@@ -211,3 +245,65 @@ func buildBundleArchiveCommand(p *porter.Porter) *cobra.Command {
 		fmt.Sprintf("Compression level to use when creating the gzipped tar archive. Allowed values are: %s", strings.Join(opts.GetCompressionLevelAllowedValues(), ", ")))
 	return &cmd
 }
+
+func buildBundleVerifyCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.VerifyOptions{}
+	cmd := cobra.Command{
+		Use:   "verify REFERENCE",
+		Short: "Verify a bundle against the verification policy",
+		Long: `Verify the signature of a bundle and its bundle image against the verification policy defined in the Porter config file.
+
+The verification policy maps repository patterns to the signers that must have signed the bundle, or allows the bundle to be unsigned. The rules are evaluated in order and the first rule that matches the repository of a reference is applied. The report lists which rule was applied to the bundle and the bundle image.
+
+When no verification policy is defined, the bundle is verified with the default signer.`,
+		Example: `  porter bundle verify ghcr.io/getporter/examples/porter-hello:v0.2.0
+  porter bundle verify localhost:5000/mybuns:v1.0.0 --insecure-registry
+  porter bundle verify ghcr.io/getporter/examples/porter-hello:v0.2.0 --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintVerifyBundle(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	addInsecureRegistryFlag(f, &opts.BundlePullOptions)
+	addForcePullFlag(f, &opts.BundlePullOptions)
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	return &cmd
+}
+
+func buildBundleProvenanceCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ProvenanceOptions{}
+	cmd := cobra.Command{
+		Use:   "provenance REFERENCE",
+		Short: "Show the provenance of a bundle",
+		Long: `Show the provenance statement that was attached to a bundle when it was published.
+
+The provenance statement is an in-toto statement with a SLSA provenance predicate that records the porter.yaml the bundle was built from, the versions of Porter and the mixins used, the build arguments, and the digests of the bundle and bundle image.
+
+Use --verify to verify the signature of the provenance statement against the verification policy, or with the default signer when no policy is defined. Provenance statements are signed when the bundle is published with --sign-bundle.`,
+		Example: `  porter bundle provenance ghcr.io/getporter/examples/porter-hello:v0.2.0
+  porter bundle provenance ghcr.io/getporter/examples/porter-hello:v0.2.0 --verify
+  porter bundle provenance localhost:5000/mybuns:v1.0.0 --insecure-registry --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintBundleProvenance(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	addInsecureRegistryFlag(f, &opts.BundlePullOptions)
+	addForcePullFlag(f, &opts.BundlePullOptions)
+	f.BoolVar(&opts.Verify, "verify", false,
+		"Verify the signature of the provenance statement.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	return &cmd
+}
@@ -20,6 +20,7 @@ func buildCredentialsCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildCredentialsDeleteCommand(p))
 	cmd.AddCommand(buildCredentialsShowCommand(p))
 	cmd.AddCommand(buildCredentialsCreateCommand(p))
+	cmd.AddCommand(buildCredentialsValidateCommand(p))
 
 	return cmd
 }
@@ -34,6 +35,8 @@ func buildCredentialsApplyCommand(p *porter.Porter) *cobra.Command {
 
 Supported file extensions: json and yaml.
 
+Files encrypted with SOPS using an age key are decrypted in memory, using the age identities in the SOPS_AGE_KEY environment variable or the key file configured with sops-age-key-file. The hard-coded values from an encrypted file are saved in the secret store instead of in Porter's database.
+
 You can use the generate and show commands to create the initial file:
   porter credentials generate mycreds --reference SOME_BUNDLE
   porter credentials show mycreds --output yaml > mycreds.yaml
@@ -241,3 +244,38 @@ func buildCredentialsCreateCommand(p *porter.Porter) *cobra.Command {
 
 	return cmd
 }
+
+func buildCredentialsValidateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.CredentialValidateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate [NAME]",
+		Short: "Validate that a credential set can be resolved",
+		Long: `Validate that every credential in a credential set can be resolved from its source, without running a bundle.
+
+Each source is resolved using the configured secrets plugin, or from the host for the env, path, command and value sources, and any that cannot be resolved are reported with the reason. The resolved values are never printed.
+
+Use --installation to validate every credential set used by an installation.
+
+The command exits with a non-zero exit code when any credential cannot be resolved.`,
+		Example: `  porter credentials validate github
+  porter credentials validate github --namespace dev --output json
+  porter credentials validate --installation wordpress --namespace dev`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintCredentialsValidation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the credential set, or the installation, is defined. Defaults to the global namespace.")
+	f.StringVarP(&opts.Installation, "installation", "i", "",
+		"Validate the credential sets used by the installation.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
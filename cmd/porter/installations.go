@@ -22,10 +22,12 @@ func buildInstallationCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildInstallationApplyCommand(p))
 	cmd.AddCommand(buildInstallationOutputsCommands(p))
 	cmd.AddCommand(buildInstallationDeleteCommand(p))
+	cmd.AddCommand(buildInstallationUnlockCommand(p))
 	cmd.AddCommand(buildInstallationLogCommands(p))
 	cmd.AddCommand(buildInstallationRunsCommands(p))
 	cmd.AddCommand(buildInstallationInstallCommand(p))
 	cmd.AddCommand(buildInstallationUpgradeCommand(p))
+	cmd.AddCommand(buildInstallationRollbackCommand(p))
 	cmd.AddCommand(buildInstallationInvokeCommand(p))
 	cmd.AddCommand(buildInstallationUninstallCommand(p))
 
@@ -117,14 +119,19 @@ func buildInstallationApplyCommand(p *porter.Porter) *cobra.Command {
 		Long: `Apply changes from the specified file to an installation. If the installation doesn't already exist, it is created.
 The installation's bundle is automatically executed if changes are detected.
 
+By default, only the names of the credential sets used by the installation are compared with the last run. Use --detect-credential-rotation to also resolve the credentials and upgrade the installation when the value of a credential has changed, such as when a secret was rotated. Combine it with --dry-run to see which credentials changed without running the bundle.
+
 When the namespace is not set in the file, the current namespace is used.
 
+Files encrypted with SOPS using an age key are decrypted in memory, using the age identities in the SOPS_AGE_KEY environment variable or the key file configured with sops-age-key-file. The hard-coded parameter values from an encrypted file are saved in the secret store instead of in Porter's database.
+
 You can use the show command to create the initial file:
   porter installation show mybuns --output yaml > mybuns.yaml
 `,
 		Example: `  porter installation apply myapp.yaml
   porter installation apply myapp.yaml --dry-run
-  porter installation apply myapp.yaml --force`,
+  porter installation apply myapp.yaml --force
+  porter installation apply myapp.yaml --detect-credential-rotation --dry-run`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context, args)
 		},
@@ -140,6 +147,8 @@ You can use the show command to create the initial file:
 		"Force the bundle to be executed when no changes are detected.")
 	f.BoolVar(&opts.DryRun, "dry-run", false,
 		"Evaluate if the bundle would be executed based on the changes in the file.")
+	f.BoolVar(&opts.DetectCredentialRotation, "detect-credential-rotation", false,
+		"Resolve the credentials used by the installation and upgrade it when the value of a credential has changed since the last run.")
 	return &cmd
 }
 
@@ -171,6 +180,34 @@ func buildInstallationDeleteCommand(p *porter.Porter) *cobra.Command {
 	return &cmd
 }
 
+func buildInstallationUnlockCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.InstallationUnlockOptions{}
+
+	cmd := cobra.Command{
+		Use:   "unlock INSTALLATION",
+		Short: "Remove the lock on an installation",
+		Long: `Remove the lock on an installation.
+
+Porter locks an installation while it runs a bundle so that only one action runs against the installation at a time. The lock is renewed while the bundle runs, and expires when it is not renewed, for example when porter was killed.
+Use this command to remove the lock when you are sure that the process holding it is no longer running, instead of waiting for it to expire.`,
+		Example: `  porter installation unlock wordpress
+  porter installation unlock wordpress --namespace dev
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.UnlockInstallation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+
+	return &cmd
+}
+
 func buildInstallationRunsCommands(p *porter.Porter) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "runs",
@@ -180,6 +217,9 @@ func buildInstallationRunsCommands(p *porter.Porter) *cobra.Command {
 	}
 
 	cmd.AddCommand(buildInstallationRunsListCommand(p))
+	cmd.AddCommand(buildInstallationRunsShowCommand(p))
+	cmd.AddCommand(buildInstallationRunsPruneCommand(p))
+	cmd.AddCommand(buildInstallationRunsDiffCommand(p))
 
 	return cmd
 }
@@ -190,10 +230,13 @@ func buildInstallationRunsListCommand(p *porter.Porter) *cobra.Command {
 	cmd := cobra.Command{
 		Use:   "list",
 		Short: "List runs of an Installation",
-		Long:  "List runs of an Installation",
+		Long: `List runs of an Installation.
+
+Each run records who started it: the user, the host, the client (cli, grpc or operator) and the version of porter used. Use --user, --host and --client to only list the runs started by them.`,
 		Example: `  porter installations runs list [NAME] [--namespace NAMESPACE] [--output FORMAT]
 
   porter installations runs list myapp --namespace dev
+  porter installations runs list myapp --user sally --client cli
 
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
@@ -209,6 +252,114 @@ func buildInstallationRunsListCommand(p *porter.Porter) *cobra.Command {
 		"Namespace in which the installation is defined. Defaults to the global namespace.")
 	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
 		"Specify an output format.  Allowed values: plaintext, json, yaml")
+	f.StringVar(&opts.User, "user", "",
+		"Only list runs started by the specified user.")
+	f.StringVar(&opts.Host, "host", "",
+		"Only list runs started from the specified host.")
+	f.StringVar(&opts.Client, "client", "",
+		"Only list runs started with the specified client. Allowed values: cli, grpc, operator")
+
+	return &cmd
+}
+
+func buildInstallationRunsShowCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunShowOptions{}
+
+	cmd := cobra.Command{
+		Use:   "show RUN_ID",
+		Short: "Show a run of an Installation",
+		Long:  "Show a run of an Installation, including its status, the bundle that was used and who started it.",
+		Example: `  porter installations runs show 01FZVC5AVP8Z7A78CSCP1EJ604
+  porter installations runs show 01FZVC5AVP8Z7A78CSCP1EJ604 --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ShowInstallationRun(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsDiffCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunDiffOptions{}
+
+	cmd := cobra.Command{
+		Use:   "diff RUN_A RUN_B",
+		Short: "Compare two runs of an Installation",
+		Long: `Compare two runs of an Installation.
+
+Shows what changed between the runs: the action, the bundle reference, version and digest, the parameters that were added, removed or changed, the parameter and credential sets that were used, and the outputs that were generated.
+The values of sensitive parameters and outputs are masked.
+
+Use --last to compare the two most recent runs of an installation, in which case the installation name may be specified instead of the run IDs.`,
+		Example: `  porter installations runs diff 01FZVC5AVP8Z7A78CSCP1EJ604 01FZVD2V2NSWXJ0QCMA7GRVCCB
+  porter installations runs diff --last myapp --namespace dev
+  porter installations runs diff --last myapp --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args, p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationRunsDiff(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.Last, "last", false,
+		"Compare the two most recent runs of the installation.")
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined, used with --last. Defaults to the global namespace.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationRunsPruneCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RunPruneOptions{}
+
+	cmd := cobra.Command{
+		Use:   "prune [NAME]",
+		Short: "Remove old runs of Installations",
+		Long: `Remove old runs of Installations, along with their results, outputs and logs.
+
+Runs are removed according to a retention policy, which defaults to the run-retention section of the Porter config file. Use --keep-last to keep only the most recent runs of each installation, and --older-than to remove runs older than a duration. When both are specified, a run is removed when it is outside either limit.
+The run that produced the current status of an installation is always kept.
+
+When no installation name is specified, the runs of every installation in the namespace are pruned.
+Sensitive values saved in the secret store for the removed runs are deleted when the secrets plugin supports it.`,
+		Example: `  porter installations runs prune myapp --keep-last 10
+  porter installations runs prune --namespace dev --older-than 720h
+  porter installations runs prune --all-namespaces --keep-last 5 --dry-run
+  porter installations runs prune --all-namespaces
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.KeepLastSet = cmd.Flags().Changed("keep-last")
+			return opts.Validate(args, p)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintPrunedInstallationRuns(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the installation is defined. Defaults to the global namespace.")
+	f.BoolVar(&opts.AllNamespaces, "all-namespaces", false,
+		"Prune installations in all namespaces.")
+	f.IntVar(&opts.KeepLast, "keep-last", 0,
+		"Number of most recent runs to keep for each installation. Use 0 to keep only the run for the current status. Defaults to run-retention.keep-last in the config file.")
+	f.StringVar(&opts.OlderThan, "older-than", "",
+		"Remove runs older than this duration, for example 720h. Defaults to run-retention.max-age in the config file.")
+	f.BoolVar(&opts.DryRun, "dry-run", false,
+		"List the runs that would be removed without removing them.")
 
 	return &cmd
 }
@@ -315,6 +466,52 @@ The docker driver runs the bundle container using the local Docker host. To use
 	return cmd
 }
 
+func buildInstallationRollbackCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.NewRollbackOptions()
+	cmd := &cobra.Command{
+		Use:   "rollback INSTALLATION",
+		Short: "Roll back an installation to a previous run",
+		Long: `Roll back an installation to a previous successful run.
+
+Porter upgrades the installation using the bundle, parameters, parameter sets and credential sets that were used by the previous run. The new run records the ID of the run that it rolled back to.
+By default the installation is rolled back to the most recent successful install or upgrade before the current state of the installation. Use --to-run to roll back to a specific run, which you can find with porter installations runs list.
+
+Parameter and credential sets are resolved again when the bundle is executed, so changes made to the sets since the previous run are used.
+`,
+		Example: `  porter installation rollback wordpress
+  porter installation rollback wordpress --namespace dev --to-run 01FZVC5AVP8Z7A78CSCP1EJ604
+  porter installation rollback wordpress --driver debug
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(cmd.Context(), args, p)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.RollbackInstallation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace of the specified installation. Defaults to the global namespace.")
+	f.StringVar(&opts.ToRun, "to-run", "",
+		"ID of the run to roll back to. Defaults to the most recent successful install or upgrade before the current state of the installation.")
+	addInsecureRegistryFlag(f, &opts.BundlePullOptions)
+	addForcePullFlag(f, &opts.BundlePullOptions)
+	f.BoolVar(&opts.AllowDockerHostAccess, "allow-docker-host-access", false,
+		"Controls if the bundle should have access to the host's Docker daemon with elevated privileges. See https://porter.sh/configuration/#allow-docker-host-access for the full implications of this flag.")
+	f.StringArrayVar(&opts.HostVolumeMounts, "mount-host-volume", nil, "Mount a host volume into the bundle. Format is <host path>:<container path>[:<option>]. May be specified multiple times. Option can be ro (read-only), rw (read-write), default is ro.")
+	f.BoolVar(&opts.NoLogs, "no-logs", false,
+		"Do not persist the bundle execution logs")
+	f.StringVarP(&opts.Driver, "driver", "d", porter.DefaultDriver,
+		"Specify a driver to use. Allowed values: docker, debug")
+
+	// Allow configuring the --driver flag with runtime-driver, to avoid conflicts with other commands
+	cmd.Flag("driver").Annotations = map[string][]string{
+		"viper-key": {"runtime-driver"},
+	}
+	return cmd
+}
+
 func buildInstallationInvokeCommand(p *porter.Porter) *cobra.Command {
 	opts := porter.NewInvokeOptions()
 	cmd := &cobra.Command{
@@ -86,10 +86,17 @@ func BuildMixinInstallCommand(p *porter.Porter) *cobra.Command {
 		Short: "Install a mixin",
 		Long: `Install a mixin.
 
-By default mixins are downloaded from the official Porter mixin feed at https://cdn.porter.sh/mixins/atom.xml. To download from a mirror, set the environment variable PORTER_MIRROR, or mirror in the Porter config file, with the value to replace https://cdn.porter.sh with.`,
+By default mixins are downloaded from the official Porter mixin feed at https://cdn.porter.sh/mixins/atom.xml. To download from a mirror, set the environment variable PORTER_MIRROR, or mirror in the Porter config file, with the value to replace https://cdn.porter.sh with.
+
+Use --from-lock to install the exact versions of the mixins pinned in porter.lock, which is generated by porter build. The installation fails, and the previously installed mixin is restored, when the checksum of an installed mixin does not match the lock file.
+
+The mixin runtime is always installed for linux/amd64. Use --platform to also install the runtime for other platforms, which is required to build a bundle for those platforms with porter build --platform.`,
 		Example: `  porter mixin install helm3 --feed-url https://mchorfa.github.io/porter-helm3/atom.xml
   porter mixin install azure --version v0.4.0-ralpha.1+dubonnet --url https://cdn.porter.sh/mixins/azure
-  porter mixin install kubernetes --version canary --url https://cdn.porter.sh/mixins/kubernetes`,
+  porter mixin install kubernetes --version canary --url https://cdn.porter.sh/mixins/kubernetes
+  porter mixin install --from-lock
+  porter mixin install --from-lock --lock-file path/to/porter.lock
+  porter mixin install exec --platform linux/arm64`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(args)
 		},
@@ -107,6 +114,12 @@ By default mixins are downloaded from the official Porter mixin feed at https://
 		"URL of an atom feed where the mixin can be downloaded. Defaults to the official Porter mixin feed.")
 	flags.StringVar(&opts.Mirror, "mirror", pkgmgmt.DefaultPackageMirror,
 		"Mirror of official Porter assets")
+	flags.BoolVar(&opts.FromLock, "from-lock", false,
+		"Install the mixins pinned in the lock file instead of a single mixin.")
+	flags.StringVar(&opts.LockFile, "lock-file", mixin.LockFileName,
+		"Path to the lock file used with --from-lock.")
+	flags.StringSliceVar(&opts.RuntimePlatforms, "platform", nil,
+		"Additional platforms, such as linux/arm64, to install the mixin runtime for. May be specified multiple times.")
 	return cmd
 }
 
@@ -20,6 +20,7 @@ func buildParametersCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildParametersDeleteCommand(p))
 	cmd.AddCommand(buildParametersShowCommand(p))
 	cmd.AddCommand(buildParametersCreateCommand(p))
+	cmd.AddCommand(buildParametersValidateCommand(p))
 
 	return cmd
 }
@@ -34,6 +35,8 @@ func buildParametersApplyCommand(p *porter.Porter) *cobra.Command {
 
 Supported file extensions: json and yaml.
 
+Files encrypted with SOPS using an age key are decrypted in memory, using the age identities in the SOPS_AGE_KEY environment variable or the key file configured with sops-age-key-file. The hard-coded values from an encrypted file are saved in the secret store instead of in Porter's database.
+
 You can use the generate and show commands to create the initial file:
   porter parameters generate myparams --reference SOME_BUNDLE
   porter parameters show myparams --output yaml > myparams.yaml
@@ -240,3 +243,38 @@ func buildParametersCreateCommand(p *porter.Porter) *cobra.Command {
 
 	return cmd
 }
+
+func buildParametersValidateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ParameterValidateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate [NAME]",
+		Short: "Validate that a parameter set can be resolved",
+		Long: `Validate that every parameter in a parameter set can be resolved from its source, without running a bundle.
+
+Each source is resolved using the configured secrets plugin, or from the host for the env, path, command and value sources, and any that cannot be resolved are reported with the reason. The resolved values are never printed.
+
+Use --installation to validate every parameter set used by an installation, including the parameters that were specified directly on the installation.
+
+The command exits with a non-zero exit code when any parameter cannot be resolved.`,
+		Example: `  porter parameters validate myparams
+  porter parameters validate myparams --namespace dev --output json
+  porter parameters validate --installation wordpress --namespace dev`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintParametersValidation(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Namespace, "namespace", "n", "",
+		"Namespace in which the parameter set, or the installation, is defined. Defaults to the global namespace.")
+	f.StringVarP(&opts.Installation, "installation", "i", "",
+		"Validate the parameter sets used by the installation and the parameters specified on the installation.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "plaintext",
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
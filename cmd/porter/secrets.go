@@ -0,0 +1,69 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildSecretsCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Secret commands",
+		Long:  "Commands for working with the secrets that Porter saves in the configured secrets plugin.",
+		Annotations: map[string]string{
+			"group": "resource",
+		},
+	}
+
+	cmd.AddCommand(buildSecretsGCCommand(p))
+	cmd.AddCommand(buildSecretsRekeyCommand(p))
+
+	return cmd
+}
+
+func buildSecretsGCCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.SecretsGCOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove orphaned secrets",
+		Long: `Remove the secrets that Porter saved for sensitive parameters and outputs, and for the values of encrypted credential and parameter set files, when the installation, run, credential set or parameter set that owns them no longer exists.
+
+Porter records the owner of each secret that it creates, and removes these secrets when the owner is deleted or runs are pruned. Use this command to clean up secrets that were left behind, for example when the secrets plugin could not remove them.
+Only the secrets recorded by Porter are removed. Secrets that were not created by Porter, or that were created by a version of Porter that did not record them, are never removed. Secrets created in the last hour are not removed, since they may belong to a run that is still being created.
+
+The configured secrets plugin must support deleting secrets.`,
+		Example: `  porter secrets gc
+  porter secrets gc --dry-run
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintGarbageCollectedSecrets(cmd.Context(), opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.DryRun, "dry-run", false,
+		"List the secrets that would be removed without removing them.")
+
+	return cmd
+}
+
+func buildSecretsRekeyCommand(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Re-encrypt secrets with the current key",
+		Long: `Rewrite every secret in the configured secrets plugin, so that they are encrypted with the key currently configured for the plugin.
+
+Use this command after enabling encryption for the filesystem secrets plugin to encrypt the secrets that were saved as plaintext.
+To rotate the key, move the key-file or passphrase setting of the plugin to previous-key-file or previous-passphrase, configure the new key, and then run this command. Once the command completes, the previous key can be removed from the configuration.
+
+The configured secrets plugin must support listing secrets. The command can be safely run again if it fails part way through.`,
+		Example: `  porter secrets rekey
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintRekeyedSecrets(cmd.Context())
+		},
+	}
+
+	return cmd
+}
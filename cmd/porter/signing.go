@@ -0,0 +1,56 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildSigningCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signing",
+		Short: "Signing commands",
+		Long:  "Commands for managing the keys used to sign and verify bundles.",
+		Annotations: map[string]string{
+			"group": "resource",
+		},
+	}
+
+	cmd.AddCommand(buildSigningKeygenCommand(p))
+
+	return cmd
+}
+
+func buildSigningKeygenCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.KeygenOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate a signing key pair",
+		Long: `Generate an ed25519 or ECDSA key pair for the keypair signer, which signs and verifies bundles without any external tools.
+
+The private key is written in PEM encoded PKCS #8 form and is only readable by the current user. The public key is written in PEM encoded PKIX form, and can be distributed to anyone that verifies your bundles.
+After the keys are generated, the configuration for a signer that uses them is printed.`,
+		Example: `  porter signing keygen
+  porter signing keygen --algorithm ecdsa
+  porter signing keygen --private-key ~/.porter/release.key --public-key ~/.porter/release.pub
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.GenerateSigningKeys(cmd.Context(), opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Algorithm, "algorithm", "ed25519",
+		"Algorithm of the generated key pair. Allowed values are: ed25519, ecdsa.")
+	flags.StringVar(&opts.PrivateKey, "private-key", porter.DefaultSigningPrivateKey,
+		"Path where the private key is written.")
+	flags.StringVar(&opts.PublicKey, "public-key", porter.DefaultSigningPublicKey,
+		"Path where the public key is written.")
+	flags.BoolVar(&opts.Force, "force", false,
+		"Overwrite existing key files.")
+
+	return cmd
+}
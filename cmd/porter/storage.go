@@ -18,6 +18,8 @@ func buildStorageCommand(p *porter.Porter) *cobra.Command {
 
 	cmd.AddCommand(buildStorageMigrateCommand(p))
 	cmd.AddCommand(buildStorageFixPermissionsCommand(p))
+	cmd.AddCommand(buildStorageExportCommand(p))
+	cmd.AddCommand(buildStorageImportCommand(p))
 
 	return &cmd
 }
@@ -72,3 +74,65 @@ func buildStorageFixPermissionsCommand(p *porter.Porter) *cobra.Command {
 		},
 	}
 }
+
+func buildStorageExportCommand(p *porter.Porter) *cobra.Command {
+	var opts porter.ExportStorageOptions
+	cmd := &cobra.Command{
+		Use:   "export FILE [--namespace NAMESPACE]",
+		Short: "Export Porter's data to an archive",
+		Long: `Export Porter's installations, runs, results, outputs, credential sets and parameter sets to a gzipped tar archive.
+
+The archive can be used to back up Porter's data, or to move it to another storage plugin with porter storage import.
+
+Sensitive parameter and output values are saved by Porter in its secret store, and only references to them are exported, never the values.
+Use porter storage import --secrets-from to copy the values to a different secret store when the archive is imported.
+Credential and parameter sets in the global namespace are always exported, because installations in any namespace may use them.`,
+		Example: `  porter storage export porter-backup.tgz
+  porter storage export porter-backup.tgz --namespace dev --namespace staging
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ExportStorage(cmd.Context(), opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringArrayVarP(&opts.Namespaces, "namespace", "n", nil,
+		"Only export documents defined in the namespace. May be specified multiple times. Use an empty string to select the global namespace. Defaults to all namespaces.")
+	return cmd
+}
+
+func buildStorageImportCommand(p *porter.Porter) *cobra.Command {
+	var opts porter.ImportStorageOptions
+	cmd := &cobra.Command{
+		Use:   "import FILE [--namespace NAMESPACE] [--secrets-from NAME]",
+		Short: "Import Porter's data from an archive",
+		Long: `Import an archive created by porter storage export into the configured storage plugin.
+
+Documents in the archive replace existing documents with the same name or id.
+
+The archive only contains references to the sensitive parameter and output values that Porter saved in its secret store. By default the values must already exist in the configured secret store.
+Use --secrets-from with the name of a secrets plugin defined in the config file, such as the secret store used by the Porter that exported the archive, to resolve the values from that secret store and save them with the configured secrets plugin.
+
+The archive must have been exported by a version of Porter that uses the same storage schema. To import data from an older version of Porter, import it with that version and then run porter storage migrate.`,
+		Example: `  porter storage import porter-backup.tgz
+  porter storage import porter-backup.tgz --namespace dev
+  porter storage import porter-backup.tgz --secrets-from old-vault
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ImportStorage(cmd.Context(), opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringArrayVarP(&opts.Namespaces, "namespace", "n", nil,
+		"Only import documents defined in the namespace. May be specified multiple times. Use an empty string to select the global namespace. Defaults to all namespaces in the archive.")
+	flags.StringVar(&opts.SecretsFrom, "secrets-from", "",
+		"Name of a secrets plugin defined in the config file to resolve the sensitive values referenced by the archive from. The values are saved with the configured secrets plugin.")
+	return cmd
+}
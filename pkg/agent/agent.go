@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
 )
 
 // allow the tests to capture output
@@ -78,6 +80,11 @@ func Execute(porterCommand []string, porterHome string, porterConfig string) (er
 	// Run the specified porter command
 	fmt.Fprintf(Stderr, "porter %s\n", strings.Join(porterCommand, " "))
 	cmd = exec.Command(porter, porterCommand...)
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv(config.EnvPorterClient); !ok {
+		// Record on the run that it was started by the operator
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", config.EnvPorterClient, storage.RunClientOperator))
+	}
 	cmd.Stdout = Stdout
 	cmd.Stderr = Stderr
 	cmd.Stdin = os.Stdin
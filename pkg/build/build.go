@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"github.com/opencontainers/go-digest"
 )
 
 var (
@@ -20,6 +22,15 @@ var (
 	// LOCAL_BUNDLE is the generated bundle.json file.
 	LOCAL_BUNDLE = filepath.Join(LOCAL_CNAB, "bundle.json")
 
+	// LOCAL_SBOM is the generated SBOM that describes the bundle image.
+	LOCAL_SBOM = filepath.Join(LOCAL_CNAB, "sbom.json")
+
+	// LOCAL_BUILD_RECORD is the generated record of how the bundle image was built.
+	LOCAL_BUILD_RECORD = filepath.Join(LOCAL_CNAB, "build.json")
+
+	// LOCAL_IMAGE_LAYOUT is the OCI image layout where the oci build driver saves the bundle image.
+	LOCAL_IMAGE_LAYOUT = filepath.Join(LOCAL_CNAB, "image")
+
 	// LOCAL_RUN is the path to the generated CNAB entrypoint script, located at /cnab/app/run.
 	LOCAL_RUN = filepath.Join(LOCAL_APP, "run")
 
@@ -30,6 +41,10 @@ var (
 	// LOCAL_MIXINS is the path where Porter stages the /cnab/app/mixins directory.
 	LOCAL_MIXINS = filepath.Join(LOCAL_APP, "mixins")
 
+	// PLATFORMS_CONTEXT is the name of the build context that contains the runtimes
+	// for each platform of a multi-platform bundle image.
+	PLATFORMS_CONTEXT = "porter-platforms"
+
 	// BUNDLE_DIR is the directory where the bundle is located in the CNAB execution environment.
 	BUNDLE_DIR = "/cnab/app"
 
@@ -50,6 +65,17 @@ type Builder interface {
 	TagBundleImage(ctx context.Context, origTag, newTag string) error
 }
 
+// ImageStore is implemented by a Builder that stores the bundle images that it
+// builds itself, instead of in the local Docker image store.
+type ImageStore interface {
+	// HasBundleImage checks if the bundle image has been built.
+	HasBundleImage(ctx context.Context, image string) (bool, error)
+
+	// PushBundleImage pushes the bundle image to its registry, returning the
+	// digest of the pushed image.
+	PushBundleImage(ctx context.Context, image string, insecureRegistry bool) (digest.Digest, error)
+}
+
 // BuildImageOptions represents some flags exposed by docker.
 type BuildImageOptions struct {
 	// SSH is the set of docker build --ssh flags specified.
@@ -66,4 +92,31 @@ type BuildImageOptions struct {
 
 	// NoCache is the docker build --no-cache flag specified.
 	NoCache bool
+
+	// CacheFrom is the set of docker build --cache-from sources to import the build cache from.
+	CacheFrom []string
+
+	// CacheTo is the set of docker build --cache-to destinations to export the build cache to.
+	CacheTo []string
+
+	// Platforms is the set of platforms specified with --platform, which
+	// override the platforms defined in porter.yaml.
+	Platforms []string
+}
+
+// GetPlatforms returns the platforms that the bundle image is built for,
+// defaulting to linux/amd64 when the manifest does not define any platforms.
+func GetPlatforms(m *manifest.Manifest) []string {
+	if len(m.Platforms) == 0 {
+		return []string{pkgmgmt.DefaultRuntimePlatform}
+	}
+	return m.Platforms
+}
+
+// RequiresPlatformRuntimes returns true when the bundle image is built for a
+// platform other than linux/amd64, and the runtimes for each platform must be
+// copied into the image instead of the runtimes that are installed by default.
+func RequiresPlatformRuntimes(m *manifest.Manifest) bool {
+	platforms := GetPlatforms(m)
+	return len(platforms) > 1 || platforms[0] != pkgmgmt.DefaultRuntimePlatform
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/docker/buildx/util/buildflags"
 	"github.com/docker/buildx/util/confutil"
 	"github.com/docker/buildx/util/dockerutil"
+	"github.com/docker/buildx/util/platformutil"
 	"github.com/docker/buildx/util/progress"
 	dockerconfig "github.com/docker/cli/cli/config"
 	"github.com/moby/buildkit/session"
@@ -114,7 +115,7 @@ func (b *Builder) BuildBundleImage(ctx context.Context, manifest *manifest.Manif
 
 	currentSession = append(currentSession, pbsecrets)
 
-	args, err := b.determineBuildArgs(ctx, manifest, opts)
+	args, err := b.DetermineBuildArgs(ctx, manifest, opts)
 	if err != nil {
 		return err
 	}
@@ -125,9 +126,26 @@ func (b *Builder) BuildBundleImage(ctx context.Context, manifest *manifest.Manif
 		return span.Errorf("error parsing the --build-context flags: %w", err)
 	}
 
+	cacheFrom, err := buildflags.ParseCacheEntry(opts.CacheFrom)
+	if err != nil {
+		return span.Errorf("error parsing the --cache-from flags: %w", err)
+	}
+	cacheTo, err := buildflags.ParseCacheEntry(opts.CacheTo)
+	if err != nil {
+		return span.Errorf("error parsing the --cache-to flags: %w", err)
+	}
+	span.SetAttributes(attribute.StringSlice("cache-from", opts.CacheFrom), attribute.StringSlice("cache-to", opts.CacheTo))
+
+	platforms, err := platformutil.Parse(build.GetPlatforms(manifest))
+	if err != nil {
+		return span.Errorf("error parsing the platforms to build: %w", err)
+	}
+	span.SetAttributes(attribute.StringSlice("platforms", build.GetPlatforms(manifest)))
+
 	buildxOpts := map[string]buildx.Options{
 		"default": {
-			Tags: []string{manifest.Image},
+			Tags:      []string{manifest.Image},
+			Platforms: platforms,
 			Inputs: buildx.Inputs{
 				ContextPath:    b.Getwd(),
 				DockerfilePath: b.getDockerfilePath(),
@@ -137,6 +155,8 @@ func (b *Builder) BuildBundleImage(ctx context.Context, manifest *manifest.Manif
 			BuildArgs: args,
 			Session:   currentSession,
 			NoCache:   opts.NoCache,
+			CacheFrom: pb.CreateCaches(cacheFrom),
+			CacheTo:   pb.CreateCaches(cacheTo),
 		},
 	}
 
@@ -146,7 +166,8 @@ func (b *Builder) BuildBundleImage(ctx context.Context, manifest *manifest.Manif
 		return span.Error(err)
 	}
 
-	_, buildErr := buildx.Build(ctx, nodes, buildxOpts, dockerutil.NewClient(cli), confutil.NewConfig(cli), printer)
+	stats := newCacheStatsWriter(printer)
+	_, buildErr := buildx.Build(ctx, nodes, buildxOpts, dockerutil.NewClient(cli), confutil.NewConfig(cli), stats)
 	printErr := printer.Wait()
 
 	if buildErr == nil && printErr != nil {
@@ -157,6 +178,10 @@ func (b *Builder) BuildBundleImage(ctx context.Context, manifest *manifest.Manif
 		return span.Errorf("error building docker image: %w", buildErr)
 	}
 
+	cached, total := stats.Stats()
+	span.SetAttributes(attribute.Int("cached-steps", cached), attribute.Int("total-steps", total))
+	span.Info(stats.String())
+
 	return nil
 }
 
@@ -164,7 +189,9 @@ func (b *Builder) getDockerfilePath() string {
 	return filepath.Join(b.Getwd(), build.DOCKER_FILE)
 }
 
-func (b *Builder) determineBuildArgs(
+// DetermineBuildArgs returns the build arguments for the Dockerfile: the custom values
+// from porter.yaml that are used by the Dockerfile, --build-arg, and the arguments defined by Porter.
+func (b *Builder) DetermineBuildArgs(
 	ctx context.Context,
 	manifest *manifest.Manifest,
 	opts build.BuildImageOptions) (map[string]string, error) {
@@ -205,7 +205,7 @@ CMD ["echo", "stuff"]
 //go:embed testdata/max-arg.txt
 var maxArg string
 
-func TestBuilder_determineBuildArgs(t *testing.T) {
+func TestBuilder_DetermineBuildArgs(t *testing.T) {
 	// This value goes over the limit of arg size
 	oversizedArg := maxArg + "oopstoobig"
 
@@ -226,20 +226,20 @@ func TestBuilder_determineBuildArgs(t *testing.T) {
 	opts := build.BuildImageOptions{
 		BuildArgs: []string{"BIG_BUILD_ARG=" + oversizedArg},
 	}
-	_, err := b.determineBuildArgs(ctx, m, opts)
+	_, err := b.DetermineBuildArgs(ctx, m, opts)
 	assert.ErrorContains(t, err, "BIG_BUILD_ARG is longer than the max")
 
 	// Make the --build-arg the max length, so it passes
 	// Try making a too big custom value in porter.yaml and using it in the Dockerfile so that it still fails
 	opts.BuildArgs = []string{"BIG_BUILD_ARG=" + maxArg}
 	c.TestContext.AddTestFile("testdata/custom-build-arg.Dockerfile", "/.cnab/Dockerfile")
-	_, err = b.determineBuildArgs(ctx, m, opts)
+	_, err = b.DetermineBuildArgs(ctx, m, opts)
 	require.ErrorContains(t, err, "CUSTOM_BIG_LABEL is longer than the max")
 
 	// Get everything to pass by making all big args the max length
 	m.Custom["BIG_LABEL"] = maxArg
-	args, err := b.determineBuildArgs(ctx, m, opts)
-	require.NoError(t, err, "determineBuildArgs should pass now that all args are at the max length")
+	args, err := b.DetermineBuildArgs(ctx, m, opts)
+	require.NoError(t, err, "DetermineBuildArgs should pass now that all args are at the max length")
 	wantArgs := map[string]string{
 		"BUNDLE_DIR":       "/cnab/app",
 		"BIG_BUILD_ARG":    maxArg,
@@ -0,0 +1,72 @@
+package buildkit
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/docker/buildx/util/progress"
+	"github.com/moby/buildkit/client"
+	"github.com/opencontainers/go-digest"
+)
+
+// dockerfileStepRegex matches the name of a vertex that was created by a
+// Dockerfile instruction, such as "[2/7] COPY . /cnab/app" or "[linux/arm64 2/7] COPY . /cnab/app".
+var dockerfileStepRegex = regexp.MustCompile(`^\[(.+ )?\d+/\d+\] `)
+
+var _ progress.Writer = &cacheStatsWriter{}
+
+// cacheStatsWriter counts the Dockerfile steps that were loaded from the build
+// cache while passing the build status through to the progress printer.
+type cacheStatsWriter struct {
+	progress.Writer
+
+	mu    sync.Mutex
+	steps map[digest.Digest]bool
+}
+
+func newCacheStatsWriter(w progress.Writer) *cacheStatsWriter {
+	return &cacheStatsWriter{
+		Writer: w,
+		steps:  make(map[digest.Digest]bool),
+	}
+}
+
+func (w *cacheStatsWriter) Write(status *client.SolveStatus) {
+	w.record(status)
+	w.Writer.Write(status)
+}
+
+// record the steps that completed, and if they were cached.
+func (w *cacheStatsWriter) record(status *client.SolveStatus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, v := range status.Vertexes {
+		if v.Completed == nil || v.Error != "" || !dockerfileStepRegex.MatchString(v.Name) {
+			continue
+		}
+		w.steps[v.Digest] = w.steps[v.Digest] || v.Cached
+	}
+}
+
+// Stats returns the number of steps that were cached, and the total number of steps that were completed.
+func (w *cacheStatsWriter) Stats() (cached int, total int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, isCached := range w.steps {
+		if isCached {
+			cached++
+		}
+	}
+	return cached, len(w.steps)
+}
+
+func (w *cacheStatsWriter) String() string {
+	cached, total := w.Stats()
+	if total == 0 {
+		return "Build cache: no build steps were run"
+	}
+	return fmt.Sprintf("Build cache: %d of %d build steps were cached (%d%%)", cached, total, cached*100/total)
+}
@@ -0,0 +1,45 @@
+package buildkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStatsWriter(t *testing.T) {
+	now := time.Now()
+	vertex := func(name string, completed bool, cached bool) *client.Vertex {
+		v := &client.Vertex{
+			Digest: digest.FromString(name),
+			Name:   name,
+			Cached: cached,
+		}
+		if completed {
+			v.Completed = &now
+		}
+		return v
+	}
+
+	w := newCacheStatsWriter(nil)
+	assert.Equal(t, "Build cache: no build steps were run", w.String())
+
+	w.record(&client.SolveStatus{Vertexes: []*client.Vertex{
+		vertex("[internal] load build definition from Dockerfile", true, false),
+		vertex("[1/4] FROM docker.io/library/debian:stable-slim", true, true),
+		vertex("[2/4] RUN useradd porter -m", false, false),
+		vertex("[linux/arm64 3/4] COPY .cnab /cnab", true, false),
+	}})
+	// The same step is reported again when it completes
+	w.record(&client.SolveStatus{Vertexes: []*client.Vertex{
+		vertex("[2/4] RUN useradd porter -m", true, true),
+		vertex("[4/4] WORKDIR /cnab/app", true, false),
+	}})
+
+	cached, total := w.Stats()
+	assert.Equal(t, 2, cached, "cached steps")
+	assert.Equal(t, 4, total, "total steps, excluding internal steps")
+	assert.Equal(t, "Build cache: 2 of 4 build steps were cached (50%)", w.String())
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -32,6 +33,11 @@ type DockerfileGenerator struct {
 	*manifest.Manifest
 	*templates.Templates
 	Mixins pkgmgmt.PackageManager
+
+	// PlatformRuntimesDir is the directory where PrepareFilesystem staged the
+	// runtimes for each platform of a multi-platform bundle image. It is
+	// passed to the build as the PLATFORMS_CONTEXT build context.
+	PlatformRuntimesDir string
 }
 
 func NewDockerfileGenerator(config *config.Config, m *manifest.Manifest, tmpl *templates.Templates, mp pkgmgmt.PackageManager) *DockerfileGenerator {
@@ -63,6 +69,10 @@ func (g *DockerfileGenerator) GenerateDockerFile(ctx context.Context) error {
 		}
 	}
 
+	if err = g.validateBaseImagePlatform(lines); err != nil {
+		return span.Error(err)
+	}
+
 	contents := strings.Join(lines, "\n")
 
 	// Output the generated dockerfile
@@ -76,6 +86,32 @@ func (g *DockerfileGenerator) GenerateDockerFile(ctx context.Context) error {
 	return nil
 }
 
+// validateBaseImagePlatform checks that the final stage of the Dockerfile, which
+// is the bundle image, does not pin a platform that the bundle is not built for.
+func (g *DockerfileGenerator) validateBaseImagePlatform(lines []string) error {
+	var pinned string
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		pinned = ""
+		if platform, ok := strings.CutPrefix(fields[1], "--platform="); ok && !strings.HasPrefix(platform, "$") {
+			pinned = platform
+		}
+	}
+	if pinned == "" {
+		return nil
+	}
+
+	for _, platform := range GetPlatforms(g.Manifest) {
+		if !strings.EqualFold(platform, pinned) {
+			return fmt.Errorf("the bundle image is built for %s but the base image in the Dockerfile is pinned to %s with FROM --platform. Remove --platform from the FROM instruction, or use --platform=$TARGETPLATFORM", strings.Join(GetPlatforms(g.Manifest), ", "), pinned)
+		}
+	}
+	return nil
+}
+
 func (g *DockerfileGenerator) readRawDockerfile(ctx context.Context) ([]string, error) {
 	if g.Manifest.Dockerfile == "" {
 		return nil, errors.New("no Dockerfile specified in the manifest")
@@ -185,22 +221,35 @@ func (g *DockerfileGenerator) buildPorterSection() []string {
 
 func (g *DockerfileGenerator) buildCNABSection() []string {
 	copyCNAB := "COPY .cnab /cnab"
+	copyPlatformRuntimes := fmt.Sprintf("COPY --from=%s ${TARGETOS}-${TARGETARCH}/ /cnab/", PLATFORMS_CONTEXT)
 	if g.GetBuildDriver() == config.BuildDriverBuildkit {
 		copyCNAB = "COPY --link .cnab /cnab"
+		copyPlatformRuntimes = fmt.Sprintf("COPY --link --from=%s ${TARGETOS}-${TARGETARCH}/ /cnab/", PLATFORMS_CONTEXT)
 	}
 
-	return []string{
+	lines := []string{
 		// Putting RUN before COPY here as a workaround for https://github.com/moby/moby/issues/37965, back to back COPY statements in the same directory (e.g. /cnab) _may_ result in an error from Docker depending on unpredictable factors
 		`RUN rm -fr ${BUNDLE_DIR}/.cnab`,
 		// Copy the non-user cnab files, like mixins and porter.yaml, from the local .cnab directory into the bundle
 		copyCNAB,
+	}
+	if RequiresPlatformRuntimes(g.Manifest) {
+		lines = append(lines,
+			// Replace the runtimes with the runtimes for the platform that is being built
+			"ARG TARGETOS",
+			"ARG TARGETARCH",
+			copyPlatformRuntimes,
+		)
+	}
+
+	return append(lines,
 		// Ensure that regardless of the container's UID, the root group (default group for arbitrary users that do not exist in the container) has the same permissions as the owner
 		// See https://developers.redhat.com/blog/2020/10/26/adapting-docker-and-kubernetes-containers-to-run-on-red-hat-openshift-container-platform#group_ownership_and_file_permission
 		`RUN chgrp -R ${BUNDLE_GID} /cnab && chmod -R g=u /cnab`,
 		// default to running as the nonroot user that the porter agent uses.
 		// When running in kubernetes, if you specify a different UID, make sure to set fsGroup to the same UID, and runasGroup to 0
 		`USER ${BUNDLE_UID}`,
-	}
+	)
 }
 
 func (g *DockerfileGenerator) buildWORKDIRSection() string {
@@ -274,9 +323,97 @@ func (g *DockerfileGenerator) PrepareFilesystem() error {
 		}
 	}
 
+	// Only the runtimes for the default platform are copied from .cnab into the image
+	if err = g.removePlatformRuntimes(); err != nil {
+		return err
+	}
+
+	if RequiresPlatformRuntimes(g.Manifest) {
+		return g.stagePlatformRuntimes(homeDir)
+	}
+	return nil
+}
+
+// removePlatformRuntimes removes the runtimes installed for additional platforms, such
+// as porter-runtime-linux-arm64, from the copies of the porter and mixin directories in .cnab.
+func (g *DockerfileGenerator) removePlatformRuntimes() error {
+	runtimeDirs := map[string]string{"porter": filepath.Join(LOCAL_APP, "runtimes")}
+	for _, m := range g.Manifest.Mixins {
+		runtimeDirs[m.Name] = filepath.Join(LOCAL_MIXINS, m.Name, "runtimes")
+	}
+
+	for name, dir := range runtimeDirs {
+		entries, err := g.FileSystem.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("error reading %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), pkgmgmt.RuntimeFileName(name, pkgmgmt.DefaultRuntimePlatform)+"-") {
+				if err = g.FileSystem.Remove(filepath.Join(dir, entry.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// stagePlatformRuntimes copies the porter and mixin runtimes for each platform of the
+// bundle image into a temporary directory, in the layout PLATFORM/app/..., so that the
+// Dockerfile can copy the runtimes for the target platform into /cnab.
+func (g *DockerfileGenerator) stagePlatformRuntimes(homeDir string) error {
+	dir, err := g.FileSystem.TempDir("", "porter-platforms")
+	if err != nil {
+		return fmt.Errorf("error creating a temporary directory for the platform runtimes: %w", err)
+	}
+	g.PlatformRuntimesDir = dir
+
+	for _, platform := range GetPlatforms(g.Manifest) {
+		fmt.Fprintf(g.Out, "Copying runtimes for %s ===> \n", platform)
+		platformDir := filepath.Join(dir, strings.ReplaceAll(platform, "/", "-"), "app")
+
+		src := filepath.Join(homeDir, "runtimes", pkgmgmt.RuntimeFileName("porter", platform))
+		dest := filepath.Join(platformDir, "runtimes", pkgmgmt.RuntimeFileName("porter", pkgmgmt.DefaultRuntimePlatform))
+		if err = g.copyRuntime(src, dest); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("the porter runtime for %s is not installed. Download the porter binary for %s from the release of porter %s and save it to %s", platform, platform, pkg.Version, src)
+			}
+			return err
+		}
+
+		for _, m := range g.Manifest.Mixins {
+			mixinDir, err := g.Mixins.GetPackageDir(m.Name)
+			if err != nil {
+				return err
+			}
+			src := filepath.Join(mixinDir, "runtimes", pkgmgmt.RuntimeFileName(m.Name, platform))
+			dest := filepath.Join(platformDir, "mixins", m.Name, "runtimes", pkgmgmt.RuntimeFileName(m.Name, pkgmgmt.DefaultRuntimePlatform))
+			if err = g.copyRuntime(src, dest); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("the %s mixin runtime for %s is not installed, install it with porter mixins install %s --platform %s", m.Name, platform, m.Name, platform)
+				}
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+func (g *DockerfileGenerator) copyRuntime(src string, dest string) error {
+	if err := g.FileSystem.MkdirAll(filepath.Dir(dest), pkg.FileModeDirectory); err != nil {
+		return err
+	}
+	if err := g.Context.CopyFile(src, dest); err != nil {
+		return err
+	}
+	// Runtimes are executed in the bundle image, regardless of how the file was installed
+	return g.FileSystem.Chmod(dest, pkg.FileModeExecutable)
+}
+
 func (g *DockerfileGenerator) copyMixin(mixin string) error {
 	fmt.Fprintf(g.Out, "Copying mixin %s ===> \n", mixin)
 	mixinDir, err := g.Mixins.GetPackageDir(mixin)
@@ -3,9 +3,11 @@ package build
 import (
 	"context"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/experimental"
 	"get.porter.sh/porter/pkg/manifest"
@@ -211,6 +213,137 @@ func TestPorter_prepareDockerFilesystem(t *testing.T) {
 	assert.True(t, execMixinExists, "The exec-runtime mixin wasn't copied into %s", wantExecMixin)
 }
 
+func TestPorter_buildDockerfile_Platforms(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.Data.BuildDriver = config.BuildDriverBuildkit
+	tmpl := templates.NewTemplates(c.Config)
+	configTpl, err := tmpl.GetManifest()
+	require.Nil(t, err)
+	require.NoError(t, c.TestContext.AddTestFileContents(configTpl, config.Name))
+
+	m, err := manifest.LoadManifestFrom(context.Background(), c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+	m.Platforms = []string{"linux/amd64", "linux/arm64"}
+
+	mp := mixin.NewTestMixinProvider()
+	g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+	gotlines, err := g.buildDockerfile(context.Background())
+	require.NoError(t, err)
+
+	copyIndex := slices.Index(gotlines, "COPY --link .cnab /cnab")
+	require.NotEqual(t, -1, copyIndex, "the .cnab directory should be copied into the image")
+	wantLines := []string{
+		"ARG TARGETOS",
+		"ARG TARGETARCH",
+		"COPY --link --from=porter-platforms ${TARGETOS}-${TARGETARCH}/ /cnab/",
+	}
+	assert.Equal(t, wantLines, gotlines[copyIndex+1:copyIndex+4], "the runtimes for the target platform should be copied after the .cnab directory")
+}
+
+func TestPorter_generateDockerfile_PinnedPlatform(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name      string
+		from      string
+		platforms []string
+		wantErr   string
+	}{
+		{name: "default platform", from: "FROM --platform=linux/amd64 ubuntu:latest"},
+		{name: "target platform", from: "FROM --platform=$TARGETPLATFORM ubuntu:latest", platforms: []string{"linux/amd64", "linux/arm64"}},
+		{name: "pinned builder stage", from: "FROM --platform=linux/amd64 golang AS builder\nFROM ubuntu:latest", platforms: []string{"linux/arm64"}},
+		{name: "pinned base image", from: "FROM --platform=linux/amd64 ubuntu:latest", platforms: []string{"linux/amd64", "linux/arm64"},
+			wantErr: "the bundle image is built for linux/amd64, linux/arm64 but the base image in the Dockerfile is pinned to linux/amd64"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.NewTestConfig(t)
+			tmpl := templates.NewTemplates(c.Config)
+			configTpl, err := tmpl.GetManifest()
+			require.Nil(t, err)
+			require.NoError(t, c.TestContext.AddTestFileContents(configTpl, config.Name))
+			require.NoError(t, c.TestContext.AddTestFileContents([]byte(tc.from+"\n# PORTER_MIXINS\n"), "Dockerfile.tmpl"))
+
+			m, err := manifest.LoadManifestFrom(context.Background(), c.Config, config.Name)
+			require.NoError(t, err, "could not load manifest")
+			m.Dockerfile = "Dockerfile.tmpl"
+			m.Platforms = tc.platforms
+
+			mp := mixin.NewTestMixinProvider()
+			g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+			err = g.GenerateDockerFile(context.Background())
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPorter_prepareDockerFilesystem_Platforms(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	tmpl := templates.NewTemplates(c.Config)
+	configTpl, err := tmpl.GetManifest()
+	require.Nil(t, err)
+	require.NoError(t, c.TestContext.AddTestFileContents(configTpl, config.Name))
+	for _, runtime := range []string{
+		"/home/myuser/.porter/runtimes/porter-runtime-linux-arm64",
+		"/home/myuser/.porter/mixins/exec/runtimes/exec-runtime-linux-arm64",
+	} {
+		require.NoError(t, c.FileSystem.WriteFile(runtime, []byte("arm64"), pkg.FileModeWritable))
+	}
+
+	m, err := manifest.LoadManifestFrom(context.Background(), c.Config, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	t.Run("default platform", func(t *testing.T) {
+		mp := mixin.NewTestMixinProvider()
+		g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+		require.NoError(t, g.PrepareFilesystem())
+
+		assert.Empty(t, g.PlatformRuntimesDir, "the runtimes should not be staged when only the default platform is built")
+		for _, runtime := range []string{
+			filepath.Join(LOCAL_APP, "runtimes", "porter-runtime-linux-arm64"),
+			filepath.Join(LOCAL_MIXINS, "exec", "runtimes", "exec-runtime-linux-arm64"),
+		} {
+			exists, err := c.FileSystem.Exists(runtime)
+			require.NoError(t, err)
+			assert.False(t, exists, "runtimes for other platforms should not be copied into %s", runtime)
+		}
+	})
+
+	t.Run("multiple platforms", func(t *testing.T) {
+		m.Platforms = []string{"linux/amd64", "linux/arm64"}
+		mp := mixin.NewTestMixinProvider()
+		g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+		require.NoError(t, g.PrepareFilesystem())
+		require.NotEmpty(t, g.PlatformRuntimesDir)
+
+		arm64Runtime, err := c.FileSystem.ReadFile(filepath.Join(g.PlatformRuntimesDir, "linux-arm64/app/mixins/exec/runtimes/exec-runtime"))
+		require.NoError(t, err)
+		assert.Equal(t, "arm64", string(arm64Runtime), "the arm64 runtime should be staged with the default runtime name")
+
+		for _, runtime := range []string{
+			"linux-amd64/app/runtimes/porter-runtime",
+			"linux-amd64/app/mixins/exec/runtimes/exec-runtime",
+			"linux-arm64/app/runtimes/porter-runtime",
+		} {
+			info, err := c.FileSystem.Stat(filepath.Join(g.PlatformRuntimesDir, runtime))
+			require.NoError(t, err, "%s was not staged", runtime)
+			tests.AssertFilePermissionsEqual(t, runtime, pkg.FileModeExecutable, info.Mode())
+		}
+	})
+}
+
 func TestPorter_appendBuildInstructionsIfMixinTokenIsNotPresent(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,272 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/build/buildkit"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+	"github.com/opencontainers/go-digest"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	_ build.Builder    = &Builder{}
+	_ build.ImageStore = &Builder{}
+)
+
+// Builder assembles the bundle image without Docker, by appending layers to
+// the base image, and saves it to an OCI image layout in the .cnab directory.
+// The image is pushed directly from the layout when the bundle is published.
+type Builder struct {
+	*config.Config
+}
+
+func NewBuilder(cfg *config.Config) *Builder {
+	return &Builder{
+		Config: cfg,
+	}
+}
+
+func (b *Builder) BuildBundleImage(ctx context.Context, manifest *manifest.Manifest, opts build.BuildImageOptions) error {
+	ctx, span := tracing.StartSpan(ctx, attribute.String("image", manifest.Image))
+	defer span.EndSpan()
+
+	span.Info("Building bundle image")
+
+	if len(opts.SSH) > 0 {
+		return span.Errorf("--ssh is not supported by the oci build driver")
+	}
+	if len(opts.Secrets) > 0 {
+		return span.Errorf("--secret is not supported by the oci build driver")
+	}
+
+	if len(opts.CacheFrom) > 0 || len(opts.CacheTo) > 0 {
+		span.Warn("The oci build driver does not use a build cache, ignoring the build cache sources and destinations")
+	}
+
+	args, err := buildkit.NewBuilder(b.Config).DetermineBuildArgs(ctx, manifest, opts)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(tracing.ObjectAttribute("build-args", args))
+
+	contexts, err := parseBuildContexts(opts.BuildContexts)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	ignore, err := b.readDockerignore()
+	if err != nil {
+		return span.Error(err)
+	}
+
+	contents, err := b.FileSystem.ReadFile(build.DOCKER_FILE)
+	if err != nil {
+		return span.Errorf("error reading Dockerfile at %s: %w", build.DOCKER_FILE, err)
+	}
+	dockerfile, err := parseDockerfile(contents)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	platforms := build.GetPlatforms(manifest)
+	span.SetAttributes(attribute.StringSlice("platforms", platforms))
+
+	created := time.Now()
+	images := make([]v1.Image, 0, len(platforms))
+	for _, platform := range platforms {
+		p, err := v1.ParsePlatform(platform)
+		if err != nil {
+			return span.Errorf("invalid platform %s: %w", platform, err)
+		}
+
+		span.Debugf("Building the bundle image for %s", platform)
+		ib := &imageBuilder{
+			fs:         b.FileSystem,
+			platform:   *p,
+			buildArgs:  args,
+			contexts:   contexts,
+			ignore:     ignore,
+			remoteOpts: []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)},
+			created:    created,
+		}
+		img, err := ib.build(ctx, dockerfile)
+		if err != nil {
+			return span.Errorf("error building the bundle image for %s: %w", platform, err)
+		}
+		images = append(images, img)
+	}
+
+	layout, err := openLayout(b.layoutDir())
+	if err != nil {
+		return span.Error(err)
+	}
+	matchesName, named := refNameOptions(manifest.Image)
+	if len(images) == 1 {
+		err = layout.ReplaceImage(images[0], matchesName, named)
+	} else {
+		// A multi-platform bundle image is an image index with an image for each platform
+		var idx v1.ImageIndex = empty.Index
+		for i, img := range images {
+			p, _ := v1.ParsePlatform(platforms[i])
+			idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+				Add:        img,
+				Descriptor: v1.Descriptor{Platform: p},
+			})
+		}
+		err = layout.ReplaceIndex(idx, matchesName, named)
+	}
+	if err != nil {
+		return span.Errorf("error saving the bundle image to %s: %w", build.LOCAL_IMAGE_LAYOUT, err)
+	}
+
+	span.Infof("Saved the bundle image %s to the OCI layout in %s", manifest.Image, build.LOCAL_IMAGE_LAYOUT)
+	return nil
+}
+
+// parseBuildContexts parses the --build-context flags, which must be local directories.
+func parseBuildContexts(values []string) (map[string]string, error) {
+	contexts := make(map[string]string, len(values))
+	for _, value := range values {
+		contextName, dir, ok := strings.Cut(value, "=")
+		if !ok || contextName == "" || dir == "" {
+			return nil, fmt.Errorf("invalid --build-context %s, the build context must be in the form NAME=PATH", value)
+		}
+		if strings.Contains(dir, "://") {
+			return nil, fmt.Errorf("invalid --build-context %s, only local directories are supported by the oci build driver", value)
+		}
+		contexts[contextName] = dir
+	}
+	return contexts, nil
+}
+
+// readDockerignore reads the patterns in the .dockerignore file of the build context.
+func (b *Builder) readDockerignore() (*patternmatcher.PatternMatcher, error) {
+	f, err := b.FileSystem.Open(".dockerignore")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading .dockerignore: %w", err)
+	}
+	defer f.Close()
+
+	patterns, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading .dockerignore: %w", err)
+	}
+	return patternmatcher.New(patterns)
+}
+
+// layoutDir is the path to the OCI image layout that the bundle image is saved to.
+func (b *Builder) layoutDir() string {
+	return filepath.Join(b.Getwd(), build.LOCAL_IMAGE_LAYOUT)
+}
+
+func (b *Builder) TagBundleImage(ctx context.Context, origTag, newTag string) error {
+	_, log := tracing.StartSpan(ctx, attribute.String("source-tag", origTag), attribute.String("destination-tag", newTag))
+	defer log.EndSpan()
+
+	layout, desc, found, err := findInLayout(b.layoutDir(), origTag)
+	if err != nil {
+		return log.Error(err)
+	}
+	if !found {
+		return log.Errorf("could not tag image %s with value %s: the image is not in %s", origTag, newTag, build.LOCAL_IMAGE_LAYOUT)
+	}
+
+	idx, err := layout.ImageIndex()
+	if err != nil {
+		return log.Error(err)
+	}
+	matchesName, named := refNameOptions(newTag)
+	if desc.MediaType.IsIndex() {
+		var child v1.ImageIndex
+		if child, err = idx.ImageIndex(desc.Digest); err == nil {
+			err = layout.ReplaceIndex(child, matchesName, named)
+		}
+	} else {
+		var img v1.Image
+		if img, err = idx.Image(desc.Digest); err == nil {
+			err = layout.ReplaceImage(img, matchesName, named)
+		}
+	}
+	if err != nil {
+		return log.Errorf("could not tag image %s with value %s: %w", origTag, newTag, err)
+	}
+	return nil
+}
+
+// HasBundleImage checks if the bundle image is in the OCI layout.
+func (b *Builder) HasBundleImage(ctx context.Context, image string) (bool, error) {
+	_, _, found, err := findInLayout(b.layoutDir(), image)
+	return found, err
+}
+
+// PushBundleImage pushes the bundle image from the OCI layout to its registry.
+func (b *Builder) PushBundleImage(ctx context.Context, image string, insecureRegistry bool) (digest.Digest, error) {
+	ctx, log := tracing.StartSpan(ctx, attribute.String("image", image))
+	defer log.EndSpan()
+
+	var nameOpts []name.Option
+	remoteOpts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	if insecureRegistry {
+		nameOpts = append(nameOpts, name.Insecure)
+		remoteOpts = append(remoteOpts, remote.WithTransport(cnabtooci.GetInsecureRegistryTransport()))
+	}
+	ref, err := name.ParseReference(image, nameOpts...)
+	if err != nil {
+		return "", log.Errorf("invalid image reference %s: %w", image, err)
+	}
+
+	layout, desc, found, err := findInLayout(b.layoutDir(), image)
+	if err != nil {
+		return "", log.Error(err)
+	}
+	if !found {
+		return "", log.Errorf("the bundle image %s is not in %s, build the bundle again with porter build", image, b.layoutDir())
+	}
+	idx, err := layout.ImageIndex()
+	if err != nil {
+		return "", log.Error(err)
+	}
+
+	log.Info("Pushing bundle image...")
+	if desc.MediaType.IsIndex() {
+		var child v1.ImageIndex
+		if child, err = idx.ImageIndex(desc.Digest); err == nil {
+			err = remote.WriteIndex(ref, child, remoteOpts...)
+		}
+	} else {
+		var img v1.Image
+		if img, err = idx.Image(desc.Digest); err == nil {
+			err = remote.Write(ref, img, remoteOpts...)
+		}
+	}
+	if err != nil {
+		return "", log.Errorf("error pushing the bundle image %s: %w", image, err)
+	}
+
+	return digest.Digest(desc.Digest.String()), nil
+}
@@ -0,0 +1,317 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/manifest"
+	"github.com/carolynvs/aferox"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRegistry runs a registry for the test, returning its host.
+func startRegistry(t *testing.T) string {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// pushBaseImage pushes an image with a single layer that contains the files to the registry.
+func pushBaseImage(t *testing.T, image string, files map[string]string) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for filePath, contents := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: filePath, Mode: 0644, Size: int64(len(contents))}))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	layer, err := tarball.LayerFromReader(&buf, tarball.WithMediaType(types.OCILayer))
+	require.NoError(t, err)
+	base := mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	img, err := mutate.ConfigFile(base, &v1.ConfigFile{
+		OS:           "linux",
+		Architecture: "amd64",
+		Config:       v1.Config{Env: []string{"PATH=/usr/bin:/bin"}, Cmd: []string{"bash"}},
+		RootFS:       v1.RootFS{Type: "layers"},
+	})
+	require.NoError(t, err)
+	img, err = mutate.Append(img, mutate.Addendum{Layer: layer})
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(image)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+}
+
+// imageFile is a file in an image.
+type imageFile struct {
+	header   tar.Header
+	contents string
+}
+
+// readImageFiles returns the files in the flattened filesystem of the image.
+func readImageFiles(t *testing.T, img v1.Image) map[string]imageFile {
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	files := make(map[string]imageFile)
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		contents, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[strings.TrimSuffix(hdr.Name, "/")] = imageFile{header: *hdr, contents: string(contents)}
+	}
+}
+
+// newTestConfig creates a config with a temporary directory on the host
+// filesystem as the bundle directory, because the OCI layout is written to disk.
+func newTestConfig(t *testing.T) *config.TestConfig {
+	c := config.NewTestConfig(t)
+	c.FileSystem = aferox.NewAferox(t.TempDir(), afero.NewOsFs())
+	c.TestContext.DisableUmask()
+	return c
+}
+
+// setupBundle adds the files of a bundle, after it is prepared by porter build, to the build context.
+func setupBundle(t *testing.T, c *config.TestConfig, dockerfile string) {
+	require.NoError(t, c.FileSystem.MkdirAll(build.LOCAL_APP, pkg.FileModeDirectory))
+	c.TestContext.AddTestFile(dockerfile, build.DOCKER_FILE, pkg.FileModeWritable)
+	require.NoError(t, c.FileSystem.WriteFile("porter.yaml", []byte("name: mybuns"), pkg.FileModeWritable))
+	require.NoError(t, c.FileSystem.WriteFile("helpers.sh", []byte("echo hello"), pkg.FileModeExecutable))
+	require.NoError(t, c.FileSystem.WriteFile("README.md", []byte("# mybuns"), pkg.FileModeWritable))
+	require.NoError(t, c.FileSystem.WriteFile(".dockerignore", []byte("*.md\n"), pkg.FileModeWritable))
+	require.NoError(t, c.FileSystem.WriteFile(build.LOCAL_RUN, []byte("#!/usr/bin/env bash"), pkg.FileModeExecutable))
+	require.NoError(t, c.FileSystem.WriteFile(build.LOCAL_MANIFEST, []byte("name: mybuns\nversion: 0.1.0"), pkg.FileModeWritable))
+	require.NoError(t, c.FileSystem.WriteFile(build.LOCAL_BUNDLE, []byte("{}"), pkg.FileModeWritable))
+}
+
+func TestBuilder_BuildBundleImage(t *testing.T) {
+	ctx := context.Background()
+	host := startRegistry(t)
+	baseImage := host + "/base:v1"
+	pushBaseImage(t, baseImage, map[string]string{
+		"etc/passwd": "root:x:0:0:root:/root:/bin/bash\n",
+	})
+
+	c := newTestConfig(t)
+	setupBundle(t, c, "testdata/Dockerfile")
+	b := NewBuilder(c.Config)
+
+	m := &manifest.Manifest{Image: host + "/mybuns:porter-123"}
+	opts := build.BuildImageOptions{BuildArgs: []string{"BASE_IMAGE=" + baseImage}}
+	require.NoError(t, b.BuildBundleImage(ctx, m, opts))
+
+	exists, err := b.HasBundleImage(ctx, m.Image)
+	require.NoError(t, err)
+	require.True(t, exists, "the bundle image should be saved to the OCI layout")
+
+	idx, err := layout.ImageIndexFromPath(filepath.Join(c.Getwd(), build.LOCAL_IMAGE_LAYOUT))
+	require.NoError(t, err)
+	index, err := idx.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, index.Manifests, 1)
+	desc := index.Manifests[0]
+	assert.Equal(t, m.Image, desc.Annotations[ocispec.AnnotationRefName], "the image should be named with its reference")
+	img, err := idx.Image(desc.Digest)
+	require.NoError(t, err)
+
+	cfg, err := img.ConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, "65532", cfg.Config.User)
+	assert.Equal(t, "/cnab/app", cfg.Config.WorkingDir)
+	assert.Equal(t, []string{"/cnab/app/run"}, cfg.Config.Cmd)
+	assert.Equal(t, []string{"PATH=/usr/bin:/bin"}, cfg.Config.Env, "the environment of the base image should be kept")
+
+	files := readImageFiles(t, img)
+	assert.Contains(t, files["etc/passwd"].contents, "nonroot:x:65532:0::/home/nonroot:/bin/sh\n", "the bundle user should be added")
+	assert.Equal(t, 65532, files["home/nonroot"].header.Uid, "the home directory of the bundle user should be created")
+	assert.Equal(t, "echo hello", files["cnab/app/helpers.sh"].contents, "the bundle directory should be copied")
+	assert.NotContains(t, files, "cnab/app/README.md", "files excluded by .dockerignore should not be copied")
+	assert.Equal(t, "name: mybuns\nversion: 0.1.0", files["cnab/app/porter.yaml"].contents, "the user manifest should be replaced with the generated manifest")
+	assert.NotContains(t, files, "cnab/app/.cnab/bundle.json", "the .cnab directory should be removed from the bundle directory")
+	assert.Equal(t, "{}", files["cnab/bundle.json"].contents, "the .cnab directory should be copied to /cnab")
+
+	run := files["cnab/app/run"].header
+	assert.Equal(t, 0, run.Gid, "the group of /cnab should be changed")
+	assert.Equal(t, int64(0770), run.Mode&0777, "the group permissions of /cnab should be the same as the user permissions")
+	assert.Equal(t, int64(0775), files["cnab"].header.Mode&0777, "the permissions of /cnab should be changed")
+
+	t.Run("push", func(t *testing.T) {
+		publishedImage := host + "/mybuns:v1"
+		require.NoError(t, b.TagBundleImage(ctx, m.Image, publishedImage))
+		require.NoError(t, b.TagBundleImage(ctx, m.Image, publishedImage), "tagging an image again should replace the previous tag")
+
+		idx, err := layout.ImageIndexFromPath(filepath.Join(c.Getwd(), build.LOCAL_IMAGE_LAYOUT))
+		require.NoError(t, err)
+		index, err := idx.IndexManifest()
+		require.NoError(t, err)
+		require.Len(t, index.Manifests, 2, "the layout should have an entry for each tag")
+
+		digest, err := b.PushBundleImage(ctx, publishedImage, false)
+		require.NoError(t, err)
+		assert.Equal(t, desc.Digest.String(), digest.String(), "the digest of the pushed image should match the image in the layout")
+
+		ref, err := name.ParseReference(publishedImage)
+		require.NoError(t, err)
+		pushed, err := remote.Head(ref)
+		require.NoError(t, err)
+		assert.Equal(t, desc.Digest, pushed.Digest)
+	})
+
+	t.Run("push missing image", func(t *testing.T) {
+		_, err := b.PushBundleImage(ctx, host+"/missing:v1", false)
+		require.ErrorContains(t, err, "build the bundle again with porter build")
+	})
+}
+
+func TestBuilder_BuildBundleImage_Platforms(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConfig(t)
+	setupBundle(t, c, "testdata/platforms.Dockerfile")
+	platformsDir := t.TempDir()
+	for _, platform := range []string{"linux-amd64", "linux-arm64"} {
+		require.NoError(t, c.FileSystem.MkdirAll(filepath.Join(platformsDir, platform, "app/runtimes"), pkg.FileModeDirectory))
+	}
+	require.NoError(t, c.FileSystem.WriteFile(filepath.Join(platformsDir, "linux-amd64/app/runtimes/porter-runtime"), []byte("amd64"), pkg.FileModeExecutable))
+	require.NoError(t, c.FileSystem.WriteFile(filepath.Join(platformsDir, "linux-arm64/app/runtimes/porter-runtime"), []byte("arm64"), pkg.FileModeExecutable))
+	b := NewBuilder(c.Config)
+
+	m := &manifest.Manifest{
+		Image:     "example.com/mybuns:porter-123",
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+	}
+	opts := build.BuildImageOptions{BuildContexts: []string{build.PLATFORMS_CONTEXT + "=" + platformsDir}}
+	require.NoError(t, b.BuildBundleImage(ctx, m, opts))
+	require.NoError(t, b.BuildBundleImage(ctx, m, opts), "building the image again should replace the image in the layout")
+
+	p, err := layout.FromPath(filepath.Join(c.Getwd(), build.LOCAL_IMAGE_LAYOUT))
+	require.NoError(t, err)
+	layoutIndex, err := p.ImageIndex()
+	require.NoError(t, err)
+	index, err := layoutIndex.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, index.Manifests, 1)
+	desc := index.Manifests[0]
+	require.True(t, desc.MediaType.IsIndex(), "a multi-platform bundle image should be an image index")
+
+	idx, err := layoutIndex.ImageIndex(desc.Digest)
+	require.NoError(t, err)
+	manifest, err := idx.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, manifest.Manifests, 2)
+
+	for _, desc := range manifest.Manifests {
+		img, err := idx.Image(desc.Digest)
+		require.NoError(t, err)
+		cfg, err := img.ConfigFile()
+		require.NoError(t, err)
+		assert.Equal(t, desc.Platform.Architecture, cfg.Architecture)
+
+		files := readImageFiles(t, img)
+		assert.Equal(t, desc.Platform.Architecture, files["cnab/app/runtimes/porter-runtime"].contents, "the runtime for the platform should be copied")
+	}
+}
+
+func TestBuilder_BuildBundleImage_UnsupportedInstructions(t *testing.T) {
+	testcases := []struct {
+		name       string
+		dockerfile string
+		wantErr    string
+	}{
+		{name: "run command", dockerfile: "FROM scratch\nRUN apt-get update", wantErr: "only supports the RUN instructions generated by Porter"},
+		{name: "run with shell", dockerfile: "FROM scratch\nRUN rm -f /tmp/foo; echo done", wantErr: "only supports the RUN instructions generated by Porter"},
+		{name: "run mount", dockerfile: "FROM scratch\nRUN --mount=type=cache,target=/var/cache/apt rm -fr /tmp", wantErr: "only supports the RUN instructions generated by Porter"},
+		{name: "multi-stage", dockerfile: "FROM scratch AS builder\nFROM scratch", wantErr: "multi-stage builds are not supported"},
+		{name: "copy from image", dockerfile: "FROM scratch\nCOPY --from=alpine /bin/sh /bin/sh", wantErr: "only named build contexts can be copied from"},
+		{name: "add", dockerfile: "FROM scratch\nADD . /cnab/app", wantErr: "ADD is not supported by the oci build driver"},
+		{name: "missing from", dockerfile: "COPY . /cnab/app", wantErr: "the Dockerfile must start with FROM"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.NewTestConfig(t)
+			require.NoError(t, c.FileSystem.WriteFile(build.DOCKER_FILE, []byte(tc.dockerfile), pkg.FileModeWritable))
+			b := NewBuilder(c.Config)
+
+			err := b.BuildBundleImage(context.Background(), &manifest.Manifest{Image: "example.com/mybuns:v1"}, build.BuildImageOptions{})
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestBuilder_BuildBundleImage_UnsupportedOptions(t *testing.T) {
+	c := config.NewTestConfig(t)
+	b := NewBuilder(c.Config)
+	m := &manifest.Manifest{Image: "example.com/mybuns:v1"}
+
+	err := b.BuildBundleImage(context.Background(), m, build.BuildImageOptions{SSH: []string{"default"}})
+	require.ErrorContains(t, err, "--ssh is not supported by the oci build driver")
+
+	err = b.BuildBundleImage(context.Background(), m, build.BuildImageOptions{Secrets: []string{"id=token,src=token.txt"}})
+	require.ErrorContains(t, err, "--secret is not supported by the oci build driver")
+}
+
+func TestBuilder_TagBundleImage_Missing(t *testing.T) {
+	c := newTestConfig(t)
+	b := NewBuilder(c.Config)
+
+	err := b.TagBundleImage(context.Background(), "example.com/mybuns:v1", "example.com/mybuns:v2")
+	require.ErrorContains(t, err, "the image is not in .cnab/image")
+}
+
+func Test_parseMode(t *testing.T) {
+	testcases := []struct {
+		mode     string
+		existing int64
+		want     int64
+	}{
+		{mode: "g=u", existing: 0750, want: 0770},
+		{mode: "g=u", existing: 0644, want: 0664},
+		{mode: "755", existing: 0600, want: 0755},
+		{mode: "u+x", existing: 0644, want: 0744},
+		{mode: "go-w", existing: 0666, want: 0644},
+		{mode: "a=r", existing: 0777, want: 0444},
+		{mode: "u=rwx,g=rx,o=", existing: 0, want: 0750},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.mode, func(t *testing.T) {
+			change, err := parseMode(tc.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, change(tc.existing), "expected %o", tc.want)
+		})
+	}
+
+	_, err := parseMode("g+s")
+	require.ErrorContains(t, err, "only the r, w and x permissions are supported")
+}
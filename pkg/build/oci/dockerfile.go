@@ -0,0 +1,1027 @@
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carolynvs/aferox"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+	"github.com/moby/patternmatcher"
+)
+
+// imageBuilder assembles the bundle image for a single platform by applying
+// the instructions in the Dockerfile to the base image.
+//
+// Only the subset of the Dockerfile syntax that Porter generates is supported,
+// so template Dockerfiles, and mixins, must not use RUN instructions. Files are
+// copied into new layers, and the RUN instructions that Porter generates to
+// create the bundle user, remove files and set the permissions of /cnab are
+// applied to those layers instead of being executed. Any other RUN instruction
+// is rejected.
+type imageBuilder struct {
+	fs aferox.Aferox
+
+	// platform of the image.
+	platform v1.Platform
+
+	// buildArgs are the values of the build arguments.
+	buildArgs map[string]string
+
+	// contexts are the directories of the named build contexts, which can be copied with COPY --from.
+	contexts map[string]string
+
+	// ignore matches the files in the build context that are excluded by .dockerignore.
+	ignore *patternmatcher.PatternMatcher
+
+	// remoteOpts are used to pull the base image.
+	remoteOpts []remote.Option
+
+	// created is the time that the image was built.
+	created time.Time
+
+	lex *shell.Lex
+
+	// metaArgs are the build arguments declared before FROM.
+	metaArgs map[string]string
+
+	// args are the build arguments that are in scope.
+	args map[string]string
+
+	// base is the image specified with FROM.
+	base v1.Image
+
+	// config of the image, which starts out as the configuration of the base image.
+	config *v1.ConfigFile
+
+	// cmdSet is true when CMD is set by the Dockerfile, instead of being inherited from the base image.
+	cmdSet bool
+
+	layers []*layer
+}
+
+// parseDockerfile parses the instructions in a Dockerfile.
+func parseDockerfile(contents []byte) (*parser.Result, error) {
+	result, err := parser.Parse(bytes.NewReader(contents))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the Dockerfile: %w", err)
+	}
+	return result, nil
+}
+
+// build the image from the parsed Dockerfile.
+func (b *imageBuilder) build(ctx context.Context, dockerfile *parser.Result) (v1.Image, error) {
+	b.lex = shell.NewLex(dockerfile.EscapeToken)
+	b.args = make(map[string]string)
+
+	for _, node := range dockerfile.AST.Children {
+		instruction := strings.ToLower(node.Value)
+		if instruction != "from" && instruction != "arg" && b.base == nil {
+			return nil, fmt.Errorf("line %d: the Dockerfile must start with FROM", node.StartLine)
+		}
+
+		var err error
+		switch instruction {
+		case "from":
+			err = b.from(ctx, node)
+		case "arg":
+			err = b.arg(node)
+		case "env":
+			err = b.env(node)
+		case "label":
+			err = b.label(node)
+		case "workdir":
+			err = b.workdir(node)
+		case "user":
+			err = b.user(node)
+		case "cmd":
+			err = b.cmd(node)
+		case "entrypoint":
+			err = b.entrypoint(node)
+		case "copy":
+			err = b.copy(node)
+		case "run":
+			err = b.run(node)
+		default:
+			err = fmt.Errorf("%s is not supported by the oci build driver", strings.ToUpper(instruction))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", node.StartLine, node.Original, err)
+		}
+	}
+
+	if b.base == nil {
+		return nil, errors.New("the Dockerfile does not have a FROM instruction")
+	}
+	return b.image()
+}
+
+// image creates the image from the base image, the configuration and the layers added by the Dockerfile.
+func (b *imageBuilder) image() (v1.Image, error) {
+	manifest, err := b.base.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the manifest of the base image: %w", err)
+	}
+	// Use the same type of layers as the base image
+	layerMediaType := types.OCILayer
+	if manifest.MediaType == types.DockerManifestSchema2 {
+		layerMediaType = types.DockerLayer
+	}
+
+	img, err := mutate.ConfigFile(b.base, b.config)
+	if err != nil {
+		return nil, err
+	}
+
+	adds := make([]mutate.Addendum, 0, len(b.layers))
+	for _, l := range b.layers {
+		// Skip layers where all the files were removed by a later instruction
+		if len(l.entries) == 0 {
+			continue
+		}
+		layer, err := l.toLayer(b.fs, layerMediaType)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the layer for %s: %w", l.createdBy, err)
+		}
+		adds = append(adds, mutate.Addendum{
+			Layer: layer,
+			History: v1.History{
+				Created:   v1.Time{Time: b.created},
+				CreatedBy: l.createdBy,
+			},
+			MediaType: layerMediaType,
+		})
+	}
+
+	img, err = mutate.Append(img, adds...)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.CreatedAt(img, v1.Time{Time: b.created})
+}
+
+// platformArgs returns the values of the build arguments that describe the platform.
+func (b *imageBuilder) platformArgs() map[string]string {
+	platform := b.platform.OS + "/" + b.platform.Architecture
+	if b.platform.Variant != "" {
+		platform += "/" + b.platform.Variant
+	}
+	return map[string]string{
+		"TARGETPLATFORM": platform,
+		"TARGETOS":       b.platform.OS,
+		"TARGETARCH":     b.platform.Architecture,
+		"TARGETVARIANT":  b.platform.Variant,
+		"BUILDPLATFORM":  platform,
+		"BUILDOS":        b.platform.OS,
+		"BUILDARCH":      b.platform.Architecture,
+		"BUILDVARIANT":   b.platform.Variant,
+	}
+}
+
+// vars returns the variables that can be used in an instruction, where environment
+// variables take precedence over build arguments.
+func (b *imageBuilder) vars() shell.EnvGetter {
+	var vars []string
+	argNames := make([]string, 0, len(b.args))
+	for k := range b.args {
+		argNames = append(argNames, k)
+	}
+	sort.Strings(argNames)
+	for _, k := range argNames {
+		vars = append(vars, k+"="+b.args[k])
+	}
+	if b.config != nil {
+		vars = append(vars, b.config.Config.Env...)
+	}
+	return shell.EnvsFromSlice(vars)
+}
+
+// expand variables in a word.
+func (b *imageBuilder) expand(word string) (string, error) {
+	result, _, err := b.lex.ProcessWord(word, b.vars())
+	return result, err
+}
+
+// nodeArgs returns the value of each argument in the linked list of nodes of an instruction.
+func nodeArgs(node *parser.Node) []string {
+	var args []string
+	for n := node.Next; n != nil; n = n.Next {
+		args = append(args, n.Value)
+	}
+	return args
+}
+
+// parseFlags parses the flags of an instruction, such as --from=NAME, returning an error for unsupported flags.
+func parseFlags(node *parser.Node, allowed ...string) (map[string]string, error) {
+	flags := make(map[string]string, len(node.Flags))
+	for _, flag := range node.Flags {
+		flagName, value, hasValue := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		supported := false
+		for _, a := range allowed {
+			if flagName == a {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("the --%s flag is not supported by the oci build driver", flagName)
+		}
+		if !hasValue {
+			value = "true"
+		}
+		flags[flagName] = value
+	}
+	return flags, nil
+}
+
+func (b *imageBuilder) from(ctx context.Context, node *parser.Node) error {
+	if b.base != nil {
+		return errors.New("multi-stage builds are not supported by the oci build driver")
+	}
+
+	flags, err := parseFlags(node, "platform")
+	if err != nil {
+		return err
+	}
+
+	// Arguments defined before FROM can only be used by FROM
+	fromArgs := b.args
+	for k, v := range b.platformArgs() {
+		if _, ok := fromArgs[k]; !ok {
+			fromArgs[k] = v
+		}
+	}
+	if platform, ok := flags["platform"]; ok {
+		platform, err = b.expand(platform)
+		if err != nil {
+			return err
+		}
+		if platform != fromArgs["TARGETPLATFORM"] {
+			return fmt.Errorf("the base image is pinned to %s but the bundle image is built for %s", platform, fromArgs["TARGETPLATFORM"])
+		}
+	}
+
+	args := nodeArgs(node)
+	if len(args) == 0 {
+		return errors.New("FROM requires the name of the base image")
+	}
+	image, err := b.expand(args[0])
+	if err != nil {
+		return err
+	}
+
+	if image == "scratch" {
+		b.base = mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	} else {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return fmt.Errorf("invalid base image %s: %w", image, err)
+		}
+		opts := append([]remote.Option{remote.WithContext(ctx), remote.WithPlatform(b.platform)}, b.remoteOpts...)
+		b.base, err = remote.Image(ref, opts...)
+		if err != nil {
+			return fmt.Errorf("error pulling the base image %s: %w", image, err)
+		}
+	}
+
+	cfg, err := b.base.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("error reading the configuration of the base image %s: %w", image, err)
+	}
+	b.config = cfg.DeepCopy()
+	if image == "scratch" {
+		b.config.OS = b.platform.OS
+		b.config.Architecture = b.platform.Architecture
+		b.config.Variant = b.platform.Variant
+	} else if b.config.OS != b.platform.OS || b.config.Architecture != b.platform.Architecture {
+		return fmt.Errorf("the base image %s is not available for %s", image, fromArgs["TARGETPLATFORM"])
+	}
+
+	// Arguments must be declared again after FROM to use them in the rest of the Dockerfile
+	b.metaArgs = b.args
+	b.args = make(map[string]string)
+	return nil
+}
+
+func (b *imageBuilder) arg(node *parser.Node) error {
+	for _, arg := range nodeArgs(node) {
+		argName, defaultValue, hasDefault := strings.Cut(arg, "=")
+		value, ok := b.buildArgs[argName]
+		if !ok {
+			value, ok = b.metaArgs[argName]
+		}
+		if !ok {
+			value, ok = b.platformArgs()[argName]
+		}
+		if !ok && hasDefault {
+			var err error
+			value, err = b.expand(defaultValue)
+			if err != nil {
+				return err
+			}
+		}
+		b.args[argName] = value
+	}
+	return nil
+}
+
+// keyValues expands the key value pairs of ENV and LABEL.
+func (b *imageBuilder) keyValues(node *parser.Node) ([][2]string, error) {
+	var results [][2]string
+	// Each pair is represented by three nodes: key, value and separator
+	for n := node.Next; n != nil && n.Next != nil; n = n.Next.Next {
+		key, err := b.expand(n.Value)
+		if err != nil {
+			return nil, err
+		}
+		value, err := b.expand(n.Next.Value)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, [2]string{key, value})
+		if n.Next.Next == nil {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (b *imageBuilder) env(node *parser.Node) error {
+	pairs, err := b.keyValues(node)
+	if err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		b.setEnv(pair[0], pair[1])
+	}
+	return nil
+}
+
+func (b *imageBuilder) setEnv(key string, value string) {
+	variable := key + "=" + value
+	for i, existing := range b.config.Config.Env {
+		if existingKey, _, _ := strings.Cut(existing, "="); existingKey == key {
+			b.config.Config.Env[i] = variable
+			return
+		}
+	}
+	b.config.Config.Env = append(b.config.Config.Env, variable)
+}
+
+func (b *imageBuilder) label(node *parser.Node) error {
+	pairs, err := b.keyValues(node)
+	if err != nil {
+		return err
+	}
+	if b.config.Config.Labels == nil {
+		b.config.Config.Labels = make(map[string]string, len(pairs))
+	}
+	for _, pair := range pairs {
+		b.config.Config.Labels[pair[0]] = pair[1]
+	}
+	return nil
+}
+
+func (b *imageBuilder) workdir(node *parser.Node) error {
+	args := nodeArgs(node)
+	if len(args) != 1 {
+		return errors.New("WORKDIR requires exactly one argument")
+	}
+	dir, err := b.expand(args[0])
+	if err != nil {
+		return err
+	}
+	b.config.Config.WorkingDir = b.resolvePath(dir)
+	return nil
+}
+
+// resolvePath converts a path in the image to an absolute path, relative to the working directory.
+func (b *imageBuilder) resolvePath(p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	wd := b.config.Config.WorkingDir
+	if wd == "" {
+		wd = "/"
+	}
+	return path.Join(wd, p)
+}
+
+func (b *imageBuilder) user(node *parser.Node) error {
+	args := nodeArgs(node)
+	if len(args) != 1 {
+		return errors.New("USER requires exactly one argument")
+	}
+	user, err := b.expand(args[0])
+	if err != nil {
+		return err
+	}
+	b.config.Config.User = user
+	return nil
+}
+
+// command returns the command of CMD, ENTRYPOINT and RUN, using /bin/sh -c
+// to run the command when the shell form of the instruction is used.
+func command(node *parser.Node) []string {
+	args := nodeArgs(node)
+	if node.Attributes["json"] {
+		return args
+	}
+	return []string{"/bin/sh", "-c", strings.Join(args, " ")}
+}
+
+func (b *imageBuilder) cmd(node *parser.Node) error {
+	b.config.Config.Cmd = command(node)
+	b.cmdSet = true
+	return nil
+}
+
+func (b *imageBuilder) entrypoint(node *parser.Node) error {
+	b.config.Config.Entrypoint = command(node)
+	// Setting the entrypoint resets the command inherited from the base image
+	if !b.cmdSet {
+		b.config.Config.Cmd = nil
+	}
+	return nil
+}
+
+func (b *imageBuilder) copy(node *parser.Node) error {
+	if len(node.Heredocs) > 0 {
+		return errors.New("heredocs are not supported by the oci build driver")
+	}
+	flags, err := parseFlags(node, "from", "chown", "chmod", "link")
+	if err != nil {
+		return err
+	}
+
+	args := nodeArgs(node)
+	if len(args) < 2 {
+		return errors.New("COPY requires at least two arguments")
+	}
+	for i := range args {
+		if args[i], err = b.expand(args[i]); err != nil {
+			return err
+		}
+	}
+	sources, dest := args[:len(args)-1], args[len(args)-1]
+
+	contextDir := "."
+	ignore := b.ignore
+	if from, ok := flags["from"]; ok {
+		if contextDir, ok = b.contexts[from]; !ok {
+			return fmt.Errorf("COPY --from=%s is not supported by the oci build driver, only named build contexts can be copied from", from)
+		}
+		// .dockerignore only applies to the main build context
+		ignore = nil
+	}
+
+	uid, gid, err := parseChown(flags["chown"])
+	if err != nil {
+		return err
+	}
+	var mode int64 = -1
+	if chmod, ok := flags["chmod"]; ok {
+		if mode, err = strconv.ParseInt(chmod, 8, 32); err != nil {
+			return fmt.Errorf("invalid --chmod %s, only octal modes are supported by the oci build driver", chmod)
+		}
+	}
+
+	// Copy into the directory when there are multiple sources, or the destination ends with a slash
+	destIsDir := strings.HasSuffix(dest, "/") || len(sources) > 1
+	dest = b.resolvePath(dest)
+
+	l := &layer{createdBy: node.Original}
+	for _, src := range sources {
+		if strings.ContainsAny(src, "*?[") {
+			return fmt.Errorf("wildcards in COPY sources are not supported by the oci build driver: %s", src)
+		}
+		relSrc := path.Clean("/" + filepath.ToSlash(src))[1:]
+		if relSrc == "" {
+			relSrc = "."
+		}
+		srcPath := filepath.Join(contextDir, filepath.FromSlash(relSrc))
+
+		info, err := b.fs.Stat(srcPath)
+		if err != nil {
+			return fmt.Errorf("error reading %s from the build context: %w", src, err)
+		}
+
+		if !info.IsDir() {
+			if excluded, _ := matchesIgnore(ignore, relSrc); excluded {
+				return fmt.Errorf("%s is excluded from the build context by .dockerignore", src)
+			}
+			target := dest
+			if destIsDir {
+				target = path.Join(dest, path.Base(relSrc))
+			}
+			l.add(newFileEntry(srcPath, target, info, uid, gid, mode))
+			continue
+		}
+
+		// The contents of a directory are copied into the destination directory
+		destDir := newFileEntry(srcPath, dest, info, uid, gid, mode)
+		if mode < 0 {
+			destDir.header.Mode = 0755
+		}
+		l.add(destDir)
+		err = b.fs.Walk(srcPath, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == srcPath {
+				return nil
+			}
+			rel, err := filepath.Rel(srcPath, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+
+			excluded, err := matchesIgnore(ignore, path.Join(relSrc, rel))
+			if err != nil {
+				return err
+			}
+			if excluded {
+				// Keep walking the directory when an exclusion may include files inside of it
+				if info.IsDir() && !ignore.Exclusions() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			l.add(newFileEntry(p, path.Join(dest, rel), info, uid, gid, mode))
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error copying %s from the build context: %w", src, err)
+		}
+	}
+
+	b.layers = append(b.layers, l)
+	return nil
+}
+
+// matchesIgnore checks if a path in the build context is excluded by .dockerignore.
+func matchesIgnore(ignore *patternmatcher.PatternMatcher, p string) (bool, error) {
+	if ignore == nil || p == "." {
+		return false, nil
+	}
+	return ignore.MatchesOrParentMatches(p)
+}
+
+// parseChown parses the numeric UID and GID of COPY --chown.
+func parseChown(chown string) (int, int, error) {
+	if chown == "" {
+		return 0, 0, nil
+	}
+
+	user, group, hasGroup := strings.Cut(chown, ":")
+	uid, err := strconv.Atoi(user)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --chown %s, only a numeric UID and GID are supported by the oci build driver", chown)
+	}
+	if !hasGroup {
+		return uid, uid, nil
+	}
+	gid, err := strconv.Atoi(group)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --chown %s, only a numeric UID and GID are supported by the oci build driver", chown)
+	}
+	return uid, gid, nil
+}
+
+// newFileEntry creates an entry for a file or directory that is copied from the build context.
+func newFileEntry(src string, dest string, info os.FileInfo, uid int, gid int, mode int64) *entry {
+	hdr := tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     dest,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Uid:      uid,
+		Gid:      gid,
+	}
+	if info.IsDir() {
+		hdr.Typeflag = tar.TypeDir
+		hdr.Size = 0
+	}
+	if mode >= 0 {
+		hdr.Mode = mode
+	}
+	return &entry{header: hdr, source: src}
+}
+
+func (b *imageBuilder) run(node *parser.Node) error {
+	if len(node.Flags) > 0 {
+		return unsupportedRunError()
+	}
+
+	var commands [][]string
+	if node.Attributes["json"] {
+		commands = [][]string{nodeArgs(node)}
+	} else {
+		script := strings.Join(nodeArgs(node), " ")
+		if strings.ContainsAny(script, ";|<>`\n") || strings.Contains(script, "$(") {
+			return unsupportedRunError()
+		}
+		for _, part := range strings.Split(script, "&&") {
+			words, err := b.lex.ProcessWords(part, b.vars())
+			if err != nil {
+				return err
+			}
+			commands = append(commands, words)
+		}
+	}
+
+	for _, words := range commands {
+		if len(words) == 0 {
+			return unsupportedRunError()
+		}
+
+		var err error
+		switch words[0] {
+		case "useradd":
+			err = b.useradd(node, words[1:])
+		case "rm":
+			err = b.rm(node, words[1:])
+		case "chgrp":
+			err = b.chgrp(words[1:])
+		case "chmod":
+			err = b.chmod(words[1:])
+		default:
+			return unsupportedRunError()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unsupportedRunError() error {
+	return errors.New("the oci build driver cannot execute commands and only supports the RUN instructions generated by Porter. Use a base image that already includes the tools that the bundle needs, or use the buildkit build driver")
+}
+
+// splitArgs separates the single letter flags of a command, such as -fr, from its arguments.
+// Flags that have a value are returned with their value.
+func splitArgs(args []string, valueFlags string) (map[string]string, []string, error) {
+	flags := make(map[string]string)
+	var operands []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			operands = append(operands, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			operands = append(operands, arg)
+			continue
+		}
+		for _, flag := range arg[1:] {
+			f := string(flag)
+			if strings.Contains(valueFlags, f) {
+				if i+1 >= len(args) {
+					return nil, nil, fmt.Errorf("the -%s flag requires a value", f)
+				}
+				i++
+				flags[f] = args[i]
+				continue
+			}
+			flags[f] = ""
+		}
+	}
+	return flags, operands, nil
+}
+
+// useradd adds a user to /etc/passwd, and creates the home directory of the user with -m.
+func (b *imageBuilder) useradd(node *parser.Node, args []string) error {
+	flags, operands, err := splitArgs(args, "ugds")
+	if err != nil {
+		return fmt.Errorf("useradd: %w", err)
+	}
+	for f := range flags {
+		if !strings.Contains("ugdsmo", f) {
+			return fmt.Errorf("useradd: the -%s flag is not supported by the oci build driver", f)
+		}
+	}
+	if len(operands) != 1 {
+		return errors.New("useradd: the name of the user is required")
+	}
+	username := operands[0]
+
+	uid, gid := flags["u"], flags["g"]
+	if gid == "" {
+		gid = uid
+	}
+	home := flags["d"]
+	if home == "" {
+		home = path.Join("/home", username)
+	}
+	userShell := flags["s"]
+	if userShell == "" {
+		userShell = "/bin/sh"
+	}
+	for _, id := range []string{uid, gid} {
+		if _, err := strconv.Atoi(id); err != nil {
+			return fmt.Errorf("useradd: the oci build driver requires a numeric UID and GID, set with -u and -g")
+		}
+	}
+
+	passwd, err := b.readFile("/etc/passwd")
+	if err != nil {
+		return fmt.Errorf("useradd: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(passwd))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 {
+			continue
+		}
+		if fields[0] == username {
+			return fmt.Errorf("useradd: user '%s' already exists", username)
+		}
+		if _, nonUnique := flags["o"]; fields[2] == uid && !nonUnique {
+			return fmt.Errorf("useradd: UID %s is not unique", uid)
+		}
+	}
+	if len(passwd) > 0 && !bytes.HasSuffix(passwd, []byte("\n")) {
+		passwd = append(passwd, '\n')
+	}
+	passwd = append(passwd, fmt.Sprintf("%s:x:%s:%s::%s:%s\n", username, uid, gid, home, userShell)...)
+
+	l := &layer{createdBy: node.Original}
+	l.add(&entry{
+		header: tar.Header{Typeflag: tar.TypeReg, Name: "/etc/passwd", Mode: 0644, ModTime: b.created},
+		data:   passwd,
+	})
+	if _, ok := flags["m"]; ok {
+		uidN, _ := strconv.Atoi(uid)
+		gidN, _ := strconv.Atoi(gid)
+		l.add(&entry{header: tar.Header{Typeflag: tar.TypeDir, Name: home, Mode: 0755, Uid: uidN, Gid: gidN, ModTime: b.created}})
+	}
+	b.layers = append(b.layers, l)
+	return nil
+}
+
+// readFile reads a file from the layers added by the Dockerfile, or from the base image.
+func (b *imageBuilder) readFile(p string) ([]byte, error) {
+	for i := len(b.layers) - 1; i >= 0; i-- {
+		for _, e := range b.layers[i].entries {
+			if e.header.Name == p && e.data != nil {
+				return e.data, nil
+			}
+		}
+	}
+
+	layers, err := b.base.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the layers of the base image: %w", err)
+	}
+	target := strings.TrimPrefix(p, "/")
+	dir, file := path.Split(target)
+	whiteout := path.Join(dir, whiteoutPrefix+file)
+	for i := len(layers) - 1; i >= 0; i-- {
+		data, found, err := readFileFromLayer(layers[i], target, whiteout)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from the base image: %w", p, err)
+		}
+		if found && data != nil {
+			return data, nil
+		}
+		if found {
+			break
+		}
+	}
+	return nil, fmt.Errorf("%s does not exist in the base image", p)
+}
+
+// readFileFromLayer returns the contents of a file in the layer, and if the layer contains or removes the file.
+func readFileFromLayer(layer v1.Layer, target string, whiteout string) ([]byte, bool, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return nil, false, err
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch path.Clean(strings.TrimPrefix(hdr.Name, "./")) {
+		case target:
+			data, err := io.ReadAll(tr)
+			return data, true, err
+		case whiteout:
+			return nil, true, nil
+		}
+	}
+}
+
+// rm removes files that were added by the Dockerfile, or files from the base image.
+func (b *imageBuilder) rm(node *parser.Node, args []string) error {
+	flags, paths, err := splitArgs(args, "")
+	if err != nil {
+		return fmt.Errorf("rm: %w", err)
+	}
+	for f := range flags {
+		if !strings.Contains("rRf", f) {
+			return fmt.Errorf("rm: the -%s flag is not supported by the oci build driver", f)
+		}
+	}
+
+	var whiteouts *layer
+	for _, p := range paths {
+		p = b.resolvePath(p)
+		var removed bool
+		for _, l := range b.layers {
+			if l.remove(p) {
+				removed = true
+			}
+		}
+		// Files from the base image are removed by a layer that hides them
+		if !removed {
+			if whiteouts == nil {
+				whiteouts = &layer{createdBy: node.Original}
+			}
+			whiteouts.add(newWhiteout(p))
+		}
+	}
+	if whiteouts != nil {
+		b.layers = append(b.layers, whiteouts)
+	}
+	return nil
+}
+
+// changeFiles applies a change to the files added by the Dockerfile at the path,
+// and all files inside of it when recursive is true.
+func (b *imageBuilder) changeFiles(p string, recursive bool, change func(hdr *tar.Header)) error {
+	p = b.resolvePath(p)
+
+	var found bool
+	var first *layer
+	for _, l := range b.layers {
+		for _, e := range l.entries {
+			if e.header.Name == p {
+				found = true
+			}
+			if e.header.Name == p || (recursive && isPathOrChild(e.header.Name, p)) {
+				change(&e.header)
+				if first == nil {
+					first = l
+				}
+			}
+		}
+	}
+	if first == nil {
+		return fmt.Errorf("%s was not added by the Dockerfile, the oci build driver can only change the files that it copies into the image", p)
+	}
+
+	// Directories that are created implicitly for the files that are copied into them must be changed too
+	if !found {
+		dir := &entry{header: tar.Header{Typeflag: tar.TypeDir, Name: p, Mode: 0755, ModTime: b.created}}
+		change(&dir.header)
+		first.add(dir)
+	}
+	return nil
+}
+
+// chgrp changes the group of files that were added by the Dockerfile.
+func (b *imageBuilder) chgrp(args []string) error {
+	flags, operands, err := splitArgs(args, "")
+	if err != nil {
+		return fmt.Errorf("chgrp: %w", err)
+	}
+	if len(operands) < 2 {
+		return errors.New("chgrp: the group and a file are required")
+	}
+	gid, err := strconv.Atoi(operands[0])
+	if err != nil {
+		return fmt.Errorf("chgrp: the oci build driver requires a numeric GID, got %s", operands[0])
+	}
+	_, recursive := flags["R"]
+
+	for _, p := range operands[1:] {
+		err := b.changeFiles(p, recursive, func(hdr *tar.Header) {
+			hdr.Gid = gid
+		})
+		if err != nil {
+			return fmt.Errorf("chgrp: %w", err)
+		}
+	}
+	return nil
+}
+
+// chmod changes the permissions of files that were added by the Dockerfile.
+func (b *imageBuilder) chmod(args []string) error {
+	flags, operands, err := splitArgs(args, "")
+	if err != nil {
+		return fmt.Errorf("chmod: %w", err)
+	}
+	if len(operands) < 2 {
+		return errors.New("chmod: the mode and a file are required")
+	}
+	changeMode, err := parseMode(operands[0])
+	if err != nil {
+		return fmt.Errorf("chmod: %w", err)
+	}
+	_, recursive := flags["R"]
+
+	for _, p := range operands[1:] {
+		err := b.changeFiles(p, recursive, func(hdr *tar.Header) {
+			hdr.Mode = changeMode(hdr.Mode)
+		})
+		if err != nil {
+			return fmt.Errorf("chmod: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseMode parses an octal mode, such as 755, or a symbolic mode, such as g=u or u+x,go-w,
+// and returns a function that applies the mode to the existing permissions of a file.
+func parseMode(mode string) (func(int64) int64, error) {
+	if octal, err := strconv.ParseInt(mode, 8, 32); err == nil {
+		return func(existing int64) int64 {
+			return existing&^0o7777 | octal
+		}, nil
+	}
+
+	// The bits of each class of users: user, group and other
+	shifts := map[rune]uint{'u': 6, 'g': 3, 'o': 0}
+	var changes []func(int64) int64
+	for _, clause := range strings.Split(mode, ",") {
+		opIndex := strings.IndexAny(clause, "+-=")
+		if opIndex < 0 {
+			return nil, fmt.Errorf("invalid mode %s", mode)
+		}
+		who, op, perms := clause[:opIndex], clause[opIndex], clause[opIndex+1:]
+		if who == "" || who == "a" {
+			who = "ugo"
+		}
+		for _, w := range who {
+			if _, ok := shifts[w]; !ok {
+				return nil, fmt.Errorf("invalid mode %s", mode)
+			}
+		}
+		if strings.Trim(perms, "rwx") != "" && !(len(perms) == 1 && strings.Contains("ugo", perms)) {
+			return nil, fmt.Errorf("invalid mode %s, only the r, w and x permissions are supported by the oci build driver", mode)
+		}
+
+		changes = append(changes, func(existing int64) int64 {
+			// Permissions copied from a class of users, such as g=u, are read before the mode is changed
+			var bits int64
+			if len(perms) == 1 && strings.Contains("ugo", perms) {
+				bits = (existing >> shifts[rune(perms[0])]) & 0o7
+			} else {
+				for _, p := range perms {
+					bits |= map[rune]int64{'r': 4, 'w': 2, 'x': 1}[p]
+				}
+			}
+
+			result := existing
+			for _, w := range who {
+				shift := shifts[w]
+				switch op {
+				case '+':
+					result |= bits << shift
+				case '-':
+					result &^= bits << shift
+				case '=':
+					result = result&^(0o7<<shift) | bits<<shift
+				}
+			}
+			return result
+		})
+	}
+
+	return func(existing int64) int64 {
+		for _, change := range changes {
+			existing = change(existing)
+		}
+		return existing
+	}, nil
+}
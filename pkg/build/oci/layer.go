@@ -0,0 +1,143 @@
+package oci
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/carolynvs/aferox"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// whiteoutPrefix marks a file in a layer that removes the file with the same
+// name from the layers below it.
+const whiteoutPrefix = ".wh."
+
+// entry is a file or directory in a layer.
+type entry struct {
+	header tar.Header
+
+	// source is the path of the file in the build context that is copied into the layer.
+	source string
+
+	// data is the contents of a file that is generated by the build, such as /etc/passwd.
+	data []byte
+}
+
+// layer is a layer of the bundle image, created by a single instruction in the Dockerfile.
+type layer struct {
+	// createdBy is the Dockerfile instruction that created the layer.
+	createdBy string
+
+	entries []*entry
+}
+
+// add an entry to the layer, replacing an existing entry with the same path.
+func (l *layer) add(e *entry) {
+	for i, existing := range l.entries {
+		if existing.header.Name == e.header.Name {
+			l.entries[i] = e
+			return
+		}
+	}
+	l.entries = append(l.entries, e)
+}
+
+// remove the entries that are the path, or are inside the path, returning true when an entry was removed.
+func (l *layer) remove(p string) bool {
+	var removed bool
+	kept := l.entries[:0]
+	for _, e := range l.entries {
+		if isPathOrChild(e.header.Name, p) {
+			removed = true
+			continue
+		}
+		kept = append(kept, e)
+	}
+	l.entries = kept
+	return removed
+}
+
+// isPathOrChild checks if name is the path p, or a file inside of p.
+func isPathOrChild(name string, p string) bool {
+	return name == p || p == "/" || strings.HasPrefix(name, p+"/")
+}
+
+// newWhiteout creates an entry that removes the path from the layers below.
+func newWhiteout(p string) *entry {
+	dir, file := path.Split(p)
+	return &entry{header: tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Join(dir, whiteoutPrefix+file),
+		Mode:     0644,
+	}}
+}
+
+// writeTar writes the layer as an uncompressed tar archive, reading copied files from the build context.
+func (l *layer) writeTar(fs aferox.Aferox, w io.Writer) error {
+	entries := make([]*entry, len(l.entries))
+	copy(entries, l.entries)
+	// Parent directories must come before their files
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].header.Name < entries[j].header.Name
+	})
+
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		hdr := e.header
+		// Paths in a layer are relative to the root of the filesystem
+		hdr.Name = strings.TrimPrefix(hdr.Name, "/")
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Name += "/"
+		}
+		if e.data != nil {
+			hdr.Size = int64(len(e.data))
+		}
+		hdr.Format = tar.FormatPAX
+
+		if err := tw.WriteHeader(&hdr); err != nil {
+			return fmt.Errorf("error writing %s to the layer: %w", e.header.Name, err)
+		}
+
+		switch {
+		case e.data != nil:
+			if _, err := tw.Write(e.data); err != nil {
+				return fmt.Errorf("error writing %s to the layer: %w", e.header.Name, err)
+			}
+		case e.source != "" && hdr.Typeflag == tar.TypeReg:
+			if err := copyFile(fs, e.source, tw); err != nil {
+				return fmt.Errorf("error writing %s to the layer: %w", e.header.Name, err)
+			}
+		}
+	}
+	return tw.Close()
+}
+
+func copyFile(fs aferox.Aferox, src string, w io.Writer) error {
+	f, err := fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// toLayer converts the layer to an OCI layer. The files in the build context
+// are read each time that the layer contents are requested.
+func (l *layer) toLayer(fs aferox.Aferox, mediaType types.MediaType) (v1.Layer, error) {
+	opener := func() (io.ReadCloser, error) {
+		r, w := io.Pipe()
+		go func() {
+			w.CloseWithError(l.writeTar(fs, w))
+		}()
+		return r, nil
+	}
+	return tarball.LayerFromOpener(opener, tarball.WithMediaType(mediaType))
+}
@@ -0,0 +1,67 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// openLayout opens the OCI image layout in the directory, creating it when it does not exist.
+func openLayout(dir string) (layout.Path, error) {
+	p, err := layout.FromPath(dir)
+	if err == nil {
+		return p, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("error reading the OCI layout in %s: %w", dir, err)
+	}
+
+	p, err = layout.Write(dir, empty.Index)
+	if err != nil {
+		return "", fmt.Errorf("error creating the OCI layout in %s: %w", dir, err)
+	}
+	return p, nil
+}
+
+// findInLayout finds the image, or image index, in the layout with the reference
+// name. The image is not found when the layout does not exist.
+func findInLayout(dir string, refName string) (layout.Path, v1.Descriptor, bool, error) {
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", v1.Descriptor{}, false, nil
+		}
+		return "", v1.Descriptor{}, false, fmt.Errorf("error reading the OCI layout in %s: %w", dir, err)
+	}
+
+	idx, err := p.ImageIndex()
+	if err != nil {
+		return "", v1.Descriptor{}, false, fmt.Errorf("error reading the OCI layout in %s: %w", dir, err)
+	}
+	index, err := idx.IndexManifest()
+	if err != nil {
+		return "", v1.Descriptor{}, false, fmt.Errorf("error reading the OCI layout in %s: %w", dir, err)
+	}
+
+	matchesName, _ := refNameOptions(refName)
+	for _, desc := range index.Manifests {
+		if matchesName(desc) {
+			return p, desc, true, nil
+		}
+	}
+	return p, v1.Descriptor{}, false, nil
+}
+
+// refNameOptions returns a matcher for the image in a layout with the reference
+// name, and the option that names an image when it is added to the layout.
+// An image is named with the org.opencontainers.image.ref.name annotation.
+func refNameOptions(refName string) (match.Matcher, layout.Option) {
+	return match.Annotation(ocispec.AnnotationRefName, refName),
+		layout.WithAnnotations(map[string]string{ocispec.AnnotationRefName: refName})
+}
@@ -0,0 +1,62 @@
+package build
+
+import (
+	"strings"
+	"time"
+)
+
+// Record describes how the bundle image was built, and is saved to
+// LOCAL_BUILD_RECORD so that it can be included in the provenance of the
+// bundle when it is published.
+type Record struct {
+	// BuildArgs are the build arguments, in the form NAME=VALUE.
+	BuildArgs []string `json:"buildArgs,omitempty"`
+
+	// BuildContexts are the additional build contexts, in the form NAME=PATH.
+	BuildContexts []string `json:"buildContexts,omitempty"`
+
+	// Secrets are the ids of the secrets exposed to the build. The values are never recorded.
+	Secrets []string `json:"secrets,omitempty"`
+
+	// SSH are the ids of the SSH agent sockets or keys exposed to the build.
+	SSH []string `json:"ssh,omitempty"`
+
+	// NoCache indicates that the build did not use the cache.
+	NoCache bool `json:"noCache,omitempty"`
+
+	// Platforms are the platforms that the bundle image was built for.
+	Platforms []string `json:"platforms,omitempty"`
+
+	// StartedOn is when the build started.
+	StartedOn time.Time `json:"startedOn"`
+
+	// FinishedOn is when the build finished.
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// NewRecord creates a record of a build that used the specified options.
+// Only the ids of secrets and SSH agents are recorded.
+func NewRecord(opts BuildImageOptions) Record {
+	r := Record{
+		BuildArgs:     opts.BuildArgs,
+		BuildContexts: opts.BuildContexts,
+		NoCache:       opts.NoCache,
+	}
+
+	// --secret id=mysecret,src=/local/secret
+	for _, secret := range opts.Secrets {
+		for _, field := range strings.Split(secret, ",") {
+			if id, ok := strings.CutPrefix(field, "id="); ok {
+				r.Secrets = append(r.Secrets, id)
+			}
+		}
+	}
+
+	// --ssh default|<id>[=<socket>|<key>[,<key>]]
+	for _, ssh := range opts.SSH {
+		id, _, _ := strings.Cut(ssh, "=")
+		r.SSH = append(r.SSH, id)
+	}
+
+	return r
+}
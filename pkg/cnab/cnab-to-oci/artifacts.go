@@ -0,0 +1,220 @@
+package cnabtooci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/opencontainers/go-digest"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// emptyConfig is the content of the config of an artifact manifest.
+var emptyConfig = []byte("{}")
+
+// Artifact is a document, such as an SBOM, that is attached to a bundle or
+// image in a registry. The artifact is pushed as an OCI manifest with a single
+// layer, and refers to the manifest that it describes with its subject, so that
+// it can be found with the OCI referrers API.
+type Artifact struct {
+	// ArtifactType identifies the kind of artifact, for example application/spdx+json.
+	ArtifactType string
+
+	// MediaType of the content.
+	MediaType string
+
+	// Content of the artifact.
+	Content []byte
+
+	// Annotations on the artifact manifest.
+	Annotations map[string]string
+
+	// Digest of the artifact manifest. It is set when the artifact is attached or listed.
+	Digest digest.Digest
+}
+
+// AttachArtifact pushes an artifact to the repository of the subject,
+// referring to the manifest of the subject. When the registry does not support
+// the OCI referrers API, the artifact is listed in the referrers tag,
+// sha256-DIGEST, instead. Returns the digest of the artifact manifest.
+func (r *Registry) AttachArtifact(ctx context.Context, subject cnab.OCIReference, artifact Artifact, opts RegistryOptions) (digest.Digest, error) {
+	ctx, log := tracing.StartSpan(ctx,
+		attribute.String("subject", subject.String()),
+		attribute.String("artifactType", artifact.ArtifactType))
+	defer log.EndSpan()
+
+	subjectRef, subjectDesc, err := r.resolveSubject(ctx, subject, opts)
+	if err != nil {
+		return "", log.Error(err)
+	}
+
+	config := static.NewLayer(emptyConfig, types.MediaType(artifact.ArtifactType))
+	content := static.NewLayer(artifact.Content, types.MediaType(artifact.MediaType))
+	remoteOpts := opts.toRemoteOptions(ctx)
+	repo := subjectRef.Context()
+	for _, blob := range []v1.Layer{config, content} {
+		if err = remote.WriteLayer(repo, blob, remoteOpts...); err != nil {
+			return "", log.Errorf("error pushing the %s artifact to %s: %w", artifact.ArtifactType, repo, err)
+		}
+	}
+
+	configDesc, err := partial.Descriptor(config)
+	if err != nil {
+		return "", log.Error(err)
+	}
+	contentDesc, err := partial.Descriptor(content)
+	if err != nil {
+		return "", log.Error(err)
+	}
+	manifest := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        *configDesc,
+		Layers:        []v1.Descriptor{*contentDesc},
+		Annotations:   artifact.Annotations,
+		Subject:       &subjectDesc,
+	}
+	manifestB, err := json.Marshal(manifest)
+	if err != nil {
+		return "", log.Errorf("error creating the %s artifact manifest: %w", artifact.ArtifactType, err)
+	}
+	manifestDigest, _, err := v1.SHA256(bytes.NewReader(manifestB))
+	if err != nil {
+		return "", log.Error(err)
+	}
+
+	// Pushing a manifest with a subject also updates the referrers tag when
+	// the registry does not support the referrers API
+	err = remote.Put(repo.Digest(manifestDigest.String()), rawManifest{data: manifestB, mediaType: types.OCIManifestSchema1}, remoteOpts...)
+	if err != nil {
+		return "", log.Errorf("error pushing the %s artifact to %s: %w", artifact.ArtifactType, repo, err)
+	}
+
+	log.Debugf("Attached %s artifact %s to %s", artifact.ArtifactType, manifestDigest, subject)
+	return digest.Digest(manifestDigest.String()), nil
+}
+
+// ListArtifacts returns the artifacts of the specified type that refer to the subject.
+func (r *Registry) ListArtifacts(ctx context.Context, subject cnab.OCIReference, artifactType string, opts RegistryOptions) ([]Artifact, error) {
+	ctx, log := tracing.StartSpan(ctx,
+		attribute.String("subject", subject.String()),
+		attribute.String("artifactType", artifactType))
+	defer log.EndSpan()
+
+	subjectRef, subjectDesc, err := r.resolveSubject(ctx, subject, opts)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	remoteOpts := opts.toRemoteOptions(ctx)
+	repo := subjectRef.Context()
+	referrers, err := remote.Referrers(repo.Digest(subjectDesc.Digest.String()), append(remoteOpts, remote.WithFilter("artifactType", artifactType))...)
+	if err != nil {
+		return nil, log.Errorf("error listing the artifacts that refer to %s: %w", subject, err)
+	}
+	index, err := referrers.IndexManifest()
+	if err != nil {
+		return nil, log.Errorf("error listing the artifacts that refer to %s: %w", subject, err)
+	}
+
+	var artifacts []Artifact
+	for _, desc := range index.Manifests {
+		if desc.ArtifactType != artifactType {
+			continue
+		}
+
+		artifact, err := r.pullArtifact(repo, desc, remoteOpts)
+		if err != nil {
+			return nil, log.Errorf("error pulling the %s artifact %s: %w", artifactType, desc.Digest, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+func (r *Registry) pullArtifact(repo name.Repository, desc v1.Descriptor, remoteOpts []remote.Option) (Artifact, error) {
+	manifestDesc, err := remote.Get(repo.Digest(desc.Digest.String()), remoteOpts...)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(manifestDesc.Manifest, &manifest); err != nil {
+		return Artifact{}, fmt.Errorf("invalid artifact manifest: %w", err)
+	}
+	if len(manifest.Layers) != 1 {
+		return Artifact{}, fmt.Errorf("expected the artifact to have a single layer but it has %d", len(manifest.Layers))
+	}
+
+	layer, err := remote.Layer(repo.Digest(manifest.Layers[0].Digest.String()), remoteOpts...)
+	if err != nil {
+		return Artifact{}, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return Artifact{}, err
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	return Artifact{
+		ArtifactType: string(manifest.Config.MediaType),
+		MediaType:    string(manifest.Layers[0].MediaType),
+		Content:      content,
+		Annotations:  manifest.Annotations,
+		Digest:       digest.Digest(desc.Digest.String()),
+	}, nil
+}
+
+// resolveSubject returns the descriptor of the manifest that is referenced.
+func (r *Registry) resolveSubject(ctx context.Context, subject cnab.OCIReference, opts RegistryOptions) (name.Reference, v1.Descriptor, error) {
+	ref, err := name.ParseReference(subject.String(), opts.toNameOptions()...)
+	if err != nil {
+		return nil, v1.Descriptor{}, fmt.Errorf("invalid reference %s: %w", subject, err)
+	}
+
+	desc, err := remote.Head(ref, opts.toRemoteOptions(ctx)...)
+	if err != nil {
+		if notFoundErr := asNotFoundError(err, subject); notFoundErr != nil {
+			return nil, v1.Descriptor{}, notFoundErr
+		}
+		return nil, v1.Descriptor{}, fmt.Errorf("error resolving the digest of %s: %w", subject, err)
+	}
+	return ref, v1.Descriptor{MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size}, nil
+}
+
+func (o RegistryOptions) toNameOptions() []name.Option {
+	return crane.GetOptions(o.toCraneOptions()...).Name
+}
+
+func (o RegistryOptions) toRemoteOptions(ctx context.Context) []remote.Option {
+	return crane.GetOptions(append(o.toCraneOptions(), crane.WithContext(ctx))...).Remote
+}
+
+// rawManifest is a manifest that can be pushed with remote.Put.
+type rawManifest struct {
+	data      []byte
+	mediaType types.MediaType
+}
+
+func (m rawManifest) RawManifest() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m rawManifest) MediaType() (types.MediaType, error) {
+	return m.mediaType, nil
+}
@@ -0,0 +1,78 @@
+package cnabtooci
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/portercontext"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_AttachArtifact(t *testing.T) {
+	testcases := []struct {
+		name      string
+		referrers bool
+	}{
+		{name: "referrers api", referrers: true},
+		{name: "referrers tag fallback", referrers: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := httptest.NewServer(registry.New(registry.WithReferrersSupport(tc.referrers)))
+			defer server.Close()
+
+			ref := strings.TrimPrefix(server.URL, "http://") + "/mybuns:v1.0.0"
+			parsedRef, err := name.ParseReference(ref)
+			require.NoError(t, err)
+			img, err := random.Image(100, 1)
+			require.NoError(t, err)
+			require.NoError(t, remote.Write(parsedRef, img))
+
+			r := NewRegistry(portercontext.NewTestContext(t).Context)
+			subject := cnab.MustParseOCIReference(ref)
+			opts := RegistryOptions{}
+
+			artifacts, err := r.ListArtifacts(ctx, subject, "application/spdx+json", opts)
+			require.NoError(t, err)
+			assert.Empty(t, artifacts)
+
+			sbom := Artifact{
+				ArtifactType: "application/spdx+json",
+				MediaType:    "application/spdx+json",
+				Content:      []byte(`{"spdxVersion":"SPDX-2.3"}`),
+				Annotations:  map[string]string{"org.opencontainers.image.created": "2024-01-01T00:00:00Z"},
+			}
+			d, err := r.AttachArtifact(ctx, subject, sbom, opts)
+			require.NoError(t, err)
+
+			other := Artifact{ArtifactType: "application/vnd.cyclonedx+json", MediaType: "application/vnd.cyclonedx+json", Content: []byte(`{}`)}
+			_, err = r.AttachArtifact(ctx, subject, other, opts)
+			require.NoError(t, err)
+
+			artifacts, err = r.ListArtifacts(ctx, subject, "application/spdx+json", opts)
+			require.NoError(t, err)
+			require.Len(t, artifacts, 1, "only artifacts of the requested type should be listed")
+			sbom.Digest = d
+			assert.Equal(t, sbom, artifacts[0])
+		})
+	}
+}
+
+func TestRegistry_AttachArtifact_SubjectNotFound(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+
+	r := NewRegistry(portercontext.NewTestContext(t).Context)
+	subject := cnab.MustParseOCIReference(strings.TrimPrefix(server.URL, "http://") + "/missing:v1.0.0")
+	_, err := r.AttachArtifact(context.Background(), subject, Artifact{ArtifactType: "application/spdx+json"}, RegistryOptions{})
+	require.ErrorIs(t, err, ErrNotFound{})
+}
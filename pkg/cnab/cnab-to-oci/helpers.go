@@ -20,12 +20,18 @@ type TestRegistry struct {
 	MockPullImage         func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) error
 	MockGetBundleMetadata func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (BundleMetadata, error)
 	MockGetImageMetadata  func(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (ImageMetadata, error)
+	MockAttachArtifact    func(ctx context.Context, subject cnab.OCIReference, artifact Artifact, opts RegistryOptions) (digest.Digest, error)
+	MockListArtifacts     func(ctx context.Context, subject cnab.OCIReference, artifactType string, opts RegistryOptions) ([]Artifact, error)
 	cache                 map[string]ImageMetadata
+
+	// Artifacts that were attached, keyed by the subject reference.
+	Artifacts map[string][]Artifact
 }
 
 func NewTestRegistry() *TestRegistry {
 	return &TestRegistry{
-		cache: make(map[string]ImageMetadata),
+		cache:     make(map[string]ImageMetadata),
+		Artifacts: make(map[string][]Artifact),
 	}
 }
 
@@ -115,3 +121,27 @@ func (t TestRegistry) GetBundleMetadata(ctx context.Context, ref cnab.OCIReferen
 
 	return BundleMetadata{}, ErrNotFound{Reference: ref}
 }
+
+func (t *TestRegistry) AttachArtifact(ctx context.Context, subject cnab.OCIReference, artifact Artifact, opts RegistryOptions) (digest.Digest, error) {
+	if t.MockAttachArtifact != nil {
+		return t.MockAttachArtifact(ctx, subject, artifact, opts)
+	}
+
+	artifact.Digest = digest.FromBytes(artifact.Content)
+	t.Artifacts[subject.String()] = append(t.Artifacts[subject.String()], artifact)
+	return artifact.Digest, nil
+}
+
+func (t *TestRegistry) ListArtifacts(ctx context.Context, subject cnab.OCIReference, artifactType string, opts RegistryOptions) ([]Artifact, error) {
+	if t.MockListArtifacts != nil {
+		return t.MockListArtifacts(ctx, subject, artifactType, opts)
+	}
+
+	var artifacts []Artifact
+	for _, artifact := range t.Artifacts[subject.String()] {
+		if artifact.ArtifactType == artifactType {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts, nil
+}
@@ -38,6 +38,13 @@ type RegistryProvider interface {
 	// GetBundleMetadata returns information about a bundle in a registry
 	// Use ErrNotFound to detect if the error is because the bundle is not in the registry.
 	GetBundleMetadata(ctx context.Context, ref cnab.OCIReference, opts RegistryOptions) (BundleMetadata, error)
+
+	// AttachArtifact pushes an artifact that refers to the manifest of the subject.
+	// Returns the digest of the artifact manifest.
+	AttachArtifact(ctx context.Context, subject cnab.OCIReference, artifact Artifact, opts RegistryOptions) (digest.Digest, error)
+
+	// ListArtifacts returns the artifacts of the specified type that refer to the subject.
+	ListArtifacts(ctx context.Context, subject cnab.OCIReference, artifactType string, opts RegistryOptions) ([]Artifact, error)
 }
 
 // RegistryOptions is the set of options for interacting with an OCI registry.
@@ -17,6 +17,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
 // ManifestConverter converts from a porter manifest to a CNAB bundle definition.
@@ -68,6 +69,11 @@ func (c *ManifestConverter) ToBundle(ctx context.Context) (cnab.ExtendedBundle,
 			Digest:    c.ImageDigests[c.Manifest.Image],
 		},
 	}
+	if len(c.Manifest.Platforms) > 1 {
+		// The digest of a multi-platform bundle image is the digest of the image index,
+		// and the driver pulls the image for the platform of the host that runs the bundle
+		image.MediaType = string(types.OCIImageIndex)
+	}
 
 	b.Actions = c.generateCustomActionDefinitions()
 	b.Definitions = make(definition.Definitions, len(c.Manifest.Parameters)+len(c.Manifest.Outputs)+len(c.Manifest.StateBag))
@@ -155,6 +155,43 @@ func TestManifestConverter_ToBundle(t *testing.T) {
 	}
 }
 
+func TestManifestConverter_ToBundle_Platforms(t *testing.T) {
+	testcases := []struct {
+		name          string
+		platforms     []string
+		wantMediaType string
+	}{
+		{name: "default platform"},
+		{name: "single platform", platforms: []string{"linux/arm64"}},
+		{name: "multiple platforms", platforms: []string{"linux/amd64", "linux/arm64"}, wantMediaType: "application/vnd.oci.image.index.v1+json"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.NewTestConfig(t)
+			c.TestContext.AddTestFileFromRoot("tests/testdata/mybuns/porter.yaml", config.Name)
+
+			ctx := context.Background()
+			m, err := manifest.LoadManifestFrom(ctx, c.Config, config.Name)
+			require.NoError(t, err, "could not load manifest")
+			m.Platforms = tc.platforms
+
+			a := NewManifestConverter(c.Config, m, nil, nil, false)
+
+			bun, err := a.ToBundle(ctx)
+			require.NoError(t, err, "ToBundle failed")
+			assert.Equal(t, tc.wantMediaType, bun.InvocationImages[0].MediaType)
+
+			stamp, err := LoadStamp(bun)
+			require.NoError(t, err, "could not load porter's stamp")
+			assert.Equal(t, tc.platforms, stamp.Platforms)
+		})
+	}
+}
+
 func TestManifestConverter_generateBundleCredentials(t *testing.T) {
 	t.Parallel()
 
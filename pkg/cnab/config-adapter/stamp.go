@@ -39,6 +39,9 @@ type Stamp struct {
 	Version      string `json:"version"`
 	Commit       string `json:"commit"`
 	PreserveTags bool   `json:"preserveTags"`
+
+	// Platforms that the bundle image was built for, when they were specified.
+	Platforms []string `json:"platforms,omitempty"`
 }
 
 // DecodeManifest base64 decodes the manifest stored in the stamp
@@ -126,6 +129,7 @@ func (c *ManifestConverter) GenerateStamp(ctx context.Context, preserveTags bool
 	}
 	stamp.EncodedManifest = base64.StdEncoding.EncodeToString(rawManifest)
 	stamp.PreserveTags = preserveTags
+	stamp.Platforms = c.Manifest.Platforms
 
 	stamp.Mixins = make(map[string]MixinRecord, len(c.Manifest.Mixins))
 	usedMixins := c.getUsedMixinRecords()
@@ -154,9 +158,10 @@ func (c *ManifestConverter) DigestManifest() (string, error) {
 		return "", fmt.Errorf("the specified porter configuration file %s does not exist", c.Manifest.ManifestPath)
 	}
 
-	data, err := c.config.FileSystem.ReadFile(c.Manifest.ManifestPath)
+	// Include the files merged into the manifest, so that changing them rebuilds the bundle
+	data, err := manifest.ReadManifestData(c.config.Context, c.Manifest.ManifestPath)
 	if err != nil {
-		return "", fmt.Errorf("could not read manifest at %q: %w", c.Manifest.ManifestPath, err)
+		return "", err
 	}
 
 	v := pkg.Version
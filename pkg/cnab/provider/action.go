@@ -134,7 +134,7 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 	// Check if we've been asked to stop before executing long blocking calls
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return context.Cause(ctx)
 	default:
 		currentRun := args.Run
 		ctx, log := tracing.StartSpan(ctx,
@@ -170,7 +170,7 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 
 		// Resolve parameters and credentials just-in-time (JIT) before running the bundle, do this at the *LAST* possible moment
 		log.Info("Just-in-time resolving credentials...")
-		if err = r.loadCredentials(ctx, b, &currentRun); err != nil {
+		if err = r.loadCredentials(ctx, b, args.Installation, &currentRun); err != nil {
 			return log.Errorf("could not resolve credentials before running the bundle: %w", err)
 		}
 		log.Info("Just-in-time resolving parameters...")
@@ -179,7 +179,7 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 		}
 
 		if currentRun.ShouldRecord() {
-			err = r.SaveRun(ctx, args.Installation, currentRun, cnab.StatusRunning)
+			err = r.SaveRun(ctx, &args.Installation, currentRun, cnab.StatusRunning)
 			if err != nil {
 				return log.Errorf("could not save the pending action's status, the bundle was not executed: %w", err)
 			}
@@ -193,6 +193,15 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 			tracing.ObjectAttribute("cnab-credentials", cnabCreds))
 		opResult, result, err := a.Run(cnabClaim, cnabCreds, r.ApplyConfig(ctx, args)...)
 
+		// The context is canceled while the bundle runs when porter can no longer
+		// guarantee that it is the only process acting on the installation, for
+		// example when its lock on the installation was lost. Record the outcome
+		// of the bundle, but fail the run.
+		if ctx.Err() != nil {
+			err = errors.Join(err, fmt.Errorf("the %s action was interrupted: %w", currentRun.Action, context.Cause(ctx)))
+			ctx = context.WithoutCancel(ctx)
+		}
+
 		if currentRun.ShouldRecord() {
 			if err != nil {
 				err = r.appendFailedResult(ctx, err, currentRun)
@@ -209,8 +218,9 @@ func (r *Runtime) Execute(ctx context.Context, args ActionArguments) error {
 	}
 }
 
-// SaveRun with the specified status.
-func (r *Runtime) SaveRun(ctx context.Context, installation storage.Installation, run storage.Run, status string) error {
+// SaveRun with the specified status. The installation is updated with the
+// revision of the saved installation document.
+func (r *Runtime) SaveRun(ctx context.Context, installation *storage.Installation, run storage.Run, status string) error {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
@@ -218,11 +228,13 @@ func (r *Runtime) SaveRun(ctx context.Context, installation storage.Installation
 
 	// update installation record to use run id encoded parameters instead of
 	// installation id
-	installation.Parameters.Parameters = run.ParameterOverrides.Parameters
-	err := r.installations.UpsertInstallation(ctx, installation)
+	saved := *installation
+	saved.Parameters.Parameters = run.ParameterOverrides.Parameters
+	err := r.installations.UpsertInstallation(ctx, &saved)
 	if err != nil {
 		return span.Error(fmt.Errorf("error saving the installation record before executing the bundle: %w", err))
 	}
+	installation.DocumentRevision = saved.DocumentRevision
 
 	err = r.installations.UpsertRun(ctx, run)
 	if err != nil {
@@ -245,8 +257,6 @@ func (r *Runtime) SaveOperationResult(ctx context.Context, opResult driver.Opera
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
-	// TODO(carolynvs): optimistic locking on updates
-
 	// Keep accumulating errors from any error returned from the operation
 	// We must save the claim even when the op failed, but we want to report
 	// ALL errors back.
@@ -259,7 +269,7 @@ func (r *Runtime) SaveOperationResult(ctx context.Context, opResult driver.Opera
 	}
 
 	installation.ApplyResult(run, result)
-	err = r.installations.UpdateInstallation(ctx, installation)
+	err = r.installations.UpdateInstallation(ctx, &installation)
 	if err != nil {
 		bigerr = multierror.Append(bigerr, fmt.Errorf("error updating installation record for %s\n%#v: %w", installation, installation, err))
 	}
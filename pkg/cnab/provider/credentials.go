@@ -8,7 +8,7 @@ import (
 	"get.porter.sh/porter/pkg/tracing"
 )
 
-func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, run *storage.Run) error {
+func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, inst storage.Installation, run *storage.Run) error {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
@@ -28,7 +28,10 @@ func (r *Runtime) loadCredentials(ctx context.Context, b cnab.ExtendedBundle, ru
 		return span.Error(err)
 	}
 
-	err = run.SetCredentialsDigest()
+	digestKey, err := r.sanitizer.CredentialsDigestKey(ctx, inst, true)
+	if err == nil {
+		err = run.SetCredentialsDigest(digestKey)
+	}
 	if err != nil {
 		// Just warn since the digest isn't critical for running the bundle
 		// If it's not set properly, we will recalculate as needed
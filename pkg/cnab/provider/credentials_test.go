@@ -14,12 +14,26 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// assertCredentialsDigest checks that the digest of the run credentials was
+// calculated with the credentials digest key of the installation.
+func assertCredentialsDigest(t *testing.T, r *TestRuntime, inst storage.Installation, run storage.Run) {
+	key, err := r.sanitizer.CredentialsDigestKey(context.Background(), inst, false)
+	require.NoError(t, err)
+	require.NotNil(t, key, "expected loadCredentials to create the credentials digest key of the installation")
+
+	_, wantDigest, err := storage.DigestCredentials(key, run.Credentials)
+	require.NoError(t, err)
+	require.Equal(t, wantDigest, run.CredentialsDigest, "expected loadCredentials to set the digest of resolved credentials")
+}
+
 func TestRuntime_loadCredentials(t *testing.T) {
 	t.Parallel()
 
 	r := NewTestRuntime(t)
 	defer r.Close()
 
+	inst := storage.NewInstallation("", "mybuns")
+
 	r.TestCredentials.AddSecret("password", "mypassword")
 	r.TestCredentials.AddSecret("db-password", "topsecret")
 
@@ -48,10 +62,9 @@ func TestRuntime_loadCredentials(t *testing.T) {
 			},
 		}),
 	}
-	err := r.loadCredentials(context.Background(), b, &run)
+	err := r.loadCredentials(context.Background(), b, inst, &run)
 	require.NoError(t, err, "loadCredentials failed")
-	require.Equal(t, "sha256:2d6d3c91ef272afeef2bb29b2fb4b1670c756c623195e71916b8ee138fba60cb",
-		run.CredentialsDigest, "expected loadCredentials to set the digest of resolved credentials")
+	assertCredentialsDigest(t, r, inst, run)
 	require.NotEmpty(t, run.Credentials.Credentials[0].ResolvedValue, "expected loadCredentials to set the resolved value of the credentials on the Run")
 
 	gotValues := run.Credentials.ToCNAB()
@@ -82,9 +95,11 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 		r := NewTestRuntime(t)
 		defer r.Close()
 
+		inst := storage.NewInstallation("", "mybuns")
+
 		run := &storage.Run{Action: "status"}
 		b := getBundle(true)
-		err := r.loadCredentials(context.Background(), b, run)
+		err := r.loadCredentials(context.Background(), b, inst, run)
 		require.NoError(t, err, "loadCredentials failed")
 
 		gotValues := run.Credentials.ToCNAB()
@@ -96,9 +111,11 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 		r := NewTestRuntime(t)
 		defer r.Close()
 
+		inst := storage.NewInstallation("", "mybuns")
+
 		run := &storage.Run{Action: "install"}
 		b := getBundle(false)
-		err := r.loadCredentials(context.Background(), b, run)
+		err := r.loadCredentials(context.Background(), b, inst, run)
 		require.NoError(t, err, "loadCredentials failed")
 
 		gotValues := run.Credentials.ToCNAB()
@@ -110,9 +127,11 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 		r := NewTestRuntime(t)
 		defer r.Close()
 
+		inst := storage.NewInstallation("", "mybuns")
+
 		run := &storage.Run{Action: "install"}
 		b := getBundle(true)
-		err := r.loadCredentials(context.Background(), b, run)
+		err := r.loadCredentials(context.Background(), b, inst, run)
 		require.Error(t, err, "expected the credential to be required")
 	})
 
@@ -121,6 +140,8 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 		r := NewTestRuntime(t)
 		defer r.Close()
 
+		inst := storage.NewInstallation("", "mybuns")
+
 		r.TestCredentials.AddSecret("password", "mypassword")
 
 		b := getBundle(true)
@@ -135,10 +156,9 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 				},
 			}),
 		}
-		err := r.loadCredentials(context.Background(), b, run)
+		err := r.loadCredentials(context.Background(), b, inst, run)
 		require.NoError(t, err, "loadCredentials failed")
-		require.Equal(t, "sha256:2d6d3c91ef272afeef2bb29b2fb4b1670c756c623195e71916b8ee138fba60cb",
-			run.CredentialsDigest, "expected loadCredentials to set the digest of resolved credentials")
+		assertCredentialsDigest(t, r, inst, *run)
 		require.NotEmpty(t, run.Credentials.Credentials[0].ResolvedValue, "expected loadCredentials to set the resolved value of the credentials on the Run")
 
 		gotValues := run.Credentials.ToCNAB()
@@ -153,6 +173,8 @@ func TestRuntime_nonSecretValue_loadCredentials(t *testing.T) {
 	r := NewTestRuntime(t)
 	defer r.Close()
 
+	inst := storage.NewInstallation("", "mybuns")
+
 	b := cnab.NewBundle(bundle.Bundle{
 		Credentials: map[string]bundle.Credential{
 			"password": {
@@ -173,10 +195,9 @@ func TestRuntime_nonSecretValue_loadCredentials(t *testing.T) {
 			},
 		}),
 	}
-	err := r.loadCredentials(context.Background(), b, &run)
+	err := r.loadCredentials(context.Background(), b, inst, &run)
 	require.NoError(t, err, "loadCredentials failed")
-	require.Equal(t, "sha256:9b6063069a6d911421cf53b30b91836b70957c30eddc70a760eff4765b8cede5",
-		run.CredentialsDigest, "expected loadCredentials to set the digest of resolved credentials")
+	assertCredentialsDigest(t, r, inst, run)
 	require.NotEmpty(t, run.Credentials.Credentials[0].ResolvedValue, "expected loadCredentials to set the resolved value of the credentials on the Run")
 
 	gotValues := run.Credentials.ToCNAB()
@@ -2,7 +2,6 @@ package config
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +10,7 @@ import (
 
 	"get.porter.sh/porter/pkg/experimental"
 	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/sbom"
 	"get.porter.sh/porter/pkg/schema"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/spf13/viper"
@@ -55,6 +55,19 @@ const (
 	// bundle image, containing the name of the installation.
 	EnvPorterInstallationName = "PORTER_INSTALLATION_NAME"
 
+	// EnvPorterClient is the name of the environment variable that identifies the
+	// client which invoked porter, such as the Porter Operator. It is recorded on
+	// each run and defaults to cli when it is not set.
+	EnvPorterClient = "PORTER_CLIENT"
+
+	// EnvSopsAgeKeyFile is the environment variable that SOPS uses for the path
+	// to the age identities that decrypt documents.
+	EnvSopsAgeKeyFile = "SOPS_AGE_KEY_FILE"
+
+	// EnvSopsAgeKey is the environment variable that SOPS uses for age
+	// identities that are specified directly instead of in a key file.
+	EnvSopsAgeKey = "SOPS_AGE_KEY"
+
 	// DefaultVerbosity is the default value for the --verbosity flag.
 	DefaultVerbosity = "info"
 )
@@ -183,7 +196,7 @@ func (c *Config) GetSecretsPlugin(name string) (SecretsPlugin, error) {
 		}
 	}
 
-	return SecretsPlugin{}, errors.New("secrets %q not defined")
+	return SecretsPlugin{}, fmt.Errorf("secrets %q not defined", name)
 }
 
 func (c *Config) GetSigningPlugin(name string) (SigningPlugin, error) {
@@ -195,7 +208,7 @@ func (c *Config) GetSigningPlugin(name string) (SigningPlugin, error) {
 		}
 	}
 
-	return SigningPlugin{}, errors.New("signing %q not defined")
+	return SigningPlugin{}, fmt.Errorf("signer %q not defined", name)
 }
 
 // GetHomeDir determines the absolute path to the porter home directory.
@@ -325,9 +338,42 @@ func (c *Config) SetExperimentalFlags(flags experimental.FeatureFlags) {
 // into account experimental flags.
 // Use this instead of Config.Data.BuildDriver directly.
 func (c *Config) GetBuildDriver() string {
+	if c.Data.BuildDriver == BuildDriverOCI {
+		return BuildDriverOCI
+	}
 	return BuildDriverBuildkit
 }
 
+// GetSBOMFormat returns the format of the SBOM generated for a bundle, defaulting to spdx.
+func (c *Config) GetSBOMFormat() string {
+	if c.Data.SBOMFormat == "" {
+		return sbom.DefaultFormat
+	}
+	return c.Data.SBOMFormat
+}
+
+// GetSopsAgeKeyFile returns the path to the age identities used to decrypt
+// documents encrypted with SOPS. Defaults to SOPS_AGE_KEY_FILE, and then to the
+// default location used by SOPS, XDG_CONFIG_HOME/sops/age/keys.txt.
+func (c *Config) GetSopsAgeKeyFile() (string, error) {
+	if c.Data.SopsAgeKeyFile != "" {
+		return c.Data.SopsAgeKeyFile, nil
+	}
+	if keyFile := c.Getenv(EnvSopsAgeKeyFile); keyFile != "" {
+		return keyFile, nil
+	}
+
+	configDir := c.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not determine the default location of the SOPS age key file, set %s: %w", EnvSopsAgeKeyFile, err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "sops", "age", "keys.txt"), nil
+}
+
 // GetVerbosity converts the user-specified verbosity flag into a LogLevel enum.
 func (c *Config) GetVerbosity() LogLevel {
 	return ParseLogLevel(c.Data.Verbosity)
@@ -93,6 +93,28 @@ func TestConfig_GetBuildDriver(t *testing.T) {
 	c := NewTestConfig(t)
 	c.Data.BuildDriver = "special"
 	require.Equal(t, BuildDriverBuildkit, c.GetBuildDriver(), "Default to docker when experimental is false, even when a build driver is set")
+
+	c.Data.BuildDriver = BuildDriverOCI
+	require.Equal(t, BuildDriverOCI, c.GetBuildDriver(), "The oci build driver should be used when it is set")
+}
+
+func TestConfig_GetSopsAgeKeyFile(t *testing.T) {
+	c := NewTestConfig(t)
+	c.Setenv("XDG_CONFIG_HOME", "/home/myuser/.config")
+
+	keyFile, err := c.GetSopsAgeKeyFile()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/home/myuser/.config/sops/age/keys.txt"), keyFile, "default to the location used by SOPS")
+
+	c.Setenv(EnvSopsAgeKeyFile, "/etc/sops/keys.txt")
+	keyFile, err = c.GetSopsAgeKeyFile()
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/sops/keys.txt", keyFile, "use the SOPS environment variable")
+
+	c.Data.SopsAgeKeyFile = "/home/myuser/.porter/keys.txt"
+	keyFile, err = c.GetSopsAgeKeyFile()
+	require.NoError(t, err)
+	assert.Equal(t, "/home/myuser/.porter/keys.txt", keyFile, "the config file takes precedence")
 }
 
 func TestConfig_ExportRemoteConfigAsEnvironmentVariables(t *testing.T) {
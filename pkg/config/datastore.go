@@ -8,6 +8,11 @@ const (
 	// the build driver.
 	BuildDriverBuildkit = "buildkit"
 
+	// BuildDriverOCI is the configuration value for specifying the daemonless
+	// build driver, which assembles the bundle image without Docker and saves
+	// it to an OCI image layout.
+	BuildDriverOCI = "oci"
+
 	// RuntimeDriverDocker specifies that the bundle image should be executed on docker.
 	RuntimeDriverDocker = "docker"
 
@@ -22,7 +27,7 @@ type Data struct {
 	// Values are dynamically applied to flags and don't need to be defined
 
 	// BuildDriver is the driver to use when building bundles.
-	// Available values are: buildkit.
+	// Available values are: buildkit, oci.
 	// Do not use directly, use Config.GetBuildDriver.
 	BuildDriver string `mapstructure:"build-driver"`
 
@@ -79,17 +84,31 @@ type Data struct {
 	// SigningPlugin defined in the configuration file.
 	SigningPlugin []SigningPlugin `mapstructure:"signers"`
 
+	// VerificationPolicy defines which signers must have signed a bundle before it is executed.
+	VerificationPolicy VerificationPolicy `mapstructure:"verification-policy"`
+
 	// Logs are settings related to Porter's log files.
 	Logs LogConfig `mapstructure:"logs"`
 
 	// Telemetry are settings related to Porter's tracing with open telemetry.
 	Telemetry TelemetryConfig `mapstructure:"telemetry"`
 
+	// RunRetention is the policy for pruning the run history of installations.
+	RunRetention RunRetentionConfig `mapstructure:"run-retention"`
+
+	// SopsAgeKeyFile is the path to the age identities used to decrypt documents
+	// that were encrypted with SOPS. Do not use directly, use Config.GetSopsAgeKeyFile.
+	SopsAgeKeyFile string `mapstructure:"sops-age-key-file"`
+
 	// SchemaCheck specifies how strict Porter should be when comparing the
 	// schemaVersion field on a resource with the supported schemaVersion.
 	// Supported values are: exact, minor, major, none.
 	SchemaCheck string `mapstructure:"schema-check"`
 
+	// SBOMFormat is the format of the SBOM generated for a bundle when it is built.
+	// Supported values are: spdx, cyclonedx, none. Do not use directly, use Config.GetSBOMFormat.
+	SBOMFormat string `mapstructure:"sbom-format"`
+
 	// Verbosity controls the level of messages output to the console.
 	// Use Logs.LogLevel if you want to change what is output to the logfile.
 	// Traces sent to an OpenTelemetry collector always include all levels of messages.
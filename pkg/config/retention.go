@@ -0,0 +1,13 @@
+package config
+
+// RunRetentionConfig is the policy used by porter installations runs prune to
+// decide which runs of an installation are deleted.
+type RunRetentionConfig struct {
+	// KeepLast is the number of most recent runs to keep for each installation.
+	// When zero, runs are not pruned by count.
+	KeepLast int `mapstructure:"keep-last"`
+
+	// MaxAge is the duration, such as 720h, that runs are kept. Older runs are pruned.
+	// When empty, runs are not pruned by age.
+	MaxAge string `mapstructure:"max-age"`
+}
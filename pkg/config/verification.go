@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// VerificationPolicy maps the repositories that bundles are pulled from to the
+// signers that must have signed them. When rules are defined, bundles are
+// verified automatically before they are executed.
+type VerificationPolicy struct {
+	// Rules are evaluated in order and the first rule with a repository
+	// pattern that matches a reference is applied to it. References that
+	// do not match a rule are rejected.
+	Rules []VerificationRule `mapstructure:"rules"`
+}
+
+// VerificationRule defines how references from a set of repositories are verified.
+type VerificationRule struct {
+	// Name of the rule, used to report which rule was applied to a reference.
+	Name string `mapstructure:"name"`
+
+	// Repositories are patterns that are matched against the fully qualified
+	// repository of a reference, including the registry, for example
+	// ghcr.io/getporter/* or docker.io/library/*. A pattern that ends with /**
+	// matches any repository beneath it, and ** matches every repository.
+	Repositories []string `mapstructure:"repositories"`
+
+	// Signers are the names of signers defined in the signers section of the
+	// config file. The reference must be verified by every signer.
+	Signers []string `mapstructure:"signers"`
+
+	// Identities configure the identity that each signer trusts for the
+	// references that match the rule, such as the public key used by a cosign
+	// or keypair signer, so that different repositories can be verified
+	// against different identities with the same signer.
+	Identities []VerificationIdentity `mapstructure:"identities"`
+
+	// AllowUnsigned skips verification of the references that match the rule.
+	AllowUnsigned bool `mapstructure:"allow-unsigned"`
+
+	// Local applies the rule to bundles that were not pulled from a registry,
+	// such as a bundle built from a local porter.yaml or loaded with
+	// --cnab-file. Local bundles are not signed, so the rule must also allow
+	// unsigned bundles. Local bundles are rejected when no rule sets Local.
+	Local bool `mapstructure:"local"`
+}
+
+// VerificationIdentity configures a signer for the references that match a rule.
+type VerificationIdentity struct {
+	// Signer is the name of one of the rule's signers.
+	Signer string `mapstructure:"signer"`
+
+	// Config is merged over the config of the signer defined in the signers
+	// section of the config file, and uses the same settings.
+	Config map[string]interface{} `mapstructure:"config"`
+}
+
+// IsEnabled returns true when a verification policy is defined.
+func (p VerificationPolicy) IsEnabled() bool {
+	return len(p.Rules) > 0
+}
+
+// Match returns the first rule that matches the specified repository, for
+// example ghcr.io/getporter/examples/porter-hello.
+func (p VerificationPolicy) Match(repository string) (VerificationRule, bool) {
+	for _, rule := range p.Rules {
+		if rule.Matches(repository) {
+			return rule, true
+		}
+	}
+	return VerificationRule{}, false
+}
+
+// MatchLocal returns the first rule that applies to bundles that were not
+// pulled from a registry.
+func (p VerificationPolicy) MatchLocal() (VerificationRule, bool) {
+	for _, rule := range p.Rules {
+		if rule.Local {
+			return rule, true
+		}
+	}
+	return VerificationRule{}, false
+}
+
+// Matches returns true when one of the rule's repository patterns matches the repository.
+func (r VerificationRule) Matches(repository string) bool {
+	for _, pattern := range r.Repositories {
+		if matchRepository(pattern, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetIdentity returns the identity configured by the rule for the specified signer.
+func (r VerificationRule) GetIdentity(signer string) (VerificationIdentity, bool) {
+	for _, identity := range r.Identities {
+		if identity.Signer == signer {
+			return identity, true
+		}
+	}
+	return VerificationIdentity{}, false
+}
+
+// GetSigner returns the signer defined with the specified name in the config
+// file, with the identity configured by the rule for the signer applied.
+func (r VerificationRule) GetSigner(c *Config, name string) (SigningPlugin, error) {
+	signer, err := c.GetSigningPlugin(name)
+	if err != nil {
+		return SigningPlugin{}, err
+	}
+
+	identity, ok := r.GetIdentity(name)
+	if !ok {
+		return signer, nil
+	}
+
+	cfg := make(map[string]interface{}, len(signer.Config)+len(identity.Config))
+	maps.Copy(cfg, signer.Config)
+	maps.Copy(cfg, identity.Config)
+	signer.Config = cfg
+	return signer, nil
+}
+
+func matchRepository(pattern string, repository string) bool {
+	if pattern == "**" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		if ok, _ := path.Match(prefix, repository); ok {
+			return true
+		}
+		// Match the prefix against each parent of the repository
+		for i := strings.LastIndex(repository, "/"); i > 0; i = strings.LastIndex(repository[:i], "/") {
+			if ok, _ := path.Match(prefix, repository[:i]); ok {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(pattern, repository)
+	return ok
+}
+
+// Validate the verification policy, checking that the referenced signers are defined.
+func (p VerificationPolicy) Validate(c *Config) error {
+	var errs error
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			errs = multierror.Append(errs, fmt.Errorf("verification-policy.rules[%d]: name is required", i))
+			name = fmt.Sprintf("rules[%d]", i)
+		}
+		if len(rule.Repositories) == 0 && !rule.Local {
+			errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: at least one repository pattern is required", name))
+		}
+		for _, pattern := range rule.Repositories {
+			if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: invalid repository pattern %q: %w", name, pattern, err))
+			}
+		}
+		if rule.AllowUnsigned && len(rule.Signers) > 0 {
+			errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: signers cannot be specified when allow-unsigned is true", name))
+		} else if !rule.AllowUnsigned && len(rule.Signers) == 0 {
+			errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: either signers or allow-unsigned must be specified", name))
+		}
+		if rule.Local && !rule.AllowUnsigned {
+			errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: allow-unsigned must be true when local is true because local bundles are not signed", name))
+		}
+		for _, signer := range rule.Signers {
+			if _, err := c.GetSigningPlugin(signer); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: %w", name, err))
+			}
+		}
+		seen := make(map[string]bool, len(rule.Identities))
+		for j, identity := range rule.Identities {
+			if !slices.Contains(rule.Signers, identity.Signer) {
+				errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: identities[%d]: signer %q is not one of the rule's signers", name, j, identity.Signer))
+			} else if seen[identity.Signer] {
+				errs = multierror.Append(errs, fmt.Errorf("verification policy rule %s: identities[%d]: signer %q already has an identity", name, j, identity.Signer))
+			}
+			seen[identity.Signer] = true
+		}
+	}
+	return errs
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationPolicy_Match(t *testing.T) {
+	policy := VerificationPolicy{
+		Rules: []VerificationRule{
+			{Name: "examples", Repositories: []string{"ghcr.io/getporter/examples/*"}, AllowUnsigned: true},
+			{Name: "myorg", Repositories: []string{"ghcr.io/myorg/**", "docker.io/myorg/*"}, Signers: []string{"cosign"}},
+			{Name: "default", Repositories: []string{"**"}, Signers: []string{"notation"}},
+		},
+	}
+
+	testcases := []struct {
+		repository string
+		wantRule   string
+	}{
+		{repository: "ghcr.io/getporter/examples/porter-hello", wantRule: "examples"},
+		{repository: "ghcr.io/getporter/examples/images/porter-hello", wantRule: "default"},
+		{repository: "ghcr.io/myorg/mysql", wantRule: "myorg"},
+		{repository: "ghcr.io/myorg/team/mysql", wantRule: "myorg"},
+		{repository: "ghcr.io/myorg", wantRule: "myorg"},
+		{repository: "docker.io/myorg/mysql", wantRule: "myorg"},
+		{repository: "ghcr.io/myorganization/mysql", wantRule: "default"},
+		{repository: "localhost:5000/mysql", wantRule: "default"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.repository, func(t *testing.T) {
+			rule, ok := policy.Match(tc.repository)
+			require.True(t, ok)
+			assert.Equal(t, tc.wantRule, rule.Name)
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		policy := VerificationPolicy{Rules: policy.Rules[:2]}
+		_, ok := policy.Match("localhost:5000/mysql")
+		assert.False(t, ok)
+	})
+
+	t.Run("local", func(t *testing.T) {
+		_, ok := policy.MatchLocal()
+		assert.False(t, ok, "local bundles should not match a rule that does not set local")
+
+		policy := VerificationPolicy{Rules: append([]VerificationRule{{Name: "local", Local: true, AllowUnsigned: true}}, policy.Rules...)}
+		rule, ok := policy.MatchLocal()
+		require.True(t, ok)
+		assert.Equal(t, "local", rule.Name)
+
+		rule, ok = policy.Match("ghcr.io/myorg/mysql")
+		require.True(t, ok)
+		assert.Equal(t, "myorg", rule.Name, "a local rule without repositories should not match a repository")
+	})
+}
+
+func TestVerificationPolicy_Validate(t *testing.T) {
+	c := NewTestConfig(t)
+	c.Data.SigningPlugin = []SigningPlugin{{PluginConfig{Name: "cosign", PluginSubKey: "cosign"}}}
+
+	t.Run("valid", func(t *testing.T) {
+		policy := VerificationPolicy{
+			Rules: []VerificationRule{
+				{Name: "examples", Repositories: []string{"ghcr.io/getporter/examples/*"}, AllowUnsigned: true},
+				{Name: "local", Local: true, AllowUnsigned: true},
+				{Name: "default", Repositories: []string{"**"}, Signers: []string{"cosign"}},
+			},
+		}
+		require.NoError(t, policy.Validate(c.Config))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		policy := VerificationPolicy{
+			Rules: []VerificationRule{
+				{Repositories: []string{"ghcr.io/["}, Signers: []string{"cosign"}},
+				{Name: "both", Repositories: []string{"**"}, Signers: []string{"cosign"}, AllowUnsigned: true},
+				{Name: "neither", Repositories: []string{"**"}},
+				{Name: "missing-signer", Signers: []string{"notation"}},
+				{Name: "signed-local", Local: true, Signers: []string{"cosign"}},
+				{Name: "identities", Repositories: []string{"**"}, Signers: []string{"cosign"}, Identities: []VerificationIdentity{
+					{Signer: "cosign"}, {Signer: "cosign"}, {Signer: "notation"},
+				}},
+			},
+		}
+		err := policy.Validate(c.Config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "verification-policy.rules[0]: name is required")
+		assert.Contains(t, err.Error(), `verification policy rule rules[0]: invalid repository pattern "ghcr.io/["`)
+		assert.Contains(t, err.Error(), "verification policy rule both: signers cannot be specified when allow-unsigned is true")
+		assert.Contains(t, err.Error(), "verification policy rule neither: either signers or allow-unsigned must be specified")
+		assert.Contains(t, err.Error(), "verification policy rule missing-signer: at least one repository pattern is required")
+		assert.Contains(t, err.Error(), `verification policy rule missing-signer: signer "notation" not defined`)
+		assert.Contains(t, err.Error(), "verification policy rule signed-local: allow-unsigned must be true when local is true")
+		assert.NotContains(t, err.Error(), "verification policy rule signed-local: at least one repository pattern is required")
+		assert.Contains(t, err.Error(), `verification policy rule identities: identities[1]: signer "cosign" already has an identity`)
+		assert.Contains(t, err.Error(), `verification policy rule identities: identities[2]: signer "notation" is not one of the rule's signers`)
+		assert.NotContains(t, err.Error(), "identities[0]")
+	})
+}
+
+func TestVerificationRule_GetSigner(t *testing.T) {
+	c := NewTestConfig(t)
+	c.Data.SigningPlugin = []SigningPlugin{
+		{PluginConfig{Name: "cosign", PluginSubKey: "cosign", Config: map[string]interface{}{"publickey": "default.pub", "registrymode": "legacy"}}},
+	}
+
+	t.Run("without identity", func(t *testing.T) {
+		rule := VerificationRule{Name: "default", Signers: []string{"cosign"}}
+		signer, err := rule.GetSigner(c.Config, "cosign")
+		require.NoError(t, err)
+		assert.Equal(t, c.Data.SigningPlugin[0], signer)
+	})
+
+	t.Run("with identity", func(t *testing.T) {
+		rule := VerificationRule{Name: "myorg", Signers: []string{"cosign"}, Identities: []VerificationIdentity{
+			{Signer: "cosign", Config: map[string]interface{}{"publickey": "myorg.pub"}},
+		}}
+		signer, err := rule.GetSigner(c.Config, "cosign")
+		require.NoError(t, err)
+		assert.Equal(t, "cosign", signer.Name)
+		assert.Equal(t, map[string]interface{}{"publickey": "myorg.pub", "registrymode": "legacy"}, signer.Config)
+		assert.Equal(t, "default.pub", c.Data.SigningPlugin[0].Config["publickey"], "the signer in the config file should not be modified")
+	})
+
+	t.Run("undefined signer", func(t *testing.T) {
+		rule := VerificationRule{Name: "myorg", Signers: []string{"notation"}}
+		_, err := rule.GetSigner(c.Config, "notation")
+		require.EqualError(t, err, `signer "notation" not defined`)
+	})
+}
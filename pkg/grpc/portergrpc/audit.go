@@ -0,0 +1,46 @@
+package portergrpc
+
+import (
+	"context"
+	"net"
+
+	"get.porter.sh/porter/pkg/storage"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// runAuditFromCaller identifies the caller of an RPC so that it is recorded on
+// any runs created while handling the request. The user is only populated when
+// the caller authenticated with a verified TLS client certificate.
+func runAuditFromCaller(ctx context.Context) storage.RunAudit {
+	audit := storage.RunAudit{Client: storage.RunClientGRPC}
+
+	caller, ok := peer.FromContext(ctx)
+	if !ok {
+		return audit
+	}
+
+	if caller.Addr != nil {
+		audit.Host = caller.Addr.String()
+		if host, _, err := net.SplitHostPort(audit.Host); err == nil {
+			audit.Host = host
+		}
+	}
+
+	if tlsInfo, ok := caller.AuthInfo.(credentials.TLSInfo); ok {
+		for _, chain := range tlsInfo.State.VerifiedChains {
+			if len(chain) == 0 {
+				continue
+			}
+			cert := chain[0]
+			if cert.Subject.CommonName != "" {
+				audit.User = cert.Subject.CommonName
+			} else if len(cert.URIs) > 0 {
+				audit.User = cert.URIs[0].String()
+			}
+			break
+		}
+	}
+
+	return audit
+}
@@ -0,0 +1,42 @@
+package portergrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestRunAuditFromCaller(t *testing.T) {
+	t.Run("no peer", func(t *testing.T) {
+		audit := runAuditFromCaller(context.Background())
+		assert.Equal(t, storage.RunAudit{Client: storage.RunClientGRPC}, audit)
+	})
+
+	t.Run("unauthenticated caller", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 52000},
+		})
+		audit := runAuditFromCaller(ctx)
+		assert.Equal(t, storage.RunAudit{Host: "10.0.0.5", Client: storage.RunClientGRPC}, audit)
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: "sally"}}
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 52000},
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{cert}},
+			}},
+		})
+		audit := runAuditFromCaller(ctx)
+		assert.Equal(t, storage.RunAudit{User: "sally", Host: "10.0.0.5", Client: storage.RunClientGRPC}, audit)
+	})
+}
@@ -28,7 +28,7 @@ func NewPorterServer(cfg *config.Config) (*PorterServer, error) {
 
 // NewConnectionInterceptor creates a middleware interceptor for the GRPC server that manages creating a porter connection for each requested RPC stream.
 // If the connection is unable to be created for the RPC then the RPC fails, otherwise the connection is added to the RPC context and the next handler in the
-// chain is called
+// chain is called. The caller is also added to the context so that it is recorded on any runs created by the RPC.
 func (s *PorterServer) NewConnectionInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	storage := storage.NewPluginAdapter(storageplugin.NewStore(s.PorterConfig))
 	secretStorage := secrets.NewPluginAdapter(secretsplugin.NewStore(s.PorterConfig))
@@ -40,5 +40,6 @@ func (s *PorterServer) NewConnectionInterceptor(ctx context.Context, req interfa
 	defer p.Close()
 
 	ctx = AddPorterConnectionToContext(p, ctx)
+	ctx = porter.WithRunAudit(ctx, runAuditFromCaller(ctx))
 	return handler(ctx, req)
 }
@@ -113,11 +113,19 @@ type Location struct {
 	//      description: THIS IS THE STEP DESCRIPTION
 	//      command: ./helper.sh
 	StepDescription string
+
+	// File that defines the step, when it is defined in a file included by the manifest.
+	// The StepNumber is the position of the step within the action in this file.
+	File string `json:",omitempty"`
 }
 
 func (l Location) String() string {
-	return fmt.Sprintf("%s: %s step in the %s mixin (%s)",
+	location := fmt.Sprintf("%s: %s step in the %s mixin (%s)",
 		l.Action, humanize.Ordinal(l.StepNumber), l.Mixin, l.StepDescription)
+	if l.File != "" {
+		location += " defined in " + l.File
+	}
+	return location
 }
 
 // Results is a set of items identified by the linter.
@@ -265,6 +273,11 @@ func (l *Linter) Lint(ctx context.Context, m *manifest.Manifest, config *config.
 		results = append(results, r...)
 	}
 
+	// Point to the included file that defines each step, instead of its position in the merged manifest
+	for i, result := range results {
+		results[i].Location = locateStep(m, result.Location)
+	}
+
 	span.Debug("Getting versions for each mixin used in the manifest...")
 	err = l.validateVersionNumberConstraints(ctx, m)
 	if err != nil {
@@ -274,6 +287,23 @@ func (l *Linter) Lint(ctx context.Context, m *manifest.Manifest, config *config.
 	return results, nil
 }
 
+// locateStep updates the location of a step to the file that defines it, when
+// the step is defined in a file included by the manifest.
+func locateStep(m *manifest.Manifest, location Location) Location {
+	if location.Action == "" || location.File != "" {
+		return location
+	}
+
+	source, ok := m.GetStepSource(location.Action, location.StepNumber)
+	if !ok || source.File == "" {
+		return location
+	}
+
+	location.File = source.File
+	location.StepNumber = source.StepNumber
+	return location
+}
+
 func (l *Linter) validateVersionNumberConstraints(ctx context.Context, m *manifest.Manifest) error {
 	for _, mixin := range m.Mixins {
 		if mixin.Version != nil {
@@ -308,7 +338,8 @@ func validateSemverConstraint(name string, installedVersion string, versionConst
 func validateParamsAppliesToAction(m *manifest.Manifest, steps manifest.Steps, tmplParams manifest.ParameterDefinitions, actionName string, config *config.Config) (Results, error) {
 	var results Results
 	for stepNumber, step := range steps {
-		data, err := yaml.Marshal(step.Data)
+		// Include the step's if condition, which uses the same template data
+		data, err := yaml.Marshal(step)
 		if err != nil {
 			return nil, fmt.Errorf("error during marshalling: %w", err)
 		}
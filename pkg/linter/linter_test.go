@@ -305,6 +305,81 @@ func TestLinter_Lint_ParameterAppliesTo(t *testing.T) {
 	}
 }
 
+func TestLinter_Lint_ParameterDoesNotApplyTo_If(t *testing.T) {
+	ctx := context.Background()
+	testConfig := config.NewTestConfig(t).Config
+
+	cxt := portercontext.NewTestContext(t)
+	mixins := mixin.NewTestMixinProvider()
+	l := New(cxt.Context, mixins)
+	m := &manifest.Manifest{
+		SchemaVersion:     "1.0.1",
+		TemplateVariables: []string{"bundle.parameters.backupUrl"},
+		Parameters: map[string]manifest.ParameterDefinition{
+			"backupUrl": {
+				Name:    "backupUrl",
+				ApplyTo: []string{"install"},
+			},
+		},
+		Upgrade: manifest.Steps{
+			&manifest.Step{
+				If: "${ bundle.parameters.backupUrl }",
+				Data: map[string]interface{}{
+					"exec": map[string]interface{}{
+						"description": "Restore the backup",
+					},
+				},
+			},
+		},
+	}
+
+	results, err := l.Lint(ctx, m, testConfig)
+	require.NoError(t, err, "Lint failed")
+	require.Len(t, results, 1, "linter should have returned 1 result")
+	require.Equal(t, Code("porter-101"), results[0].Code)
+	require.Equal(t, "Parameter backupUrl does not apply to upgrade action", results[0].Message)
+}
+
+func TestLinter_Lint_IncludedSteps(t *testing.T) {
+	ctx := context.Background()
+	testConfig := config.NewTestConfig(t).Config
+
+	cxt := portercontext.NewTestContext(t)
+	mixins := mixin.NewTestMixinProvider()
+	l := New(cxt.Context, mixins)
+	m := &manifest.Manifest{
+		ManifestPath: "porter.yaml",
+		Mixins:       []manifest.MixinDeclaration{{Name: "exec"}},
+		StepSources: map[string][]manifest.StepSource{
+			"install": {
+				{StepNumber: 1},
+				{File: "shared/steps.yaml", StepNumber: 1},
+			},
+		},
+	}
+	mixins.LintResults = Results{
+		{
+			Level:    LevelWarning,
+			Location: Location{Action: "install", Mixin: "exec", StepNumber: 1, StepDescription: "Say Hello"},
+			Code:     "exec-101",
+		},
+		{
+			Level:    LevelWarning,
+			Location: Location{Action: "install", Mixin: "exec", StepNumber: 2, StepDescription: "Configure Region"},
+			Code:     "exec-101",
+		},
+	}
+
+	results, err := l.Lint(ctx, m, testConfig)
+	require.NoError(t, err, "Lint failed")
+	require.Len(t, results, 2, "linter should have returned 2 results")
+	require.Equal(t, Location{Action: "install", Mixin: "exec", StepNumber: 1, StepDescription: "Say Hello"}, results[0].Location,
+		"steps defined in the manifest should keep their location")
+	require.Equal(t, Location{Action: "install", Mixin: "exec", StepNumber: 1, StepDescription: "Configure Region", File: "shared/steps.yaml"}, results[1].Location,
+		"steps defined in an included file should be located in that file")
+	require.Equal(t, "install: 1st step in the exec mixin (Configure Region) defined in shared/steps.yaml", results[1].Location.String())
+}
+
 func TestLinter_DependencyMultipleTimes(t *testing.T) {
 	testConfig := config.NewTestConfig(t).Config
 
@@ -0,0 +1,251 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// conditionOperators are the operators supported in the if condition of a step.
+var conditionOperators = []string{"==", "!="}
+
+// StepCondition is the parsed if condition of a step, which decides if the step is run.
+//
+// The condition is either a single operand, which is true unless it is empty,
+// false or 0, or a comparison of two operands with == or !=, which must be
+// surrounded by spaces. Operands may use templating, such as
+// ${ bundle.parameters.backupUrl }, and quotes around an operand are removed.
+//
+// Examples
+//
+//	if: ${ bundle.parameters.backupUrl }
+//	if: "!${ bundle.parameters.skipMigrations }"
+//	if: ${ bundle.parameters.environment } == production
+type StepCondition struct {
+	// Negate the result of a condition with a single operand.
+	Negate bool
+
+	// Left is the first operand of the condition.
+	Left string
+
+	// Operator comparing the operands, or empty when the condition has a single operand.
+	Operator string
+
+	// Right is the second operand of a comparison.
+	Right string
+}
+
+// ParseStepCondition parses the if condition of a step.
+func ParseStepCondition(expr string) (StepCondition, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return StepCondition{}, errors.New("the condition is empty")
+	}
+
+	for _, op := range conditionOperators {
+		left, right, found := strings.Cut(expr, " "+op+" ")
+		if !found {
+			continue
+		}
+
+		c := StepCondition{
+			Left:     unquoteOperand(left),
+			Operator: op,
+			Right:    unquoteOperand(right),
+		}
+		if c.Left == "" || c.Right == "" {
+			return StepCondition{}, fmt.Errorf("the %s comparison in %q must have an operand on both sides", op, expr)
+		}
+		if strings.HasPrefix(strings.TrimSpace(left), "!") {
+			return StepCondition{}, fmt.Errorf("a comparison cannot be negated with !, use != instead: %q", expr)
+		}
+		return c, nil
+	}
+	for _, op := range conditionOperators {
+		if expr == op || strings.HasPrefix(expr, op+" ") || strings.HasSuffix(expr, " "+op) {
+			return StepCondition{}, fmt.Errorf("the %s comparison in %q must have an operand on both sides", op, expr)
+		}
+		if strings.Contains(expr, op) {
+			return StepCondition{}, fmt.Errorf("the %s operator in %q must be surrounded by spaces", op, expr)
+		}
+	}
+
+	c := StepCondition{Left: expr}
+	if strings.HasPrefix(expr, "!") {
+		c.Negate = true
+		c.Left = strings.TrimSpace(expr[1:])
+	}
+	c.Left = unquoteOperand(c.Left)
+	if c.Left == "" {
+		return StepCondition{}, fmt.Errorf("the condition %q is missing an operand", expr)
+	}
+	return c, nil
+}
+
+// Operands returns the operands of the condition.
+func (c StepCondition) Operands() []string {
+	if c.Operator == "" {
+		return []string{c.Left}
+	}
+	return []string{c.Left, c.Right}
+}
+
+// Evaluate the condition, using render to resolve the templating used in each operand.
+func (c StepCondition) Evaluate(render func(operand string) (string, error)) (bool, error) {
+	left, err := render(c.Left)
+	if err != nil {
+		return false, err
+	}
+	left = strings.TrimSpace(left)
+
+	if c.Operator == "" {
+		return isTrue(left) != c.Negate, nil
+	}
+
+	right, err := render(c.Right)
+	if err != nil {
+		return false, err
+	}
+	right = strings.TrimSpace(right)
+
+	if c.Operator == "==" {
+		return left == right, nil
+	}
+	return left != right, nil
+}
+
+// isTrue checks if the value of an operand is true.
+func isTrue(value string) bool {
+	return value != "" && value != "0" && !strings.EqualFold(value, "false")
+}
+
+// unquoteOperand trims the whitespace and the quotes around an operand.
+func unquoteOperand(operand string) string {
+	operand = strings.TrimSpace(operand)
+	if len(operand) >= 2 {
+		first, last := operand[0], operand[len(operand)-1]
+		if first == last && (first == '"' || first == '\'') {
+			return operand[1 : len(operand)-1]
+		}
+	}
+	return operand
+}
+
+// validateCondition checks that the if condition of the step can be parsed,
+// and that it only uses variables that are defined by the bundle.
+func (s *Step) validateCondition(m *Manifest) error {
+	if s.If == "" {
+		return nil
+	}
+
+	c, err := ParseStepCondition(s.If)
+	if err != nil {
+		return fmt.Errorf("invalid if condition: %w", err)
+	}
+
+	for _, operand := range c.Operands() {
+		vars, err := m.getTemplateVariables(operand)
+		if err != nil {
+			return fmt.Errorf("invalid if condition: %w", err)
+		}
+		for variable := range vars {
+			if !m.isTemplateVariableDefined(variable) {
+				return fmt.Errorf("invalid if condition: unknown variable %s", variable)
+			}
+		}
+	}
+	return nil
+}
+
+// isTemplateVariableDefined checks if a template variable refers to data that
+// is defined by the bundle, such as a parameter, credential, output or dependency.
+func (m *Manifest) isTemplateVariableDefined(variable string) bool {
+	parts := strings.Split(variable, ".")
+	switch parts[0] {
+	case "env":
+		return len(parts) == 2
+	case "installation":
+		return len(parts) == 2 && (parts[1] == "name" || parts[1] == "namespace")
+	case "bundle":
+	default:
+		return false
+	}
+
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "name", "version", "description", "installerImage":
+			return true
+		}
+		return false
+	}
+	if len(parts) < 3 {
+		return false
+	}
+
+	name := parts[2]
+	switch parts[1] {
+	case "parameters":
+		_, ok := m.Parameters[name]
+		return ok && len(parts) == 3
+	case "credentials":
+		_, ok := m.Credentials[name]
+		return ok && len(parts) == 3
+	case "outputs":
+		if len(parts) != 3 {
+			return false
+		}
+		if _, ok := m.Outputs[name]; ok {
+			return true
+		}
+		_, ok := m.getStepOutputNames()[name]
+		return ok
+	case "images":
+		_, ok := m.ImageMap[name]
+		return ok
+	case "custom":
+		return true
+	case "dependencies":
+		for _, dep := range m.Dependencies.Requires {
+			if dep.Name == name {
+				return (len(parts) == 4 && parts[3] != "outputs") || (len(parts) == 5 && parts[3] == "outputs")
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// getStepOutputNames returns the names of the outputs declared by the steps in the manifest.
+func (m *Manifest) getStepOutputNames() map[string]struct{} {
+	names := map[string]struct{}{}
+	collect := func(steps Steps) {
+		for _, step := range steps {
+			if step == nil {
+				continue
+			}
+			stepData, ok := step.Data[step.GetMixinName()].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			outputs, ok := stepData["outputs"].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, output := range outputs {
+				if outputData, ok := output.(map[string]interface{}); ok {
+					if name, ok := outputData["name"].(string); ok {
+						names[name] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	collect(m.Install)
+	collect(m.Upgrade)
+	collect(m.Uninstall)
+	for _, steps := range m.CustomActions {
+		collect(steps)
+	}
+	return names
+}
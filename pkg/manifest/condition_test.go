@@ -0,0 +1,176 @@
+package manifest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStepCondition(t *testing.T) {
+	testcases := []struct {
+		expr    string
+		want    StepCondition
+		wantErr string
+	}{
+		{expr: "${ bundle.parameters.backupUrl }", want: StepCondition{Left: "${ bundle.parameters.backupUrl }"}},
+		{expr: "!${ bundle.parameters.skip }", want: StepCondition{Negate: true, Left: "${ bundle.parameters.skip }"}},
+		{expr: `! "${ bundle.parameters.skip }"`, want: StepCondition{Negate: true, Left: "${ bundle.parameters.skip }"}},
+		{expr: "${ bundle.parameters.env } == production", want: StepCondition{Left: "${ bundle.parameters.env }", Operator: "==", Right: "production"}},
+		{expr: "${ bundle.parameters.env } != 'my env'", want: StepCondition{Left: "${ bundle.parameters.env }", Operator: "!=", Right: "my env"}},
+		{expr: " ", wantErr: "the condition is empty"},
+		{expr: "!", wantErr: "is missing an operand"},
+		{expr: "${ bundle.parameters.env } == ''", wantErr: "must have an operand on both sides"},
+		{expr: "${ bundle.parameters.env } ==", wantErr: "must have an operand on both sides"},
+		{expr: "!= production", wantErr: "must have an operand on both sides"},
+		{expr: "!${ bundle.parameters.env } == production", wantErr: "use != instead"},
+		{expr: "${ bundle.parameters.env }==production", wantErr: "the == operator in \"${ bundle.parameters.env }==production\" must be surrounded by spaces"},
+		{expr: "${ bundle.parameters.env }!= production", wantErr: "the != operator in \"${ bundle.parameters.env }!= production\" must be surrounded by spaces"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := ParseStepCondition(tc.expr)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestStepCondition_Evaluate(t *testing.T) {
+	values := map[string]string{
+		"${ empty }": "",
+		"${ zero }":  "0",
+		"${ false }": "False",
+		"${ url }":   " https://example.com ",
+		"${ env }":   "production",
+	}
+	render := func(operand string) (string, error) {
+		if value, ok := values[operand]; ok {
+			return value, nil
+		}
+		return operand, nil
+	}
+
+	testcases := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "${ url }", want: true},
+		{expr: "${ empty }", want: false},
+		{expr: "${ zero }", want: false},
+		{expr: "${ false }", want: false},
+		{expr: "!${ empty }", want: true},
+		{expr: "!${ url }", want: false},
+		{expr: "${ env } == production", want: true},
+		{expr: "${ env } == staging", want: false},
+		{expr: "${ env } != production", want: false},
+		{expr: "${ url } == https://example.com", want: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.expr, func(t *testing.T) {
+			c, err := ParseStepCondition(tc.expr)
+			require.NoError(t, err)
+			got, err := c.Evaluate(render)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLoadManifest_StepCondition(t *testing.T) {
+	mContent := `schemaVersion: 1.0.1
+name: conditions
+version: 0.1.0
+registry: localhost:5000
+
+mixins:
+  - exec
+
+parameters:
+  - name: backupUrl
+    type: string
+    default: ""
+
+credentials:
+  - name: token
+    env: TOKEN
+
+outputs:
+  - name: endpoint
+    type: string
+
+dependencies:
+  requires:
+    - name: mysql
+      bundle:
+        reference: getporter/mysql:v0.1.0
+
+install:
+  - exec:
+      description: "Create the database"
+      command: ./helpers.sh
+      outputs:
+        - name: database_url
+          regex: "(.*)"
+  - if: CONDITION
+    exec:
+      description: "Restore the backup"
+      command: ./helpers.sh
+
+upgrade:
+  - exec:
+      description: "Upgrade"
+      command: ./helpers.sh
+
+uninstall:
+  - exec:
+      description: "Uninstall"
+      command: ./helpers.sh
+`
+
+	testcases := []struct {
+		condition string
+		wantErr   string
+	}{
+		{condition: "${ bundle.parameters.backupUrl }"},
+		{condition: `"!${ bundle.credentials.token }"`},
+		{condition: "${ bundle.outputs.endpoint }"},
+		{condition: "${ bundle.outputs.database_url }"},
+		{condition: "${ bundle.dependencies.mysql.outputs.password }"},
+		{condition: "${ installation.name } == ${ env.NAME }"},
+		{condition: "${ bundle.parameters.backup }", wantErr: "failed to validate 2nd step: invalid if condition: unknown variable bundle.parameters.backup"},
+		{condition: "${ bundle.dependencies.postgres.outputs.password }", wantErr: "unknown variable bundle.dependencies.postgres.outputs.password"},
+		{condition: "${ bundle.paramters.backupUrl }", wantErr: "unknown variable bundle.paramters.backupUrl"},
+		{condition: "${ bundle }", wantErr: "unknown variable bundle"},
+		{condition: "${ bundle.parameters }", wantErr: "unknown variable bundle.parameters"},
+		{condition: `"!"`, wantErr: "invalid if condition: the condition \"!\" is missing an operand"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.condition, func(t *testing.T) {
+			c := config.NewTestConfig(t)
+			data := strings.Replace(mContent, "CONDITION", tc.condition, 1)
+			require.NoError(t, c.FileSystem.WriteFile(config.Name, []byte(data), pkg.FileModeWritable))
+
+			m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			step := m.Install[1]
+			assert.Equal(t, "exec", step.GetMixinName(), "the condition should not be treated as the mixin")
+			assert.NotEmpty(t, step.If)
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package manifest
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/yaml"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// includeField is the field of the manifest that lists the files to include.
+const includeField = "include"
+
+// namedSections are the sections of the manifest that are lists of definitions
+// with unique names, which can be defined in an included file.
+var namedSections = map[string]string{
+	"parameters":  "parameter",
+	"credentials": "credential",
+	"outputs":     "output",
+}
+
+// customActionsField is the section of the manifest with the custom action
+// definitions, which can be defined in an included file.
+const customActionsField = "customActions"
+
+// StepSource identifies the file that defines a step of an action, when the
+// steps of the action are merged from the files included by the manifest.
+type StepSource struct {
+	// File is the path, relative to the manifest, or the URL of the included file that defines the step.
+	// File is empty when the step is defined in the manifest.
+	File string
+
+	// StepNumber is the position of the step, starting from 1, within the action in File.
+	StepNumber int
+}
+
+// GetStepSource returns the file that defines a step of an action, and the
+// position of the step within the action in that file. It returns false when
+// the manifest does not include other files.
+func (m *Manifest) GetStepSource(action string, stepNumber int) (StepSource, bool) {
+	sources := m.StepSources[action]
+	if stepNumber < 1 || stepNumber > len(sources) {
+		return StepSource{}, false
+	}
+	return sources[stepNumber-1], true
+}
+
+// manifestFields returns the names of the fields of the manifest, which are
+// not custom actions.
+func manifestFields() map[string]struct{} {
+	fields := map[string]struct{}{}
+	manifestType := reflect.TypeOf(Manifest{})
+	for i := 0; i < manifestType.NumField(); i++ {
+		tagName := strings.Split(manifestType.Field(i).Tag.Get("yaml"), ",")[0]
+		if tagName != "" && tagName != "-" {
+			fields[tagName] = struct{}{}
+		}
+	}
+	return fields
+}
+
+// isStepsField checks if the field of the manifest is the steps of an action:
+// install, upgrade, uninstall, or a custom action.
+func isStepsField(fields map[string]struct{}, field string) bool {
+	switch field {
+	case "install", "upgrade", "uninstall":
+		return true
+	}
+	_, isManifestField := fields[field]
+	return !isManifestField
+}
+
+// includeResolver merges the files included by a manifest into the manifest.
+type includeResolver struct {
+	cxt *portercontext.Context
+
+	// manifestPath is the path or URL of the manifest that includes the other files.
+	manifestPath string
+
+	fields map[string]struct{}
+}
+
+// includedDefinitions tracks the file that defines each parameter, credential,
+// output and custom action, so that conflicts can be reported.
+type includedDefinitions map[string]string
+
+// readManifestWithIncludes reads the manifest and merges the files that it
+// includes into it. The steps of each action are returned with the file that
+// defined them, when the manifest includes other files.
+func readManifestWithIncludes(cxt *portercontext.Context, path string) ([]byte, map[string][]StepSource, error) {
+	data, err := readManifestFile(cxt, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc yaml3.Node
+	if err = yaml3.Unmarshal(data, &doc); err != nil {
+		// Let the manifest parser report the error
+		return data, nil, nil
+	}
+	root := documentRoot(&doc)
+	if root == nil || mappingValue(root, includeField) == nil {
+		return data, nil, nil
+	}
+
+	r := includeResolver{cxt: cxt, manifestPath: path, fields: manifestFields()}
+	sources, _, err := r.resolve(path, root, []string{path})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error writing the manifest merged with its included files: %w", err)
+	}
+	return merged, sources, nil
+}
+
+// resolve merges the files included by the document at path into the document.
+// chain is the list of files being included, used to detect include cycles.
+func (r *includeResolver) resolve(path string, root *yaml3.Node, chain []string) (map[string][]StepSource, includedDefinitions, error) {
+	displayPath := r.displayPath(path)
+	stepFile := displayPath
+	if path == r.manifestPath {
+		stepFile = ""
+	}
+
+	// Remember where the steps and definitions in this file were defined
+	sources := map[string][]StepSource{}
+	defined := includedDefinitions{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		field, value := root.Content[i].Value, root.Content[i+1]
+		switch {
+		case field == includeField:
+			continue
+		case field == customActionsField:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				defined[field+"."+value.Content[j].Value] = displayPath
+			}
+		case namedSections[field] != "":
+			for _, item := range value.Content {
+				if name := mappingValue(item, "name"); name != nil {
+					defined[field+"."+name.Value] = displayPath
+				}
+			}
+		case isStepsField(r.fields, field):
+			for j := range value.Content {
+				sources[field] = append(sources[field], StepSource{File: stepFile, StepNumber: j + 1})
+			}
+		}
+	}
+
+	includes, err := removeIncludes(root, displayPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, include := range includes {
+		includePath, err := resolveIncludePath(path, include)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid include %s in %s: %w", include, displayPath, err)
+		}
+		for _, parent := range chain {
+			if parent == includePath {
+				var cycle []string
+				for _, p := range append(chain, includePath) {
+					cycle = append(cycle, r.displayPath(p))
+				}
+				return nil, nil, fmt.Errorf("the manifest includes itself: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		data, err := readManifestFile(r.cxt, includePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error including %s in %s: %w", include, displayPath, err)
+		}
+		var includedDoc yaml3.Node
+		if err = yaml3.Unmarshal(data, &includedDoc); err != nil {
+			return nil, nil, fmt.Errorf("error parsing the included file %s: %w", r.displayPath(includePath), err)
+		}
+		includedRoot := documentRoot(&includedDoc)
+		if includedRoot == nil {
+			// The included file is empty
+			continue
+		}
+		if includedRoot.Kind != yaml3.MappingNode {
+			return nil, nil, fmt.Errorf("the included file %s must be a map of manifest fields", r.displayPath(includePath))
+		}
+
+		includedSources, includedDefined, err := r.resolve(includePath, includedRoot, append(chain, includePath))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = r.merge(root, includedRoot, r.displayPath(includePath), defined, includedDefined); err != nil {
+			return nil, nil, err
+		}
+		for action, actionSources := range includedSources {
+			sources[action] = append(sources[action], actionSources...)
+		}
+	}
+
+	return sources, defined, nil
+}
+
+// merge the fields of an included file into the document that includes it.
+func (r *includeResolver) merge(root *yaml3.Node, included *yaml3.Node, includedPath string, defined includedDefinitions, includedDefined includedDefinitions) error {
+	for i := 0; i+1 < len(included.Content); i += 2 {
+		keyNode, value := included.Content[i], included.Content[i+1]
+		field := keyNode.Value
+
+		switch {
+		case field == customActionsField:
+			if value.Kind != yaml3.MappingNode {
+				return fmt.Errorf("%s in the included file %s must be a map", field, includedPath)
+			}
+			target := ensureField(root, keyNode, yaml3.MappingNode)
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name := value.Content[j].Value
+				if err := defined.add(field+"."+name, "custom action", name, includedDefined); err != nil {
+					return err
+				}
+				target.Content = append(target.Content, value.Content[j], value.Content[j+1])
+			}
+		case namedSections[field] != "":
+			if value.Kind != yaml3.SequenceNode {
+				return fmt.Errorf("%s in the included file %s must be a list", field, includedPath)
+			}
+			target := ensureField(root, keyNode, yaml3.SequenceNode)
+			for _, item := range value.Content {
+				if name := mappingValue(item, "name"); name != nil {
+					if err := defined.add(field+"."+name.Value, namedSections[field], name.Value, includedDefined); err != nil {
+						return err
+					}
+				}
+				target.Content = append(target.Content, item)
+			}
+		case isStepsField(r.fields, field):
+			if value.Kind != yaml3.SequenceNode {
+				return fmt.Errorf("the %s action in the included file %s must be a list of steps", field, includedPath)
+			}
+			// Steps from an included file run after the steps already defined for the action
+			target := ensureField(root, keyNode, yaml3.SequenceNode)
+			target.Content = append(target.Content, value.Content...)
+		default:
+			return fmt.Errorf("%s cannot be defined in the included file %s, only parameters, credentials, outputs, customActions and the steps of actions can be included", field, includedPath)
+		}
+	}
+	return nil
+}
+
+// add a definition from an included file, returning an error when it was already defined.
+func (d includedDefinitions) add(key string, kind string, name string, included includedDefinitions) error {
+	if existing, ok := d[key]; ok {
+		return fmt.Errorf("the %s %s is defined in both %s and %s", kind, name, existing, included[key])
+	}
+	d[key] = included[key]
+	return nil
+}
+
+// removeIncludes removes the include field from the document, returning the files that were included.
+func removeIncludes(root *yaml3.Node, displayPath string) ([]string, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != includeField {
+			continue
+		}
+
+		var includes []string
+		if err := root.Content[i+1].Decode(&includes); err != nil {
+			return nil, fmt.Errorf("%s in %s must be a list of files: %w", includeField, displayPath, err)
+		}
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		return includes, nil
+	}
+	return nil, nil
+}
+
+// resolveIncludePath resolves the path of an included file relative to the file that includes it.
+func resolveIncludePath(parent string, include string) (string, error) {
+	if isURL(include) {
+		return include, nil
+	}
+
+	if isURL(parent) {
+		parentURL, err := url.Parse(parent)
+		if err != nil {
+			return "", err
+		}
+		includeURL, err := url.Parse(filepath.ToSlash(include))
+		if err != nil {
+			return "", err
+		}
+		return parentURL.ResolveReference(includeURL).String(), nil
+	}
+
+	if filepath.IsAbs(include) {
+		return filepath.Clean(include), nil
+	}
+	return filepath.Join(filepath.Dir(parent), include), nil
+}
+
+// displayPath returns the path of a file relative to the manifest, when it is a local file.
+func (r *includeResolver) displayPath(path string) string {
+	if isURL(path) || isURL(r.manifestPath) {
+		return path
+	}
+	if rel, err := filepath.Rel(filepath.Dir(r.manifestPath), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func documentRoot(doc *yaml3.Node) *yaml3.Node {
+	if doc.Kind != yaml3.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingValue returns the value of the key in the mapping node, or nil when it is not set.
+func mappingValue(node *yaml3.Node, key string) *yaml3.Node {
+	if node == nil || node.Kind != yaml3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ensureField returns the value of the field in the mapping node, adding the field when it is not set.
+func ensureField(node *yaml3.Node, keyNode *yaml3.Node, kind yaml3.Kind) *yaml3.Node {
+	if value := mappingValue(node, keyNode.Value); value != nil {
+		if value.Kind == kind {
+			return value
+		}
+		// The field is set to null
+		value.Kind, value.Tag, value.Value = kind, "", ""
+		return value
+	}
+
+	value := &yaml3.Node{Kind: kind}
+	node.Content = append(node.Content, &yaml3.Node{Kind: yaml3.ScalarNode, Tag: "!!str", Value: keyNode.Value}, value)
+	return value
+}
@@ -0,0 +1,190 @@
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest_Include(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestDirectory("testdata/include", "/bundle")
+
+	m, err := LoadManifestFrom(context.Background(), c.Config, "/bundle/porter.yaml")
+	require.NoError(t, err, "could not load manifest")
+
+	assert.Empty(t, m.Include, "the include field should be removed after the included files are merged")
+
+	assert.Contains(t, m.Parameters, "name")
+	assert.Contains(t, m.Parameters, "region", "the included parameters should be merged into the manifest")
+	assert.Contains(t, m.Credentials, "token")
+	assert.Contains(t, m.Outputs, "endpoint")
+	assert.Contains(t, m.CustomActionDefinitions, "status")
+
+	getDescriptions := func(steps Steps) []string {
+		var descriptions []string
+		for _, step := range steps {
+			description, err := step.GetDescription()
+			require.NoError(t, err)
+			descriptions = append(descriptions, description)
+		}
+		return descriptions
+	}
+	assert.Equal(t, []string{"Say Hello", "Configure Region"}, getDescriptions(m.Install), "the included steps should run after the steps in the manifest")
+	assert.Equal(t, []string{"Upgrade World"}, getDescriptions(m.Upgrade))
+	assert.Equal(t, []string{"Say Goodbye", "Clean Up"}, getDescriptions(m.Uninstall))
+	assert.Equal(t, []string{"Get Status"}, getDescriptions(m.CustomActions["status"]))
+	assert.Contains(t, m.TemplateVariables, "bundle.parameters.region", "the templating in the included files should be scanned")
+
+	t.Run("step sources", func(t *testing.T) {
+		source, ok := m.GetStepSource("install", 1)
+		require.True(t, ok)
+		assert.Equal(t, StepSource{StepNumber: 1}, source, "steps defined in the manifest should not have a file")
+
+		source, ok = m.GetStepSource("install", 2)
+		require.True(t, ok)
+		assert.Equal(t, StepSource{File: "shared/steps.yaml", StepNumber: 1}, source)
+
+		source, ok = m.GetStepSource("uninstall", 2)
+		require.True(t, ok)
+		assert.Equal(t, StepSource{File: "shared/actions.yaml", StepNumber: 1}, source, "nested includes should be relative to the file that includes them")
+
+		source, ok = m.GetStepSource("status", 1)
+		require.True(t, ok)
+		assert.Equal(t, StepSource{File: "shared/actions.yaml", StepNumber: 1}, source)
+
+		_, ok = m.GetStepSource("install", 3)
+		assert.False(t, ok)
+	})
+}
+
+func TestReadManifestData_WithoutInclude(t *testing.T) {
+	c := config.NewTestConfig(t)
+	want := c.TestContext.AddTestFile("testdata/simple.porter.yaml", config.Name)
+
+	got, err := ReadManifestData(c.Context, config.Name)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "a manifest without includes should be returned as is")
+}
+
+func TestReadManifestData_IncludeLocalFileNamedHttp(t *testing.T) {
+	c := config.NewTestConfig(t)
+	files := map[string]string{
+		"porter.yaml":      "name: mybuns\ninclude:\n  - httpd.yaml\n  - https/steps.yaml\n",
+		"httpd.yaml":       "parameters:\n  - name: port\n    type: integer\n",
+		"https/steps.yaml": "install:\n  - exec:\n      description: Start httpd\n",
+	}
+	for path, contents := range files {
+		require.NoError(t, c.FileSystem.WriteFile("/bundle/"+path, []byte(contents), pkg.FileModeWritable))
+	}
+
+	data, err := ReadManifestData(c.Context, "/bundle/porter.yaml")
+	require.NoError(t, err, "files whose names start with http should be read from the filesystem")
+	assert.Contains(t, string(data), "name: port")
+	assert.Contains(t, string(data), "description: Start httpd")
+}
+
+func TestReadManifestData_IncludeErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "duplicate parameter",
+			files: map[string]string{
+				"porter.yaml": "include: [params.yaml]\nparameters:\n- name: region\n  type: string\n",
+				"params.yaml": "parameters:\n- name: region\n  type: string\n",
+			},
+			wantErr: "the parameter region is defined in both porter.yaml and params.yaml",
+		},
+		{
+			name: "duplicate credential between included files",
+			files: map[string]string{
+				"porter.yaml": "include: [a.yaml, b.yaml]\n",
+				"a.yaml":      "credentials:\n- name: token\n",
+				"b.yaml":      "credentials:\n- name: token\n",
+			},
+			wantErr: "the credential token is defined in both a.yaml and b.yaml",
+		},
+		{
+			name: "duplicate custom action",
+			files: map[string]string{
+				"porter.yaml":         "include: [actions/status.yaml]\ncustomActions:\n  status:\n    description: status\n",
+				"actions/status.yaml": "customActions:\n  status:\n    description: status\n",
+			},
+			wantErr: "the custom action status is defined in both porter.yaml and actions/status.yaml",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"porter.yaml": "include: [a.yaml]\n",
+				"a.yaml":      "include: [sub/b.yaml]\n",
+				"sub/b.yaml":  "include: [../a.yaml]\n",
+			},
+			wantErr: "the manifest includes itself: porter.yaml -> a.yaml -> sub/b.yaml -> a.yaml",
+		},
+		{
+			name: "field cannot be included",
+			files: map[string]string{
+				"porter.yaml": "include: [images.yaml]\n",
+				"images.yaml": "images:\n  app:\n    repository: nginx\n",
+			},
+			wantErr: "images cannot be defined in the included file images.yaml, only parameters, credentials, outputs, customActions and the steps of actions can be included",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"porter.yaml": "include: [missing.yaml]\n",
+			},
+			wantErr: "error including missing.yaml in porter.yaml: the specified porter configuration file /bundle/missing.yaml does not exist",
+		},
+		{
+			name: "include is not a list",
+			files: map[string]string{
+				"porter.yaml": "include: params.yaml\n",
+			},
+			wantErr: "include in porter.yaml must be a list of files",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.NewTestConfig(t)
+			for path, contents := range tc.files {
+				require.NoError(t, c.FileSystem.WriteFile("/bundle/"+path, []byte(contents), pkg.FileModeWritable))
+			}
+
+			_, err := ReadManifestData(c.Context, "/bundle/porter.yaml")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestResolveIncludePath(t *testing.T) {
+	testcases := []struct {
+		parent  string
+		include string
+		want    string
+	}{
+		{parent: "porter.yaml", include: "shared/params.yaml", want: "shared/params.yaml"},
+		{parent: "/bundle/shared/steps.yaml", include: "../actions.yaml", want: "/bundle/actions.yaml"},
+		{parent: "/bundle/porter.yaml", include: "/shared/params.yaml", want: "/shared/params.yaml"},
+		{parent: "/bundle/porter.yaml", include: "https://example.com/params.yaml", want: "https://example.com/params.yaml"},
+		{parent: "https://example.com/bundles/porter.yaml", include: "shared/params.yaml", want: "https://example.com/bundles/shared/params.yaml"},
+		{parent: "/bundle/porter.yaml", include: "httpd.yaml", want: "/bundle/httpd.yaml"},
+		{parent: "/bundle/porter.yaml", include: "https/steps.yaml", want: "/bundle/https/steps.yaml"},
+		{parent: "/bundle/http-params.yaml", include: "steps.yaml", want: "/bundle/steps.yaml"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.include, func(t *testing.T) {
+			got, err := resolveIncludePath(tc.parent, tc.include)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
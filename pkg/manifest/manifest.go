@@ -2,10 +2,12 @@ package manifest
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"path"
 	"reflect"
 	"regexp"
@@ -15,6 +17,7 @@ import (
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/experimental"
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/schema"
 	"get.porter.sh/porter/pkg/tracing"
@@ -64,6 +67,13 @@ type Manifest struct {
 	// TemplateVariables are the variables used in the templating, e.g. bundle.parameters.NAME, or bundle.outputs.NAME
 	TemplateVariables []string `yaml:"-"`
 
+	// StepSources are the files that define the steps of each action, when the manifest includes other files.
+	StepSources map[string][]StepSource `yaml:"-"`
+
+	// Include is a list of files, relative to the manifest, or URLs that are merged into the manifest.
+	// Included files may only define parameters, credentials, outputs, custom actions and the steps of actions.
+	Include []string `yaml:"include,omitempty"`
+
 	// SchemaType indicates the type of resource contained in an imported file.
 	SchemaType string `yaml:"schemaType,omitempty"`
 
@@ -94,6 +104,13 @@ type Manifest struct {
 	// Dockerfile is the relative path to the Dockerfile template for the bundle image
 	Dockerfile string `yaml:"dockerfile,omitempty"`
 
+	// Platforms are the platforms, such as linux/amd64 and linux/arm64, that the bundle image is built for.
+	// When more than one platform is specified, the bundle image is a multi-platform image index.
+	Platforms []string `yaml:"platforms,omitempty"`
+
+	// BuildCache defines where the build cache for the bundle image is imported from and exported to.
+	BuildCache *BuildCache `yaml:"buildCache,omitempty"`
+
 	Mixins []MixinDeclaration `yaml:"mixins,omitempty"`
 
 	Install   Steps `yaml:"install"`
@@ -141,6 +158,19 @@ func (m *Manifest) Validate(ctx context.Context, cfg *config.Config) error {
 		result = multierror.Append(result, errors.New("no mixins declared"))
 	}
 
+	platforms, err := pkgmgmt.ParseRuntimePlatforms(m.Platforms)
+	if err != nil {
+		result = multierror.Append(result, fmt.Errorf("invalid platforms: %w", err))
+	} else {
+		m.Platforms = platforms
+	}
+
+	if m.BuildCache != nil {
+		if err = m.BuildCache.Validate(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	if m.Install == nil {
 		result = multierror.Append(result, errors.New("no install action defined"))
 	}
@@ -794,6 +824,84 @@ func (mi *MappedImage) ToOCIReference() (cnab.OCIReference, error) {
 	return ref, nil
 }
 
+// BuildCache defines the build cache sources and destinations used when the bundle image is built.
+type BuildCache struct {
+	// From are the cache sources to import, in the format of docker buildx build --cache-from.
+	From []string `yaml:"from,omitempty"`
+
+	// To are the cache destinations to export to, in the format of docker buildx build --cache-to.
+	To []string `yaml:"to,omitempty"`
+}
+
+// BuildCacheTypes are the supported types of build cache.
+var BuildCacheTypes = []string{"registry", "local", "inline"}
+
+func (c *BuildCache) Validate() error {
+	var result error
+	for _, entry := range c.From {
+		if err := ValidateBuildCacheEntry(entry, false); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid buildCache.from %s: %w", entry, err))
+		}
+	}
+	for _, entry := range c.To {
+		if err := ValidateBuildCacheEntry(entry, true); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid buildCache.to %s: %w", entry, err))
+		}
+	}
+	return result
+}
+
+// ValidateBuildCacheEntry checks that a build cache source, or destination when export is true,
+// is in the format used by docker buildx build, for example type=registry,ref=example.com/mybuns:buildcache.
+// A value without any key=value pairs is the reference of a registry cache.
+func ValidateBuildCacheEntry(entry string, export bool) error {
+	fields, err := csv.NewReader(strings.NewReader(entry)).Read()
+	if err != nil {
+		return fmt.Errorf("the value must be a comma separated list of key=value pairs: %w", err)
+	}
+
+	attrs := make(map[string]string, len(fields))
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			if len(attrs) > 0 {
+				return fmt.Errorf("invalid field %s, expected key=value", field)
+			}
+			// A registry reference, e.g. --cache-from example.com/mybuns:buildcache
+			key, value = "ref", field
+			attrs["type"] = "registry"
+		}
+		attrs[strings.ToLower(key)] = value
+	}
+
+	cacheType := attrs["type"]
+	switch cacheType {
+	case "":
+		return errors.New("the type of the build cache is required")
+	case "registry":
+		if attrs["ref"] == "" {
+			return errors.New("the ref of the registry cache is required")
+		}
+		if _, err := cnab.ParseOCIReference(attrs["ref"]); err != nil {
+			return fmt.Errorf("invalid ref of the registry cache: %w", err)
+		}
+	case "local":
+		if export && attrs["dest"] == "" {
+			return errors.New("the dest directory of the local cache is required")
+		}
+		if !export && attrs["src"] == "" {
+			return errors.New("the src directory of the local cache is required")
+		}
+	case "inline":
+		if !export {
+			return errors.New("an inline cache is imported from the bundle image, use type=registry,ref=IMAGE instead")
+		}
+	default:
+		return fmt.Errorf("unsupported build cache type %s, allowed values are: %s", cacheType, strings.Join(BuildCacheTypes, ", "))
+	}
+	return nil
+}
+
 // Dependencies defies both v2 and v1 dependencies.
 // Dependencies v1 is a subset of Dependencies v2.
 type Dependencies struct {
@@ -1081,6 +1189,9 @@ func (s Steps) Validate(m *Manifest) error {
 }
 
 type Step struct {
+	// If is a condition that must be true for the step to run, see StepCondition.
+	If string `yaml:"if,omitempty"`
+
 	Data map[string]interface{} `yaml:",inline"`
 }
 
@@ -1111,7 +1222,7 @@ func (s *Step) Validate(m *Manifest) error {
 		return err
 	}
 
-	return nil
+	return s.validateCondition(m)
 }
 
 // GetDescription returns a description of the step.
@@ -1324,18 +1435,35 @@ func readFromURL(path string) ([]byte, error) {
 	return data, nil
 }
 
-func ReadManifestData(cxt *portercontext.Context, path string) ([]byte, error) {
-	if strings.HasPrefix(path, "http") {
+// isURL determines if a path is an http or https URL, instead of a local file
+// whose name happens to start with http, such as httpd.yaml.
+func isURL(path string) bool {
+	u, err := url.Parse(path)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
+func readManifestFile(cxt *portercontext.Context, path string) ([]byte, error) {
+	if isURL(path) {
 		return readFromURL(path)
 	} else {
 		return readFromFile(cxt, path)
 	}
 }
 
+// ReadManifestData reads the manifest from a URL or a filepath, and merges
+// the files that it includes into the manifest.
+func ReadManifestData(cxt *portercontext.Context, path string) ([]byte, error) {
+	data, _, err := readManifestWithIncludes(cxt, path)
+	return data, err
+}
+
 // ReadManifest determines if specified path is a URL or a filepath.
 // After reading the data in the path it returns a Manifest and any errors
 func ReadManifest(cxt *portercontext.Context, path string, config *config.Config) (*Manifest, error) {
-	data, err := ReadManifestData(cxt, path)
+	data, stepSources, err := readManifestWithIncludes(cxt, path)
 	if err != nil {
 		return nil, err
 	}
@@ -1352,6 +1480,7 @@ func ReadManifest(cxt *portercontext.Context, path string, config *config.Config
 
 	m.ManifestPath = path
 	m.TemplateVariables = tmplResult.Variables
+	m.StepSources = stepSources
 
 	return m, nil
 }
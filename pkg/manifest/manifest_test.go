@@ -400,6 +400,97 @@ func TestManifest_Validate_Dockerfile(t *testing.T) {
 	assert.EqualError(t, err, "Dockerfile template cannot be named 'Dockerfile' because that is the filename generated during porter build")
 }
 
+func TestManifest_Validate_Platforms(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.Data.SchemaCheck = string(schema.CheckStrategyNone)
+
+	c.TestContext.AddTestFile("testdata/simple.porter.yaml", config.Name)
+
+	t.Run("valid platforms", func(t *testing.T) {
+		m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+		require.NoError(t, err, "could not load manifest")
+
+		m.Platforms = []string{"linux/amd64", "Linux/ARM64"}
+		err = m.Validate(context.Background(), c.Config)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, m.Platforms, "the platforms should be normalized")
+	})
+
+	t.Run("invalid platform", func(t *testing.T) {
+		m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+		require.NoError(t, err, "could not load manifest")
+
+		m.Platforms = []string{"windows/amd64"}
+		err = m.Validate(context.Background(), c.Config)
+		require.ErrorContains(t, err, "invalid platforms")
+	})
+}
+
+func TestManifest_Validate_BuildCache(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.Data.SchemaCheck = string(schema.CheckStrategyNone)
+
+	c.TestContext.AddTestFile("testdata/simple.porter.yaml", config.Name)
+
+	t.Run("valid build cache", func(t *testing.T) {
+		m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+		require.NoError(t, err, "could not load manifest")
+
+		m.BuildCache = &BuildCache{
+			From: []string{"example.com/mybuns:buildcache", "type=local,src=/tmp/buildcache"},
+			To:   []string{"type=registry,ref=example.com/mybuns:buildcache,mode=max", "type=local,dest=/tmp/buildcache", "type=inline"},
+		}
+		err = m.Validate(context.Background(), c.Config)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid build cache", func(t *testing.T) {
+		m, err := LoadManifestFrom(context.Background(), c.Config, config.Name)
+		require.NoError(t, err, "could not load manifest")
+
+		m.BuildCache = &BuildCache{
+			From: []string{"type=inline"},
+			To:   []string{"type=s3,bucket=mybuns"},
+		}
+		err = m.Validate(context.Background(), c.Config)
+		require.ErrorContains(t, err, "invalid buildCache.from type=inline")
+		require.ErrorContains(t, err, "invalid buildCache.to type=s3,bucket=mybuns: unsupported build cache type s3")
+	})
+}
+
+func TestValidateBuildCacheEntry(t *testing.T) {
+	testcases := []struct {
+		name    string
+		entry   string
+		export  bool
+		wantErr string
+	}{
+		{name: "registry reference", entry: "example.com/mybuns:buildcache"},
+		{name: "registry", entry: "type=registry,ref=example.com/mybuns:buildcache", export: true},
+		{name: "registry without ref", entry: "type=registry", wantErr: "the ref of the registry cache is required"},
+		{name: "invalid registry ref", entry: "type=registry,ref=EXAMPLE", wantErr: "invalid ref of the registry cache"},
+		{name: "local source", entry: "type=local,src=/tmp/buildcache"},
+		{name: "local source without src", entry: "type=local,dest=/tmp/buildcache", wantErr: "the src directory of the local cache is required"},
+		{name: "local destination", entry: "type=local,dest=/tmp/buildcache", export: true},
+		{name: "local destination without dest", entry: "type=local,src=/tmp/buildcache", export: true, wantErr: "the dest directory of the local cache is required"},
+		{name: "inline destination", entry: "type=inline", export: true},
+		{name: "inline source", entry: "type=inline", wantErr: "an inline cache is imported from the bundle image"},
+		{name: "missing type", entry: "ref=example.com/mybuns:buildcache", wantErr: "the type of the build cache is required"},
+		{name: "unsupported type", entry: "type=gha", wantErr: "unsupported build cache type gha, allowed values are: registry, local, inline"},
+		{name: "mixed fields", entry: "type=registry,example.com/mybuns:buildcache", wantErr: "invalid field example.com/mybuns:buildcache"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBuildCacheEntry(tc.entry, tc.export)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestManifest_Validate_WrongSchema(t *testing.T) {
 	c := config.NewTestConfig(t)
 
@@ -1,14 +1,38 @@
 package mixin
 
 import (
+	"fmt"
+
 	"get.porter.sh/porter/pkg/pkgmgmt"
 )
 
 type InstallOptions struct {
 	pkgmgmt.InstallOptions
+
+	// FromLock installs the mixins pinned in the lock file instead of a single mixin.
+	FromLock bool
+
+	// LockFile is the path to the lock file used with FromLock.
+	LockFile string
 }
 
 func (o *InstallOptions) Validate(args []string) error {
 	o.PackageType = "mixin"
+	if o.FromLock {
+		return o.validateFromLock(args)
+	}
 	return o.InstallOptions.Validate(args)
 }
+
+func (o *InstallOptions) validateFromLock(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("a mixin name cannot be specified with --from-lock, but %s was received", args)
+	}
+	if o.Version != "" && o.Version != "latest" {
+		return fmt.Errorf("--version cannot be specified with --from-lock, the version is defined in the lock file")
+	}
+	if o.LockFile == "" {
+		o.LockFile = LockFileName
+	}
+	return o.PackageDownloadOptions.Validate()
+}
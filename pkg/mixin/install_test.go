@@ -14,3 +14,22 @@ func TestInstallOptions_Validate(t *testing.T) {
 	require.NoError(t, err, "Validate failed")
 	assert.NotEmpty(t, opts.FeedURL, "Feed URL was not defaulted")
 }
+
+func TestInstallOptions_Validate_FromLock(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts := InstallOptions{FromLock: true}
+		require.NoError(t, opts.Validate(nil))
+		assert.Equal(t, LockFileName, opts.LockFile)
+	})
+
+	t.Run("name specified", func(t *testing.T) {
+		opts := InstallOptions{FromLock: true}
+		require.ErrorContains(t, opts.Validate([]string{"exec"}), "a mixin name cannot be specified with --from-lock")
+	})
+
+	t.Run("version specified", func(t *testing.T) {
+		opts := InstallOptions{FromLock: true}
+		opts.Version = "v1.0.0"
+		require.ErrorContains(t, opts.Validate(nil), "--version cannot be specified with --from-lock")
+	})
+}
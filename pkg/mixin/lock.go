@@ -0,0 +1,135 @@
+package mixin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/yaml"
+	"github.com/carolynvs/aferox"
+)
+
+const (
+	// LockFileName is the name of the lock file generated next to porter.yaml.
+	LockFileName = "porter.lock"
+
+	// LockFileSchemaVersion is the schema version of the lock file.
+	LockFileSchemaVersion = "1.0.0"
+
+	lockFileHeader = "# porter.lock pins the mixins used to build the bundle. It is generated by porter build.\n" +
+		"# Install the pinned mixins with: porter mixins install --from-lock\n"
+)
+
+// LockFile pins the exact versions of the mixins used to build a bundle, so
+// that the same bundle image is built on every machine.
+type LockFile struct {
+	SchemaVersion string        `yaml:"schemaVersion"`
+	Mixins        []LockedMixin `yaml:"mixins"`
+}
+
+// LockedMixin is a mixin that is pinned by the lock file.
+type LockedMixin struct {
+	// Name of the mixin.
+	Name string `yaml:"name"`
+
+	// Version of the mixin that was installed when the lock file was generated.
+	Version string `yaml:"version"`
+
+	// Checksum of the mixin runtime that is copied into the bundle image, sha256:DIGEST.
+	Checksum string `yaml:"checksum"`
+
+	// URL from where the mixin is downloaded. Optional, defaults to the Porter mixin feed.
+	URL string `yaml:"url,omitempty"`
+
+	// FeedURL of an atom feed from where the mixin is downloaded. Optional.
+	FeedURL string `yaml:"feedUrl,omitempty"`
+}
+
+// NewLockFile creates a lock file for the mixins, sorted by name.
+func NewLockFile(mixins []LockedMixin) LockFile {
+	sorted := make([]LockedMixin, len(mixins))
+	copy(sorted, mixins)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return LockFile{SchemaVersion: LockFileSchemaVersion, Mixins: sorted}
+}
+
+// ReadLockFile reads a lock file. Use os.ErrNotExist to detect if the lock file does not exist.
+func ReadLockFile(fs aferox.Aferox, path string) (LockFile, error) {
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return LockFile{}, fmt.Errorf("error reading the lock file %s: %w", path, err)
+	}
+
+	var l LockFile
+	if err = yaml.Unmarshal(data, &l); err != nil {
+		return LockFile{}, fmt.Errorf("error parsing the lock file %s: %w", path, err)
+	}
+	if l.SchemaVersion != LockFileSchemaVersion {
+		return LockFile{}, fmt.Errorf("unsupported schemaVersion %q in the lock file %s, expected %s", l.SchemaVersion, path, LockFileSchemaVersion)
+	}
+	for i, m := range l.Mixins {
+		if m.Name == "" || m.Version == "" {
+			return LockFile{}, fmt.Errorf("invalid lock file %s: mixins[%d] must define a name and version", path, i)
+		}
+	}
+	return l, nil
+}
+
+// Write the lock file to the specified path.
+func (l LockFile) Write(fs aferox.Aferox, path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("error marshaling the lock file: %w", err)
+	}
+	data = append([]byte(lockFileHeader), data...)
+	if err = fs.WriteFile(path, data, pkg.FileModeWritable); err != nil {
+		return fmt.Errorf("error writing the lock file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Get the locked mixin with the specified name.
+func (l LockFile) Get(name string) (LockedMixin, bool) {
+	for _, m := range l.Mixins {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return LockedMixin{}, false
+}
+
+// Compare the locked mixins to the mixins that are installed, returning an
+// error that lists every difference.
+func (l LockFile) Compare(installed []LockedMixin) error {
+	var diffs []error
+	for _, m := range installed {
+		locked, ok := l.Get(m.Name)
+		if !ok {
+			diffs = append(diffs, fmt.Errorf("the %s mixin is not in the lock file", m.Name))
+			continue
+		}
+		if locked.Version != m.Version {
+			diffs = append(diffs, fmt.Errorf("the %s mixin is locked to %s but %s is installed", m.Name, locked.Version, m.Version))
+		} else if locked.Checksum != m.Checksum {
+			diffs = append(diffs, fmt.Errorf("the installed %s mixin %s has checksum %s but the lock file requires %s", m.Name, m.Version, m.Checksum, locked.Checksum))
+		}
+	}
+
+	for _, locked := range l.Mixins {
+		found := false
+		for _, m := range installed {
+			if m.Name == locked.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			diffs = append(diffs, fmt.Errorf("the %s mixin is in the lock file but is not used by the bundle", locked.Name))
+		}
+	}
+
+	return errors.Join(diffs...)
+}
@@ -0,0 +1,83 @@
+package mixin
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile_RoundTrip(t *testing.T) {
+	c := portercontext.NewTestContext(t)
+
+	lock := NewLockFile([]LockedMixin{
+		{Name: "helm3", Version: "v1.0.1", Checksum: "sha256:def", FeedURL: "https://example.com/atom.xml"},
+		{Name: "exec", Version: "v1.0.0", Checksum: "sha256:abc"},
+	})
+	assert.Equal(t, "exec", lock.Mixins[0].Name, "the mixins should be sorted by name")
+
+	require.NoError(t, lock.Write(c.FileSystem, LockFileName))
+	data, err := c.FileSystem.ReadFile(LockFileName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# porter.lock pins the mixins used to build the bundle")
+
+	readLock, err := ReadLockFile(c.FileSystem, LockFileName)
+	require.NoError(t, err)
+	assert.Equal(t, lock, readLock)
+}
+
+func TestReadLockFile_Invalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "schema version", data: "schemaVersion: 2.0.0\n", wantErr: `unsupported schemaVersion "2.0.0"`},
+		{name: "missing version", data: "schemaVersion: 1.0.0\nmixins:\n  - name: exec\n", wantErr: "mixins[0] must define a name and version"},
+		{name: "invalid yaml", data: "mixins: [", wantErr: "error parsing the lock file"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := portercontext.NewTestContext(t)
+			require.NoError(t, c.FileSystem.WriteFile(LockFileName, []byte(tc.data), 0600))
+
+			_, err := ReadLockFile(c.FileSystem, LockFileName)
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLockFile_Compare(t *testing.T) {
+	lock := NewLockFile([]LockedMixin{
+		{Name: "exec", Version: "v1.0.0", Checksum: "sha256:abc"},
+		{Name: "helm3", Version: "v1.0.1", Checksum: "sha256:def"},
+	})
+
+	t.Run("match", func(t *testing.T) {
+		err := lock.Compare([]LockedMixin{
+			{Name: "helm3", Version: "v1.0.1", Checksum: "sha256:def"},
+			{Name: "exec", Version: "v1.0.0", Checksum: "sha256:abc"},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("differences", func(t *testing.T) {
+		err := lock.Compare([]LockedMixin{
+			{Name: "exec", Version: "v1.1.0", Checksum: "sha256:abc"},
+			{Name: "az", Version: "v0.1.0", Checksum: "sha256:123"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the exec mixin is locked to v1.0.0 but v1.1.0 is installed")
+		assert.Contains(t, err.Error(), "the az mixin is not in the lock file")
+		assert.Contains(t, err.Error(), "the helm3 mixin is in the lock file but is not used by the bundle")
+	})
+
+	t.Run("checksum", func(t *testing.T) {
+		err := lock.Compare([]LockedMixin{
+			{Name: "exec", Version: "v1.0.0", Checksum: "sha256:xyz"},
+			{Name: "helm3", Version: "v1.0.1", Checksum: "sha256:def"},
+		})
+		require.ErrorContains(t, err, "the installed exec mixin v1.0.0 has checksum sha256:xyz but the lock file requires sha256:abc")
+	})
+}
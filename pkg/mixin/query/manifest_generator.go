@@ -56,7 +56,8 @@ func (g ManifestGenerator) buildInputForMixin(mixinName string) BuildInput {
 			if step.GetMixinName() != mixinName {
 				continue
 			}
-			mixinSteps = append(mixinSteps, step)
+			// The if condition is evaluated by porter, so it isn't passed to the mixin
+			mixinSteps = append(mixinSteps, &manifest.Step{Data: step.Data})
 		}
 		input.Actions[action] = mixinSteps
 	}
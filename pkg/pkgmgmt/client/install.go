@@ -11,6 +11,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/pkgmgmt"
@@ -89,7 +90,18 @@ type packages struct {
 }
 
 func (fs *FileSystem) InstallFromURL(ctx context.Context, opts pkgmgmt.InstallOptions) error {
-	return fs.installFromURLFor(ctx, opts, runtime.GOOS, runtime.GOARCH)
+	if err := fs.installFromURLFor(ctx, opts, runtime.GOOS, runtime.GOARCH); err != nil {
+		return err
+	}
+
+	for _, platform := range opts.GetExtraRuntimePlatforms() {
+		runtimeUrl := opts.GetParsedURL()
+		runtimeUrl.Path = path.Join(runtimeUrl.Path, opts.Version, fmt.Sprintf("%s-%s", opts.Name, strings.ReplaceAll(platform, "/", "-")))
+		if err := fs.downloadRuntime(ctx, opts.Name, platform, runtimeUrl); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (fs *FileSystem) installFromURLFor(ctx context.Context, opts pkgmgmt.InstallOptions, os string, arch string) error {
@@ -150,7 +162,22 @@ func (fs *FileSystem) InstallFromFeedURL(ctx context.Context, opts pkgmgmt.Insta
 		return log.Error(fmt.Errorf("%s @ %s did not publish a download for linux/amd64", opts.Name, opts.Version))
 	}
 
-	return errors.Join(err, fs.downloadPackage(ctx, opts.Name, *clientUrl, *runtimeUrl))
+	err = errors.Join(err, fs.downloadPackage(ctx, opts.Name, *clientUrl, *runtimeUrl))
+	if err != nil {
+		return err
+	}
+
+	for _, platform := range opts.GetExtraRuntimePlatforms() {
+		os, arch, _ := strings.Cut(platform, "/")
+		runtimeUrl := result.FindDownloadURL(ctx, os, arch)
+		if runtimeUrl == nil {
+			return log.Error(fmt.Errorf("%s @ %s did not publish a download for %s", opts.Name, opts.Version, platform))
+		}
+		if err = fs.downloadRuntime(ctx, opts.Name, platform, *runtimeUrl); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (fs *FileSystem) downloadPackage(ctx context.Context, name string, clientUrl url.URL, runtimeUrl url.URL) error {
@@ -166,7 +193,7 @@ func (fs *FileSystem) downloadPackage(ctx context.Context, name string, clientUr
 		return err
 	}
 
-	runtimePath := filepath.Join(pkgDir, "runtimes", name+"-runtime")
+	runtimePath := filepath.Join(pkgDir, "runtimes", pkgmgmt.RuntimeFileName(name, pkgmgmt.DefaultRuntimePlatform))
 	err = fs.downloadFile(ctx, runtimeUrl, runtimePath, true)
 	if err != nil {
 		err = errors.Join(err, fs.FileSystem.RemoveAll(pkgDir)) // If the runtime download fails, cleanup the package so it's not half installed
@@ -176,6 +203,21 @@ func (fs *FileSystem) downloadPackage(ctx context.Context, name string, clientUr
 	return nil
 }
 
+// downloadRuntime downloads the runtime of an installed package for an additional platform,
+// which is used when building a bundle image for that platform.
+func (fs *FileSystem) downloadRuntime(ctx context.Context, name string, platform string, runtimeUrl url.URL) error {
+	parentDir, err := fs.GetPackagesDir()
+	if err != nil {
+		return err
+	}
+
+	runtimePath := filepath.Join(parentDir, name, "runtimes", pkgmgmt.RuntimeFileName(name, platform))
+	if err = fs.downloadFile(ctx, runtimeUrl, runtimePath, true); err != nil {
+		return fmt.Errorf("error downloading the %s runtime for %s: %w", name, platform, err)
+	}
+	return nil
+}
+
 func (fs *FileSystem) downloadFile(ctx context.Context, url url.URL, destPath string, executable bool) error {
 	log := tracing.LoggerFromContext(ctx)
 	log.Debugf("Downloading %s to %s\n", url.String(), destPath)
@@ -127,6 +127,73 @@ func TestFileSystem_InstallFromFeedUrl(t *testing.T) {
 	assert.True(t, runtimeExists)
 }
 
+func TestFileSystem_InstallFromUrl_RuntimePlatforms(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.RequestURI)
+		fmt.Fprintf(w, "#!/usr/bin/env bash\necho i am a random package\n")
+	}))
+	defer ts.Close()
+
+	c := config.NewTestConfig(t)
+	p := NewFileSystem(c.Config, "packages")
+
+	opts := pkgmgmt.InstallOptions{
+		PackageType:      "mixin",
+		Version:          "v1.0.0",
+		URL:              ts.URL,
+		RuntimePlatforms: []string{"linux/amd64", "linux/arm64"},
+	}
+	err := opts.Validate([]string{"mypkg"})
+	require.NoError(t, err, "Validate failed")
+
+	err = p.InstallFromURL(context.Background(), opts)
+	require.NoError(t, err)
+
+	assert.Contains(t, requests, "/v1.0.0/mypkg-linux-arm64", "the arm64 runtime should have been downloaded")
+	runtimePath := "/home/myuser/.porter/packages/mypkg/runtimes/mypkg-runtime-linux-arm64"
+	runtimeStats, err := p.FileSystem.Stat(runtimePath)
+	require.NoError(t, err)
+	tests.AssertFilePermissionsEqual(t, runtimePath, pkg.FileModeExecutable, runtimeStats.Mode())
+}
+
+func TestFileSystem_InstallFromFeedUrl_MissingRuntimePlatform(t *testing.T) {
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		t.Skip("skipping because there is no release for helm for darwin/arm64")
+	}
+
+	var testURL = ""
+	feed, err := os.ReadFile("../feed/testdata/atom.xml")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.RequestURI, "atom.xml") {
+			testAtom := strings.Replace(string(feed), "https://cdn.porter.sh", testURL, -1)
+			fmt.Fprintln(w, testAtom)
+		} else {
+			fmt.Fprintf(w, "#!/usr/bin/env bash\necho i am helm\n")
+		}
+	}))
+	defer ts.Close()
+	testURL = ts.URL
+
+	c := config.NewTestConfig(t)
+	p := NewFileSystem(c.Config, "packages")
+
+	opts := pkgmgmt.InstallOptions{
+		PackageType:      "mixin",
+		Version:          "v1.2.4",
+		FeedURL:          ts.URL + "/atom.xml",
+		RuntimePlatforms: []string{"linux/arm64"},
+	}
+	err = opts.Validate([]string{"helm"})
+	require.NoError(t, err, "Validate failed")
+
+	// The feed does not have a download for linux/arm64
+	err = p.InstallFromFeedURL(context.Background(), opts)
+	tests.RequireErrorContains(t, err, "helm @ v1.2.4 did not publish a download for linux/arm64")
+}
+
 func TestFileSystem_Install_RollbackMissingRuntime(t *testing.T) {
 	// serve out a fake package
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -19,6 +19,10 @@ type InstallOptions struct {
 	parsedFeedURL *url.URL
 
 	PackageType string
+
+	// RuntimePlatforms are additional platforms, such as linux/arm64, to
+	// install the package runtime for. The runtime for linux/amd64 is always installed.
+	RuntimePlatforms []string
 }
 
 // GetParsedURL returns a copy of of the parsed URL that is safe to modify.
@@ -69,11 +73,37 @@ func (o *InstallOptions) Validate(args []string) error {
 		return err
 	}
 
+	err = o.validateRuntimePlatforms()
+	if err != nil {
+		return err
+	}
+
 	o.defaultVersion()
 
 	return nil
 }
 
+func (o *InstallOptions) validateRuntimePlatforms() error {
+	platforms, err := ParseRuntimePlatforms(o.RuntimePlatforms)
+	if err != nil {
+		return fmt.Errorf("invalid --platform: %w", err)
+	}
+	o.RuntimePlatforms = platforms
+	return nil
+}
+
+// GetExtraRuntimePlatforms returns the platforms that the runtime is installed
+// for in addition to the default platform, linux/amd64.
+func (o *InstallOptions) GetExtraRuntimePlatforms() []string {
+	platforms := make([]string, 0, len(o.RuntimePlatforms))
+	for _, platform := range o.RuntimePlatforms {
+		if platform != DefaultRuntimePlatform {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
 func (o *InstallOptions) validateURL() error {
 	if o.URL == "" {
 		return nil
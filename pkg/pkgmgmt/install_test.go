@@ -129,6 +129,21 @@ func TestInstallOptions_ValidateURL(t *testing.T) {
 	})
 }
 
+func TestInstallOptions_ValidateRuntimePlatforms(t *testing.T) {
+	t.Run("valid platforms", func(t *testing.T) {
+		opts := InstallOptions{RuntimePlatforms: []string{"linux/ARM64", "linux/amd64"}}
+		err := opts.validateRuntimePlatforms()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"linux/arm64", "linux/amd64"}, opts.RuntimePlatforms)
+		assert.Equal(t, []string{"linux/arm64"}, opts.GetExtraRuntimePlatforms(), "the default platform is always installed")
+	})
+	t.Run("invalid platform", func(t *testing.T) {
+		opts := InstallOptions{RuntimePlatforms: []string{"windows/amd64"}}
+		err := opts.validateRuntimePlatforms()
+		require.ErrorContains(t, err, "invalid --platform")
+	})
+}
+
 func TestInstallOptions_Validate(t *testing.T) {
 	t.Run("mixin", func(t *testing.T) {
 		opts := InstallOptions{
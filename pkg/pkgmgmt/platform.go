@@ -0,0 +1,56 @@
+package pkgmgmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultRuntimePlatform is the platform of the runtime binaries that are
+// installed for every package, and the platform of the bundle image when
+// no other platforms are requested.
+const DefaultRuntimePlatform = "linux/amd64"
+
+// ParseRuntimePlatform validates a platform, such as linux/arm64, that a
+// package runtime can be installed for, and returns it in its normalized form.
+// Runtimes are always executed inside the bundle image, so only linux
+// platforms are supported.
+func ParseRuntimePlatform(platform string) (string, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(platform)), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid platform %q, the platform must be in the form OS/ARCH, for example linux/arm64", platform)
+	}
+	if parts[0] != "linux" {
+		return "", fmt.Errorf("invalid platform %q, only linux platforms are supported", platform)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// ParseRuntimePlatforms validates a list of platforms with ParseRuntimePlatform,
+// removing any duplicates while preserving the order of the list.
+func ParseRuntimePlatforms(platforms []string) ([]string, error) {
+	results := make([]string, 0, len(platforms))
+	found := make(map[string]struct{}, len(platforms))
+	for _, platform := range platforms {
+		p, err := ParseRuntimePlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := found[p]; ok {
+			continue
+		}
+		found[p] = struct{}{}
+		results = append(results, p)
+	}
+	return results, nil
+}
+
+// RuntimeFileName returns the name of the runtime binary of a package that
+// is installed for the platform. The runtime for the default platform is
+// named NAME-runtime, and runtimes for other platforms are named
+// NAME-runtime-OS-ARCH, for example exec-runtime-linux-arm64.
+func RuntimeFileName(name string, platform string) string {
+	if platform == "" || platform == DefaultRuntimePlatform {
+		return name + "-runtime"
+	}
+	return fmt.Sprintf("%s-runtime-%s", name, strings.ReplaceAll(platform, "/", "-"))
+}
@@ -0,0 +1,46 @@
+package pkgmgmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuntimePlatform(t *testing.T) {
+	testcases := []struct {
+		platform string
+		want     string
+		wantErr  string
+	}{
+		{platform: "linux/amd64", want: "linux/amd64"},
+		{platform: " Linux/ARM64 ", want: "linux/arm64"},
+		{platform: "linux", wantErr: "the platform must be in the form OS/ARCH"},
+		{platform: "linux/arm64/v8", wantErr: "the platform must be in the form OS/ARCH"},
+		{platform: "darwin/arm64", wantErr: "only linux platforms are supported"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.platform, func(t *testing.T) {
+			got, err := ParseRuntimePlatform(tc.platform)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseRuntimePlatforms(t *testing.T) {
+	got, err := ParseRuntimePlatforms([]string{"linux/arm64", "linux/amd64", "LINUX/arm64"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux/arm64", "linux/amd64"}, got, "duplicate platforms should be removed")
+}
+
+func TestRuntimeFileName(t *testing.T) {
+	assert.Equal(t, "exec-runtime", RuntimeFileName("exec", ""))
+	assert.Equal(t, "exec-runtime", RuntimeFileName("exec", DefaultRuntimePlatform))
+	assert.Equal(t, "exec-runtime-linux-arm64", RuntimeFileName("exec", "linux/arm64"))
+}
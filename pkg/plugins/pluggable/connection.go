@@ -160,6 +160,10 @@ func (c *PluginConnection) Start(ctx context.Context, pluginCfg io.Reader) error
 		if pluginErr != "" {
 			pluginErr = ": plugin stderr was " + pluginErr
 		}
+		if strings.Contains(err.Error(), "Incompatible API version") {
+			err = fmt.Errorf("the plugin does not support version %d of the %s plugin protocol used by this version of Porter, upgrade the plugin: %w",
+				c.pluginType.ProtocolVersion, c.key.Interface, err)
+		}
 		err = fmt.Errorf("could not connect to the %s plugin%s: %w", c.key, pluginErr, err)
 		err = span.Error(err) // Emit the error before trying to close the connection
 		c.Close(ctx)
@@ -2,13 +2,21 @@ package porter
 
 import (
 	"context"
+	"fmt"
 
 	"get.porter.sh/porter/pkg/storage"
 )
 
 // ExecuteAction runs the specified action. Supported actions are: install, upgrade, invoke.
 // The uninstall action works in reverse so it's implemented separately.
-func (p *Porter) ExecuteAction(ctx context.Context, installation storage.Installation, action BundleAction) error {
+// Only one action may run against an installation at a time, so the caller
+// must hold the lock on the installation, taken before the installation was
+// read and modified, until the action completes.
+func (p *Porter) ExecuteAction(ctx context.Context, lock *heldInstallationLock, installation storage.Installation, action BundleAction) error {
+	if lock == nil || !lock.IsFor(installation) {
+		return fmt.Errorf("the lock on installation %s must be held to run the %s action", installation, action.GetAction())
+	}
+
 	deperator := newDependencyExecutioner(p, installation, action)
 	err := deperator.Prepare(ctx)
 	if err != nil {
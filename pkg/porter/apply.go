@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 
-	"get.porter.sh/porter/pkg/encoding"
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/printer"
 	"get.porter.sh/porter/pkg/storage"
@@ -23,6 +22,10 @@ type ApplyOptions struct {
 
 	// DryRun only checks if the changes would trigger a bundle run
 	DryRun bool
+
+	// DetectCredentialRotation re-applies the installation when the value of a
+	// credential changed since the last run, even if nothing else changed.
+	DetectCredentialRotation bool
 }
 
 const ApplyDefaultFormat = printer.FormatPlaintext
@@ -56,7 +59,7 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 
 	log.Debugf("Reading input file %s", opts.File)
 
-	namespace, err := p.getNamespaceFromFile(opts)
+	namespace, err := p.getNamespaceFromFile(ctx, opts)
 	if err != nil {
 		return log.Error(err)
 	}
@@ -68,7 +71,8 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 	}
 
 	var input DisplayInstallation
-	if err := encoding.UnmarshalFile(p.FileSystem, opts.File, &input); err != nil {
+	encrypted, err := p.unmarshalInputFile(ctx, opts.File, &input)
+	if err != nil {
 		return log.Errorf("unable to parse %s as an installation document: %w", opts.File, err)
 	}
 	input.Namespace = namespace
@@ -77,6 +81,16 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 		return log.Error(err)
 	}
 
+	// A dry run doesn't modify the installation, so it doesn't need the lock
+	var lock *heldInstallationLock
+	if !opts.DryRun {
+		ctx, lock, err = p.lockInstallation(ctx, inputInstallation.Namespace, inputInstallation.Name, storage.DefaultInstallationLockTTL)
+		if err != nil {
+			return log.Error(err)
+		}
+		defer lock.Release()
+	}
+
 	installation, err := p.Installations.GetInstallation(ctx, inputInstallation.Namespace, inputInstallation.Name)
 	if err != nil {
 		if !errors.Is(err, storage.ErrNotFound{}) {
@@ -96,12 +110,22 @@ func (p *Porter) InstallationApply(ctx context.Context, opts ApplyOptions) error
 		return log.Errorf("invalid installation: %w", err)
 	}
 
+	// Only keep the parameter values from an encrypted file in memory and the secret store
+	if encrypted && !opts.DryRun {
+		installation.Parameters.Parameters, err = p.Sanitizer.CleanValueSources(ctx, installation.Parameters.Parameters, installation.SecretOwner())
+		if err != nil {
+			return log.Error(err)
+		}
+	}
+
 	reconcileOpts := ReconcileOptions{
-		Namespace:    input.Namespace,
-		Name:         input.Name,
-		Installation: installation,
-		Force:        opts.Force,
-		DryRun:       opts.DryRun,
+		Namespace:                input.Namespace,
+		Name:                     input.Name,
+		Installation:             installation,
+		Force:                    opts.Force,
+		DryRun:                   opts.DryRun,
+		DetectCredentialRotation: opts.DetectCredentialRotation,
+		lock:                     lock,
 	}
 	return p.ReconcileInstallation(ctx, reconcileOpts)
 }
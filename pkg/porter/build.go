@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/build"
@@ -13,7 +14,9 @@ import (
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/sbom"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/Masterminds/semver/v3"
@@ -29,6 +32,13 @@ type BuildOptions struct {
 	// NoLint indicates if lint should be run before build.
 	NoLint bool
 
+	// Watch rebuilds the bundle each time that the files in the build context change.
+	Watch bool
+
+	// Locked fails the build when the installed mixins are different from
+	// the mixins pinned in porter.lock, instead of updating the lock file.
+	Locked bool
+
 	// Driver to use when building the bundle image.
 	Driver string
 
@@ -38,13 +48,16 @@ type BuildOptions struct {
 	// InsecureRegistry allows connecting to an unsecured registry or one without verifiable certificates.
 	InsecureRegistry bool
 
+	// SBOMFormat is the format of the SBOM generated for the bundle: spdx, cyclonedx or none.
+	SBOMFormat string
+
 	// parsedCustoms is the parsed set of custom inputs from Customs.
 	parsedCustoms map[string]string
 }
 
 const BuildDriverDefault = config.BuildDriverBuildkit
 
-var BuildDriverAllowedValues = []string{config.BuildDriverBuildkit}
+var BuildDriverAllowedValues = []string{config.BuildDriverBuildkit, config.BuildDriverOCI}
 
 func (o *BuildOptions) Validate(p *Porter) error {
 	if o.Version != "" {
@@ -67,7 +80,32 @@ func (o *BuildOptions) Validate(p *Porter) error {
 	// This would be less awkward if we didn't do an automatic build during publish
 	p.Data.BuildDriver = o.Driver
 
-	err := o.parseCustomInputs()
+	if o.SBOMFormat == "" {
+		o.SBOMFormat = p.GetSBOMFormat()
+	}
+	if err := sbom.ValidateFormat(o.SBOMFormat); err != nil {
+		return err
+	}
+	p.Data.SBOMFormat = o.SBOMFormat
+
+	platforms, err := pkgmgmt.ParseRuntimePlatforms(o.Platforms)
+	if err != nil {
+		return fmt.Errorf("invalid --platform: %w", err)
+	}
+	o.Platforms = platforms
+
+	for _, entry := range o.CacheFrom {
+		if err := manifest.ValidateBuildCacheEntry(entry, false); err != nil {
+			return fmt.Errorf("invalid --cache-from %s: %w", entry, err)
+		}
+	}
+	for _, entry := range o.CacheTo {
+		if err := manifest.ValidateBuildCacheEntry(entry, true); err != nil {
+			return fmt.Errorf("invalid --cache-to %s: %w", entry, err)
+		}
+	}
+
+	err = o.parseCustomInputs()
 	if err != nil {
 		return err
 	}
@@ -116,20 +154,20 @@ func (p *Porter) Build(ctx context.Context, opts BuildOptions) error {
 		return span.Error(fmt.Errorf("could not cleanup generated .cnab directory before building: %w", err))
 	}
 
-	// Generate Porter's canonical version of the user-provided manifest
-	if err := p.generateInternalManifest(ctx, opts); err != nil {
-		return fmt.Errorf("unable to generate manifest: %w", err)
-	}
-
-	m, err := manifest.LoadManifestFrom(ctx, p.Config, build.LOCAL_MANIFEST)
+	m, err := p.loadBuildManifest(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	// Capture the path to the original, user-provided manifest.
-	// This value will be referenced elsewhere, for instance by
-	// the digest logic (to dictate auto-rebuild)
-	m.ManifestPath = opts.File
+	// The build cache specified on the command line overrides the build cache in porter.yaml
+	if m.BuildCache != nil {
+		if len(opts.CacheFrom) == 0 {
+			opts.CacheFrom = m.BuildCache.From
+		}
+		if len(opts.CacheTo) == 0 {
+			opts.CacheTo = m.BuildCache.To
+		}
+	}
 
 	if !opts.NoLint {
 		if err := p.preLint(ctx, opts.File); err != nil {
@@ -137,6 +175,10 @@ func (p *Porter) Build(ctx context.Context, opts BuildOptions) error {
 		}
 	}
 
+	if err := p.lockMixins(ctx, m, opts.Dir, opts.Locked); err != nil {
+		return err
+	}
+
 	// Build bundle so that resulting bundle.json is available for inclusion
 	// into the bundle image.
 	// Note: the content digest field on the bundle image section of the
@@ -152,20 +194,68 @@ func (p *Porter) Build(ctx context.Context, opts BuildOptions) error {
 	if err := generator.PrepareFilesystem(); err != nil {
 		return span.Error(fmt.Errorf("unable to copy run script, runtimes or mixins: %s", err))
 	}
+	imageOpts := opts.BuildImageOptions
+	if generator.PlatformRuntimesDir != "" {
+		defer p.FileSystem.RemoveAll(generator.PlatformRuntimesDir)
+
+		// Make the runtimes for each platform available to the Dockerfile
+		platformsContext := fmt.Sprintf("%s=%s", build.PLATFORMS_CONTEXT, generator.PlatformRuntimesDir)
+		imageOpts.BuildContexts = append(append([]string{}, opts.BuildContexts...), platformsContext)
+	}
 	if err := generator.GenerateDockerFile(ctx); err != nil {
 		return span.Error(fmt.Errorf("unable to generate Dockerfile: %s", err))
 	}
 
 	builder := p.GetBuilder(ctx)
 
-	err = builder.BuildBundleImage(ctx, m, opts.BuildImageOptions)
+	record := build.NewRecord(opts.BuildImageOptions)
+	record.Platforms = build.GetPlatforms(m)
+	record.StartedOn = time.Now()
+	err = builder.BuildBundleImage(ctx, m, imageOpts)
 	if err != nil {
 		return span.Error(fmt.Errorf("unable to build bundle image: %w", err))
 	}
+	record.FinishedOn = time.Now()
+
+	// Remember how the image was built for the provenance statement created when the bundle is published
+	if err := p.writeBuildRecord(record); err != nil {
+		return span.Error(err)
+	}
+
+	// The digest of the bundle image is not known until it is published,
+	// and the SBOM is generated again at that time
+	if err := p.generateSBOM(ctx, m, ""); err != nil {
+		return span.Error(fmt.Errorf("unable to generate the SBOM: %w", err))
+	}
 
 	return nil
 }
 
+// loadBuildManifest generates Porter's canonical version of the user-provided manifest, and loads it.
+func (p *Porter) loadBuildManifest(ctx context.Context, opts BuildOptions) (*manifest.Manifest, error) {
+	// Generate Porter's canonical version of the user-provided manifest
+	if err := p.generateInternalManifest(ctx, opts); err != nil {
+		return nil, fmt.Errorf("unable to generate manifest: %w", err)
+	}
+
+	m, err := manifest.LoadManifestFrom(ctx, p.Config, build.LOCAL_MANIFEST)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture the path to the original, user-provided manifest.
+	// This value will be referenced elsewhere, for instance by
+	// the digest logic (to dictate auto-rebuild)
+	m.ManifestPath = opts.File
+
+	// Platforms specified on the command line override the platforms in porter.yaml
+	if len(opts.Platforms) > 0 {
+		m.Platforms = opts.Platforms
+	}
+
+	return m, nil
+}
+
 func (p *Porter) preLint(ctx context.Context, file string) error {
 	lintOpts := LintOptions{
 		PrintOptions: printer.PrintOptions{},
@@ -1,10 +1,15 @@
 package porter
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/pkgmgmt"
@@ -64,3 +69,180 @@ func TestPorter_NoErrorWhenPorterYamlIsPresent(t *testing.T) {
 	err = o.Validate(p.Porter)
 	require.NoError(t, err, "validate BuildOptions failed")
 }
+
+func TestPorter_Build_Platforms(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runtimes installed", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+		for _, runtime := range []string{
+			"/home/myuser/.porter/runtimes/porter-runtime-linux-arm64",
+			"/home/myuser/.porter/mixins/exec/runtimes/exec-runtime-linux-arm64",
+			"/home/myuser/.porter/mixins/testmixin/runtimes/testmixin-runtime-linux-arm64",
+		} {
+			require.NoError(t, p.FileSystem.WriteFile(runtime, []byte("arm64"), pkg.FileModeExecutable))
+		}
+
+		opts := BuildOptions{}
+		opts.Platforms = []string{"linux/amd64", "linux/arm64"}
+		require.NoError(t, opts.Validate(p.Porter))
+		require.NoError(t, p.Build(ctx, opts))
+
+		bun, err := cnab.LoadBundle(p.Context, build.LOCAL_BUNDLE)
+		require.NoError(t, err)
+		assert.Equal(t, "application/vnd.oci.image.index.v1+json", bun.InvocationImages[0].MediaType,
+			"the bundle image of a multi-platform bundle should be an image index")
+		stamp, err := configadapter.LoadStamp(bun)
+		require.NoError(t, err)
+		assert.Equal(t, opts.Platforms, stamp.Platforms, "the platforms should be recorded in the stamp")
+
+		dockerfile, err := p.FileSystem.ReadFile(build.DOCKER_FILE)
+		require.NoError(t, err)
+		assert.Contains(t, string(dockerfile), "COPY --link --from=porter-platforms ${TARGETOS}-${TARGETARCH}/ /cnab/")
+
+		exists, _ := p.FileSystem.Exists(".cnab/app/runtimes/porter-runtime-linux-arm64")
+		assert.False(t, exists, "runtimes for other platforms should not be copied into .cnab")
+
+		record, err := p.readBuildRecord()
+		require.NoError(t, err)
+		assert.Equal(t, opts.Platforms, record.Platforms)
+		assert.Empty(t, record.BuildContexts, "the platforms build context should not be recorded")
+	})
+
+	t.Run("missing mixin runtime", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+		require.NoError(t, p.FileSystem.WriteFile("/home/myuser/.porter/runtimes/porter-runtime-linux-arm64", []byte("arm64"), pkg.FileModeExecutable))
+
+		opts := BuildOptions{}
+		opts.Platforms = []string{"linux/arm64"}
+		require.NoError(t, opts.Validate(p.Porter))
+		err := p.Build(ctx, opts)
+		require.ErrorContains(t, err, "the exec mixin runtime for linux/arm64 is not installed, install it with porter mixins install exec --platform linux/arm64")
+	})
+
+	t.Run("invalid platform", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+		opts := BuildOptions{}
+		opts.Platforms = []string{"windows/amd64"}
+		err := opts.Validate(p.Porter)
+		require.ErrorContains(t, err, "invalid --platform")
+	})
+}
+
+func TestPorter_Build_BuildCache(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *TestPorter {
+		p := NewTestPorter(t)
+		p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+		manifest, err := p.FileSystem.ReadFile(config.Name)
+		require.NoError(t, err)
+		manifest = append(manifest, []byte(`
+buildCache:
+  from:
+    - type=registry,ref=example.com/mybuns:buildcache
+  to:
+    - type=registry,ref=example.com/mybuns:buildcache,mode=max
+`)...)
+		require.NoError(t, p.FileSystem.WriteFile(config.Name, manifest, pkg.FileModeWritable))
+		return p
+	}
+
+	t.Run("from porter.yaml", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := BuildOptions{}
+		require.NoError(t, opts.Validate(p.Porter))
+		require.NoError(t, p.Build(ctx, opts))
+
+		builder := p.builder.(*TestBuildProvider)
+		assert.Equal(t, []string{"type=registry,ref=example.com/mybuns:buildcache"}, builder.BuildOptions.CacheFrom)
+		assert.Equal(t, []string{"type=registry,ref=example.com/mybuns:buildcache,mode=max"}, builder.BuildOptions.CacheTo)
+	})
+
+	t.Run("flags override porter.yaml", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := BuildOptions{}
+		opts.CacheFrom = []string{"type=local,src=/tmp/buildcache"}
+		opts.CacheTo = []string{"type=inline"}
+		require.NoError(t, opts.Validate(p.Porter))
+		require.NoError(t, p.Build(ctx, opts))
+
+		builder := p.builder.(*TestBuildProvider)
+		assert.Equal(t, opts.CacheFrom, builder.BuildOptions.CacheFrom)
+		assert.Equal(t, opts.CacheTo, builder.BuildOptions.CacheTo)
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		opts := BuildOptions{}
+		opts.CacheFrom = []string{"type=inline"}
+		err := opts.Validate(p.Porter)
+		require.ErrorContains(t, err, "invalid --cache-from type=inline")
+	})
+}
+
+func TestPorter_Build_Include(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+	manifestData, err := p.FileSystem.ReadFile(config.Name)
+	require.NoError(t, err)
+	manifestData = append(manifestData, []byte(`
+include:
+  - shared/extras.yaml
+`)...)
+	require.NoError(t, p.FileSystem.WriteFile(config.Name, manifestData, pkg.FileModeWritable))
+	require.NoError(t, p.FileSystem.WriteFile("shared/extras.yaml", []byte(`parameters:
+  - name: region
+    type: string
+    default: eastus
+
+install:
+  - exec:
+      description: "Configure the region"
+      command: echo
+      arguments:
+        - ${ bundle.parameters.region }
+`), pkg.FileModeWritable))
+
+	opts := BuildOptions{}
+	require.NoError(t, opts.Validate(p.Porter))
+	require.NoError(t, p.Build(ctx, opts))
+
+	bun, err := cnab.LoadBundle(p.Context, build.LOCAL_BUNDLE)
+	require.NoError(t, err)
+	assert.Contains(t, bun.Parameters, "region", "the included parameter should be defined in the bundle")
+
+	stamp, err := configadapter.LoadStamp(bun)
+	require.NoError(t, err)
+	embeddedManifest, err := stamp.DecodeManifest()
+	require.NoError(t, err)
+	assert.Contains(t, string(embeddedManifest), "Configure the region", "the embedded manifest should include the merged steps")
+	assert.NotContains(t, string(embeddedManifest), "include:", "the embedded manifest should be self-contained")
+
+	canonicalManifest, err := manifest.ReadManifest(p.Context, build.LOCAL_MANIFEST, p.Config)
+	require.NoError(t, err)
+	lastStep := canonicalManifest.Install[len(canonicalManifest.Install)-1]
+	description, err := lastStep.GetDescription()
+	require.NoError(t, err)
+	assert.Equal(t, "Configure the region", description, "the included steps should run after the steps in porter.yaml")
+}
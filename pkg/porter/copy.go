@@ -9,6 +9,8 @@ import (
 	"get.porter.sh/porter/pkg/cnab"
 	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/provenance"
+	"get.porter.sh/porter/pkg/sbom"
 	"get.porter.sh/porter/pkg/tracing"
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -104,6 +106,14 @@ func (p *Porter) CopyBundle(ctx context.Context, opts *CopyOpts) error {
 		return span.Error(fmt.Errorf("unable to copy bundle to new location: %w", err))
 	}
 
+	if _, err = p.copyArtifacts(ctx, opts.sourceRef, bunRef, sbom.MediaTypes, regOpts); err != nil {
+		return err
+	}
+	provenanceRefs, err := p.copyArtifacts(ctx, opts.sourceRef, bunRef, []string{provenance.MediaType}, regOpts)
+	if err != nil {
+		return err
+	}
+
 	if opts.SignBundle {
 		for _, invImage := range bunRef.Definition.InvocationImages {
 			relocInvImage := bunRef.RelocationMap[invImage.Image]
@@ -119,7 +129,54 @@ func (p *Porter) CopyBundle(ctx context.Context, opts *CopyOpts) error {
 		if err != nil {
 			return span.Errorf("failed to bundle %s: %w", bunRef.Reference.String(), err)
 		}
+
+		for _, provenanceRef := range provenanceRefs {
+			span.Debugf("Signing provenance statement %s", provenanceRef.String())
+			err = p.Signer.Sign(ctx, provenanceRef.String())
+			if err != nil {
+				return span.Errorf("failed to sign provenance statement %s: %w", provenanceRef.String(), err)
+			}
+		}
 	}
 
 	return nil
 }
+
+// copyArtifacts copies the artifacts of the specified types, such as SBOMs, that refer to the
+// source bundle so that they refer to the destination bundle. Returns references to the copied artifacts.
+func (p *Porter) copyArtifacts(ctx context.Context, source cnab.OCIReference, destination cnab.BundleReference, artifactTypes []string, regOpts cnabtooci.RegistryOptions) ([]cnab.OCIReference, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	var artifacts []cnabtooci.Artifact
+	for _, artifactType := range artifactTypes {
+		results, err := p.Registry.ListArtifacts(ctx, source, artifactType, regOpts)
+		if err != nil {
+			return nil, log.Errorf("error listing the %s artifacts of %s: %w", artifactType, source, err)
+		}
+		artifacts = append(artifacts, results...)
+	}
+	if len(artifacts) == 0 {
+		log.Debugf("No %s artifacts refer to %s", strings.Join(artifactTypes, ", "), source)
+		return nil, nil
+	}
+
+	subject, err := destination.Reference.WithDigest(destination.Digest)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+	refs := make([]cnab.OCIReference, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		log.Debugf("Copying the %s artifact %s to %s", artifact.ArtifactType, artifact.Digest, subject)
+		artifactDigest, err := p.Registry.AttachArtifact(ctx, subject, artifact, regOpts)
+		if err != nil {
+			return nil, log.Errorf("error copying the %s artifact %s to %s: %w", artifact.ArtifactType, artifact.Digest, destination.Reference, err)
+		}
+		ref, err := destination.Reference.WithDigest(artifactDigest)
+		if err != nil {
+			return nil, log.Error(err)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
@@ -332,7 +332,7 @@ func (p *Porter) DeleteCredential(ctx context.Context, opts CredentialDeleteOpti
 	)
 	defer span.EndSpan()
 
-	err := p.Credentials.RemoveCredentialSet(ctx, opts.Namespace, opts.Name)
+	creds, err := p.Credentials.GetCredentialSet(ctx, opts.Namespace, opts.Name)
 	if errors.Is(err, storage.ErrNotFound{}) {
 		span.Debug("nothing to remove, credential already does not exist")
 		return nil
@@ -341,6 +341,12 @@ func (p *Porter) DeleteCredential(ctx context.Context, opts CredentialDeleteOpti
 		return span.Error(fmt.Errorf("unable to delete credential set: %w", err))
 	}
 
+	err = p.Credentials.RemoveCredentialSet(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return span.Error(fmt.Errorf("unable to delete credential set: %w", err))
+	}
+
+	p.removeSecrets(ctx, storage.SanitizedSourceKeys(creds.Credentials, creds.SecretsOwnerID()))
 	return nil
 }
 
@@ -369,13 +375,13 @@ func (p *Porter) CredentialsApply(ctx context.Context, o ApplyOptions) error {
 	defer span.EndSpan()
 
 	span.Debugf("Reading input file %s...\n", o.File)
-	namespace, err := p.getNamespaceFromFile(o)
+	namespace, err := p.getNamespaceFromFile(ctx, o)
 	if err != nil {
 		return span.Error(err)
 	}
 
 	var creds DisplayCredentialSet
-	err = encoding.UnmarshalFile(p.FileSystem, o.File, &creds)
+	encrypted, err := p.unmarshalInputFile(ctx, o.File, &creds)
 	if err != nil {
 		return span.Error(fmt.Errorf("could not load %s as a credential set: %w", o.File, err))
 	}
@@ -392,19 +398,36 @@ func (p *Porter) CredentialsApply(ctx context.Context, o ApplyOptions) error {
 		return span.Error(fmt.Errorf("credential set is invalid: %w", err))
 	}
 
+	// Find the secrets saved for the previous version of the credential set
+	var previousSecrets []string
+	if existing, err := p.Credentials.GetCredentialSet(ctx, creds.Namespace, creds.Name); err == nil {
+		previousSecrets = storage.SanitizedSourceKeys(existing.Credentials, existing.SecretsOwnerID())
+	} else if !errors.Is(err, storage.ErrNotFound{}) {
+		return span.Error(err)
+	}
+
+	// Only keep the values from an encrypted file in memory and the secret store
+	if encrypted {
+		creds.Credentials, err = p.Sanitizer.CleanValueSources(ctx, creds.Credentials, creds.SecretOwner())
+		if err != nil {
+			return span.Error(err)
+		}
+	}
+
 	err = p.Credentials.UpsertCredentialSet(ctx, creds.CredentialSet)
 	if err != nil {
 		return err
 	}
+	p.removeUnreferencedSecrets(ctx, previousSecrets, storage.SanitizedSourceKeys(creds.Credentials, creds.SecretsOwnerID()))
 
 	fmt.Fprintf(p.Out, "Applied %s credential set\n", creds)
 	return nil
 }
 
-func (p *Porter) getNamespaceFromFile(o ApplyOptions) (string, error) {
+func (p *Porter) getNamespaceFromFile(ctx context.Context, o ApplyOptions) (string, error) {
 	// Check if the namespace was set in the file, if not, use the namespace set on the command
 	var raw map[string]interface{}
-	err := encoding.UnmarshalFile(p.FileSystem, o.File, &raw)
+	_, err := p.unmarshalInputFile(ctx, o.File, &raw)
 	if err != nil {
 		return "", fmt.Errorf("invalid file '%s': %w", o.File, err)
 	}
@@ -11,6 +11,7 @@ import (
 
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/test"
 	"get.porter.sh/porter/pkg/yaml"
@@ -579,4 +580,45 @@ func TestPorter_CredentialsApply(t *testing.T) {
 		assert.Equal(t, "path", cs.Credentials[0].Source.Strategy, "unexpected credential source")
 		assert.Equal(t, "/path/to/kool-config", cs.Credentials[0].Source.Hint, "unexpected credential mapping value")
 	})
+
+	t.Run("encrypted credential set", func(t *testing.T) {
+		ctx := context.Background()
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.AddTestFile("testdata/credentials/kool-kreds.yaml", "kool-kreds.yaml")
+		p.addSopsEncryptedFile(t, "kool-kreds.yaml")
+
+		opts := ApplyOptions{File: "kool-kreds.yaml"}
+		require.NoError(t, p.CredentialsApply(ctx, opts), "CredentialsApply failed")
+
+		cs, err := p.Credentials.GetCredentialSet(ctx, "dev", "kool-kreds")
+		require.NoError(t, err, "Failed to retrieve applied credential set")
+		require.Len(t, cs.Credentials, 4, "expected 4 credentials in the set")
+		assert.Equal(t, "/path/to/kool-config", cs.Credentials[0].Source.Hint, "unexpected credential mapping value")
+		assert.Equal(t, secrets.Source{Strategy: secrets.SourceSecret, Hint: "credentialset-dev-kool-kreds-kool-val"}, cs.Credentials[3].Source,
+			"the decrypted value should be saved in the secret store")
+		value, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, "credentialset-dev-kool-kreds-kool-val")
+		require.NoError(t, err)
+		assert.Equal(t, "kool", value)
+
+		// Deleting the credential set removes its secrets
+		require.NoError(t, p.DeleteCredential(ctx, CredentialDeleteOptions{Namespace: "dev", Name: "kool-kreds"}))
+		keys, err := p.Secrets.List(ctx, secrets.SourceSecret)
+		require.NoError(t, err)
+		assert.Empty(t, keys, "the secrets of the credential set should be removed")
+	})
+
+	t.Run("encrypted without a key", func(t *testing.T) {
+		ctx := context.Background()
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.AddTestFile("testdata/credentials/kool-kreds.yaml", "kool-kreds.yaml")
+		p.addSopsEncryptedFile(t, "kool-kreds.yaml")
+		p.Data.SopsAgeKeyFile = "/missing/keys.txt"
+
+		err := p.CredentialsApply(ctx, ApplyOptions{File: "kool-kreds.yaml"})
+		require.ErrorContains(t, err, "kool-kreds.yaml is encrypted with SOPS: could not read the age key file /missing/keys.txt")
+	})
 }
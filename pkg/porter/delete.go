@@ -53,5 +53,5 @@ func (p *Porter) DeleteInstallation(ctx context.Context, opts DeleteOptions) err
 	}
 
 	fmt.Fprintf(p.Out, installationDeleteTmpl, opts.Name)
-	return p.Installations.RemoveInstallation(ctx, opts.Namespace, opts.Name)
+	return p.removeInstallation(ctx, opts.Namespace, opts.Name)
 }
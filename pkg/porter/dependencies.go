@@ -415,7 +415,7 @@ func (e *dependencyExecutioner) getActionArgs(ctx context.Context,
 	if err != nil {
 		return fmt.Errorf("error resolving parameters for dependency %s: %w", dep.Alias, err)
 	}
-	depRun, err := e.porter.createRun(ctx, dep.BundleReference, e.depArgs.Installation, actionName, finalParams)
+	depRun, err := e.porter.createRun(ctx, dep.BundleReference, e.depArgs.Installation, actionName, finalParams, nil)
 	if err != nil {
 		return fmt.Errorf("error creating run for dependency %s: %w", dep.Alias, err)
 	}
@@ -459,7 +459,7 @@ func (e *dependencyExecutioner) finalizeExecute(ctx context.Context, dep *queued
 	// will resolve to false and thus be a no-op
 	if uninstallOpts.shouldDelete() {
 		span.Infof(installationDeleteTmpl, e.depArgs.Installation)
-		return e.Installations.RemoveInstallation(ctx, e.depArgs.Installation.Namespace, e.depArgs.Installation.Name)
+		return e.porter.removeInstallation(ctx, e.depArgs.Installation.Namespace, e.depArgs.Installation.Name)
 	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/encoding"
+	"get.porter.sh/porter/pkg/sops"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// unmarshalInputFile reads a document that was passed to one of the apply
+// commands into a struct. Documents that were encrypted with SOPS are decrypted
+// in memory, and true is returned so that the caller can save the decrypted
+// values in the secret store instead of in Porter's database.
+func (p *Porter) unmarshalInputFile(ctx context.Context, path string, out interface{}) (bool, error) {
+	data, err := p.FileSystem.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("error reading file %s: %w", path, err)
+	}
+
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	if !sops.IsEncrypted(data) {
+		return false, encoding.Unmarshal(format, data, out)
+	}
+
+	identities, err := p.getSopsIdentities(ctx)
+	if err != nil {
+		return true, fmt.Errorf("%s is encrypted with SOPS: %w", path, err)
+	}
+	data, err = sops.Decrypt(format, data, identities)
+	if err != nil {
+		return true, fmt.Errorf("could not decrypt %s: %w", path, err)
+	}
+	return true, encoding.Unmarshal(format, data, out)
+}
+
+// getSopsIdentities loads the age identities used to decrypt documents that
+// were encrypted with SOPS. The key file in the config file takes precedence
+// over the SOPS environment variables.
+func (p *Porter) getSopsIdentities(ctx context.Context) ([]age.Identity, error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	if keys := p.Getenv(config.EnvSopsAgeKey); keys != "" && p.Data.SopsAgeKeyFile == "" {
+		log.Debugf("Using the age identities from %s", config.EnvSopsAgeKey)
+		identities, err := sops.ParseIdentities([]byte(keys))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age identities in %s: %w", config.EnvSopsAgeKey, err)
+		}
+		return identities, nil
+	}
+
+	keyFile, err := p.GetSopsAgeKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Using the age identities from %s", keyFile)
+	data, err := p.FileSystem.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the age key file %s, set sops-age-key-file in the Porter config file or the %s environment variable: %w", keyFile, config.EnvSopsAgeKeyFile, err)
+	}
+	identities, err := sops.ParseIdentities(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age key file %s: %w", keyFile, err)
+	}
+	return identities, nil
+}
+
+// removeUnreferencedSecrets removes the secrets that Porter saved for a record
+// that are no longer referenced after the record was updated.
+func (p *Porter) removeUnreferencedSecrets(ctx context.Context, before []string, after []string) {
+	referenced := make(map[string]struct{}, len(after))
+	for _, key := range after {
+		referenced[key] = struct{}{}
+	}
+
+	var unreferenced []string
+	for _, key := range before {
+		if _, ok := referenced[key]; !ok {
+			unreferenced = append(unreferenced, key)
+		}
+	}
+	p.removeSecrets(ctx, unreferenced)
+}
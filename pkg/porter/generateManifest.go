@@ -42,12 +42,17 @@ func (p *Porter) generateInternalManifest(ctx context.Context, opts BuildOptions
 		return span.Error(fmt.Errorf("unable to create directory %s: %w", build.LOCAL_APP, err))
 	}
 
-	e := yaml.NewEditor(p.FileSystem)
-	err = e.ReadFile(opts.File)
+	// Merge the files included by the manifest, so that the canonical manifest is self-contained
+	data, err := manifest.ReadManifestData(p.Context, opts.File)
 	if err != nil {
 		return span.Error(fmt.Errorf("unable to read manifest file %s: %w", opts.File, err))
 	}
 
+	e := yaml.NewEditor(p.FileSystem)
+	if _, err = e.Read(data); err != nil {
+		return span.Error(fmt.Errorf("unable to read manifest file %s: %w", opts.File, err))
+	}
+
 	if opts.Name != "" {
 		if err = e.SetValue("name", opts.Name); err != nil {
 			return err
@@ -1,6 +1,7 @@
 package porter
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand"
@@ -11,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/cache"
 	"get.porter.sh/porter/pkg/cnab"
@@ -23,6 +25,7 @@ import (
 	"get.porter.sh/porter/pkg/plugins"
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/signing"
+	"get.porter.sh/porter/pkg/sops"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"get.porter.sh/porter/pkg/yaml"
@@ -81,8 +84,13 @@ func NewTestPorter(t *testing.T) *TestPorter {
 	p.Credentials = testCredentials
 	p.Parameters = testParameters
 	p.Secrets = testSecrets
+	p.Sanitizer = storage.NewSanitizer(testParameters, testSecrets)
+	p.SecretRecords = storage.NewSecretRecordStore(testStore)
 	p.CNAB = cnabprovider.NewTestRuntimeFor(tc, testInstallations, testCredentials, testParameters, testSecrets)
 	p.Registry = testRegistry
+	p.newSigner = func(signer config.SigningPlugin) signing.Signer {
+		return signing.NewTestSigningProvider()
+	}
 
 	tp := TestPorter{
 		Porter:            p,
@@ -158,6 +166,23 @@ func (p *TestPorter) AddTestFile(src string, dest string) {
 	p.TestConfig.TestContext.AddTestFile(src, dest)
 }
 
+// addSopsEncryptedFile encrypts the values in a test file with SOPS, and
+// configures Porter with the age key that decrypts it. The status at the end
+// of the file is removed first, because SOPS cannot encrypt YAML timestamps.
+func (p *TestPorter) addSopsEncryptedFile(t *testing.T, path string) {
+	contents, err := p.FileSystem.ReadFile(path)
+	require.NoError(t, err)
+	if i := bytes.Index(contents, []byte("\nstatus:")); i >= 0 {
+		contents = contents[:i+1]
+	}
+
+	identity, recipient := sops.NewTestIdentity(t)
+	keyFile := "/home/myuser/.config/sops/age/keys.txt"
+	require.NoError(t, p.FileSystem.WriteFile(keyFile, []byte(identity), pkg.FileModeWritable))
+	require.NoError(t, p.FileSystem.WriteFile(path, sops.EncryptTestDocument(t, recipient, strings.TrimPrefix(filepath.Ext(path), "."), string(contents)), pkg.FileModeWritable))
+	p.Data.SopsAgeKeyFile = keyFile
+}
+
 type TestDriver struct {
 	Name     string
 	Filepath string
@@ -245,8 +270,8 @@ func (p *TestPorter) CompareGoldenFile(goldenFile string, got string) {
 
 // CreateInstallation saves an installation record into claim store and store
 // sensitive parameters into secret store.
-func (p *TestPorter) SanitizeParameters(raw []secrets.SourceMap, recordID string, bun cnab.ExtendedBundle) []secrets.SourceMap {
-	strategies, err := p.Sanitizer.CleanParameters(context.Background(), raw, bun, recordID)
+func (p *TestPorter) SanitizeParameters(raw []secrets.SourceMap, owner storage.SecretOwner, bun cnab.ExtendedBundle) []secrets.SourceMap {
+	strategies, err := p.Sanitizer.CleanParameters(context.Background(), raw, bun, owner)
 	require.NoError(p.T(), err)
 
 	return strategies
@@ -261,6 +286,8 @@ func (p *TestPorter) CreateOutput(o storage.Output, bun cnab.ExtendedBundle) sto
 }
 
 type TestBuildProvider struct {
+	// BuildOptions are the options from the last call to BuildBundleImage.
+	BuildOptions build.BuildImageOptions
 }
 
 func NewTestBuildProvider() *TestBuildProvider {
@@ -268,6 +295,7 @@ func NewTestBuildProvider() *TestBuildProvider {
 }
 
 func (t *TestBuildProvider) BuildBundleImage(ctx context.Context, manifest *manifest.Manifest, opts build.BuildImageOptions) error {
+	t.BuildOptions = opts
 	return nil
 }
 
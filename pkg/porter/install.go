@@ -60,6 +60,12 @@ func (p *Porter) InstallBundle(ctx context.Context, opts InstallOptions) error {
 	ctx, log := tracing.StartSpan(ctx)
 	defer log.EndSpan()
 
+	ctx, lock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, storage.DefaultInstallationLockTTL)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	i, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if err == nil {
 		// Validate that we are not overwriting an existing installation
@@ -83,49 +89,21 @@ func (p *Porter) InstallBundle(ctx context.Context, opts InstallOptions) error {
 		return err
 	}
 
-	err = p.Installations.UpsertInstallation(ctx, i)
+	err = p.Installations.UpsertInstallation(ctx, &i)
 	if err != nil {
 		return fmt.Errorf("error saving installation record: %w", err)
 	}
 
-	if opts.VerifyBundleBeforeExecution {
-		ref, ok, err := i.Bundle.GetBundleReference()
-		if err != nil {
-			return err
-		}
-		log.Debugf("verifying bundle signature for %s", ref.String())
-		if !ok {
-			return log.Errorf("unable to get reference for bundle %s: %w", ref.String(), err)
-		}
-		err = p.Signer.Verify(ctx, ref.String())
-		if err != nil {
-			return log.Errorf("unable to verify signature: %w", err)
-		}
-		log.Debugf("bundle signature verified for %s", ref.String())
-
-		bun, err := opts.GetOptions().GetBundleReference(ctx, p)
-		if err != nil {
-			return log.Errorf("unable to get bundle reference")
-		}
-
-		invocationImage := bun.Definition.InvocationImages[0].Image
-		if relocInvImage, ok := bun.RelocationMap[invocationImage]; ok {
-			invocationImage = relocInvImage
-		}
-		log.Debugf("verifying bundle image signature for %s", invocationImage)
-		err = p.Signer.Verify(ctx, invocationImage)
-		if err != nil {
-			return log.Errorf("unable to verify signature: %w", err)
-		}
-		log.Debugf("bundle image signature verified for %s", invocationImage)
+	if err = p.verifyBundleBeforeExecution(ctx, opts); err != nil {
+		return err
 	}
 
 	// Run install using the updated installation record
-	return p.ExecuteAction(ctx, i, opts)
+	return p.ExecuteAction(ctx, lock, i, opts)
 }
 
 func (p *Porter) sanitizeInstallation(ctx context.Context, inst *storage.Installation, bun cnab.ExtendedBundle) error {
-	strategies, err := p.Sanitizer.CleanParameters(ctx, inst.Parameters.Parameters, bun, inst.ID)
+	strategies, err := p.Sanitizer.CleanParameters(ctx, inst.Parameters.Parameters, bun, inst.SecretOwner())
 	if err != nil {
 		return err
 	}
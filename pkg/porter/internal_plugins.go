@@ -15,10 +15,13 @@ import (
 	"get.porter.sh/porter/pkg/secrets/plugins/host"
 	signingplugins "get.porter.sh/porter/pkg/signing/plugins"
 	"get.porter.sh/porter/pkg/signing/plugins/cosign"
+	"get.porter.sh/porter/pkg/signing/plugins/keypair"
 	"get.porter.sh/porter/pkg/signing/plugins/notation"
 	storageplugins "get.porter.sh/porter/pkg/storage/plugins"
+	storagefilesystem "get.porter.sh/porter/pkg/storage/plugins/filesystem"
 	"get.porter.sh/porter/pkg/storage/plugins/mongodb"
 	"get.porter.sh/porter/pkg/storage/plugins/mongodb_docker"
+	"get.porter.sh/porter/pkg/storage/plugins/sqlite"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/hashicorp/go-plugin"
 )
@@ -129,7 +132,7 @@ func getInternalPlugins() map[string]InternalPlugin {
 			Interface:       secretsplugins.PluginInterface,
 			ProtocolVersion: secretsplugins.PluginProtocolVersion,
 			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
-				return filesystem.NewPlugin(c, pluginCfg), nil
+				return filesystem.NewPlugin(c, pluginCfg)
 			},
 		},
 		mongodb.PluginKey: {
@@ -146,6 +149,20 @@ func getInternalPlugins() map[string]InternalPlugin {
 				return mongodb_docker.NewPlugin(c.Context, pluginCfg)
 			},
 		},
+		storagefilesystem.PluginKey: {
+			Interface:       storageplugins.PluginInterface,
+			ProtocolVersion: storageplugins.PluginProtocolVersion,
+			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
+				return storagefilesystem.NewPlugin(c, pluginCfg)
+			},
+		},
+		sqlite.PluginKey: {
+			Interface:       storageplugins.PluginInterface,
+			ProtocolVersion: storageplugins.PluginProtocolVersion,
+			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
+				return sqlite.NewPlugin(c, pluginCfg)
+			},
+		},
 		notation.PluginKey: {
 			Interface:       signingplugins.PluginInterface,
 			ProtocolVersion: signingplugins.PluginProtocolVersion,
@@ -160,5 +177,12 @@ func getInternalPlugins() map[string]InternalPlugin {
 				return cosign.NewPlugin(c.Context, pluginCfg)
 			},
 		},
+		keypair.PluginKey: {
+			Interface:       signingplugins.PluginInterface,
+			ProtocolVersion: signingplugins.PluginProtocolVersion,
+			Create: func(c *config.Config, pluginCfg interface{}) (plugin.Plugin, error) {
+				return keypair.NewPlugin(c.Context, pluginCfg)
+			},
+		},
 	}
 }
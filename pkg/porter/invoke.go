@@ -50,6 +50,12 @@ func (p *Porter) InvokeBundle(ctx context.Context, opts InvokeOptions) error {
 		return err
 	}
 
+	ctx, lock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, storage.DefaultInstallationLockTTL)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	installation, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if errors.Is(err, storage.ErrNotFound{}) {
 		action, actionErr := bundleRef.Definition.GetAction(opts.Action)
@@ -71,5 +77,9 @@ func (p *Porter) InvokeBundle(ctx context.Context, opts InvokeOptions) error {
 		return err
 	}
 
-	return p.ExecuteAction(ctx, installation, opts)
+	if err = p.verifyBundleBeforeExecution(ctx, opts); err != nil {
+		return err
+	}
+
+	return p.ExecuteAction(ctx, lock, installation, opts)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"runtime"
+	"slices"
 	"strings"
 	"unicode"
 
@@ -18,8 +19,8 @@ import (
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
+	"github.com/cnabio/cnab-go/bundle"
 	"github.com/opencontainers/go-digest"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 // BundleAction is an interface that defines a method for supplying
@@ -80,6 +81,13 @@ type BundleExecutionOptions struct {
 	// Do not use directly, use GetParameters instead.
 	finalParams map[string]interface{}
 
+	// The names of the final parameters that were resolved from a template that uses a sensitive value.
+	// They are stored in the secret store, even when the bundle does not define them as sensitive.
+	sensitiveParams []string
+
+	// rollbackTo is the ID of the run that is being rolled back to, recorded on the new run.
+	rollbackTo string
+
 	VerifyBundleBeforeExecution bool
 }
 
@@ -345,10 +353,11 @@ func (p *Porter) BuildActionArgs(ctx context.Context, installation storage.Insta
 		}
 	}
 
-	run, err := p.createRun(ctx, bundleRef, installation, action.GetAction(), opts.GetParameters())
+	run, err := p.createRun(ctx, bundleRef, installation, action.GetAction(), opts.GetParameters(), opts.sensitiveParams)
 	if err != nil {
 		return cnabprovider.ActionArguments{}, err
 	}
+	run.RollbackTo = opts.rollbackTo
 
 	args := cnabprovider.ActionArguments{
 		Run:                   run,
@@ -425,7 +434,7 @@ func (p *Porter) prepullBundleByReference(ctx context.Context, opts *BundleRefer
 
 // createRun generates a Run record instructing porter exactly how to run the bundle
 // and includes audit/status fields as well.
-func (p *Porter) createRun(ctx context.Context, bundleRef cnab.BundleReference, inst storage.Installation, action string, params map[string]interface{}) (storage.Run, error) {
+func (p *Porter) createRun(ctx context.Context, bundleRef cnab.BundleReference, inst storage.Installation, action string, params map[string]interface{}, sensitiveParams []string) (storage.Run, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
@@ -434,12 +443,25 @@ func (p *Porter) createRun(ctx context.Context, bundleRef cnab.BundleReference,
 	currentRun.Bundle = bundleRef.Definition.Bundle
 	currentRun.BundleReference = bundleRef.Reference.String()
 	currentRun.BundleDigest = bundleRef.Digest.String()
+	currentRun.Audit = p.getRunAudit(ctx)
 
 	var err error
-	cleanParams, err := p.Sanitizer.CleanRawParameters(ctx, params, bundleRef.Definition, currentRun.ID)
+	cleanParams, err := p.Sanitizer.CleanRawParameters(ctx, params, bundleRef.Definition, currentRun.SecretOwner())
 	if err != nil {
 		return storage.Run{}, span.Error(err)
 	}
+
+	// Parameters resolved from a template that uses a sensitive value are sensitive too
+	for i, param := range cleanParams {
+		if !slices.Contains(sensitiveParams, param.Name) {
+			continue
+		}
+		cleaned, err := p.Sanitizer.CleanValueSources(ctx, []secrets.SourceMap{param}, currentRun.SecretOwner())
+		if err != nil {
+			return storage.Run{}, span.Error(err)
+		}
+		cleanParams[i] = cleaned[0]
+	}
 	currentRun.Parameters.Parameters = cleanParams
 
 	// TODO: Do not save secrets when the run isn't recorded
@@ -455,29 +477,45 @@ func (p *Porter) createRun(ctx context.Context, bundleRef cnab.BundleReference,
 	currentRun.CredentialSets = inst.CredentialSets
 
 	// Combine the credential sets above into a single credential set we can resolve just-in-time (JIT) before running the bundle.
-	finalCreds := make(map[string]secrets.SourceMap, len(currentRun.Bundle.Credentials))
-	for _, csName := range currentRun.CredentialSets {
+	finalCreds, err := p.combineCredentialSets(ctx, inst, currentRun.Bundle, currentRun.Action)
+	if err != nil {
+		return storage.Run{}, span.Error(err)
+	}
+
+	if len(finalCreds) > 0 {
+		// Store the composite credential set on the run, so that the runtime can later resolve them in a single step
+		currentRun.Credentials = storage.NewInternalCredentialSet()
+		for _, cred := range finalCreds {
+			currentRun.Credentials.Credentials = append(currentRun.Credentials.Credentials, cred)
+		}
+	}
+
+	return currentRun, nil
+}
+
+// combineCredentialSets combines the credential sets used by an installation
+// into the final credentials used by the bundle for an action, keyed by the
+// name of the credential.
+func (p *Porter) combineCredentialSets(ctx context.Context, inst storage.Installation, bun bundle.Bundle, action string) (map[string]secrets.SourceMap, error) {
+	finalCreds := make(map[string]secrets.SourceMap, len(bun.Credentials))
+	for _, csName := range inst.CredentialSets {
 		var cs storage.CredentialSet
 		// Try to get the creds in the local namespace first, fallback to the global creds
-		query := storage.FindOptions{
-			Sort: []string{"-namespace"},
-			Filter: bson.M{
-				"name": csName,
-				"$or": []bson.M{
-					{"namespace": ""},
-					{"namespace": currentRun.Namespace},
-				},
-			},
+		err := p.findInstallationSet(ctx, p.Credentials.GetDataStore(), storage.CollectionCredentials, inst.Namespace, csName, &cs)
+		if err != nil {
+			return nil, fmt.Errorf("could not find credential set named %s in the %s namespace or global namespace: %w", csName, inst.Namespace, err)
 		}
-		store := p.Credentials.GetDataStore()
-		err := store.FindOne(ctx, storage.CollectionCredentials, query, &cs)
+
+		// Templates may reference credentials in the set that are not used by the bundle,
+		// so inline them before the credentials are combined with the other sets
+		creds, err := storage.InlineTemplateSources(storage.CollectionCredentials, cs.Credentials)
 		if err != nil {
-			return storage.Run{}, span.Errorf("could not find credential set named %s in the %s namespace or global namespace: %w", csName, inst.Namespace, err)
+			return nil, fmt.Errorf("invalid credential set %s: %w", csName, err)
 		}
 
-		for _, cred := range cs.Credentials {
-			credDef, ok := currentRun.Bundle.Credentials[cred.Name]
-			if !ok || !credDef.AppliesTo(currentRun.Action) {
+		for _, cred := range creds {
+			credDef, ok := bun.Credentials[cred.Name]
+			if !ok || !credDef.AppliesTo(action) {
 				// ignore extra credential mappings in the set that are not defined by the bundle or used by the current action
 				// it's okay to over specify so that people can reuse sets better
 				continue
@@ -487,14 +525,5 @@ func (p *Porter) createRun(ctx context.Context, bundleRef cnab.BundleReference,
 			finalCreds[cred.Name] = cred
 		}
 	}
-
-	if len(finalCreds) > 0 {
-		// Store the composite credential set on the run, so that the runtime can later resolve them in a single step
-		currentRun.Credentials = storage.NewInternalCredentialSet()
-		for _, cred := range finalCreds {
-			currentRun.Credentials.Credentials = append(currentRun.Credentials.Credentials, cred)
-		}
-	}
-
-	return currentRun, nil
+	return finalCreds, nil
 }
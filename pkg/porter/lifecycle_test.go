@@ -13,6 +13,7 @@ import (
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/tests"
@@ -476,6 +477,87 @@ func TestPorter_applyActionOptionsToInstallation_sanitizesParameters(t *testing.
 	require.Equal(t, "secret", i.Parameters.Parameters[1].Source.Strategy)
 }
 
+// Validate that parameters resolved from a template that uses a secret are
+// not persisted on the run record, even when the bundle does not define them as sensitive
+func TestPorter_applyActionOptionsToInstallation_sanitizesSensitiveTemplates(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	ctx := context.Background()
+
+	p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", config.Name)
+	m, err := manifest.LoadManifestFrom(context.Background(), p.Config, config.Name)
+	require.NoError(t, err)
+	bun, err := configadapter.ConvertToTestBundle(ctx, p.Config, m)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, "first", "7"))
+	err = p.TestParameters.InsertParameterSet(ctx, storage.NewParameterSet("", "myps",
+		secrets.SourceMap{Name: "my-first-param", Source: secrets.Source{Strategy: secrets.SourceTemplate, Hint: "${secret.first}"}}))
+	require.NoError(t, err)
+
+	opts := NewInstallOptions()
+	opts.BundleReferenceOptions.bundleRef = &cnab.BundleReference{
+		Reference:  kahnlatest,
+		Definition: bun,
+	}
+	opts.ParameterSets = []string{"myps"}
+
+	i := storage.NewInstallation("", bun.Name)
+	err = p.applyActionOptionsToInstallation(ctx, opts, &i)
+	require.NoError(t, err)
+	assert.Equal(t, 7, opts.GetParameters()["my-first-param"])
+
+	run, err := p.createRun(ctx, *opts.bundleRef, i, cnab.ActionInstall, opts.GetParameters(), opts.sensitiveParams)
+	require.NoError(t, err)
+
+	var param secrets.SourceMap
+	for _, runParam := range run.Parameters.Parameters {
+		if runParam.Name == "my-first-param" {
+			param = runParam
+		}
+	}
+	assert.Equal(t, secrets.SourceSecret, param.Source.Strategy, "the resolved value of the template should be stored in the secret store")
+	value, err := p.Secrets.Resolve(ctx, param.Source.Strategy, param.Source.Hint)
+	require.NoError(t, err)
+	assert.Equal(t, "7", value)
+}
+
+// Validate that credential templates can reference credentials in the same set
+// that are not defined by the bundle, and are resolved against their own set
+// when the installation uses multiple sets
+func TestPorter_createRun_credentialTemplates(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	ctx := context.Background()
+	bun, err := cnab.LoadBundle(portercontext.New(), "testdata/bundle.json")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, "first-helper", "first"))
+	require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, "second-helper", "second"))
+	require.NoError(t, p.Credentials.InsertCredentialSet(ctx, storage.NewCredentialSet("", "firstcreds",
+		secrets.SourceMap{Name: "helper", Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: "first-helper"}},
+		secrets.SourceMap{Name: "my-first-cred", Source: secrets.Source{Strategy: secrets.SourceTemplate, Hint: "${credentials.helper}-cred"}},
+	)))
+	require.NoError(t, p.Credentials.InsertCredentialSet(ctx, storage.NewCredentialSet("", "secondcreds",
+		secrets.SourceMap{Name: "helper", Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: "second-helper"}},
+		secrets.SourceMap{Name: "user", Source: secrets.Source{Strategy: host.SourceValue, Hint: "admin"}},
+		secrets.SourceMap{Name: "my-second-cred", Source: secrets.Source{Strategy: secrets.SourceTemplate, Hint: "${credentials.user}:${credentials.helper}"}},
+	)))
+
+	i := storage.NewInstallation("", bun.Name)
+	i.CredentialSets = []string{"firstcreds", "secondcreds"}
+	bundleRef := cnab.BundleReference{Reference: kahnlatest, Definition: bun}
+	run, err := p.createRun(ctx, bundleRef, i, cnab.ActionInstall, nil, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"my-first-cred", "my-second-cred"}, run.Credentials.Keys(), "only the credentials used by the bundle should be stored on the run")
+	resolved, err := p.Credentials.ResolveAll(ctx, run.Credentials, run.Credentials.Keys())
+	require.NoError(t, err)
+	assert.Equal(t, secrets.Set{"my-first-cred": "first-cred", "my-second-cred": "admin:second"}, resolved)
+}
+
 // When the installation has been used before with a parameter value
 // the previous param value should be updated and the other previous values
 // that were not set this time around should be re-used.
@@ -215,20 +215,24 @@ type DisplayRun struct {
 	Bundle     string                 `json:"bundle,omitempty" yaml:"bundle,omitempty"`
 	Version    string                 `json:"version" yaml:"version"`
 	Action     string                 `json:"action" yaml:"action"`
+	RollbackTo string                 `json:"rollbackTo,omitempty" yaml:"rollbackTo,omitempty"`
 	Parameters map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	Started    time.Time              `json:"started" yaml:"started"`
 	Stopped    *time.Time             `json:"stopped" yaml:"stopped"`
 	Status     string                 `json:"status" yaml:"status"`
+	Audit      storage.RunAudit       `json:"audit" yaml:"audit"`
 }
 
 func NewDisplayRun(run storage.Run) DisplayRun {
 	return DisplayRun{
 		ID:         run.ID,
 		Action:     run.Action,
+		RollbackTo: run.RollbackTo,
 		Parameters: run.TypedParameterValues(),
 		Started:    run.Created,
 		Bundle:     run.BundleReference,
 		Version:    run.Bundle.Version,
+		Audit:      run.Audit,
 	}
 }
 
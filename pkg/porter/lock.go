@@ -0,0 +1,179 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// heldInstallationLock is a lock on an installation held by this process.
+// The lock is renewed in the background until it is released.
+type heldInstallationLock struct {
+	p *Porter
+
+	// ctx is canceled when the lock can no longer be renewed.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	// mu protects lock, which is replaced each time the lock is renewed.
+	mu   sync.Mutex
+	lock storage.InstallationLock
+}
+
+// lockInstallation acquires the lock on an installation so that other porter
+// processes cannot modify it or execute a bundle against it at the same time.
+// Take the lock before reading the installation that is modified, and call
+// Release when done with the installation.
+//
+// The returned context is canceled when the lock can no longer be renewed,
+// before another process can take over the installation, so use it for the
+// work done while holding the lock.
+func (p *Porter) lockInstallation(ctx context.Context, namespace string, name string, ttl time.Duration) (context.Context, *heldInstallationLock, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	lock, err := p.Installations.AcquireInstallationLock(ctx, namespace, name, lockOwner(), ttl)
+	if err != nil {
+		return ctx, nil, span.Error(fmt.Errorf("could not lock installation %s/%s: %w", namespace, name, err))
+	}
+	span.Debugf("Acquired lock %s on installation %s", lock.ID, lock)
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	held := &heldInstallationLock{
+		p:      p,
+		ctx:    lockCtx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		lock:   lock,
+	}
+	held.wg.Add(1)
+	go func() {
+		defer held.wg.Done()
+		held.renew(ttl)
+	}()
+	return lockCtx, held, nil
+}
+
+// Current returns the lock, as of its most recent renewal.
+func (l *heldInstallationLock) Current() storage.InstallationLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lock
+}
+
+// IsFor checks if the lock is held on the specified installation.
+func (l *heldInstallationLock) IsFor(installation storage.Installation) bool {
+	lock := l.Current()
+	return lock.Namespace == installation.Namespace && lock.Installation == installation.Name
+}
+
+// Err returns why the lock was lost, or nil while the lock is held.
+func (l *heldInstallationLock) Err() error {
+	if l.ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(l.ctx)
+}
+
+// Release stops renewing the lock and removes it.
+func (l *heldInstallationLock) Release() {
+	close(l.stop)
+	l.wg.Wait()
+	defer l.cancel(nil)
+
+	// Release the lock even when the action was canceled
+	lock := l.Current()
+	log := tracing.LoggerFromContext(l.ctx)
+	if err := l.p.Installations.ReleaseInstallationLock(context.WithoutCancel(l.ctx), lock); err != nil {
+		log.Warnf("Could not release the lock on installation %s, it will expire at %s: %s", lock, lock.Expires.Format(time.RFC3339), err.Error())
+	}
+}
+
+// renew sends a heartbeat for the lock until it is released. When the lock
+// is lost, or will expire before it can be renewed again, the context of the
+// lock is canceled so that the action stops.
+func (l *heldInstallationLock) renew(ttl time.Duration) {
+	log := tracing.LoggerFromContext(l.ctx)
+
+	interval := ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			lock := l.Current()
+			renewed, err := l.p.Installations.RenewInstallationLock(l.ctx, lock, ttl)
+			if err != nil {
+				if errors.Is(err, storage.ErrInstallationLockLost{}) || !time.Now().Add(interval).Before(lock.Expires) {
+					lostErr := fmt.Errorf("stopping because the lock on installation %s could not be renewed: %w", lock, err)
+					log.Error(lostErr)
+					l.cancel(lostErr)
+					return
+				}
+				log.Warnf("Could not renew the lock on installation %s, retrying: %s", lock, err.Error())
+				continue
+			}
+			l.mu.Lock()
+			l.lock = renewed
+			l.mu.Unlock()
+		}
+	}
+}
+
+// lockOwner describes the current process, so that users can identify who holds a lock.
+func lockOwner() string {
+	return fmt.Sprintf("%s@%s (pid %d)", currentUsername(), currentHostname(), os.Getpid())
+}
+
+// InstallationUnlockOptions are the options for the porter installations unlock command.
+type InstallationUnlockOptions struct {
+	// Namespace of the installation.
+	Namespace string
+
+	// Name of the installation to unlock.
+	Name string
+}
+
+// Validate prepares for the unlock action and validates the args/options.
+func (o *InstallationUnlockOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one positional argument, the installation name, but received %d: %s", len(args), args)
+	}
+	o.Name = args[0]
+	return nil
+}
+
+// UnlockInstallation removes the lock on an installation, for example when the
+// porter process that held the lock was killed before it could release it.
+func (p *Porter) UnlockInstallation(ctx context.Context, opts InstallationUnlockOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	lock, err := p.Installations.GetInstallationLock(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound{}) {
+			fmt.Fprintf(p.Out, "Installation %s/%s is not locked\n", opts.Namespace, opts.Name)
+			return nil
+		}
+		return span.Error(fmt.Errorf("could not retrieve the lock on installation %s/%s: %w", opts.Namespace, opts.Name, err))
+	}
+
+	if err = p.Installations.RemoveInstallationLock(ctx, opts.Namespace, opts.Name); err != nil {
+		return span.Error(fmt.Errorf("could not remove the lock on installation %s: %w", lock, err))
+	}
+
+	fmt.Fprintf(p.Out, "Removed the lock on installation %s held by %s since %s\n", lock, lock.Owner, lock.Acquired.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,101 @@
+package porter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_lockInstallation(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	ctx := context.Background()
+	inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+
+	_, held, err := p.lockInstallation(ctx, "dev", "mysql", 30*time.Millisecond)
+	require.NoError(t, err, "lockInstallation failed")
+	assert.True(t, held.IsFor(inst), "expected the lock to be held on the installation")
+	assert.False(t, held.IsFor(storage.NewInstallation("dev", "postgres")), "expected the lock to only be held on the locked installation")
+
+	lock, err := p.Installations.GetInstallationLock(ctx, "dev", "mysql")
+	require.NoError(t, err, "expected the installation to be locked")
+	assert.Contains(t, lock.Owner, "pid")
+
+	_, _, err = p.lockInstallation(ctx, "dev", "mysql", time.Minute)
+	require.ErrorIs(t, err, storage.ErrInstallationLocked{}, "expected a second action to be blocked by the lock")
+
+	// Wait long enough for the lock to expire unless it is renewed by the heartbeat
+	time.Sleep(100 * time.Millisecond)
+	_, _, err = p.lockInstallation(ctx, "dev", "mysql", time.Minute)
+	require.ErrorIs(t, err, storage.ErrInstallationLocked{}, "expected the heartbeat to renew the lock")
+
+	held.Release()
+	_, err = p.Installations.GetInstallationLock(ctx, "dev", "mysql")
+	require.ErrorIs(t, err, storage.ErrNotFound{}, "expected the lock to be released")
+}
+
+func TestPorter_UnlockInstallation(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	ctx := context.Background()
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+
+	t.Run("not locked", func(t *testing.T) {
+		p.TestConfig.TestContext.ClearOutputs()
+		err := p.UnlockInstallation(ctx, InstallationUnlockOptions{Namespace: "dev", Name: "mysql"})
+		require.NoError(t, err)
+		assert.Equal(t, "Installation dev/mysql is not locked\n", p.TestConfig.TestContext.GetOutput())
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		p.TestConfig.TestContext.ClearOutputs()
+		_, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mysql", "sally@example (pid 123)", time.Hour)
+		require.NoError(t, err)
+
+		err = p.UnlockInstallation(ctx, InstallationUnlockOptions{Namespace: "dev", Name: "mysql"})
+		require.NoError(t, err)
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Removed the lock on installation dev/mysql held by sally@example (pid 123)")
+
+		_, err = p.Installations.GetInstallationLock(ctx, "dev", "mysql")
+		require.ErrorIs(t, err, storage.ErrNotFound{})
+	})
+}
+
+func TestPorter_lockInstallation_Lost(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	ctx := context.Background()
+	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+
+	lockCtx, held, err := p.lockInstallation(ctx, "dev", "mysql", 30*time.Millisecond)
+	require.NoError(t, err, "lockInstallation failed")
+	defer held.Release()
+	require.NoError(t, held.Err(), "expected the lock to be held")
+
+	// Remove the lock out from under the holder, so that it cannot be renewed
+	require.NoError(t, p.Installations.RemoveInstallationLock(ctx, "dev", "mysql"))
+
+	select {
+	case <-lockCtx.Done():
+	case <-time.After(time.Second):
+		require.Fail(t, "expected the context to be canceled when the lock could not be renewed")
+	}
+	require.ErrorIs(t, held.Err(), storage.ErrInstallationLockLost{})
+	require.ErrorIs(t, context.Cause(lockCtx), storage.ErrInstallationLockLost{})
+}
+
+func TestInstallationUnlockOptions_Validate(t *testing.T) {
+	opts := InstallationUnlockOptions{}
+	require.NoError(t, opts.Validate([]string{"mysql"}))
+	assert.Equal(t, "mysql", opts.Name)
+
+	err := opts.Validate(nil)
+	require.ErrorContains(t, err, "expected exactly one positional argument")
+}
@@ -0,0 +1,226 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/opencontainers/go-digest"
+)
+
+// lockMixins pins the mixins used by the bundle in porter.lock. When locked is
+// true, the lock file is not updated and the build fails when the installed
+// mixins are different from the mixins pinned by the lock file.
+func (p *Porter) lockMixins(ctx context.Context, m *manifest.Manifest, dir string, locked bool) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	lockPath := filepath.Join(dir, mixin.LockFileName)
+	installed, err := p.getInstalledMixinLocks(ctx, m)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	lock, err := mixin.ReadLockFile(p.FileSystem, lockPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return log.Error(err)
+		}
+		if locked {
+			return log.Errorf("--locked was specified but the lock file %s does not exist. Run porter build without --locked to generate it", lockPath)
+		}
+	}
+
+	if locked {
+		if err = lock.Compare(installed); err != nil {
+			return log.Errorf("the installed mixins do not match %s. Run porter mixins install --from-lock to install the locked mixins:\n%w", lockPath, err)
+		}
+		log.Debugf("The installed mixins match %s", lockPath)
+		return nil
+	}
+
+	// Keep where the mixins are downloaded from, which is not known by the installed mixins
+	for i, installedMixin := range installed {
+		if prev, ok := lock.Get(installedMixin.Name); ok {
+			installed[i].URL = prev.URL
+			installed[i].FeedURL = prev.FeedURL
+		}
+	}
+
+	if err = mixin.NewLockFile(installed).Write(p.FileSystem, lockPath); err != nil {
+		return log.Error(err)
+	}
+	log.Debugf("Wrote the mixin versions to %s", lockPath)
+	return nil
+}
+
+// getInstalledMixinLocks returns the version and checksum of the installed mixins used by the bundle.
+func (p *Porter) getInstalledMixinLocks(ctx context.Context, m *manifest.Manifest) ([]mixin.LockedMixin, error) {
+	mixins, err := p.getUsedMixins(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	locks := make([]mixin.LockedMixin, 0, len(mixins))
+	for _, used := range mixins {
+		lock, err := p.getInstalledMixinLock(ctx, used.Name)
+		if err != nil {
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+// getInstalledMixinLock returns the version of an installed mixin, and the
+// checksum of its runtime, which is the binary that is copied into the bundle image.
+func (p *Porter) getInstalledMixinLock(ctx context.Context, name string) (mixin.LockedMixin, error) {
+	meta, err := p.Mixins.GetMetadata(ctx, name)
+	if err != nil {
+		return mixin.LockedMixin{}, err
+	}
+
+	pkgDir, err := p.Mixins.GetPackageDir(name)
+	if err != nil {
+		return mixin.LockedMixin{}, err
+	}
+	runtimePath := filepath.Join(pkgDir, "runtimes", name+"-runtime")
+	f, err := p.FileSystem.Open(runtimePath)
+	if err != nil {
+		return mixin.LockedMixin{}, fmt.Errorf("error reading the runtime of the %s mixin: %w", name, err)
+	}
+	defer f.Close()
+	checksum, err := digest.FromReader(f)
+	if err != nil {
+		return mixin.LockedMixin{}, fmt.Errorf("error calculating the checksum of the %s mixin: %w", name, err)
+	}
+
+	return mixin.LockedMixin{
+		Name:     name,
+		Version:  meta.GetVersionInfo().Version,
+		Checksum: checksum.String(),
+	}, nil
+}
+
+// installMixinsFromLock installs the mixins pinned by the lock file, and checks
+// that the installed mixins match the checksums in the lock file.
+func (p *Porter) installMixinsFromLock(ctx context.Context, opts mixin.InstallOptions) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	lock, err := mixin.ReadLockFile(p.FileSystem, opts.LockFile)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	for _, locked := range lock.Mixins {
+		installOpts := mixin.InstallOptions{
+			InstallOptions: pkgmgmt.InstallOptions{
+				PackageDownloadOptions: opts.PackageDownloadOptions,
+				URL:                    opts.URL,
+				FeedURL:                opts.FeedURL,
+				Version:                locked.Version,
+				RuntimePlatforms:       opts.RuntimePlatforms,
+			},
+		}
+		// Download from the location in the lock file, when specified
+		if locked.URL != "" || locked.FeedURL != "" {
+			installOpts.URL = locked.URL
+			installOpts.FeedURL = locked.FeedURL
+		}
+		if err = installOpts.Validate([]string{locked.Name}); err != nil {
+			return log.Errorf("invalid source for the %s mixin in %s: %w", locked.Name, opts.LockFile, err)
+		}
+
+		installed, err := p.installLockedMixin(ctx, opts.LockFile, locked, installOpts)
+		if err != nil {
+			return log.Error(err)
+		}
+
+		fmt.Fprintf(p.Out, "installed %s mixin %s (%s)\n", locked.Name, installed.Version, installed.Checksum)
+	}
+
+	return nil
+}
+
+// installLockedMixin installs a mixin pinned by the lock file, and checks that
+// it matches the version and checksum in the lock file. The previously installed
+// mixin is restored when the installation fails or the mixin does not match.
+func (p *Porter) installLockedMixin(ctx context.Context, lockFile string, locked mixin.LockedMixin, installOpts mixin.InstallOptions) (installed mixin.LockedMixin, err error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	pkgDir, err := p.Mixins.GetPackageDir(locked.Name)
+	if err != nil {
+		return mixin.LockedMixin{}, err
+	}
+
+	backupDir, err := p.backupMixin(pkgDir)
+	if err != nil {
+		return mixin.LockedMixin{}, fmt.Errorf("error backing up the installed %s mixin: %w", locked.Name, err)
+	}
+	defer func() {
+		if err != nil {
+			if restoreErr := p.restoreMixin(pkgDir, backupDir); restoreErr != nil {
+				err = errors.Join(err, fmt.Errorf("error restoring the previously installed %s mixin: %w", locked.Name, restoreErr))
+			} else {
+				log.Debugf("Restored the previously installed %s mixin", locked.Name)
+			}
+		}
+		if backupDir != "" {
+			err = errors.Join(err, p.FileSystem.RemoveAll(backupDir))
+		}
+	}()
+
+	if err = p.Mixins.Install(ctx, installOpts.InstallOptions); err != nil {
+		return mixin.LockedMixin{}, fmt.Errorf("error installing the %s mixin %s: %w", locked.Name, locked.Version, err)
+	}
+
+	installed, err = p.getInstalledMixinLock(ctx, locked.Name)
+	if err != nil {
+		return mixin.LockedMixin{}, err
+	}
+	if installed.Version != locked.Version {
+		return mixin.LockedMixin{}, fmt.Errorf("the %s mixin is locked to %s but %s was installed", locked.Name, locked.Version, installed.Version)
+	}
+	if locked.Checksum != "" && installed.Checksum != locked.Checksum {
+		return mixin.LockedMixin{}, fmt.Errorf("the installed %s mixin %s has checksum %s but %s requires %s", locked.Name, locked.Version, installed.Checksum, lockFile, locked.Checksum)
+	}
+	return installed, nil
+}
+
+// backupMixin copies an installed mixin to a temporary directory, returning an
+// empty directory name when the mixin is not installed.
+func (p *Porter) backupMixin(pkgDir string) (string, error) {
+	exists, err := p.FileSystem.DirExists(pkgDir)
+	if err != nil || !exists {
+		return "", err
+	}
+
+	backupDir, err := p.FileSystem.TempDir("", "porter-mixin")
+	if err != nil {
+		return "", err
+	}
+	if err = p.CopyDirectory(pkgDir, backupDir, false); err != nil {
+		return "", errors.Join(err, p.FileSystem.RemoveAll(backupDir))
+	}
+	return backupDir, nil
+}
+
+// restoreMixin replaces the mixin with its backup, or removes the mixin when
+// it was not installed before.
+func (p *Porter) restoreMixin(pkgDir string, backupDir string) error {
+	if err := p.FileSystem.RemoveAll(pkgDir); err != nil {
+		return err
+	}
+	if backupDir == "" {
+		return nil
+	}
+	return p.CopyDirectory(backupDir, pkgDir, false)
+}
@@ -0,0 +1,197 @@
+package porter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyChecksum is the checksum of the empty mixin runtimes created by the test config.
+var emptyChecksum = digest.FromBytes(nil).String()
+
+func TestPorter_Build_LockMixins(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *TestPorter {
+		p := NewTestPorter(t)
+		p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+		return p
+	}
+
+	build := func(t *testing.T, p *TestPorter, locked bool) error {
+		opts := BuildOptions{Locked: locked}
+		require.NoError(t, opts.Validate(p.Porter))
+		return p.Build(ctx, opts)
+	}
+
+	t.Run("generate lock file", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		require.NoError(t, build(t, p, false))
+
+		lock, err := mixin.ReadLockFile(p.FileSystem, filepath.Join(p.BundleDir, mixin.LockFileName))
+		require.NoError(t, err)
+		require.Len(t, lock.Mixins, 2)
+		assert.Equal(t, mixin.LockedMixin{Name: "exec", Version: "v1.0", Checksum: emptyChecksum}, lock.Mixins[0])
+		assert.Equal(t, "testmixin", lock.Mixins[1].Name)
+	})
+
+	t.Run("keep the mixin source", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		lockPath := filepath.Join(p.BundleDir, mixin.LockFileName)
+		prevLock := mixin.NewLockFile([]mixin.LockedMixin{
+			{Name: "exec", Version: "v0.9", Checksum: "sha256:old", URL: "https://example.com/mixins/exec"},
+		})
+		require.NoError(t, prevLock.Write(p.FileSystem, lockPath))
+
+		require.NoError(t, build(t, p, false))
+
+		lock, err := mixin.ReadLockFile(p.FileSystem, lockPath)
+		require.NoError(t, err)
+		exec, ok := lock.Get("exec")
+		require.True(t, ok)
+		assert.Equal(t, "v1.0", exec.Version, "the lock file should be updated with the installed version")
+		assert.Equal(t, "https://example.com/mixins/exec", exec.URL, "the source of the mixin should be preserved")
+	})
+
+	t.Run("locked matches", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		require.NoError(t, build(t, p, false))
+		require.NoError(t, build(t, p, true))
+	})
+
+	t.Run("locked without lock file", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		err := build(t, p, true)
+		require.ErrorContains(t, err, "--locked was specified but the lock file")
+	})
+
+	t.Run("locked with different mixins", func(t *testing.T) {
+		p := setup(t)
+		defer p.Close()
+
+		lockPath := filepath.Join(p.BundleDir, mixin.LockFileName)
+		prevLock := mixin.NewLockFile([]mixin.LockedMixin{
+			{Name: "exec", Version: "v0.9", Checksum: emptyChecksum},
+			{Name: "testmixin", Version: "v0.1.0", Checksum: emptyChecksum},
+		})
+		require.NoError(t, prevLock.Write(p.FileSystem, lockPath))
+
+		err := build(t, p, true)
+		require.ErrorContains(t, err, "the exec mixin is locked to v0.9 but v1.0 is installed")
+
+		lock, err := mixin.ReadLockFile(p.FileSystem, lockPath)
+		require.NoError(t, err)
+		assert.Equal(t, prevLock, lock, "the lock file should not be updated when --locked is specified")
+	})
+}
+
+func TestPorter_InstallMixin_FromLock(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, locked ...mixin.LockedMixin) (*TestPorter, *[]pkgmgmt.InstallOptions) {
+		p := NewTestPorter(t)
+		require.NoError(t, mixin.NewLockFile(locked).Write(p.FileSystem, mixin.LockFileName))
+
+		var installs []pkgmgmt.InstallOptions
+		mixins := p.Mixins.(*mixin.TestMixinProvider)
+		mixins.InstallAssertions = append(mixins.InstallAssertions, func(opts pkgmgmt.InstallOptions) error {
+			installs = append(installs, opts)
+			return nil
+		})
+		return p, &installs
+	}
+
+	t.Run("install locked mixins", func(t *testing.T) {
+		p, installs := setup(t,
+			mixin.LockedMixin{Name: "exec", Version: "v1.0", Checksum: emptyChecksum},
+			mixin.LockedMixin{Name: "testmixin", Version: "v0.1.0", Checksum: emptyChecksum, URL: "https://example.com/mixins/testmixin"},
+		)
+		defer p.Close()
+
+		opts := mixin.InstallOptions{FromLock: true}
+		require.NoError(t, opts.Validate(nil))
+		require.NoError(t, p.InstallMixin(ctx, opts))
+
+		require.Len(t, *installs, 2)
+		assert.Equal(t, "exec", (*installs)[0].Name)
+		assert.Equal(t, "v1.0", (*installs)[0].Version)
+		assert.Equal(t, "https://cdn.porter.sh/mixins/atom.xml", (*installs)[0].FeedURL, "the default feed should be used when the lock file does not specify a source")
+		assert.Equal(t, "https://example.com/mixins/testmixin", (*installs)[1].URL)
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "installed exec mixin v1.0 ("+emptyChecksum+")")
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		p, _ := setup(t, mixin.LockedMixin{Name: "exec", Version: "v1.0", Checksum: "sha256:abc"})
+		defer p.Close()
+
+		opts := mixin.InstallOptions{FromLock: true}
+		require.NoError(t, opts.Validate(nil))
+		err := p.InstallMixin(ctx, opts)
+		require.ErrorContains(t, err, "the installed exec mixin v1.0 has checksum "+emptyChecksum+" but porter.lock requires sha256:abc")
+	})
+
+	t.Run("restore mixin on checksum mismatch", func(t *testing.T) {
+		downloaded := []byte("downloaded runtime")
+		for _, tc := range []struct {
+			name     string
+			checksum string
+			wantErr  string
+			want     []byte
+		}{
+			{name: "match", checksum: digest.FromBytes(downloaded).String(), want: downloaded},
+			{name: "mismatch", checksum: "sha256:abc", wantErr: "but porter.lock requires sha256:abc", want: []byte{}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				p, _ := setup(t, mixin.LockedMixin{Name: "exec", Version: "v1.0", Checksum: tc.checksum})
+				defer p.Close()
+
+				pkgDir, err := p.Mixins.GetPackageDir("exec")
+				require.NoError(t, err)
+				runtimePath := filepath.Join(pkgDir, "runtimes", "exec-runtime")
+				mixins := p.Mixins.(*mixin.TestMixinProvider)
+				mixins.InstallAssertions = append(mixins.InstallAssertions, func(opts pkgmgmt.InstallOptions) error {
+					return p.FileSystem.WriteFile(runtimePath, downloaded, 0700)
+				})
+
+				opts := mixin.InstallOptions{FromLock: true}
+				require.NoError(t, opts.Validate(nil))
+				err = p.InstallMixin(ctx, opts)
+				if tc.wantErr != "" {
+					require.ErrorContains(t, err, tc.wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+
+				got, err := p.FileSystem.ReadFile(runtimePath)
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, got, "the previously installed mixin should be restored when it does not match the lock file")
+			})
+		}
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		p, _ := setup(t, mixin.LockedMixin{Name: "exec", Version: "v2.0", Checksum: emptyChecksum})
+		defer p.Close()
+
+		opts := mixin.InstallOptions{FromLock: true}
+		require.NoError(t, opts.Validate(nil))
+		err := p.InstallMixin(ctx, opts)
+		require.ErrorContains(t, err, "the exec mixin is locked to v2.0 but v1.0 was installed")
+	})
+}
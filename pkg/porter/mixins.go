@@ -75,6 +75,10 @@ func (p *Porter) ListMixins(ctx context.Context) ([]mixin.Metadata, error) {
 }
 
 func (p *Porter) InstallMixin(ctx context.Context, opts mixin.InstallOptions) error {
+	if opts.FromLock {
+		return p.installMixinsFromLock(ctx, opts)
+	}
+
 	err := p.Mixins.Install(ctx, opts.InstallOptions)
 	if err != nil {
 		return err
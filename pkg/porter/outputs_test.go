@@ -105,11 +105,11 @@ func TestPorter_PrintBundleOutputs(t *testing.T) {
 
 			extB := cnab.NewBundle(b)
 			i := p.TestInstallations.CreateInstallation(storage.NewInstallation("", "test"), func(i *storage.Installation) {
-				i.Parameters.Parameters = p.SanitizeParameters(i.Parameters.Parameters, i.ID, extB)
+				i.Parameters.Parameters = p.SanitizeParameters(i.Parameters.Parameters, i.SecretOwner(), extB)
 			})
 			c := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionInstall, extB), func(r *storage.Run) {
 				r.Bundle = b
-				r.ParameterOverrides.Parameters = p.SanitizeParameters(r.ParameterOverrides.Parameters, r.ID, extB)
+				r.ParameterOverrides.Parameters = p.SanitizeParameters(r.ParameterOverrides.Parameters, r.SecretOwner(), extB)
 			})
 			r := p.TestInstallations.CreateResult(c.NewResult(cnab.StatusSucceeded))
 			p.CreateOutput(r.NewOutput("foo", []byte("foo-output")), extB)
@@ -325,7 +325,7 @@ func (p *Porter) DeleteParameter(ctx context.Context, opts ParameterDeleteOption
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
-	err := p.Parameters.RemoveParameterSet(ctx, opts.Namespace, opts.Name)
+	params, err := p.Parameters.GetParameterSet(ctx, opts.Namespace, opts.Name)
 	if errors.Is(err, storage.ErrNotFound{}) {
 		span.Debug("Cannot remove parameter set because it already doesn't exist")
 		return nil
@@ -334,6 +334,12 @@ func (p *Porter) DeleteParameter(ctx context.Context, opts ParameterDeleteOption
 		return span.Error(fmt.Errorf("unable to delete parameter set: %w", err))
 	}
 
+	err = p.Parameters.RemoveParameterSet(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return span.Error(fmt.Errorf("unable to delete parameter set: %w", err))
+	}
+
+	p.removeSecrets(ctx, storage.SanitizedSourceKeys(params.Parameters, params.SecretsOwnerID()))
 	return nil
 }
 
@@ -357,9 +363,11 @@ func validateParameterName(args []string) error {
 	}
 }
 
-// loadParameterSets loads parameter values per their parameter set strategies
-func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle, namespace string, params []string, overridenParameters secrets.StrategyList) (secrets.Set, error) {
+// loadParameterSets loads parameter values per their parameter set strategies.
+// The names of the parameters resolved from a template that uses a sensitive value are also returned.
+func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle, namespace string, params []string, overridenParameters secrets.StrategyList) (secrets.Set, []string, error) {
 	resolvedParameters := secrets.Set{}
+	sensitiveTemplates := map[string]bool{}
 
 	for _, name := range params {
 		// Try to get the params in the local namespace first, fallback to the global creds
@@ -378,7 +386,14 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 		var pset storage.ParameterSet
 		err := store.FindOne(ctx, storage.CollectionParameters, query, &pset)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		// Templates may reference file parameters, which are removed from the set
+		// below, so inline the references before the parameters are resolved
+		pset.Parameters, err = storage.InlineTemplateSources(storage.CollectionParameters, pset.Parameters)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid parameter set %s: %w", name, err)
 		}
 
 		var skipParams []string
@@ -393,7 +408,7 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 		for paramName, paramDef := range bun.Parameters {
 			paramSchema, ok := bun.Definitions[paramDef.Definition]
 			if !ok {
-				return nil, fmt.Errorf("definition %s not defined in bundle", paramDef.Definition)
+				return nil, nil, fmt.Errorf("definition %s not defined in bundle", paramDef.Definition)
 			}
 
 			if bun.IsFileType(paramSchema) {
@@ -422,15 +437,24 @@ func (p *Porter) loadParameterSets(ctx context.Context, bun cnab.ExtendedBundle,
 
 		rc, err := p.Parameters.ResolveAll(ctx, pset, keysToResolve)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
+		// The last parameter set that defines a parameter determines if it is sensitive
+		templateKeys := pset.SensitiveTemplateKeys()
 		for k, v := range rc {
 			resolvedParameters[k] = v
+			sensitiveTemplates[k] = slices.Contains(templateKeys, k)
 		}
 	}
 
-	return resolvedParameters, nil
+	var sensitiveParameters []string
+	for k, sensitive := range sensitiveTemplates {
+		if sensitive {
+			sensitiveParameters = append(sensitiveParameters, k)
+		}
+	}
+	return resolvedParameters, sensitiveParameters, nil
 }
 
 type DisplayValue struct {
@@ -546,13 +570,13 @@ func (p *Porter) ParametersApply(ctx context.Context, o ApplyOptions) error {
 	defer span.EndSpan()
 
 	span.Debugf("Reading input file %s...", o.File)
-	namespace, err := p.getNamespaceFromFile(o)
+	namespace, err := p.getNamespaceFromFile(ctx, o)
 	if err != nil {
 		return span.Error(err)
 	}
 
 	var params DisplayParameterSet
-	err = encoding.UnmarshalFile(p.FileSystem, o.File, &params)
+	encrypted, err := p.unmarshalInputFile(ctx, o.File, &params)
 	if err != nil {
 		return span.Error(fmt.Errorf("could not load %s as a parameter set: %w", o.File, err))
 	}
@@ -570,10 +594,27 @@ func (p *Porter) ParametersApply(ctx context.Context, o ApplyOptions) error {
 		return span.Error(fmt.Errorf("parameter set is invalid: %w", err))
 	}
 
+	// Find the secrets saved for the previous version of the parameter set
+	var previousSecrets []string
+	if existing, err := p.Parameters.GetParameterSet(ctx, params.Namespace, params.Name); err == nil {
+		previousSecrets = storage.SanitizedSourceKeys(existing.Parameters, existing.SecretsOwnerID())
+	} else if !errors.Is(err, storage.ErrNotFound{}) {
+		return span.Error(err)
+	}
+
+	// Only keep the values from an encrypted file in memory and the secret store
+	if encrypted {
+		params.Parameters, err = p.Sanitizer.CleanValueSources(ctx, params.Parameters, params.SecretOwner())
+		if err != nil {
+			return span.Error(err)
+		}
+	}
+
 	err = p.Parameters.UpsertParameterSet(ctx, params.ParameterSet)
 	if err != nil {
 		return err
 	}
+	p.removeUnreferencedSecrets(ctx, previousSecrets, storage.SanitizedSourceKeys(params.Parameters, params.SecretsOwnerID()))
 
 	fmt.Fprintf(p.Out, "Applied %s parameter set\n", params)
 	return nil
@@ -902,7 +943,7 @@ func (p *Porter) applyActionOptionsToInstallation(ctx context.Context, ba Bundle
 	//
 	// 3. Resolve named parameter sets
 	//
-	resolvedParams, err := p.loadParameterSets(ctx, bun, o.Namespace, inst.ParameterSets, inst.Parameters.Parameters)
+	resolvedParams, sensitiveParams, err := p.loadParameterSets(ctx, bun, o.Namespace, inst.ParameterSets, inst.Parameters.Parameters)
 	if err != nil {
 		return fmt.Errorf("unable to process provided parameter sets: %w", err)
 	}
@@ -923,8 +964,13 @@ func (p *Porter) applyActionOptionsToInstallation(ctx context.Context, ba Bundle
 	//
 	// 5. Apply the overrides on top of the parameter sets
 	//
+	overrideTemplateKeys := inst.Parameters.SensitiveTemplateKeys()
 	for k, v := range resolvedOverrides {
 		resolvedParams[k] = v
+		sensitiveParams = slices.DeleteFunc(sensitiveParams, func(name string) bool { return name == k })
+		if slices.Contains(overrideTemplateKeys, k) {
+			sensitiveParams = append(sensitiveParams, k)
+		}
 	}
 
 	for name, value := range parsedOverrides {
@@ -961,6 +1007,7 @@ func (p *Porter) applyActionOptionsToInstallation(ctx context.Context, ba Bundle
 
 	// Remember the final set of parameters so we don't have to resolve them more than once
 	o.finalParams = finalParams
+	o.sensitiveParams = sensitiveParams
 
 	// Ensure we aren't storing any secrets on the installation resource
 	if err = p.sanitizeInstallation(ctx, inst, bundleRef.Definition); err != nil {
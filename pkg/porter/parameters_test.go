@@ -1,11 +1,13 @@
 package porter
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
 	"testing"
 
+	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/printer"
@@ -986,6 +988,39 @@ func TestPorter_ParametersApply(t *testing.T) {
 		assert.Equal(t, "secret", ps.Parameters[0].Source.Strategy, "expected the foo parameter mapping to come from a secret")
 		assert.Equal(t, "foo_secret", ps.Parameters[0].Source.Hint, "expected the foo parameter mapping to use foo_secret")
 	})
+
+	t.Run("encrypted parameter set", func(t *testing.T) {
+		ctx := context.Background()
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		p.AddTestFile("testdata/parameters/mypset.yaml", "mypset.yaml")
+		contents, err := p.FileSystem.ReadFile("mypset.yaml")
+		require.NoError(t, err)
+		contents = bytes.Replace(contents, []byte("status:"), []byte("  - name: password\n    source:\n      value: topsecret\nstatus:"), 1)
+		require.NoError(t, p.FileSystem.WriteFile("mypset.yaml", contents, pkg.FileModeWritable))
+		p.addSopsEncryptedFile(t, "mypset.yaml")
+
+		err = p.ParametersApply(ctx, ApplyOptions{File: "mypset.yaml"})
+		require.NoError(t, err, "ParametersApply failed")
+
+		ps, err := p.Parameters.GetParameterSet(ctx, "", "mypset")
+		require.NoError(t, err, "Failed to retrieve applied parameter set")
+		require.Len(t, ps.Parameters, 2, "expected 2 parameters in the set")
+		assert.Equal(t, secrets.Source{Strategy: secrets.SourceSecret, Hint: "foo_secret"}, ps.Parameters[0].Source)
+		assert.Equal(t, secrets.Source{Strategy: secrets.SourceSecret, Hint: "parameterset--mypset-password"}, ps.Parameters[1].Source,
+			"the decrypted value should be saved in the secret store")
+		value, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, "parameterset--mypset-password")
+		require.NoError(t, err)
+		assert.Equal(t, "topsecret", value)
+
+		// Applying a version of the parameter set without the value removes the secret
+		p.AddTestFile("testdata/parameters/mypset.yaml", "mypset.yaml")
+		require.NoError(t, p.ParametersApply(ctx, ApplyOptions{File: "mypset.yaml"}))
+		keys, err := p.Secrets.List(ctx, secrets.SourceSecret)
+		require.NoError(t, err)
+		assert.Empty(t, keys, "the secret that is no longer referenced should be removed")
+	})
 }
 
 func TestParameterRemovedFromBundle(t *testing.T) {
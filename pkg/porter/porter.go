@@ -9,6 +9,7 @@ import (
 
 	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/build/buildkit"
+	"get.porter.sh/porter/pkg/build/oci"
 	"get.porter.sh/porter/pkg/cache"
 	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
@@ -40,6 +41,7 @@ type Porter struct {
 	Credentials   storage.CredentialSetProvider
 	Parameters    storage.ParameterSetProvider
 	Sanitizer     *storage.Sanitizer
+	SecretRecords *storage.SecretRecordStore
 	Installations storage.InstallationProvider
 	Registry      cnabtooci.RegistryProvider
 	Templates     *templates.Templates
@@ -49,6 +51,19 @@ type Porter struct {
 	Secrets       secrets.Store
 	Storage       storage.Provider
 	Signer        signing.Signer
+
+	// newSigner creates a signer from its definition in the config file.
+	// It can be switched out for tests.
+	newSigner func(signer config.SigningPlugin) signing.Signer
+
+	// newSecrets creates a store that uses the secrets plugin defined with the specified name in the config file.
+	// It can be switched out for tests.
+	newSecrets func(name string) secrets.Store
+
+	// signers are the signers used by the verification policy, keyed by the
+	// signer name, or by the rule and signer name when the rule configures an
+	// identity for the signer. They are loaded on demand and closed with Porter.
+	signers map[string]signing.Signer
 }
 
 // New porter client, initialized with useful defaults.
@@ -85,7 +100,14 @@ func NewFor(c *config.Config, store storage.Store, secretStorage secrets.Store,
 		Plugins:       plugins.NewPackageManager(c),
 		CNAB:          cnabprovider.NewRuntime(c, installationStorage, credStorage, paramStorage, secretStorage, sanitizerService),
 		Sanitizer:     sanitizerService,
+		SecretRecords: storage.NewSecretRecordStore(storageManager),
 		Signer:        signer,
+		newSigner: func(signer config.SigningPlugin) signing.Signer {
+			return signing.NewPluginAdapter(signingplugin.NewConfiguredSigner(c, signer))
+		},
+		newSecrets: func(name string) secrets.Store {
+			return secrets.NewPluginAdapter(secretsplugin.NewNamedStore(c, name))
+		},
 	}
 }
 
@@ -144,6 +166,13 @@ func (p *Porter) Close() error {
 		bigErr = multierror.Append(bigErr, err)
 	}
 
+	for _, signer := range p.signers {
+		err = signer.Close()
+		if err != nil {
+			bigErr = multierror.Append(bigErr, err)
+		}
+	}
+
 	return bigErr.ErrorOrNil()
 }
 
@@ -154,14 +183,17 @@ func (p *Porter) GetBuilder(ctx context.Context) build.Builder {
 	if p.builder == nil {
 		driver := p.GetBuildDriver()
 		switch driver {
+		case config.BuildDriverOCI:
+			p.builder = oci.NewBuilder(p.Config)
 		case config.BuildDriverBuildkit:
-			// supported, yay!
+			p.builder = buildkit.NewBuilder(p.Config)
 		case config.BuildDriverDocker:
 			log.Warn("The docker build driver is no longer supported. Using buildkit instead.")
+			p.builder = buildkit.NewBuilder(p.Config)
 		default:
 			log.Warnf("Unsupported build driver: %s. Using buildkit instead.", driver)
+			p.builder = buildkit.NewBuilder(p.Config)
 		}
-		p.builder = buildkit.NewBuilder(p.Config)
 	}
 	return p.builder
 }
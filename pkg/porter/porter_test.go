@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/build/buildkit"
+	"get.porter.sh/porter/pkg/build/oci"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/tests"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +27,12 @@ func TestPorter_GetBuilder(t *testing.T) {
 		driver := p.GetBuilder(context.Background())
 		assert.IsType(t, &buildkit.Builder{}, driver)
 	})
+	t.Run("oci", func(t *testing.T) {
+		p := Porter{Config: &config.Config{}}
+		p.Data.BuildDriver = config.BuildDriverOCI
+		driver := p.GetBuilder(context.Background())
+		assert.IsType(t, &oci.Builder{}, driver)
+	})
 	t.Run("unspecified", func(t *testing.T) {
 		// Always default to Docker
 		p := Porter{Config: &config.Config{}}
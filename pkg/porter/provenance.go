@@ -0,0 +1,338 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/provenance"
+	"get.porter.sh/porter/pkg/sbom"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/opencontainers/go-digest"
+)
+
+// ProvenanceOptions are the options for porter bundle provenance.
+type ProvenanceOptions struct {
+	BundlePullOptions
+	printer.PrintOptions
+
+	// Verify the signature of the provenance statements.
+	Verify bool
+}
+
+// Validate the args provided to porter bundle provenance.
+func (o *ProvenanceOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("a bundle reference is required")
+	case 1:
+		o.Reference = args[0]
+	default:
+		return fmt.Errorf("only one bundle reference can be specified, but multiple were received: %s", args)
+	}
+
+	if err := o.BundlePullOptions.Validate(); err != nil {
+		return err
+	}
+
+	return o.ParseFormat()
+}
+
+// BundleProvenance is a provenance statement that is attached to a bundle.
+type BundleProvenance struct {
+	// Digest of the attached statement in the registry.
+	Digest digest.Digest `json:"digest" yaml:"digest"`
+
+	// Verification of the signature of the statement, when --verify is specified.
+	Verification *ReferenceVerification `json:"verification,omitempty" yaml:"verification,omitempty"`
+
+	// Statement is the in-toto statement with a SLSA provenance predicate.
+	Statement provenance.Statement `json:"statement" yaml:"statement"`
+}
+
+// writeBuildRecord saves how the bundle image was built, so that it can be
+// included in the provenance statement when the bundle is published.
+func (p *Porter) writeBuildRecord(record build.Record) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling the build record: %w", err)
+	}
+	if err = p.FileSystem.WriteFile(build.LOCAL_BUILD_RECORD, data, pkg.FileModeWritable); err != nil {
+		return fmt.Errorf("error writing %s: %w", build.LOCAL_BUILD_RECORD, err)
+	}
+	return nil
+}
+
+// readBuildRecord reads the record saved when the bundle image was built.
+// An empty record is returned when the bundle was built by a version of Porter
+// that did not save a record.
+func (p *Porter) readBuildRecord() (build.Record, error) {
+	var record build.Record
+	data, err := p.FileSystem.ReadFile(build.LOCAL_BUILD_RECORD)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return record, nil
+		}
+		return record, fmt.Errorf("error reading %s: %w", build.LOCAL_BUILD_RECORD, err)
+	}
+	if err = json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("error parsing %s: %w", build.LOCAL_BUILD_RECORD, err)
+	}
+	return record, nil
+}
+
+// newProvenance creates a provenance statement for a published bundle and its bundle image.
+func (p *Porter) newProvenance(ctx context.Context, m *manifest.Manifest, manifestDigest string, bundleRef cnab.BundleReference, imageRef cnab.OCIReference, imageDigest digest.Digest) (provenance.Statement, error) {
+	manifestData, err := p.FileSystem.ReadFile(m.ManifestPath)
+	if err != nil {
+		return provenance.Statement{}, fmt.Errorf("error reading %s: %w", m.ManifestPath, err)
+	}
+
+	record, err := p.readBuildRecord()
+	if err != nil {
+		return provenance.Statement{}, err
+	}
+
+	// The dependencies are the same components that are described by the SBOM
+	inv, err := p.buildSBOMInventory(ctx, m, imageDigest)
+	if err != nil {
+		return provenance.Statement{}, err
+	}
+	var deps []provenance.ResourceDescriptor
+	for _, components := range [][]sbom.Component{inv.BaseImages, {inv.Runtime}, inv.Mixins, inv.Images} {
+		for _, c := range components {
+			deps = append(deps, provenance.ResourceDescriptor{
+				Name:   c.Name,
+				URI:    c.PackageURL(),
+				Digest: provenance.DigestSet(digest.Digest(c.Digest)),
+			})
+		}
+	}
+
+	subjects := []provenance.Subject{
+		provenance.NewSubject(bundleRef.Reference.Repository(), bundleRef.Digest),
+		provenance.NewSubject(imageRef.Repository(), imageDigest),
+	}
+
+	def := provenance.BuildDefinition{
+		ExternalParameters: provenance.ExternalParameters{
+			Manifest: provenance.ResourceDescriptor{
+				URI:    m.ManifestPath,
+				Digest: provenance.DigestSet(digest.FromBytes(manifestData)),
+			},
+			Reference:     bundleRef.Reference.String(),
+			BuildArgs:     record.BuildArgs,
+			BuildContexts: record.BuildContexts,
+			Secrets:       record.Secrets,
+			SSH:           record.SSH,
+			NoCache:       record.NoCache,
+			Platforms:     record.Platforms,
+		},
+		InternalParameters: provenance.InternalParameters{
+			BuildDriver:    p.GetBuildDriver(),
+			ManifestDigest: manifestDigest,
+		},
+		ResolvedDependencies: deps,
+	}
+
+	run := provenance.RunDetails{
+		Builder: provenance.Builder{Version: map[string]string{"porter": pkg.Version}},
+	}
+	if pkg.Commit != "" {
+		run.Builder.Version["commit"] = pkg.Commit
+	}
+	if !record.StartedOn.IsZero() {
+		run.Metadata.StartedOn = &record.StartedOn
+		run.Metadata.FinishedOn = &record.FinishedOn
+	}
+
+	return provenance.New(subjects, def, run), nil
+}
+
+// attachProvenance pushes a provenance statement as an artifact that refers to the bundle,
+// returning a reference to the statement.
+func (p *Porter) attachProvenance(ctx context.Context, statement provenance.Statement, bundleRef cnab.BundleReference, regOpts cnabtooci.RegistryOptions) (cnab.OCIReference, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	data, err := statement.Marshal()
+	if err != nil {
+		return cnab.OCIReference{}, log.Error(err)
+	}
+
+	subject, err := bundleRef.Reference.WithDigest(bundleRef.Digest)
+	if err != nil {
+		return cnab.OCIReference{}, log.Error(err)
+	}
+	artifact := cnabtooci.Artifact{
+		ArtifactType: provenance.MediaType,
+		MediaType:    provenance.MediaType,
+		Content:      data,
+		Annotations:  map[string]string{provenance.AnnotationPredicateType: provenance.PredicateType},
+	}
+	artifactDigest, err := p.Registry.AttachArtifact(ctx, subject, artifact, regOpts)
+	if err != nil {
+		return cnab.OCIReference{}, log.Errorf("error attaching the provenance statement to %s: %w", bundleRef.Reference, err)
+	}
+
+	log.Infof("Attached the provenance statement to %s", bundleRef.Reference)
+	return bundleRef.Reference.WithDigest(artifactDigest)
+}
+
+// GetBundleProvenance retrieves the provenance statements that are attached to a bundle,
+// verifying their signatures when requested.
+func (p *Porter) GetBundleProvenance(ctx context.Context, opts ProvenanceOptions) ([]BundleProvenance, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if opts.Verify && p.Data.VerificationPolicy.IsEnabled() {
+		if err := validateVerificationPolicy(p.Config); err != nil {
+			return nil, log.Error(err)
+		}
+	}
+
+	pullOpts := &BundleReferenceOptions{BundlePullOptions: opts.BundlePullOptions}
+	bundleRef, err := pullOpts.GetBundleReference(ctx, p)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	subject := bundleRef.Reference
+	if bundleRef.Digest != "" {
+		if subject, err = bundleRef.Reference.WithDigest(bundleRef.Digest); err != nil {
+			return nil, log.Error(err)
+		}
+	}
+
+	regOpts := cnabtooci.RegistryOptions{InsecureRegistry: opts.InsecureRegistry}
+	artifacts, err := p.Registry.ListArtifacts(ctx, subject, provenance.MediaType, regOpts)
+	if err != nil {
+		return nil, log.Errorf("error listing the provenance statements of %s: %w", bundleRef.Reference, err)
+	}
+	if len(artifacts) == 0 {
+		return nil, log.Errorf("no provenance statement is attached to %s", bundleRef.Reference)
+	}
+
+	// A copied bundle may have a different digest, but the digest of its bundle image is unchanged
+	var imageDigest digest.Digest
+	if len(bundleRef.Definition.InvocationImages) > 0 {
+		imageDigest = digest.Digest(bundleRef.Definition.InvocationImages[0].Digest)
+	}
+
+	results := make([]BundleProvenance, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		statement, err := provenance.Parse(artifact.Content)
+		if err != nil {
+			return nil, log.Errorf("invalid provenance statement %s: %w", artifact.Digest, err)
+		}
+		if !statement.HasSubject(bundleRef.Digest) && !statement.HasSubject(imageDigest) {
+			return nil, log.Errorf("the provenance statement %s does not describe %s", artifact.Digest, bundleRef.Reference)
+		}
+
+		result := BundleProvenance{Digest: artifact.Digest, Statement: statement}
+		if opts.Verify {
+			statementRef, err := bundleRef.Reference.WithDigest(artifact.Digest)
+			if err != nil {
+				return nil, log.Error(err)
+			}
+			verification := p.verifyReference(ctx, "provenance", statementRef.String())
+			result.Verification = &verification
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// PrintBundleProvenance prints the provenance statements that are attached to a bundle,
+// returning an error when --verify is specified and a statement could not be verified.
+func (p *Porter) PrintBundleProvenance(ctx context.Context, opts ProvenanceOptions) error {
+	results, err := p.GetBundleProvenance(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, results)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, results)
+	case printer.FormatPlaintext:
+		for i, result := range results {
+			if i > 0 {
+				fmt.Fprintln(p.Out)
+			}
+			p.printProvenance(result)
+		}
+	default:
+		err = fmt.Errorf("invalid format: %s", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	var verifications []ReferenceVerification
+	for _, result := range results {
+		if result.Verification != nil {
+			verifications = append(verifications, *result.Verification)
+		}
+	}
+	return verificationError(opts.Reference, verifications)
+}
+
+func (p *Porter) printProvenance(result BundleProvenance) {
+	s := result.Statement
+	def := s.Predicate.BuildDefinition
+	params := def.ExternalParameters
+
+	fmt.Fprintf(p.Out, "Provenance: %s\n", result.Digest)
+	if result.Verification != nil {
+		fmt.Fprintf(p.Out, "Signature: %s\n", result.Verification.Status)
+	}
+	fmt.Fprintf(p.Out, "Builder: %s %s\n", s.Predicate.RunDetails.Builder.ID, s.Predicate.RunDetails.Builder.Version["porter"])
+	if started := s.Predicate.RunDetails.Metadata.StartedOn; started != nil {
+		fmt.Fprintf(p.Out, "Built: %s\n", started.Format(time.RFC3339))
+	}
+	fmt.Fprintf(p.Out, "Source: %s@%s\n", params.Manifest.URI, formatDigestSet(params.Manifest.Digest))
+	fmt.Fprintf(p.Out, "Reference: %s\n", params.Reference)
+	if len(params.Platforms) > 0 {
+		fmt.Fprintf(p.Out, "Platforms: %s\n", strings.Join(params.Platforms, ", "))
+	}
+
+	fmt.Fprintln(p.Out, "Subjects:")
+	for _, subject := range s.Subject {
+		fmt.Fprintf(p.Out, "  %s@%s\n", subject.Name, formatDigestSet(subject.Digest))
+	}
+
+	if len(params.BuildArgs) > 0 {
+		fmt.Fprintln(p.Out, "Build Arguments:")
+		for _, arg := range params.BuildArgs {
+			fmt.Fprintf(p.Out, "  %s\n", arg)
+		}
+	}
+
+	if len(def.ResolvedDependencies) > 0 {
+		fmt.Fprintln(p.Out, "Dependencies:")
+		for _, dep := range def.ResolvedDependencies {
+			fmt.Fprintf(p.Out, "  %s\n", dep.URI)
+		}
+	}
+}
+
+// formatDigestSet formats the sha256 digest in a digest set as sha256:DIGEST.
+func formatDigestSet(digests map[string]string) string {
+	if d, ok := digests[digest.SHA256.String()]; ok {
+		return digest.NewDigestFromEncoded(digest.SHA256, d).String()
+	}
+	return ""
+}
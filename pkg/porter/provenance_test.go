@@ -0,0 +1,222 @@
+package porter
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/provenance"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_Build_WritesBuildRecord(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+	opts := BuildOptions{}
+	opts.BuildArgs = []string{"MYARG=1"}
+	opts.Secrets = []string{"id=token,src=/tmp/token"}
+	opts.SSH = []string{"default=/tmp/agent.sock"}
+	require.NoError(t, opts.Validate(p.Porter))
+	require.NoError(t, p.Build(ctx, opts))
+
+	record, err := p.readBuildRecord()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MYARG=1"}, record.BuildArgs)
+	assert.Equal(t, []string{"token"}, record.Secrets, "only the id of the secret should be recorded")
+	assert.Equal(t, []string{"default"}, record.SSH, "only the id of the ssh agent should be recorded")
+	assert.False(t, record.StartedOn.IsZero(), "the start of the build should be recorded")
+	assert.False(t, record.FinishedOn.Before(record.StartedOn), "the build should finish after it started")
+}
+
+func TestPublish_AttachProvenance(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+	opts := PublishOptions{SignBundle: true}
+	require.NoError(t, opts.Validate(p.Config))
+	require.NoError(t, p.Publish(ctx, opts))
+
+	var statements []cnabtooci.Artifact
+	for _, attached := range p.TestRegistry.Artifacts {
+		for _, artifact := range attached {
+			if artifact.ArtifactType == provenance.MediaType {
+				statements = append(statements, artifact)
+			}
+		}
+	}
+	require.Len(t, statements, 1, "the provenance statement should be attached to the bundle")
+	assert.Equal(t, provenance.PredicateType, statements[0].Annotations[provenance.AnnotationPredicateType])
+
+	s, err := provenance.Parse(statements[0].Content)
+	require.NoError(t, err)
+
+	imageDigest := digest.Digest("sha256:75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041")
+	assert.True(t, s.HasSubject(imageDigest), "the bundle image should be a subject")
+
+	params := s.Predicate.BuildDefinition.ExternalParameters
+	assert.Equal(t, "porter.yaml", filepath.Base(params.Manifest.URI))
+	assert.NotEmpty(t, params.Manifest.Digest["sha256"], "the digest of porter.yaml should be recorded")
+	assert.Equal(t, "localhost:5000/mybuns:v0.1.2", params.Reference)
+	assert.NotEmpty(t, s.Predicate.BuildDefinition.InternalParameters.ManifestDigest)
+	assert.Equal(t, provenance.BuilderID, s.Predicate.RunDetails.Builder.ID)
+	assert.NotNil(t, s.Predicate.RunDetails.Metadata.StartedOn, "the build time should be included from the build record")
+
+	var deps []string
+	for _, dep := range s.Predicate.BuildDefinition.ResolvedDependencies {
+		deps = append(deps, dep.URI)
+	}
+	assert.Contains(t, deps, "pkg:generic/exec@v1.0", "the mixins should be included")
+
+	statementRef := "localhost:5000/mybuns:v0.1.2@" + statements[0].Digest.String()
+	require.NoError(t, p.Signer.Verify(ctx, statementRef), "the provenance statement should be signed")
+}
+
+func TestPorter_PrintBundleProvenance(t *testing.T) {
+	bun, err := cnab.LoadBundle(portercontext.New(), filepath.Join("testdata/bundle.json"))
+	require.NoError(t, err)
+
+	const ref = "example.com/mybuns:v0.1.0"
+	bundleDigest := digest.FromString("bundle")
+
+	setup := func(t *testing.T, subject digest.Digest) (*TestPorter, string) {
+		p := NewTestPorter(t)
+		p.TestRegistry.MockPullBundle = func(ctx context.Context, ref cnab.OCIReference, opts cnabtooci.RegistryOptions) (cnab.BundleReference, error) {
+			return cnab.BundleReference{Reference: ref, Digest: bundleDigest, Definition: bun}, nil
+		}
+
+		s := provenance.New(
+			[]provenance.Subject{provenance.NewSubject("example.com/mybuns", subject)},
+			provenance.BuildDefinition{
+				ExternalParameters: provenance.ExternalParameters{
+					Manifest:  provenance.ResourceDescriptor{URI: "porter.yaml", Digest: provenance.DigestSet(digest.FromString("porter.yaml"))},
+					Reference: ref,
+					BuildArgs: []string{"MYARG=1"},
+				},
+			},
+			provenance.RunDetails{Builder: provenance.Builder{Version: map[string]string{"porter": "v1.0.0"}}})
+		data, err := s.Marshal()
+		require.NoError(t, err)
+
+		subjectRef := ref + "@" + bundleDigest.String()
+		artifactDigest := digest.FromBytes(data)
+		p.TestRegistry.Artifacts[subjectRef] = []cnabtooci.Artifact{
+			{ArtifactType: provenance.MediaType, MediaType: provenance.MediaType, Content: data, Digest: artifactDigest},
+		}
+		return p, ref + "@" + artifactDigest.String()
+	}
+
+	t.Run("plaintext", func(t *testing.T) {
+		p, _ := setup(t, bundleDigest)
+		defer p.Close()
+
+		opts := ProvenanceOptions{}
+		require.NoError(t, opts.Validate([]string{ref}))
+		require.NoError(t, p.PrintBundleProvenance(p.RootContext, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Builder: https://porter.sh/porter v1.0.0")
+		assert.Contains(t, output, "Source: porter.yaml@"+digest.FromString("porter.yaml").String())
+		assert.Contains(t, output, "example.com/mybuns@"+bundleDigest.String())
+		assert.Contains(t, output, "MYARG=1")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		p, _ := setup(t, bundleDigest)
+		defer p.Close()
+
+		opts := ProvenanceOptions{}
+		opts.RawFormat = string(printer.FormatJson)
+		require.NoError(t, opts.Validate([]string{ref}))
+		require.NoError(t, p.PrintBundleProvenance(p.RootContext, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, `"predicateType": "https://slsa.dev/provenance/v1"`)
+	})
+
+	t.Run("subject does not match", func(t *testing.T) {
+		p, _ := setup(t, digest.FromString("another bundle"))
+		defer p.Close()
+
+		opts := ProvenanceOptions{}
+		require.NoError(t, opts.Validate([]string{ref}))
+		err := p.PrintBundleProvenance(p.RootContext, opts)
+		require.ErrorContains(t, err, "does not describe "+ref)
+	})
+
+	t.Run("verify signed", func(t *testing.T) {
+		p, statementRef := setup(t, bundleDigest)
+		defer p.Close()
+
+		require.NoError(t, p.Signer.Sign(p.RootContext, statementRef))
+
+		opts := ProvenanceOptions{Verify: true}
+		require.NoError(t, opts.Validate([]string{ref}))
+		require.NoError(t, p.PrintBundleProvenance(p.RootContext, opts))
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Signature: verified")
+	})
+
+	t.Run("verify unsigned", func(t *testing.T) {
+		p, statementRef := setup(t, bundleDigest)
+		defer p.Close()
+
+		opts := ProvenanceOptions{Verify: true}
+		require.NoError(t, opts.Validate([]string{ref}))
+		err := p.PrintBundleProvenance(p.RootContext, opts)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "provenance "+statementRef), "the unverified statement should be reported: %s", err)
+	})
+
+	t.Run("no provenance", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		opts := ProvenanceOptions{}
+		require.NoError(t, opts.Validate([]string{ref}))
+		require.ErrorContains(t, p.PrintBundleProvenance(p.RootContext, opts), "no provenance statement is attached")
+	})
+}
+
+func TestCopy_CopyProvenance(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	destDigest := digest.FromString("destination")
+	p.TestRegistry.MockPushBundle = func(ctx context.Context, ref cnab.BundleReference, opts cnabtooci.RegistryOptions) (cnab.BundleReference, error) {
+		ref.Digest = destDigest
+		return ref, nil
+	}
+
+	source := "example1.com/mybuns:v0.1.0"
+	statement := cnabtooci.Artifact{ArtifactType: provenance.MediaType, MediaType: provenance.MediaType, Content: []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)}
+	p.TestRegistry.Artifacts[source] = []cnabtooci.Artifact{statement}
+
+	opts := &CopyOpts{
+		Source:      source,
+		Destination: "example2.com/mybuns:v0.1.0",
+		SignBundle:  true,
+	}
+	require.NoError(t, opts.Validate(p.Config))
+	require.NoError(t, p.CopyBundle(ctx, opts))
+
+	copied := p.TestRegistry.Artifacts["example2.com/mybuns:v0.1.0@"+destDigest.String()]
+	require.Len(t, copied, 1, "the provenance statement should be attached to the copied bundle")
+	assert.Equal(t, statement.Content, copied[0].Content)
+
+	copiedRef := "example2.com/mybuns:v0.1.0@" + copied[0].Digest.String()
+	require.NoError(t, p.Signer.Verify(ctx, copiedRef), "the copied provenance statement should be signed")
+}
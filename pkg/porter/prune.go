@@ -0,0 +1,228 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// RunPruneOptions represent options for Porter's installation runs prune command.
+type RunPruneOptions struct {
+	// Namespace of the installations to prune.
+	Namespace string
+
+	// Name of the installation to prune. When empty, every installation in the namespace is pruned.
+	Name string
+
+	// AllNamespaces prunes installations in all namespaces.
+	AllNamespaces bool
+
+	// KeepLast is the number of most recent runs to keep for each installation.
+	// Defaults to the run-retention policy in the config file.
+	KeepLast int
+
+	// KeepLastSet indicates that KeepLast was specified, so that --keep-last 0
+	// keeps only the run for the current status instead of using the config file.
+	KeepLastSet bool
+
+	// OlderThan is the duration after which runs are pruned.
+	// Defaults to the run-retention policy in the config file.
+	OlderThan string
+
+	// DryRun lists the runs that would be pruned without removing them.
+	DryRun bool
+
+	olderThan time.Duration
+}
+
+// Validate prepares for the prune runs action and validates the args/options.
+func (o *RunPruneOptions) Validate(args []string, p *Porter) error {
+	if len(args) == 1 {
+		o.Name = args[0]
+	} else if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the installation name, but multiple were received: %s", args)
+	}
+
+	if o.Name != "" && o.AllNamespaces {
+		return errors.New("cannot specify both an installation name and --all-namespaces")
+	}
+
+	if o.KeepLast < 0 {
+		return fmt.Errorf("invalid --keep-last %d, it cannot be negative", o.KeepLast)
+	}
+
+	// Apply the retention policy from the config file when the flags are not set
+	policy := p.Data.RunRetention
+	if !o.KeepLastSet {
+		o.KeepLast = policy.KeepLast
+		o.KeepLastSet = policy.KeepLast > 0
+	}
+	if o.OlderThan == "" {
+		o.OlderThan = policy.MaxAge
+	}
+
+	if o.OlderThan != "" {
+		d, err := time.ParseDuration(o.OlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid older-than duration %q: %w", o.OlderThan, err)
+		}
+		o.olderThan = d
+	}
+
+	if !o.KeepLastSet && o.olderThan == 0 {
+		return errors.New("no retention policy was specified, use --keep-last or --older-than, or define run-retention in the config file")
+	}
+
+	return nil
+}
+
+// PrunedRun is a run that was removed by porter installations runs prune.
+type PrunedRun struct {
+	Namespace    string
+	Installation string
+	ID           string
+	Action       string
+	Created      time.Time
+}
+
+// PruneInstallationRuns removes runs of installations, along with their results
+// and outputs, according to the retention policy. The run that produced the
+// current status of an installation is always kept.
+func (p *Porter) PruneInstallationRuns(ctx context.Context, opts RunPruneOptions) ([]PrunedRun, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var installations []storage.Installation
+	if opts.Name != "" {
+		inst, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		installations = []storage.Installation{inst}
+	} else {
+		listOpts := storage.ListOptions{Namespace: opts.Namespace}
+		if opts.AllNamespaces {
+			listOpts.Namespace = "*"
+		}
+		var err error
+		installations, err = p.Installations.ListInstallations(ctx, listOpts)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+	}
+
+	var pruned []PrunedRun
+	var secretKeys []string
+	now := time.Now()
+	for _, inst := range installations {
+		instPruned, instSecrets, err := p.pruneRuns(ctx, inst, now, opts)
+		pruned = append(pruned, instPruned...)
+		secretKeys = append(secretKeys, instSecrets...)
+		if err != nil {
+			p.removeSecrets(ctx, secretKeys)
+			return pruned, span.Error(err)
+		}
+	}
+
+	p.removeSecrets(ctx, secretKeys)
+	return pruned, nil
+}
+
+// pruneRuns removes the runs of a single installation that are outside the
+// retention policy, returning the pruned runs and the keys of their secrets.
+func (p *Porter) pruneRuns(ctx context.Context, inst storage.Installation, now time.Time, opts RunPruneOptions) ([]PrunedRun, []string, error) {
+	if !opts.DryRun {
+		// Hold the lock so that the installation's status isn't changed by a
+		// concurrent action while its runs are removed
+		var lock *heldInstallationLock
+		var err error
+		ctx, lock, err = p.lockInstallation(ctx, inst.Namespace, inst.Name, storage.DefaultInstallationLockTTL)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer lock.Release()
+
+		// Reload the installation now that it is locked, in case its status changed
+		inst, err = p.Installations.GetInstallation(ctx, inst.Namespace, inst.Name)
+		if errors.Is(err, storage.ErrNotFound{}) {
+			return nil, nil, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	runs, results, err := p.Installations.ListRuns(ctx, inst.Namespace, inst.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing runs of installation %s/%s: %w", inst.Namespace, inst.Name, err)
+	}
+
+	log := tracing.LoggerFromContext(ctx)
+	var pruned []PrunedRun
+	var secretKeys []string
+	for i, run := range runs {
+		if !shouldPruneRun(inst, run, len(runs)-i, now, opts) {
+			continue
+		}
+
+		pr := PrunedRun{Namespace: inst.Namespace, Installation: inst.Name, ID: run.ID, Action: run.Action, Created: run.Created}
+		if opts.DryRun {
+			pruned = append(pruned, pr)
+			continue
+		}
+
+		// Find the secrets before the documents that reference them are removed
+		runSecrets, err := p.getRunSecretKeys(ctx, run, results[run.ID])
+		if err != nil {
+			return pruned, secretKeys, err
+		}
+
+		log.Debugf("Pruning run %s of installation %s/%s", run.ID, inst.Namespace, inst.Name)
+		if err = p.Installations.RemoveRun(ctx, run.ID); err != nil {
+			return pruned, secretKeys, fmt.Errorf("error removing run %s: %w", run.ID, err)
+		}
+		pruned = append(pruned, pr)
+		secretKeys = append(secretKeys, runSecrets...)
+	}
+	return pruned, secretKeys, nil
+}
+
+// shouldPruneRun determines if a run is outside the retention policy.
+// position is the position of the run in the installation's history, where 1 is the most recent run.
+func shouldPruneRun(inst storage.Installation, run storage.Run, position int, now time.Time, opts RunPruneOptions) bool {
+	if run.ID == inst.Status.RunID {
+		return false
+	}
+
+	if opts.KeepLastSet && position > opts.KeepLast {
+		return true
+	}
+
+	if opts.olderThan > 0 && now.Sub(run.Created) > opts.olderThan {
+		return true
+	}
+
+	return false
+}
+
+// PrintPrunedInstallationRuns prunes runs and prints the runs that were removed.
+func (p *Porter) PrintPrunedInstallationRuns(ctx context.Context, opts RunPruneOptions) error {
+	pruned, err := p.PruneInstallationRuns(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	verb := "Pruned"
+	if opts.DryRun {
+		verb = "Would prune"
+	}
+	for _, run := range pruned {
+		fmt.Fprintf(p.Out, "%s run %s (%s) of installation %s/%s created %s\n",
+			verb, run.ID, run.Action, run.Namespace, run.Installation, run.Created.Format(time.RFC3339))
+	}
+	fmt.Fprintf(p.Out, "%s %d runs\n", verb, len(pruned))
+	return nil
+}
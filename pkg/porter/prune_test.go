@@ -0,0 +1,202 @@
+package porter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPruneOptions_Validate(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	t.Run("no policy", func(t *testing.T) {
+		opts := RunPruneOptions{}
+		err := opts.Validate(nil, p.Porter)
+		require.ErrorContains(t, err, "no retention policy was specified")
+	})
+
+	t.Run("policy from config", func(t *testing.T) {
+		p.Data.RunRetention = config.RunRetentionConfig{KeepLast: 5, MaxAge: "720h"}
+		defer func() { p.Data.RunRetention = config.RunRetentionConfig{} }()
+
+		opts := RunPruneOptions{}
+		require.NoError(t, opts.Validate([]string{"mysql"}, p.Porter))
+		assert.Equal(t, "mysql", opts.Name)
+		assert.Equal(t, 5, opts.KeepLast)
+		assert.Equal(t, 720*time.Hour, opts.olderThan)
+	})
+
+	t.Run("flags override config", func(t *testing.T) {
+		p.Data.RunRetention = config.RunRetentionConfig{KeepLast: 5, MaxAge: "720h"}
+		defer func() { p.Data.RunRetention = config.RunRetentionConfig{} }()
+
+		opts := RunPruneOptions{KeepLast: 2, KeepLastSet: true, OlderThan: "1h"}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		assert.Equal(t, 2, opts.KeepLast)
+		assert.Equal(t, time.Hour, opts.olderThan)
+	})
+
+	t.Run("keep last zero", func(t *testing.T) {
+		p.Data.RunRetention = config.RunRetentionConfig{KeepLast: 5}
+		defer func() { p.Data.RunRetention = config.RunRetentionConfig{} }()
+
+		opts := RunPruneOptions{KeepLast: 0, KeepLastSet: true}
+		require.NoError(t, opts.Validate(nil, p.Porter), "--keep-last 0 is a retention policy")
+		assert.Equal(t, 0, opts.KeepLast, "--keep-last 0 should not be replaced by the config file")
+		assert.True(t, opts.KeepLastSet)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		opts := RunPruneOptions{OlderThan: "30 days"}
+		err := opts.Validate(nil, p.Porter)
+		require.ErrorContains(t, err, `invalid older-than duration "30 days"`)
+	})
+
+	t.Run("name and all namespaces", func(t *testing.T) {
+		opts := RunPruneOptions{KeepLast: 1, KeepLastSet: true, AllNamespaces: true}
+		err := opts.Validate([]string{"mysql"}, p.Porter)
+		require.EqualError(t, err, "cannot specify both an installation name and --all-namespaces")
+	})
+}
+
+func TestPorter_PruneInstallationRuns(t *testing.T) {
+	ctx := context.Background()
+
+	// Create an installation with 4 runs, one per day, where the current status
+	// was set by the oldest run
+	setupRuns := func(p *TestPorter) (storage.Installation, []storage.Run) {
+		inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+		var runs []storage.Run
+		for i := 4; i > 0; i-- {
+			run := p.TestInstallations.CreateRun(inst.NewRun(cnab.ActionUpgrade, cnab.ExtendedBundle{}), func(r *storage.Run) {
+				r.Created = time.Now().Add(-time.Duration(i) * 24 * time.Hour)
+			})
+			result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded))
+			p.TestInstallations.CreateOutput(result.NewOutput(cnab.OutputInvocationImageLogs, []byte("logs")))
+			if i == 4 {
+				inst.ApplyResult(run, result)
+				require.NoError(t, p.TestInstallations.UpdateInstallation(ctx, &inst))
+			}
+			runs = append(runs, run)
+		}
+		return inst, runs
+	}
+
+	listRunIDs := func(p *TestPorter) []string {
+		runs, _, err := p.Installations.ListRuns(ctx, "dev", "mysql")
+		require.NoError(t, err)
+		ids := make([]string, len(runs))
+		for i, run := range runs {
+			ids[i] = run.ID
+		}
+		return ids
+	}
+
+	t.Run("keep last", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		_, runs := setupRuns(p)
+
+		opts := RunPruneOptions{Namespace: "dev", KeepLast: 2, KeepLastSet: true}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		pruned, err := p.PruneInstallationRuns(ctx, opts)
+		require.NoError(t, err)
+
+		require.Len(t, pruned, 1)
+		assert.Equal(t, runs[1].ID, pruned[0].ID)
+		assert.Equal(t, []string{runs[0].ID, runs[2].ID, runs[3].ID}, listRunIDs(p), "the run for the current status should always be kept")
+
+		results, err := p.Installations.ListResults(ctx, runs[1].ID)
+		require.NoError(t, err)
+		assert.Empty(t, results, "the results of the pruned run should be removed")
+	})
+
+	t.Run("keep last zero", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		_, runs := setupRuns(p)
+
+		opts := RunPruneOptions{Namespace: "dev", KeepLast: 0, KeepLastSet: true}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		pruned, err := p.PruneInstallationRuns(ctx, opts)
+		require.NoError(t, err)
+
+		require.Len(t, pruned, 3)
+		assert.Equal(t, []string{runs[0].ID}, listRunIDs(p), "only the run for the current status should be kept")
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		_, runs := setupRuns(p)
+
+		_, err := p.Installations.AcquireInstallationLock(ctx, "dev", "mysql", "other-porter", storage.DefaultInstallationLockTTL)
+		require.NoError(t, err)
+
+		opts := RunPruneOptions{Namespace: "dev", KeepLast: 1, KeepLastSet: true}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		_, err = p.PruneInstallationRuns(ctx, opts)
+		require.ErrorIs(t, err, storage.ErrInstallationLocked{})
+		assert.Len(t, listRunIDs(p), len(runs), "no runs should be removed while another process holds the lock")
+	})
+
+	t.Run("removes secrets", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		_, runs := setupRuns(p)
+
+		// Save a sensitive output for the run that will be pruned, and the run that is kept
+		for _, run := range runs[1:3] {
+			results, err := p.Installations.ListResults(ctx, run.ID)
+			require.NoError(t, err)
+			output := results[0].NewOutput("password", nil)
+			output.Key = run.ID + "-password"
+			p.TestInstallations.CreateOutput(output)
+			require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, output.Key, "topsecret"))
+		}
+
+		opts := RunPruneOptions{Namespace: "dev", KeepLast: 2, KeepLastSet: true}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		_, err := p.PruneInstallationRuns(ctx, opts)
+		require.NoError(t, err)
+
+		keys, err := p.Secrets.List(ctx, secrets.SourceSecret)
+		require.NoError(t, err)
+		assert.Equal(t, []string{runs[2].ID + "-password"}, keys, "only the secrets of the pruned run should be removed")
+	})
+
+	t.Run("older than", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		_, runs := setupRuns(p)
+
+		opts := RunPruneOptions{Name: "mysql", Namespace: "dev", OlderThan: "36h"}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		pruned, err := p.PruneInstallationRuns(ctx, opts)
+		require.NoError(t, err)
+
+		require.Len(t, pruned, 2)
+		assert.Equal(t, []string{runs[0].ID, runs[3].ID}, listRunIDs(p))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		_, runs := setupRuns(p)
+
+		opts := RunPruneOptions{AllNamespaces: true, KeepLast: 1, KeepLastSet: true, DryRun: true}
+		require.NoError(t, opts.Validate(nil, p.Porter))
+		require.NoError(t, p.PrintPrunedInstallationRuns(ctx, opts))
+
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Would prune 2 runs")
+		assert.Len(t, listRunIDs(p), len(runs), "no runs should be removed during a dry run")
+	})
+}
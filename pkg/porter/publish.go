@@ -14,6 +14,7 @@ import (
 	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/sbom"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/cnabio/cnab-go/bundle/loader"
 	"github.com/cnabio/cnab-go/packager"
@@ -33,6 +34,9 @@ type PublishOptions struct {
 	Registry    string
 	ArchiveFile string
 	SignBundle  bool
+
+	// SBOMFormat is the format of the SBOM generated for the bundle: spdx, cyclonedx or none.
+	SBOMFormat string
 }
 
 // Validate performs validation on the publish options
@@ -58,6 +62,13 @@ func (o *PublishOptions) Validate(cfg *config.Config) error {
 		}
 	}
 
+	if o.SBOMFormat != "" {
+		if err := sbom.ValidateFormat(o.SBOMFormat); err != nil {
+			return err
+		}
+		cfg.Data.SBOMFormat = o.SBOMFormat
+	}
+
 	if o.Reference != "" {
 		return o.BundlePullOptions.Validate()
 	}
@@ -202,7 +213,12 @@ func (p *Porter) publishFromFile(ctx context.Context, opts PublishOptions) error
 		}
 	}
 
-	bundleRef.Digest, err = p.Registry.PushImage(ctx, imgRef, regOpts)
+	if store, ok := p.GetBuilder(ctx).(build.ImageStore); ok {
+		// The bundle image is not in the Docker image store, push it directly to the registry
+		bundleRef.Digest, err = store.PushBundleImage(ctx, m.Image, opts.InsecureRegistry)
+	} else {
+		bundleRef.Digest, err = p.Registry.PushImage(ctx, imgRef, regOpts)
+	}
 	if err != nil {
 		return log.Errorf("unable to push bundle image %q: %w", m.Image, err)
 	}
@@ -211,16 +227,40 @@ func (p *Porter) publishFromFile(ctx context.Context, opts PublishOptions) error
 	if err != nil {
 		return log.Errorf("failed to load stamp from bundle definition: %w", err)
 	}
+	// Keep the platforms that the bundle image was built for, which may have been set with porter build --platform
+	if len(stamp.Platforms) > 0 {
+		m.Platforms = stamp.Platforms
+	}
 	bundleRef.Definition, err = p.rewriteBundleWithBundleImageDigest(ctx, m, bundleRef.Digest, stamp.PreserveTags)
 	if err != nil {
 		return err
 	}
 
+	// Now that the bundle image digest is known, include it in the SBOM
+	if err = p.generateSBOM(ctx, m, bundleRef.Digest); err != nil {
+		return log.Errorf("unable to generate the SBOM: %w", err)
+	}
+
+	// PushBundle replaces the digest of the bundle image with the digest of the bundle
+	imageDigest := bundleRef.Digest
 	bundleRef, err = p.Registry.PushBundle(ctx, bundleRef, regOpts)
 	if err != nil {
 		return err
 	}
 
+	if err = p.attachSBOM(ctx, bundleRef, regOpts); err != nil {
+		return err
+	}
+
+	statement, err := p.newProvenance(ctx, m, stamp.ManifestDigest, bundleRef, imgRef, imageDigest)
+	if err != nil {
+		return log.Errorf("unable to create the provenance statement: %w", err)
+	}
+	provenanceRef, err := p.attachProvenance(ctx, statement, bundleRef, regOpts)
+	if err != nil {
+		return err
+	}
+
 	if opts.SignBundle {
 		log.Debugf("signing bundle %s", bundleRef.String())
 		inImage, err := cnab.CalculateTemporaryImageTag(bundleRef.Reference)
@@ -237,6 +277,11 @@ func (p *Porter) publishFromFile(ctx context.Context, opts PublishOptions) error
 		if err != nil {
 			return log.Errorf("error signing bundle artifact: %w", err)
 		}
+		log.Debugf("Signing provenance statement %s.", provenanceRef.String())
+		err = p.signImage(ctx, provenanceRef)
+		if err != nil {
+			return log.Errorf("error signing provenance statement: %w", err)
+		}
 	}
 
 	// Perhaps we have a cached version of a bundle with the same reference, previously pulled
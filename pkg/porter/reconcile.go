@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/storage"
@@ -24,6 +25,16 @@ type ReconcileOptions struct {
 
 	// DryRun only checks if the changes would trigger a bundle run
 	DryRun bool
+
+	// DetectCredentialRotation resolves the credentials of an installation that is
+	// otherwise up-to-date, and re-applies it when the value of a credential
+	// has changed since the last run, such as when a secret was rotated.
+	DetectCredentialRotation bool
+
+	// lock is the lock on the installation, held by the caller since before
+	// the installation was read. When it is not set, the installation is
+	// locked by ReconcileInstallation.
+	lock *heldInstallationLock
 }
 
 // ReconcileInstallation compares the desired state of an installation
@@ -37,6 +48,19 @@ func (p *Porter) ReconcileInstallation(ctx context.Context, opts ReconcileOption
 	defer log.EndSpan()
 	log.Debugf("Reconciling %s/%s installation", opts.Namespace, opts.Name)
 
+	// Callers outside of porter, such as the operator, do not hold the lock.
+	// The installation was read before it was locked, so saving it fails with
+	// a conflict when it was modified by another process in the meantime.
+	if opts.lock == nil && !opts.DryRun {
+		lockCtx, lock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, storage.DefaultInstallationLockTTL)
+		if err != nil {
+			return log.Error(err)
+		}
+		defer lock.Release()
+		ctx = lockCtx
+		opts.lock = lock
+	}
+
 	// Get the last run of the installation, if available
 	var lastRun *storage.Run
 	r, err := p.Installations.GetLastRun(ctx, opts.Namespace, opts.Name)
@@ -86,6 +110,14 @@ func (p *Porter) ReconcileInstallation(ctx context.Context, opts ReconcileOption
 		return err
 	}
 
+	if inSync && opts.DetectCredentialRotation {
+		rotated, err := p.HaveCredentialsRotated(ctx, opts.Installation, lastRun, actionOpts)
+		if err != nil {
+			return err
+		}
+		inSync = !rotated
+	}
+
 	if inSync {
 		if opts.Force {
 			log.Info("The installation is up-to-date but will be re-applied because --force was specified")
@@ -100,16 +132,20 @@ func (p *Porter) ReconcileInstallation(ctx context.Context, opts ReconcileOption
 		return err
 	}
 
+	if err := p.verifyBundleBeforeExecution(ctx, actionOpts); err != nil {
+		return err
+	}
+
 	if opts.DryRun {
 		log.Info("Skipping bundle execution because --dry-run was specified")
 		return nil
 	} else {
-		if err = p.Installations.UpsertInstallation(ctx, opts.Installation); err != nil {
+		if err = p.Installations.UpsertInstallation(ctx, &opts.Installation); err != nil {
 			return err
 		}
 	}
 
-	return p.ExecuteAction(ctx, opts.Installation, actionOpts)
+	return p.ExecuteAction(ctx, opts.lock, opts.Installation, actionOpts)
 }
 
 // IsInstallationInSync determines if the desired state of the installation matches
@@ -231,3 +267,78 @@ func (p *Porter) IsInstallationInSync(ctx context.Context, i storage.Installatio
 	}
 	return true, nil
 }
+
+// HaveCredentialsRotated determines if the resolved value of a credential used
+// by the installation has changed since the last run, such as when a secret
+// was rotated, by comparing the digest of the credentials with the digest
+// recorded on the last run.
+func (p *Porter) HaveCredentialsRotated(ctx context.Context, i storage.Installation, lastRun *storage.Run, action BundleAction) (bool, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	// Only an installed bundle is upgraded when its credentials change
+	if lastRun == nil || !i.IsInstalled() || i.Uninstalled {
+		return false, nil
+	}
+
+	bundleRef, err := action.GetOptions().GetBundleReference(ctx, p)
+	if err != nil {
+		return false, err
+	}
+
+	finalCreds, err := p.combineCredentialSets(ctx, i, bundleRef.Definition.Bundle, action.GetAction())
+	if err != nil {
+		return false, log.Error(err)
+	}
+	creds := storage.NewInternalCredentialSet()
+	for _, cred := range finalCreds {
+		creds.Credentials = append(creds.Credentials, cred)
+	}
+
+	resolvedCreds, err := p.Credentials.ResolveAll(ctx, creds, creds.Keys())
+	if err != nil {
+		return false, log.Errorf("could not resolve the credentials of the installation to detect if they have changed: %w", err)
+	}
+	for j, cred := range creds.Credentials {
+		creds.Credentials[j].ResolvedValue = resolvedCreds[cred.Name]
+	}
+
+	digestKey, err := p.Sanitizer.CredentialsDigestKey(ctx, i, false)
+	if err != nil {
+		return false, log.Error(err)
+	}
+	if digestKey == nil {
+		log.Info("Unable to detect if the credentials have changed because the installation does not have a credentials digest key")
+		return false, nil
+	}
+
+	digests, digest, err := storage.DigestCredentials(digestKey, creds)
+	if err != nil {
+		return false, log.Error(err)
+	}
+	if digest == lastRun.CredentialsDigest {
+		log.Debug("The credentials have not changed since the last run")
+		return false, nil
+	}
+
+	if lastRun.CredentialDigests == nil {
+		log.Info("Unable to detect if the credentials have changed because the last run did not record the digest of each credential")
+		return false, nil
+	}
+
+	// Only compare the credentials used by both runs, a credential that is
+	// added or removed is not used by the bundle for both actions.
+	var changed []string
+	for name, credDigest := range digests {
+		if lastDigest, ok := lastRun.CredentialDigests[name]; ok && lastDigest != credDigest {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return false, nil
+	}
+
+	sort.Strings(changed)
+	log.Infof("Triggering because the resolved value of these credentials has changed since the last run: %s", strings.Join(changed, ", "))
+	return true, nil
+}
@@ -188,3 +188,87 @@ func TestPorter_IsInstallationInSync(t *testing.T) {
 		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Ignoring because the installation is uninstalled")
 	})
 }
+
+func TestPorter_HaveCredentialsRotated(t *testing.T) {
+	cxt := portercontext.New()
+	bun, err := cnab.LoadBundle(cxt, filepath.Join("testdata/bundle.json"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	setup := func(t *testing.T) (*TestPorter, storage.Installation, *storage.Run, *UpgradeOptions) {
+		ctx := context.Background()
+		p := NewTestPorter(t)
+
+		require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, "first-cred", "oldvalue"))
+		cs := storage.NewCredentialSet("", "mycreds",
+			secrets.SourceMap{Name: "my-first-cred", Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: "first-cred"}},
+			secrets.SourceMap{Name: "my-second-cred", Source: secrets.Source{Strategy: "value", Hint: "second"}},
+		)
+		require.NoError(t, p.Credentials.InsertCredentialSet(ctx, cs))
+
+		i := storage.NewInstallation("", "mybuns")
+		i.Status.Installed = &now
+		i.CredentialSets = []string{"mycreds"}
+
+		// Record the digest of the credentials on the last run, as if it had been executed
+		run := i.NewRun(cnab.ActionUpgrade, bun)
+		run.CredentialSets = i.CredentialSets
+		run.Credentials = storage.NewInternalCredentialSet(
+			secrets.SourceMap{Name: "my-first-cred", ResolvedValue: "oldvalue"},
+			secrets.SourceMap{Name: "my-second-cred", ResolvedValue: "second"},
+		)
+		digestKey, err := p.Sanitizer.CredentialsDigestKey(ctx, i, true)
+		require.NoError(t, err)
+		require.NoError(t, run.SetCredentialsDigest(digestKey))
+
+		upgradeOpts := NewUpgradeOptions()
+		upgradeOpts.bundleRef = &cnab.BundleReference{Definition: bun}
+		require.NoError(t, p.applyActionOptionsToInstallation(ctx, upgradeOpts, &i))
+		return p, i, &run, upgradeOpts
+	}
+
+	t.Run("credentials unchanged", func(t *testing.T) {
+		p, i, run, opts := setup(t)
+		defer p.Close()
+
+		rotated, err := p.HaveCredentialsRotated(p.RootContext, i, run, opts)
+		require.NoError(t, err)
+		assert.False(t, rotated)
+	})
+
+	t.Run("secret rotated", func(t *testing.T) {
+		p, i, run, opts := setup(t)
+		defer p.Close()
+
+		require.NoError(t, p.Secrets.Create(context.Background(), secrets.SourceSecret, "first-cred", "newvalue"))
+		rotated, err := p.HaveCredentialsRotated(p.RootContext, i, run, opts)
+		require.NoError(t, err)
+		assert.True(t, rotated)
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Triggering because the resolved value of these credentials has changed since the last run: my-first-cred")
+		assert.NotContains(t, output, "newvalue", "the resolved value should never be logged")
+	})
+
+	t.Run("last run without credential digests", func(t *testing.T) {
+		p, i, run, opts := setup(t)
+		defer p.Close()
+
+		run.CredentialDigests = nil
+		require.NoError(t, p.Secrets.Create(context.Background(), secrets.SourceSecret, "first-cred", "newvalue"))
+		rotated, err := p.HaveCredentialsRotated(p.RootContext, i, run, opts)
+		require.NoError(t, err)
+		assert.False(t, rotated)
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Unable to detect if the credentials have changed")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		p, i, run, opts := setup(t)
+		defer p.Close()
+
+		i.Status.Installed = nil
+		require.NoError(t, p.Secrets.Create(context.Background(), secrets.SourceSecret, "first-cred", "newvalue"))
+		rotated, err := p.HaveCredentialsRotated(p.RootContext, i, run, opts)
+		require.NoError(t, err)
+		assert.False(t, rotated)
+	})
+}
@@ -0,0 +1,178 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/opencontainers/go-digest"
+)
+
+var _ BundleAction = NewRollbackOptions()
+
+// RollbackOptions that may be specified when rolling back an installation.
+type RollbackOptions struct {
+	*BundleExecutionOptions
+
+	// ToRun is the ID of the run to roll back to. Defaults to the most recent
+	// successful install or upgrade run before the current state of the installation.
+	ToRun string
+}
+
+func NewRollbackOptions() *RollbackOptions {
+	return &RollbackOptions{
+		BundleExecutionOptions: NewBundleExecutionOptions(),
+	}
+}
+
+func (o *RollbackOptions) Validate(ctx context.Context, args []string, p *Porter) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one positional argument, the installation name, but received %d: %s", len(args), args)
+	}
+
+	return o.BundleExecutionOptions.Validate(ctx, args, p)
+}
+
+// GetAction returns the action that is executed to roll back an installation.
+func (o *RollbackOptions) GetAction() string {
+	return cnab.ActionUpgrade
+}
+
+func (o *RollbackOptions) GetActionVerb() string {
+	return "rolling back"
+}
+
+// RollbackInstallation upgrades an installation using the bundle, parameters,
+// parameter sets and credential sets of a previous successful run.
+func (p *Porter) RollbackInstallation(ctx context.Context, opts *RollbackOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	ctx, lock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, storage.DefaultInstallationLockTTL)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	i, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return span.Errorf("could not find installation %s/%s: %w", opts.Namespace, opts.Name, err)
+	}
+
+	if !i.IsInstalled() {
+		return span.Errorf("The installation cannot be rolled back, because it is not installed.")
+	}
+	if i.IsUninstalled() {
+		return span.Errorf("The installation cannot be rolled back, because it was uninstalled.")
+	}
+
+	target, err := p.getRollbackRun(ctx, i, opts.ToRun)
+	if err != nil {
+		return span.Error(err)
+	}
+	span.Infof("Rolling back installation %s to the %s run %s", i, target.Action, target.ID)
+
+	// Use the bundle from the previous run, ignoring any bundle in the current directory
+	opts.File = ""
+	opts.CNABFile = ""
+	opts.UnsetBundleReference()
+	if target.BundleReference != "" {
+		opts.Reference = target.BundleReference
+		opts.ReferenceSet = true
+		if err = opts.BundlePullOptions.Validate(); err != nil {
+			return span.Errorf("invalid bundle reference, %s, found on run %s: %w", target.BundleReference, target.ID, err)
+		}
+	} else {
+		// The bundle was installed from source, use the bundle definition saved on the run
+		opts.bundleRef = &cnab.BundleReference{
+			Definition: cnab.NewBundle(target.Bundle),
+			Digest:     digest.Digest(target.BundleDigest),
+		}
+	}
+
+	// Restore the inputs that were used by the previous run
+	i.ParameterSets = target.ParameterSets
+	i.CredentialSets = target.CredentialSets
+	i.Parameters.Parameters = make(secrets.StrategyList, len(target.ParameterOverrides.Parameters))
+	copy(i.Parameters.Parameters, target.ParameterOverrides.Parameters)
+
+	err = p.applyActionOptionsToInstallation(ctx, opts, &i)
+	if err != nil {
+		return span.Errorf("could not apply the inputs of run %s to the installation: %w", target.ID, err)
+	}
+	i.Status.Modified = time.Now()
+
+	err = i.Validate(ctx, p.GetSchemaCheckStrategy(ctx))
+	if err != nil {
+		return err
+	}
+
+	err = p.Installations.UpdateInstallation(ctx, &i)
+	if err != nil {
+		return err
+	}
+
+	opts.rollbackTo = target.ID
+	return p.ExecuteAction(ctx, lock, i, opts)
+}
+
+// getRollbackRun returns the run that the installation should be rolled back to.
+// When toRun is empty, the most recent successful install or upgrade before the
+// run that produced the current state of the installation is used.
+func (p *Porter) getRollbackRun(ctx context.Context, inst storage.Installation, toRun string) (storage.Run, error) {
+	if toRun != "" {
+		run, err := p.Installations.GetRun(ctx, toRun)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound{}) {
+				return storage.Run{}, fmt.Errorf("run %s not found: %w", toRun, err)
+			}
+			return storage.Run{}, fmt.Errorf("could not retrieve run %s: %w", toRun, err)
+		}
+		if run.Namespace != inst.Namespace || run.Installation != inst.Name {
+			return storage.Run{}, fmt.Errorf("run %s belongs to installation %s/%s, not %s", run.ID, run.Namespace, run.Installation, inst)
+		}
+
+		results, err := p.Installations.ListResults(ctx, run.ID)
+		if err != nil {
+			return storage.Run{}, fmt.Errorf("could not retrieve the results of run %s: %w", run.ID, err)
+		}
+		if !isRollbackCandidate(run, results) {
+			return storage.Run{}, fmt.Errorf("cannot roll back to run %s, only successful install and upgrade runs may be used", run.ID)
+		}
+		return run, nil
+	}
+
+	runs, results, err := p.Installations.ListRuns(ctx, inst.Namespace, inst.Name)
+	if err != nil {
+		return storage.Run{}, fmt.Errorf("could not list the runs of installation %s: %w", inst, err)
+	}
+
+	// Runs are sorted from oldest to newest, start with the run before the current state of the installation
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if inst.Status.RunID != "" && run.ID >= inst.Status.RunID {
+			continue
+		}
+		if isRollbackCandidate(run, results[run.ID]) {
+			return run, nil
+		}
+	}
+
+	return storage.Run{}, fmt.Errorf("no successful install or upgrade run was found before the current state of installation %s, use --to-run to specify the run to roll back to", inst)
+}
+
+// isRollbackCandidate determines if a run can be used to roll back an installation.
+func isRollbackCandidate(run storage.Run, results []storage.Result) bool {
+	if run.Action != cnab.ActionInstall && run.Action != cnab.ActionUpgrade {
+		return false
+	}
+	if len(results) == 0 {
+		return false
+	}
+	return results[len(results)-1].Status == cnab.StatusSucceeded
+}
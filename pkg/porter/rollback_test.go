@@ -0,0 +1,173 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackOptions_Validate(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	opts := NewRollbackOptions()
+	err := opts.Validate(context.Background(), nil, p.Porter)
+	require.ErrorContains(t, err, "expected exactly one positional argument, the installation name")
+
+	opts = NewRollbackOptions()
+	require.NoError(t, opts.Validate(context.Background(), []string{"mysql"}, p.Porter))
+	assert.Equal(t, "mysql", opts.Name)
+	assert.Equal(t, cnab.ActionUpgrade, opts.GetAction())
+}
+
+// createRollbackHistory creates an installation that was installed, upgraded to
+// 0.2.0 and then failed to upgrade to 0.3.0. The runs are returned in order.
+func createRollbackHistory(t *testing.T, p *TestPorter) (storage.Installation, []storage.Run) {
+	ctx := context.Background()
+	inst := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "mysql"))
+
+	var runs []storage.Run
+	addRun := func(action string, version string, logLevel string, status string) {
+		b := cnab.NewBundle(bundle.Bundle{
+			SchemaVersion: "1.2.0",
+			Name:          "mysql",
+			Version:       version,
+			InvocationImages: []bundle.InvocationImage{
+				{BaseImage: bundle.BaseImage{Image: "example.com/mysql-installer:v" + version}},
+			},
+			Actions: map[string]bundle.Action{
+				cnab.ActionUpgrade: {Modifies: true},
+			},
+			Parameters: map[string]bundle.Parameter{
+				"logLevel": {Definition: "logLevel", Destination: &bundle.Location{EnvironmentVariable: "LOG_LEVEL"}},
+			},
+			Definitions: map[string]*definition.Schema{
+				"logLevel": {Type: "string"},
+			},
+		})
+		run := p.TestInstallations.CreateRun(inst.NewRun(action, b), func(r *storage.Run) {
+			r.Bundle = b.Bundle
+			r.ParameterOverrides.Parameters = secrets.StrategyList{storage.ValueStrategy("logLevel", logLevel)}
+			r.ParameterSets = []string{"ps-" + version}
+			r.CredentialSets = []string{"cs-" + version}
+		})
+		result := p.TestInstallations.CreateResult(run.NewResult(status))
+		inst.ApplyResult(run, result)
+		if action == cnab.ActionInstall {
+			inst.Status.Installed = &result.Created
+		}
+		runs = append(runs, run)
+	}
+
+	addRun(cnab.ActionInstall, "0.1.0", "info", cnab.StatusSucceeded)
+	addRun(cnab.ActionUpgrade, "0.2.0", "debug", cnab.StatusSucceeded)
+	addRun("logs", "0.2.0", "debug", cnab.StatusSucceeded)
+	addRun(cnab.ActionUpgrade, "0.3.0", "trace", cnab.StatusFailed)
+	inst.Status.RunID = runs[3].ID
+	require.NoError(t, p.TestInstallations.UpdateInstallation(ctx, &inst))
+
+	return inst, runs
+}
+
+func TestPorter_getRollbackRun(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	inst, runs := createRollbackHistory(t, p)
+
+	t.Run("previous successful run", func(t *testing.T) {
+		run, err := p.getRollbackRun(ctx, inst, "")
+		require.NoError(t, err)
+		assert.Equal(t, runs[1].ID, run.ID, "expected the last successful upgrade before the failed upgrade")
+	})
+
+	t.Run("current run succeeded", func(t *testing.T) {
+		current := inst
+		current.Status.RunID = runs[1].ID
+		run, err := p.getRollbackRun(ctx, current, "")
+		require.NoError(t, err)
+		assert.Equal(t, runs[0].ID, run.ID, "expected the successful run before the current state")
+	})
+
+	t.Run("no previous run", func(t *testing.T) {
+		current := inst
+		current.Status.RunID = runs[0].ID
+		_, err := p.getRollbackRun(ctx, current, "")
+		require.ErrorContains(t, err, "no successful install or upgrade run was found")
+	})
+
+	t.Run("to run", func(t *testing.T) {
+		run, err := p.getRollbackRun(ctx, inst, runs[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, runs[0].ID, run.ID)
+	})
+
+	t.Run("to failed run", func(t *testing.T) {
+		_, err := p.getRollbackRun(ctx, inst, runs[3].ID)
+		require.ErrorContains(t, err, "only successful install and upgrade runs may be used")
+	})
+
+	t.Run("to custom action run", func(t *testing.T) {
+		_, err := p.getRollbackRun(ctx, inst, runs[2].ID)
+		require.ErrorContains(t, err, "only successful install and upgrade runs may be used")
+	})
+
+	t.Run("to run of another installation", func(t *testing.T) {
+		other := p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", "wordpress"))
+		_, err := p.getRollbackRun(ctx, other, runs[0].ID)
+		require.ErrorContains(t, err, "belongs to installation dev/mysql, not dev/wordpress")
+	})
+
+	t.Run("to missing run", func(t *testing.T) {
+		_, err := p.getRollbackRun(ctx, inst, "missing")
+		require.ErrorIs(t, err, storage.ErrNotFound{})
+	})
+}
+
+func TestPorter_RollbackInstallation(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	for _, name := range []string{"ps-0.2.0", "ps-0.3.0"} {
+		require.NoError(t, p.TestParameters.InsertParameterSet(ctx, storage.NewParameterSet("", name)))
+	}
+	for _, name := range []string{"cs-0.2.0", "cs-0.3.0"} {
+		require.NoError(t, p.TestCredentials.InsertCredentialSet(ctx, storage.NewCredentialSet("", name)))
+	}
+	_, runs := createRollbackHistory(t, p)
+
+	opts := NewRollbackOptions()
+	opts.Namespace = "dev"
+	opts.Driver = "debug"
+	require.NoError(t, opts.Validate(ctx, []string{"mysql"}, p.Porter))
+	require.NoError(t, p.RollbackInstallation(ctx, opts))
+
+	inst, err := p.Installations.GetInstallation(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ps-0.2.0"}, inst.ParameterSets, "expected the parameter sets of the previous run to be restored")
+	assert.Equal(t, []string{"cs-0.2.0"}, inst.CredentialSets, "expected the credential sets of the previous run to be restored")
+	assert.Equal(t, "0.2.0", inst.Status.BundleVersion, "expected the bundle of the previous run to be restored")
+
+	rollback, err := p.Installations.GetLastRun(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, cnab.ActionUpgrade, rollback.Action)
+	assert.Equal(t, runs[1].ID, rollback.RollbackTo, "expected the new run to link to the run it rolled back to")
+	assert.Equal(t, "0.2.0", rollback.Bundle.Version)
+	assert.Equal(t, []string{"ps-0.2.0"}, rollback.ParameterSets)
+	assert.Equal(t, []string{"cs-0.2.0"}, rollback.CredentialSets)
+	require.Len(t, rollback.ParameterOverrides.Parameters, 1)
+	assert.Equal(t, "logLevel", rollback.ParameterOverrides.Parameters[0].Name)
+	assert.Equal(t, "debug", rollback.ParameterOverrides.Parameters[0].Source.Hint, "expected the parameter overrides of the previous run to be restored")
+
+	_, err = p.Installations.GetInstallationLock(ctx, "dev", "mysql")
+	require.ErrorIs(t, err, storage.ErrNotFound{}, "expected the installation lock to be released")
+}
@@ -0,0 +1,59 @@
+package porter
+
+import (
+	"context"
+	"os"
+	"os/user"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+)
+
+type runAuditCtxKey struct{}
+
+// WithRunAudit returns a context that records the specified user, host and
+// client on any runs created with it. Use it when porter is invoked on behalf of
+// another user, for example by the gRPC server, instead of from the CLI.
+func WithRunAudit(ctx context.Context, audit storage.RunAudit) context.Context {
+	return context.WithValue(ctx, runAuditCtxKey{}, audit)
+}
+
+// getRunAudit returns the audit record for a new run. It defaults to the
+// current user and host, running the porter CLI, unless a caller was set on the
+// context with WithRunAudit.
+func (p *Porter) getRunAudit(ctx context.Context) storage.RunAudit {
+	audit, ok := ctx.Value(runAuditCtxKey{}).(storage.RunAudit)
+	if !ok {
+		audit = storage.RunAudit{
+			User:   currentUsername(),
+			Host:   currentHostname(),
+			Client: p.Getenv(config.EnvPorterClient),
+		}
+		if audit.Client == "" {
+			audit.Client = storage.RunClientCLI
+		}
+	}
+
+	if audit.PorterVersion == "" {
+		audit.PorterVersion = pkg.Version
+	}
+	return audit
+}
+
+// currentUsername returns the name of the user running porter.
+func currentUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// currentHostname returns the name of the host running porter.
+func currentHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return hostname
+}
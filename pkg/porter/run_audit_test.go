@@ -0,0 +1,46 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPorter_getRunAudit(t *testing.T) {
+	origVersion := pkg.Version
+	pkg.Version = "v1.2.3"
+	defer func() { pkg.Version = origVersion }()
+
+	t.Run("cli", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		audit := p.getRunAudit(context.Background())
+		assert.Equal(t, currentUsername(), audit.User)
+		assert.Equal(t, currentHostname(), audit.Host)
+		assert.Equal(t, "v1.2.3", audit.PorterVersion)
+		assert.Equal(t, storage.RunClientCLI, audit.Client)
+	})
+
+	t.Run("client from environment", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+		p.Setenv(config.EnvPorterClient, storage.RunClientOperator)
+
+		audit := p.getRunAudit(context.Background())
+		assert.Equal(t, storage.RunClientOperator, audit.Client)
+	})
+
+	t.Run("caller from context", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		caller := storage.RunAudit{User: "sally", Host: "10.0.0.5", Client: storage.RunClientGRPC}
+		audit := p.getRunAudit(WithRunAudit(context.Background(), caller))
+		assert.Equal(t, storage.RunAudit{User: "sally", Host: "10.0.0.5", PorterVersion: "v1.2.3", Client: storage.RunClientGRPC}, audit)
+	})
+}
@@ -2,11 +2,14 @@ package porter
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"time"
 
 	"get.porter.sh/porter/pkg/portercontext"
 	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
 	dtprinter "github.com/carolynvs/datetime-printer"
 )
 
@@ -14,6 +17,15 @@ import (
 type RunListOptions struct {
 	installationOptions
 	printer.PrintOptions
+
+	// User only lists runs started by the specified user.
+	User string
+
+	// Host only lists runs started from the specified host.
+	Host string
+
+	// Client only lists runs started with the specified client, such as cli, grpc or operator.
+	Client string
 }
 
 // Validate prepares for the list installation runs action and validates the args/options.
@@ -59,29 +71,38 @@ func (p *Porter) ListInstallationRuns(ctx context.Context, opts RunListOptions)
 		return nil, err
 	}
 
+	filter := storage.RunAudit{User: opts.User, Host: opts.Host, Client: opts.Client}
 	for _, run := range runs {
-		results := runResults[run.ID]
+		if !run.Audit.Matches(filter) {
+			continue
+		}
 
-		displayRun := NewDisplayRun(run)
+		displayRuns = append(displayRuns, newDisplayRunWithResults(run, runResults[run.ID]))
+	}
 
-		if len(results) > 0 {
-			displayRun.Status = results[len(results)-1].Status
+	return displayRuns, nil
+}
 
-			switch len(results) {
-			case 2:
-				displayRun.Started = results[0].Created
-				displayRun.Stopped = &results[1].Created
-			case 1:
-				displayRun.Started = results[0].Created
-			default:
-				displayRun.Stopped = &results[len(results)-1].Created
-			}
+// newDisplayRunWithResults populates the status of the run and when it was
+// started and stopped from its results.
+func newDisplayRunWithResults(run storage.Run, results []storage.Result) DisplayRun {
+	displayRun := NewDisplayRun(run)
+
+	if len(results) > 0 {
+		displayRun.Status = results[len(results)-1].Status
+
+		switch len(results) {
+		case 2:
+			displayRun.Started = results[0].Created
+			displayRun.Stopped = &results[1].Created
+		case 1:
+			displayRun.Started = results[0].Created
+		default:
+			displayRun.Stopped = &results[len(results)-1].Created
 		}
-
-		displayRuns = append(displayRuns, displayRun)
 	}
 
-	return displayRuns, nil
+	return displayRun
 }
 
 func (p *Porter) PrintInstallationRuns(ctx context.Context, opts RunListOptions) error {
@@ -115,10 +136,112 @@ func (p *Porter) PrintInstallationRuns(ctx context.Context, opts RunListOptions)
 					stopped = tp.Format(*a.Stopped)
 				}
 
-				return []string{a.ID, a.Action, tp.Format(a.Started), stopped, a.Status}
+				return []string{a.ID, a.Action, tp.Format(a.Started), stopped, a.Status, a.Audit.User, a.Audit.Client}
 			}
-		return printer.PrintTable(p.Out, displayRuns, row, "Run ID", "Action", "Started", "Stopped", "Status")
+		return printer.PrintTable(p.Out, displayRuns, row, "Run ID", "Action", "Started", "Stopped", "Status", "User", "Client")
 	}
 
 	return nil
 }
+
+// RunShowOptions represent options for showing a single run of an installation.
+type RunShowOptions struct {
+	printer.PrintOptions
+
+	// RunID is the ID of the run to show.
+	RunID string
+}
+
+// Validate prepares for the show installation run action and validates the args/options.
+func (o *RunShowOptions) Validate(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one positional argument, the run ID, but received %d: %s", len(args), args)
+	}
+	o.RunID = args[0]
+
+	return o.PrintOptions.Validate(ShowDefaultFormat, ShowAllowedFormats)
+}
+
+// GetInstallationRun retrieves a run of an installation, including its status
+// and who started it.
+func (p *Porter) GetInstallationRun(ctx context.Context, opts RunShowOptions) (storage.Run, DisplayRun, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	run, err := p.Installations.GetRun(ctx, opts.RunID)
+	if err != nil {
+		return storage.Run{}, DisplayRun{}, span.Errorf("could not retrieve run %s: %w", opts.RunID, err)
+	}
+
+	results, err := p.Installations.ListResults(ctx, run.ID)
+	if err != nil {
+		return storage.Run{}, DisplayRun{}, span.Errorf("could not retrieve the results of run %s: %w", run.ID, err)
+	}
+
+	return run, newDisplayRunWithResults(run, results), nil
+}
+
+// ShowInstallationRun prints a run of an installation.
+func (p *Porter) ShowInstallationRun(ctx context.Context, opts RunShowOptions) error {
+	run, displayRun, err := p.GetInstallationRun(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, displayRun)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, displayRun)
+	case printer.FormatPlaintext:
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		fmt.Fprintf(p.Out, "Run ID: %s\n", displayRun.ID)
+		fmt.Fprintf(p.Out, "Installation: %s/%s\n", run.Namespace, run.Installation)
+		fmt.Fprintf(p.Out, "Action: %s\n", displayRun.Action)
+		if displayRun.RollbackTo != "" {
+			fmt.Fprintf(p.Out, "Rollback To: %s\n", displayRun.RollbackTo)
+		}
+		fmt.Fprintf(p.Out, "Status: %s\n", displayRun.Status)
+		fmt.Fprintf(p.Out, "Started: %s\n", tp.Format(displayRun.Started))
+		if displayRun.Stopped != nil {
+			fmt.Fprintf(p.Out, "Stopped: %s\n", tp.Format(*displayRun.Stopped))
+		}
+
+		fmt.Fprintln(p.Out)
+		fmt.Fprintln(p.Out, "Bundle:")
+		fmt.Fprintf(p.Out, "  Reference: %s\n", displayRun.Bundle)
+		fmt.Fprintf(p.Out, "  Version: %s\n", displayRun.Version)
+		fmt.Fprintf(p.Out, "  Digest: %s\n", run.BundleDigest)
+
+		fmt.Fprintln(p.Out)
+		fmt.Fprintln(p.Out, "Started By:")
+		fmt.Fprintf(p.Out, "  User: %s\n", displayRun.Audit.User)
+		fmt.Fprintf(p.Out, "  Host: %s\n", displayRun.Audit.Host)
+		fmt.Fprintf(p.Out, "  Client: %s\n", displayRun.Audit.Client)
+		fmt.Fprintf(p.Out, "  Porter Version: %s\n", displayRun.Audit.PorterVersion)
+
+		if len(run.ParameterSets) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Parameter Sets:")
+			for _, ps := range run.ParameterSets {
+				fmt.Fprintf(p.Out, "  - %s\n", ps)
+			}
+		}
+
+		if len(run.CredentialSets) > 0 {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Credential Sets:")
+			for _, cs := range run.CredentialSets {
+				fmt.Fprintf(p.Out, "  - %s\n", cs)
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
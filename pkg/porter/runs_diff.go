@@ -0,0 +1,394 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// Types of changes between two runs.
+const (
+	RunChangeAdded     = "added"
+	RunChangeRemoved   = "removed"
+	RunChangeChanged   = "changed"
+	RunChangeReordered = "reordered"
+)
+
+// Kinds of items that are compared between two runs.
+const (
+	RunDiffKindRun           = "run"
+	RunDiffKindBundle        = "bundle"
+	RunDiffKindParameter     = "parameter"
+	RunDiffKindParameterSet  = "parameter-set"
+	RunDiffKindCredentialSet = "credential-set"
+	RunDiffKindOutput        = "output"
+)
+
+// RunDiffOptions represent options for comparing two runs of an installation.
+type RunDiffOptions struct {
+	installationOptions
+	printer.PrintOptions
+
+	// RunA is the ID of the run to compare from.
+	RunA string
+
+	// RunB is the ID of the run to compare to.
+	RunB string
+
+	// Last compares the two most recent runs of the installation.
+	Last bool
+}
+
+// Validate prepares for the diff installation runs action and validates the args/options.
+func (o *RunDiffOptions) Validate(args []string, cxt *portercontext.Context) error {
+	if o.Last {
+		// The installation name is optional and defaults to the bundle in the current directory
+		if err := o.installationOptions.validateInstallationName(args); err != nil {
+			return err
+		}
+		if err := o.installationOptions.defaultBundleFiles(cxt); err != nil {
+			return err
+		}
+	} else {
+		if len(args) != 2 {
+			return fmt.Errorf("expected two positional arguments, the IDs of the runs to compare, but received %d: %s. Use --last to compare the two most recent runs of an installation", len(args), args)
+		}
+		o.RunA = args[0]
+		o.RunB = args[1]
+	}
+
+	return o.PrintOptions.Validate(ShowDefaultFormat, ShowAllowedFormats)
+}
+
+// DisplayRunDiff describes the differences between two runs of an installation.
+type DisplayRunDiff struct {
+	Namespace    string             `json:"namespace" yaml:"namespace"`
+	Installation string             `json:"installation" yaml:"installation"`
+	RunA         string             `json:"runA" yaml:"runA"`
+	RunB         string             `json:"runB" yaml:"runB"`
+	Changes      []DisplayRunChange `json:"changes" yaml:"changes"`
+}
+
+// DisplayRunChange is a single difference between two runs.
+type DisplayRunChange struct {
+	// Kind of item that changed, such as bundle, parameter, parameter-set, credential-set or output.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// Name of the item that changed.
+	Name string `json:"name" yaml:"name"`
+
+	// Change is how the item changed: added, removed, changed or reordered.
+	Change string `json:"change" yaml:"change"`
+
+	// Sensitive indicates that the values were masked because the item contains sensitive data.
+	Sensitive bool `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+
+	// Before is the value in the first run.
+	Before string `json:"before,omitempty" yaml:"before,omitempty"`
+
+	// After is the value in the second run.
+	After string `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// DiffInstallationRuns compares two runs of an installation.
+func (p *Porter) DiffInstallationRuns(ctx context.Context, opts RunDiffOptions) (DisplayRunDiff, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	runA, runB, err := p.getRunsToDiff(ctx, &opts)
+	if err != nil {
+		return DisplayRunDiff{}, span.Error(err)
+	}
+	if runA.Namespace != runB.Namespace || runA.Installation != runB.Installation {
+		return DisplayRunDiff{}, span.Errorf("runs %s and %s belong to different installations, %s/%s and %s/%s",
+			runA.ID, runB.ID, runA.Namespace, runA.Installation, runB.Namespace, runB.Installation)
+	}
+
+	diff := DisplayRunDiff{
+		Namespace:    runA.Namespace,
+		Installation: runA.Installation,
+		RunA:         runA.ID,
+		RunB:         runB.ID,
+		Changes:      []DisplayRunChange{},
+	}
+
+	bunA := cnab.NewBundle(runA.Bundle)
+	bunB := cnab.NewBundle(runB.Bundle)
+	diff.Changes = append(diff.Changes, diffRunValue(RunDiffKindRun, "action", runA.Action, runB.Action)...)
+	diff.Changes = append(diff.Changes, diffRunValue(RunDiffKindBundle, "reference", runA.BundleReference, runB.BundleReference)...)
+	diff.Changes = append(diff.Changes, diffRunValue(RunDiffKindBundle, "version", bunA.Version, bunB.Version)...)
+	diff.Changes = append(diff.Changes, diffRunValue(RunDiffKindBundle, "digest", runA.BundleDigest, runB.BundleDigest)...)
+
+	paramsA, err := p.getRunParameterValues(ctx, runA)
+	if err != nil {
+		return DisplayRunDiff{}, span.Error(err)
+	}
+	paramsB, err := p.getRunParameterValues(ctx, runB)
+	if err != nil {
+		return DisplayRunDiff{}, span.Error(err)
+	}
+	// Parameters stored in the secret store are sensitive, including those
+	// resolved from a template that uses a secret, which the bundle may not define as sensitive
+	isSensitiveParam := func(name string) bool {
+		return bunA.IsSensitiveParameter(name) || bunB.IsSensitiveParameter(name) ||
+			isSecretRunParameter(runA, name) || isSecretRunParameter(runB, name)
+	}
+	diff.Changes = append(diff.Changes, diffRunValues(RunDiffKindParameter, paramsA, paramsB, isSensitiveParam)...)
+
+	diff.Changes = append(diff.Changes, diffRunSets(RunDiffKindParameterSet, runA.ParameterSets, runB.ParameterSets)...)
+	diff.Changes = append(diff.Changes, diffRunSets(RunDiffKindCredentialSet, runA.CredentialSets, runB.CredentialSets)...)
+
+	outputsA, err := p.getRunOutputValues(ctx, runA)
+	if err != nil {
+		return DisplayRunDiff{}, span.Error(err)
+	}
+	outputsB, err := p.getRunOutputValues(ctx, runB)
+	if err != nil {
+		return DisplayRunDiff{}, span.Error(err)
+	}
+	isSensitiveOutput := func(name string) bool {
+		sensitiveA, _ := bunA.IsOutputSensitive(name)
+		sensitiveB, _ := bunB.IsOutputSensitive(name)
+		return sensitiveA || sensitiveB
+	}
+	diff.Changes = append(diff.Changes, diffRunValues(RunDiffKindOutput, outputsA, outputsB, isSensitiveOutput)...)
+
+	return diff, nil
+}
+
+// getRunsToDiff retrieves the runs specified by the options.
+func (p *Porter) getRunsToDiff(ctx context.Context, opts *RunDiffOptions) (storage.Run, storage.Run, error) {
+	if opts.Last {
+		if err := p.applyDefaultOptions(ctx, &opts.installationOptions); err != nil {
+			return storage.Run{}, storage.Run{}, err
+		}
+
+		runs, _, err := p.Installations.ListRuns(ctx, opts.Namespace, opts.Name)
+		if err != nil {
+			return storage.Run{}, storage.Run{}, fmt.Errorf("could not list the runs of installation %s/%s: %w", opts.Namespace, opts.Name, err)
+		}
+		if len(runs) < 2 {
+			return storage.Run{}, storage.Run{}, fmt.Errorf("installation %s/%s has %d runs, at least two runs are required to compare", opts.Namespace, opts.Name, len(runs))
+		}
+		return runs[len(runs)-2], runs[len(runs)-1], nil
+	}
+
+	runA, err := p.getRunToDiff(ctx, opts.RunA)
+	if err != nil {
+		return storage.Run{}, storage.Run{}, err
+	}
+	runB, err := p.getRunToDiff(ctx, opts.RunB)
+	if err != nil {
+		return storage.Run{}, storage.Run{}, err
+	}
+	return runA, runB, nil
+}
+
+func (p *Porter) getRunToDiff(ctx context.Context, id string) (storage.Run, error) {
+	run, err := p.Installations.GetRun(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound{}) {
+			return storage.Run{}, fmt.Errorf("run %s not found: %w", id, err)
+		}
+		return storage.Run{}, fmt.Errorf("could not retrieve run %s: %w", id, err)
+	}
+	return run, nil
+}
+
+// getRunParameterValues resolves the parameters used by a run, excluding internal parameters.
+func (p *Porter) getRunParameterValues(ctx context.Context, run storage.Run) (map[string]string, error) {
+	bun := cnab.NewBundle(run.Bundle)
+	params, err := p.Sanitizer.RestoreParameterSet(ctx, run.Parameters, bun)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve the parameters of run %s: %w", run.ID, err)
+	}
+
+	values := make(map[string]string, len(params))
+	for name, value := range params {
+		if bun.IsInternalParameter(name) {
+			continue
+		}
+		values[name] = formatRunDiffValue(value)
+	}
+	return values, nil
+}
+
+// isSecretRunParameter checks if the value of a parameter used by a run is stored in the secret store.
+func isSecretRunParameter(run storage.Run, name string) bool {
+	for _, param := range run.Parameters.Parameters {
+		if param.Name == name {
+			return param.Source.Strategy == secrets.SourceSecret
+		}
+	}
+	return false
+}
+
+// getRunOutputValues resolves the outputs generated by a run that are defined by the bundle.
+func (p *Porter) getRunOutputValues(ctx context.Context, run storage.Run) (map[string]string, error) {
+	bun := cnab.NewBundle(run.Bundle)
+	results, err := p.Installations.ListResults(ctx, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve the results of run %s: %w", run.ID, err)
+	}
+
+	values := make(map[string]string)
+	for _, result := range results {
+		outputs, err := p.Installations.ListOutputs(ctx, result.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve the outputs of run %s: %w", run.ID, err)
+		}
+
+		for _, output := range outputs {
+			// Skip outputs not defined in the bundle, e.g. io.cnab.outputs.invocationImageLogs
+			if _, ok := output.GetSchema(bun); !ok || bun.IsInternalOutput(output.Name) {
+				continue
+			}
+
+			output, err = p.Sanitizer.RestoreOutput(ctx, output)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve the %s output of run %s: %w", output.Name, run.ID, err)
+			}
+			values[output.Name] = string(output.Value)
+		}
+	}
+	return values, nil
+}
+
+// formatRunDiffValue converts a value to a string so that it can be compared and printed.
+func formatRunDiffValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(b)
+	}
+}
+
+func diffRunValue(kind string, name string, before string, after string) []DisplayRunChange {
+	if before == after {
+		return nil
+	}
+	return diffRunValues(kind, map[string]string{name: before}, map[string]string{name: after}, nil)
+}
+
+// diffRunValues compares named values, masking the values of sensitive items.
+func diffRunValues(kind string, before map[string]string, after map[string]string, isSensitive func(name string) bool) []DisplayRunChange {
+	names := make(map[string]struct{}, len(before)+len(after))
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var changes []DisplayRunChange
+	for _, name := range sortedNames {
+		beforeValue, inBefore := before[name]
+		afterValue, inAfter := after[name]
+
+		change := DisplayRunChange{Kind: kind, Name: name, Before: beforeValue, After: afterValue}
+		switch {
+		case !inBefore:
+			change.Change = RunChangeAdded
+		case !inAfter:
+			change.Change = RunChangeRemoved
+		case beforeValue != afterValue:
+			change.Change = RunChangeChanged
+		default:
+			continue
+		}
+
+		if isSensitive != nil && isSensitive(name) {
+			change.Sensitive = true
+			if inBefore {
+				change.Before = "******"
+			}
+			if inAfter {
+				change.After = "******"
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffRunSets compares the membership of the parameter or credential sets used by two runs.
+// The order of the sets is significant, because later sets override values from earlier sets.
+func diffRunSets(kind string, before []string, after []string) []DisplayRunChange {
+	var changes []DisplayRunChange
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			changes = append(changes, DisplayRunChange{Kind: kind, Name: name, Change: RunChangeRemoved})
+		}
+	}
+	for _, name := range after {
+		if !slices.Contains(before, name) {
+			changes = append(changes, DisplayRunChange{Kind: kind, Name: name, Change: RunChangeAdded})
+		}
+	}
+
+	if len(changes) == 0 && strings.Join(before, ",") != strings.Join(after, ",") {
+		changes = append(changes, DisplayRunChange{
+			Kind:   kind,
+			Change: RunChangeReordered,
+			Before: strings.Join(before, ", "),
+			After:  strings.Join(after, ", "),
+		})
+	}
+	return changes
+}
+
+// PrintInstallationRunsDiff compares two runs of an installation and prints the differences.
+func (p *Porter) PrintInstallationRunsDiff(ctx context.Context, opts RunDiffOptions) error {
+	diff, err := p.DiffInstallationRuns(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, diff)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, diff)
+	case printer.FormatPlaintext:
+		fmt.Fprintf(p.Out, "Comparing run %s to run %s of installation %s/%s\n", diff.RunA, diff.RunB, diff.Namespace, diff.Installation)
+		if len(diff.Changes) == 0 {
+			fmt.Fprintln(p.Out, "No differences found")
+			return nil
+		}
+
+		row :=
+			func(v interface{}) []string {
+				c, ok := v.(DisplayRunChange)
+				if !ok {
+					return nil
+				}
+				return []string{c.Kind, c.Name, c.Change, truncateString(c.Before, 60), truncateString(c.After, 60)}
+			}
+		return printer.PrintTable(p.Out, diff.Changes, row, "Kind", "Name", "Change", "Before", "After")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
@@ -29,9 +29,11 @@ func TestPorter_ListInstallationRuns(t *testing.T) {
 	installationName2 := "shared-k8s"
 
 	run2 := storage.NewRun("dev", installationName2)
+	run2.Audit = storage.RunAudit{User: "sally", Host: "sally-laptop", Client: storage.RunClientCLI}
 	run2.NewResult("running")
 
 	run3 := storage.NewRun("dev", installationName2)
+	run3.Audit = storage.RunAudit{User: "porter", Host: "10.0.0.5", Client: storage.RunClientGRPC}
 	run3.NewResult("running")
 
 	p.TestInstallations.CreateInstallation(storage.NewInstallation("dev", installationName2), p.TestInstallations.SetMutableInstallationValues)
@@ -57,6 +59,33 @@ func TestPorter_ListInstallationRuns(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, results, 2)
 	})
+
+	t.Run("filter by audit", func(t *testing.T) {
+		testcases := []struct {
+			name    string
+			opts    RunListOptions
+			wantRun string
+		}{
+			{name: "user", opts: RunListOptions{User: "sally"}, wantRun: run2.ID},
+			{name: "host", opts: RunListOptions{Host: "10.0.0.5"}, wantRun: run3.ID},
+			{name: "client", opts: RunListOptions{Client: storage.RunClientGRPC}, wantRun: run3.ID},
+			{name: "user and client", opts: RunListOptions{User: "sally", Client: storage.RunClientGRPC}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				opts := tc.opts
+				opts.installationOptions = installationOptions{Namespace: "dev", Name: installationName2}
+				results, err := p.ListInstallationRuns(context.Background(), opts)
+				require.NoError(t, err)
+				if tc.wantRun == "" {
+					assert.Empty(t, results)
+					return
+				}
+				require.Len(t, results, 1)
+				assert.Equal(t, tc.wantRun, results[0].ID)
+			})
+		}
+	})
 }
 
 func TestPorter_PrintInstallationRunsOutput(t *testing.T) {
@@ -80,8 +109,12 @@ func TestPorter_PrintInstallationRunsOutput(t *testing.T) {
 			installation := p.TestInstallations.CreateInstallation(storage.NewInstallation("staging", "shared-k8s"), p.TestInstallations.SetMutableInstallationValues)
 
 			bun := cnab.ExtendedBundle{}
-			installRun := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall, bun), p.TestInstallations.SetMutableRunValues)
-			uninstallRun := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionUninstall, bun), p.TestInstallations.SetMutableRunValues)
+			installRun := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionInstall, bun), p.TestInstallations.SetMutableRunValues, func(r *storage.Run) {
+				r.Audit = storage.RunAudit{User: "sally", Host: "sally-laptop", PorterVersion: "v1.0.0", Client: storage.RunClientCLI}
+			})
+			uninstallRun := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionUninstall, bun), p.TestInstallations.SetMutableRunValues, func(r *storage.Run) {
+				r.Audit = storage.RunAudit{User: "system:serviceaccount:porter", Host: "porter-agent", PorterVersion: "v1.0.0", Client: storage.RunClientOperator}
+			})
 			result := p.TestInstallations.CreateResult(installRun.NewResult(cnab.StatusSucceeded), p.TestInstallations.SetMutableResultValues)
 			result2 := p.TestInstallations.CreateResult(uninstallRun.NewResult(cnab.StatusSucceeded), p.TestInstallations.SetMutableResultValues)
 
@@ -89,7 +122,7 @@ func TestPorter_PrintInstallationRunsOutput(t *testing.T) {
 			installation.ApplyResult(uninstallRun, result2)
 			installation.Status.Installed = &now
 
-			require.NoError(t, p.TestInstallations.UpdateInstallation(ctx, installation))
+			require.NoError(t, p.TestInstallations.UpdateInstallation(ctx, &installation))
 
 			opts := RunListOptions{installationOptions: installationOptions{
 				Namespace: "staging",
@@ -105,3 +138,56 @@ func TestPorter_PrintInstallationRunsOutput(t *testing.T) {
 
 	}
 }
+
+func TestRunShowOptions_Validate(t *testing.T) {
+	opts := RunShowOptions{}
+	require.NoError(t, opts.Validate([]string{"1"}))
+	assert.Equal(t, "1", opts.RunID)
+
+	opts = RunShowOptions{}
+	err := opts.Validate(nil)
+	require.ErrorContains(t, err, "expected exactly one positional argument, the run ID")
+}
+
+func TestPorter_ShowInstallationRun(t *testing.T) {
+	testcases := []struct {
+		name       string
+		format     printer.Format
+		outputFile string
+	}{
+		{name: "json", format: printer.FormatJson, outputFile: "testdata/runs/expected-show.json"},
+		{name: "plaintext", format: printer.FormatPlaintext, outputFile: "testdata/runs/expected-show.txt"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			installation := p.TestInstallations.CreateInstallation(storage.NewInstallation("staging", "shared-k8s"), p.TestInstallations.SetMutableInstallationValues)
+			bun := cnab.ExtendedBundle{}
+			run := p.TestInstallations.CreateRun(installation.NewRun(cnab.ActionUpgrade, bun), p.TestInstallations.SetMutableRunValues, func(r *storage.Run) {
+				r.BundleReference = "example.com/mysql:v0.1.0"
+				r.BundleDigest = "sha256:aaa"
+				r.CredentialSets = []string{"azure"}
+				r.Audit = storage.RunAudit{User: "sally", Host: "sally-laptop", PorterVersion: "v1.0.0", Client: storage.RunClientCLI}
+			})
+			p.TestInstallations.CreateResult(run.NewResult(cnab.StatusRunning), p.TestInstallations.SetMutableResultValues)
+			p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded), p.TestInstallations.SetMutableResultValues)
+
+			opts := RunShowOptions{RunID: run.ID, PrintOptions: printer.PrintOptions{Format: tc.format}}
+			require.NoError(t, p.ShowInstallationRun(context.Background(), opts))
+
+			p.CompareGoldenFile(tc.outputFile, p.TestConfig.TestContext.GetOutput())
+		})
+	}
+
+	t.Run("missing run", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		err := p.ShowInstallationRun(context.Background(), RunShowOptions{RunID: "missing"})
+		require.ErrorIs(t, err, storage.ErrNotFound{})
+	})
+}
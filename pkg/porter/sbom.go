@@ -0,0 +1,176 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/sbom"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/opencontainers/go-digest"
+)
+
+// generateSBOM writes an SBOM that lists the components the bundle is built from to .cnab/sbom.json,
+// in the format configured with --sbom-format or sbom-format in the config file.
+// The digest of the bundle image is included when it is known.
+func (p *Porter) generateSBOM(ctx context.Context, m *manifest.Manifest, imageDigest digest.Digest) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	format := p.GetSBOMFormat()
+	if format == sbom.FormatNone {
+		log.Debug("Skipping SBOM generation because the sbom format is none")
+		// Do not leave behind an SBOM from a previous build
+		if err := p.FileSystem.Remove(build.LOCAL_SBOM); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	inv, err := p.buildSBOMInventory(ctx, m, imageDigest)
+	if err != nil {
+		return err
+	}
+
+	data, err := sbom.Generate(format, inv)
+	if err != nil {
+		return err
+	}
+
+	if err = p.FileSystem.WriteFile(build.LOCAL_SBOM, data, pkg.FileModeWritable); err != nil {
+		return fmt.Errorf("error writing %s: %w", build.LOCAL_SBOM, err)
+	}
+	log.Debugf("Generated a %s SBOM at %s", format, build.LOCAL_SBOM)
+	return nil
+}
+
+// buildSBOMInventory collects the components of the bundle that are described by the SBOM.
+func (p *Porter) buildSBOMInventory(ctx context.Context, m *manifest.Manifest, imageDigest digest.Digest) (sbom.Inventory, error) {
+	inv := sbom.Inventory{
+		Bundle: sbom.Component{
+			Name:        m.Name,
+			Version:     m.Version,
+			Description: m.Description,
+		},
+		BundleImage: sbom.Component{
+			Name:      m.Name,
+			Version:   m.Version,
+			Reference: m.Image,
+			Digest:    imageDigest.String(),
+		},
+		Runtime: sbom.Component{
+			Name:        "porter-runtime",
+			Version:     pkg.Version,
+			Description: "Porter runtime that executes the bundle",
+		},
+		Created: time.Now(),
+		Tool:    sbom.Component{Name: "porter", Version: pkg.Version},
+	}
+
+	dockerfile, err := p.FileSystem.ReadFile(build.DOCKER_FILE)
+	if err != nil {
+		return sbom.Inventory{}, fmt.Errorf("error reading %s: %w", build.DOCKER_FILE, err)
+	}
+	for _, img := range sbom.ParseBaseImages(dockerfile) {
+		inv.BaseImages = append(inv.BaseImages, newImageComponent(img))
+	}
+
+	mixins, err := p.getUsedMixins(ctx, m)
+	if err != nil {
+		return sbom.Inventory{}, err
+	}
+	// Mixin metadata is collected in parallel, keep the order stable
+	sort.Slice(mixins, func(i, j int) bool {
+		return mixins[i].Name < mixins[j].Name
+	})
+	for _, mixin := range mixins {
+		inv.Mixins = append(inv.Mixins, sbom.Component{
+			Name:        mixin.Name,
+			Version:     mixin.VersionInfo.Version,
+			Description: fmt.Sprintf("Porter %s mixin", mixin.Name),
+		})
+	}
+
+	imageKeys := make([]string, 0, len(m.ImageMap))
+	for key := range m.ImageMap {
+		imageKeys = append(imageKeys, key)
+	}
+	sort.Strings(imageKeys)
+	for _, key := range imageKeys {
+		img := m.ImageMap[key]
+		ref, err := img.ToOCIReference()
+		if err != nil {
+			return sbom.Inventory{}, fmt.Errorf("invalid image %s: %w", key, err)
+		}
+		c := newImageComponent(ref.String())
+		c.Description = img.Description
+		if img.Tag != "" {
+			c.Version = img.Tag
+		}
+		inv.Images = append(inv.Images, c)
+	}
+
+	return inv, nil
+}
+
+// newImageComponent creates an SBOM component for an image reference.
+func newImageComponent(image string) sbom.Component {
+	ref, err := cnab.ParseOCIReference(image)
+	if err != nil {
+		// The reference may use a build argument, e.g. FROM ${BASE_IMAGE}
+		return sbom.Component{Name: image}
+	}
+
+	c := sbom.Component{Name: ref.Repository(), Reference: image}
+	if ref.HasTag() {
+		c.Version = ref.Tag()
+	}
+	if ref.HasDigest() {
+		c.Digest = ref.Digest().String()
+	}
+	return c
+}
+
+// attachSBOM pushes the SBOM generated when the bundle was built as an artifact that refers to the bundle.
+func (p *Porter) attachSBOM(ctx context.Context, bundleRef cnab.BundleReference, regOpts cnabtooci.RegistryOptions) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	format := p.GetSBOMFormat()
+	if format == sbom.FormatNone {
+		return nil
+	}
+
+	data, err := p.FileSystem.ReadFile(build.LOCAL_SBOM)
+	if err != nil {
+		return log.Errorf("error reading %s: %w", build.LOCAL_SBOM, err)
+	}
+	mediaType, err := sbom.MediaType(format)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	subject, err := bundleRef.Reference.WithDigest(bundleRef.Digest)
+	if err != nil {
+		return log.Error(err)
+	}
+	artifact := cnabtooci.Artifact{
+		ArtifactType: mediaType,
+		MediaType:    mediaType,
+		Content:      data,
+	}
+	if _, err = p.Registry.AttachArtifact(ctx, subject, artifact, regOpts); err != nil {
+		return log.Errorf("error attaching the SBOM to %s: %w", bundleRef.Reference, err)
+	}
+
+	log.Infof("Attached the %s SBOM to %s", format, bundleRef.Reference)
+	return nil
+}
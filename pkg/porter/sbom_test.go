@@ -0,0 +1,133 @@
+package porter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	"get.porter.sh/porter/pkg/provenance"
+	"get.porter.sh/porter/pkg/sbom"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish_AttachSBOM(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		format        string
+		wantMediaType string
+	}{
+		{format: sbom.FormatSPDX, wantMediaType: sbom.SPDXMediaType},
+		{format: sbom.FormatCycloneDX, wantMediaType: sbom.CycloneDXMediaType},
+		{format: sbom.FormatNone},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+			opts := PublishOptions{SBOMFormat: tc.format}
+			require.NoError(t, opts.Validate(p.Config))
+			require.NoError(t, p.Publish(ctx, opts))
+
+			var artifacts []cnabtooci.Artifact
+			for _, attached := range p.TestRegistry.Artifacts {
+				for _, artifact := range attached {
+					// Ignore the provenance statement that is also attached
+					if artifact.ArtifactType != provenance.MediaType {
+						artifacts = append(artifacts, artifact)
+					}
+				}
+			}
+
+			if tc.format == sbom.FormatNone {
+				assert.Empty(t, artifacts, "no SBOM should be attached")
+				exists, err := p.FileSystem.Exists(build.LOCAL_SBOM)
+				require.NoError(t, err)
+				assert.False(t, exists, "no SBOM should be generated")
+				return
+			}
+
+			require.Len(t, artifacts, 1, "the SBOM should be attached to the bundle")
+			assert.Equal(t, tc.wantMediaType, artifacts[0].ArtifactType)
+
+			localSBOM, err := p.FileSystem.ReadFile(build.LOCAL_SBOM)
+			require.NoError(t, err)
+			assert.Equal(t, string(localSBOM), string(artifacts[0].Content), "the published SBOM should be saved next to the bundle.json")
+			assert.Contains(t, string(localSBOM), "pkg:oci/mybuns@sha256%3A75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041", "the SBOM should include the digest of the published bundle image")
+		})
+	}
+}
+
+func TestPorter_GenerateSBOM(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+
+	opts := BuildOptions{}
+	require.NoError(t, opts.Validate(p.Porter))
+	require.NoError(t, p.Build(ctx, opts))
+
+	data, err := p.FileSystem.ReadFile(build.LOCAL_SBOM)
+	require.NoError(t, err)
+
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			Name        string `json:"name"`
+			VersionInfo string `json:"versionInfo"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+
+	var names []string
+	for _, pkg := range doc.Packages {
+		names = append(names, pkg.Name)
+	}
+	assert.Contains(t, names, "mybuns", "the bundle should be included")
+	assert.Contains(t, names, "debian", "the base image should be included")
+	assert.Contains(t, names, "exec", "the mixins should be included")
+	assert.Contains(t, names, "porter-runtime", "the porter runtime should be included")
+	assert.Contains(t, names, "carolynvs/whalesayd", "the referenced images should be included")
+}
+
+func TestCopy_CopySBOMs(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	destDigest := digest.FromString("destination")
+	p.TestRegistry.MockPushBundle = func(ctx context.Context, ref cnab.BundleReference, opts cnabtooci.RegistryOptions) (cnab.BundleReference, error) {
+		ref.Digest = destDigest
+		return ref, nil
+	}
+
+	source := "example1.com/mybuns:v0.1.0"
+	sourceSBOM := cnabtooci.Artifact{ArtifactType: sbom.SPDXMediaType, MediaType: sbom.SPDXMediaType, Content: []byte(`{"spdxVersion":"SPDX-2.3"}`)}
+	p.TestRegistry.Artifacts[source] = []cnabtooci.Artifact{sourceSBOM}
+
+	opts := &CopyOpts{
+		Source:      source,
+		Destination: "example2.com/mybuns:v0.1.0",
+	}
+	require.NoError(t, opts.Validate(p.Config))
+	require.NoError(t, p.CopyBundle(ctx, opts))
+
+	copied := p.TestRegistry.Artifacts["example2.com/mybuns:v0.1.0@"+destDigest.String()]
+	require.Len(t, copied, 1, "the SBOM should be attached to the copied bundle")
+	assert.Equal(t, sourceSBOM.Content, copied[0].Content)
+}
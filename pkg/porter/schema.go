@@ -154,6 +154,17 @@ func (p *Porter) injectMixinSchemas(ctx context.Context, manifestSchema jsonSche
 			mixinDeclSchema = append(mixinDeclSchema, jsonObject{"$ref": mixinConfigRef})
 		}
 
+		// Allow an if condition on the mixin's steps
+		for _, stepDef := range []string{"installStep", "upgradeStep", "uninstallStep", "invokeStep"} {
+			stepProperties, err := jsonpath.Get(fmt.Sprintf("$.definitions.%s.properties", stepDef), mixinSchemaMap)
+			if err != nil {
+				continue
+			}
+			if stepPropertiesSchema, ok := stepProperties.(jsonSchema); ok {
+				stepPropertiesSchema["if"] = jsonObject{"$ref": "#/definitions/stepCondition"}
+			}
+		}
+
 		// embed the entire mixin schema in the root
 		manifestSchema["mixin."+mixin] = mixinSchemaMap
 
@@ -0,0 +1,256 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"get.porter.sh/porter/pkg/secrets"
+	secretsplugins "get.porter.sh/porter/pkg/secrets/plugins"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+// secretsGCGracePeriod is how long a secret created by Porter is kept before it
+// may be garbage collected. Porter saves sensitive values before the run that
+// references them, so recent secrets are skipped to avoid removing the secrets
+// of a run that is still being created.
+const secretsGCGracePeriod = time.Hour
+
+// SecretsGCOptions are the options for the porter secrets gc command.
+type SecretsGCOptions struct {
+	// DryRun lists the secrets that would be removed without removing them.
+	DryRun bool
+}
+
+// GarbageCollectSecrets removes the secrets that Porter created for sensitive
+// parameters, outputs and the values of encrypted credential and parameter sets,
+// when the record that owns them no longer exists. Only the secrets that Porter
+// recorded when it created them are removed. The keys of the orphaned secrets are returned.
+func (p *Porter) GarbageCollectSecrets(ctx context.Context, opts SecretsGCOptions) ([]string, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	records, err := p.SecretRecords.ListSecretRecords(ctx)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not list the secrets created by porter: %w", err))
+	}
+
+	owners, err := p.listSecretOwners(ctx)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	var orphaned []string
+	now := time.Now()
+	for _, record := range records {
+		if _, ok := owners[record.Owner]; ok {
+			continue
+		}
+		if now.Sub(record.Created) < secretsGCGracePeriod {
+			span.Debugf("Skipping secret %s because it was created less than %s ago", record.Key, secretsGCGracePeriod)
+			continue
+		}
+		orphaned = append(orphaned, record.Key)
+	}
+	sort.Strings(orphaned)
+
+	if opts.DryRun {
+		return orphaned, nil
+	}
+
+	for _, key := range orphaned {
+		if err := p.Secrets.Delete(ctx, secrets.SourceSecret, key); err != nil {
+			return nil, span.Error(fmt.Errorf("could not remove secret %s: %w", key, err))
+		}
+		if err := p.SecretRecords.RemoveSecretRecord(ctx, key); err != nil {
+			return nil, span.Error(fmt.Errorf("could not remove the record of secret %s: %w", key, err))
+		}
+	}
+	return orphaned, nil
+}
+
+// listSecretOwners returns the records that may own secrets created by Porter:
+// installations, runs, credential sets and parameter sets.
+func (p *Porter) listSecretOwners(ctx context.Context) (map[storage.SecretOwner]struct{}, error) {
+	owners := make(map[storage.SecretOwner]struct{})
+	installations, err := p.Installations.ListInstallations(ctx, storage.ListOptions{Namespace: "*"})
+	if err != nil {
+		return nil, fmt.Errorf("could not list installations: %w", err)
+	}
+	for _, inst := range installations {
+		owners[inst.SecretOwner()] = struct{}{}
+		runs, _, err := p.Installations.ListRuns(ctx, inst.Namespace, inst.Name)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the runs of installation %s: %w", inst, err)
+		}
+		for _, run := range runs {
+			owners[run.SecretOwner()] = struct{}{}
+		}
+	}
+
+	credentialSets, err := p.Credentials.ListCredentialSets(ctx, storage.ListOptions{Namespace: "*"})
+	if err != nil {
+		return nil, fmt.Errorf("could not list credential sets: %w", err)
+	}
+	for _, cs := range credentialSets {
+		owners[cs.SecretOwner()] = struct{}{}
+	}
+
+	parameterSets, err := p.Parameters.ListParameterSets(ctx, storage.ListOptions{Namespace: "*"})
+	if err != nil {
+		return nil, fmt.Errorf("could not list parameter sets: %w", err)
+	}
+	for _, ps := range parameterSets {
+		owners[ps.SecretOwner()] = struct{}{}
+	}
+	return owners, nil
+}
+
+// PrintGarbageCollectedSecrets removes orphaned secrets and prints the secrets that were removed.
+func (p *Porter) PrintGarbageCollectedSecrets(ctx context.Context, opts SecretsGCOptions) error {
+	removed, err := p.GarbageCollectSecrets(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if len(removed) == 0 {
+		fmt.Fprintln(p.Out, "No orphaned secrets were found")
+		return nil
+	}
+
+	verb := "Removed"
+	if opts.DryRun {
+		verb = "Would remove"
+	}
+	for _, key := range removed {
+		fmt.Fprintf(p.Out, "%s secret %s\n", verb, key)
+	}
+	return nil
+}
+
+// getRunSecretKeys returns the keys of the secrets that Porter created for the
+// sensitive parameters and outputs of a run.
+func (p *Porter) getRunSecretKeys(ctx context.Context, run storage.Run, results []storage.Result) ([]string, error) {
+	keys := storage.SensitiveParameterKeys(run.Parameters, run.ID)
+	keys = append(keys, storage.SensitiveParameterKeys(run.ParameterOverrides, run.ID)...)
+	for _, result := range results {
+		outputs, err := p.Installations.ListOutputs(ctx, result.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error listing outputs of run %s: %w", run.ID, err)
+		}
+		for _, output := range outputs {
+			if output.Key != "" {
+				keys = append(keys, output.Key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// removeInstallation removes an installation and all associated data, including
+// the sensitive values that Porter saved in the secret store for it.
+func (p *Porter) removeInstallation(ctx context.Context, namespace string, name string) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	inst, err := p.Installations.GetInstallation(ctx, namespace, name)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	// Find the secrets before the documents that reference them are removed
+	secretKeys := storage.SensitiveParameterKeys(inst.Parameters, inst.ID)
+	hasDigestKey, err := p.SecretRecords.HasSecretRecord(ctx, storage.CredentialsDigestKeyName(inst.ID))
+	if err != nil {
+		return span.Error(err)
+	}
+	if hasDigestKey {
+		secretKeys = append(secretKeys, storage.CredentialsDigestKeyName(inst.ID))
+	}
+	runs, results, err := p.Installations.ListRuns(ctx, namespace, name)
+	if err != nil {
+		return span.Error(fmt.Errorf("error listing runs of installation %s: %w", inst, err))
+	}
+	for _, run := range runs {
+		runSecrets, err := p.getRunSecretKeys(ctx, run, results[run.ID])
+		if err != nil {
+			return span.Error(err)
+		}
+		secretKeys = append(secretKeys, runSecrets...)
+	}
+
+	if err = p.Installations.RemoveInstallation(ctx, namespace, name); err != nil {
+		return span.Error(err)
+	}
+
+	p.removeSecrets(ctx, secretKeys)
+	return nil
+}
+
+// removeSecrets removes sensitive values that Porter saved in the secret store
+// for records that have been deleted. Failures are logged and do not stop the
+// operation, since the records that referenced the secrets are already gone.
+// Use porter secrets gc to remove secrets that could not be removed.
+func (p *Porter) removeSecrets(ctx context.Context, keys []string) {
+	log := tracing.LoggerFromContext(ctx)
+
+	for i, key := range keys {
+		err := p.Secrets.Delete(ctx, secrets.SourceSecret, key)
+		if errors.Is(err, secretsplugins.ErrNotImplemented) {
+			log.Warnf("The configured secrets plugin does not support removing secrets, %d sensitive values were left in the secret store", len(keys)-i)
+			return
+		}
+		if err != nil {
+			log.Warnf("Could not remove secret %s: %s", key, err.Error())
+			continue
+		}
+		if err = p.SecretRecords.RemoveSecretRecord(ctx, key); err != nil {
+			log.Warnf("Could not remove the record of secret %s: %s", key, err.Error())
+		}
+	}
+}
+
+// RekeySecrets rewrites every secret in the secret store, so that plugins that
+// encrypt secrets at rest, such as the filesystem plugin, re-encrypt them with
+// the currently configured key. Secrets that were saved before encryption was
+// enabled are encrypted. The keys of the rewritten secrets are returned.
+func (p *Porter) RekeySecrets(ctx context.Context) ([]string, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	keys, err := p.Secrets.List(ctx, secrets.SourceSecret)
+	if err != nil {
+		return nil, span.Error(fmt.Errorf("could not list the secrets in the secret store: %w", err))
+	}
+	sort.Strings(keys)
+
+	rekeyed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := p.Secrets.Resolve(ctx, secrets.SourceSecret, key)
+		if err != nil {
+			return rekeyed, span.Error(fmt.Errorf("could not read secret %s: %w", key, err))
+		}
+		if err = p.Secrets.Create(ctx, secrets.SourceSecret, key, value); err != nil {
+			return rekeyed, span.Error(fmt.Errorf("could not rewrite secret %s: %w", key, err))
+		}
+		rekeyed = append(rekeyed, key)
+	}
+	return rekeyed, nil
+}
+
+// PrintRekeyedSecrets rekeys the secrets in the secret store and prints the secrets that were rewritten.
+func (p *Porter) PrintRekeyedSecrets(ctx context.Context) error {
+	rekeyed, err := p.RekeySecrets(ctx)
+	for _, key := range rekeyed {
+		fmt.Fprintf(p.Out, "Rekeyed secret %s\n", key)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "Rekeyed %d secrets\n", len(rekeyed))
+	return nil
+}
@@ -100,7 +100,7 @@ func TestPorter_ShowInstallationWithBundle(t *testing.T) {
 
 				i.ParameterSets = []string{"dev-env"}
 
-				i.Parameters.Parameters = p.SanitizeParameters(i.Parameters.Parameters, i.ID, bun)
+				i.Parameters.Parameters = p.SanitizeParameters(i.Parameters.Parameters, i.SecretOwner(), bun)
 			})
 
 			run := p.TestInstallations.CreateRun(i.NewRun(cnab.ActionUpgrade, bun), p.TestInstallations.SetMutableRunValues, func(r *storage.Run) {
@@ -120,19 +120,19 @@ func TestPorter_ShowInstallationWithBundle(t *testing.T) {
 				}
 
 				r.ParameterSets = []string{"dev-env"}
-				r.ParameterOverrides.Parameters = p.SanitizeParameters(r.ParameterOverrides.Parameters, r.ID, bun)
-				r.Parameters.Parameters = p.SanitizeParameters(params, r.ID, bun)
+				r.ParameterOverrides.Parameters = p.SanitizeParameters(r.ParameterOverrides.Parameters, r.SecretOwner(), bun)
+				r.Parameters.Parameters = p.SanitizeParameters(params, r.SecretOwner(), bun)
 			})
 
 			i.Parameters.Parameters = run.ParameterOverrides.Parameters
-			err := p.TestInstallations.UpsertInstallation(context.Background(), i)
+			err := p.TestInstallations.UpsertInstallation(context.Background(), &i)
 			require.NoError(t, err)
 
 			result := p.TestInstallations.CreateResult(run.NewResult(cnab.StatusSucceeded), p.TestInstallations.SetMutableResultValues)
 			i.ApplyResult(run, result)
 			i.Status.Installed = &now
 			ctx := context.Background()
-			require.NoError(t, p.TestInstallations.UpdateInstallation(ctx, i))
+			require.NoError(t, p.TestInstallations.UpdateInstallation(ctx, &i))
 
 			err = p.ShowInstallation(ctx, opts)
 			require.NoError(t, err, "ShowInstallation failed")
@@ -212,7 +212,7 @@ func TestPorter_ShowInstallationWithoutRecordedRun(t *testing.T) {
 
 		i.ParameterSets = []string{"dev-env"}
 
-		i.Parameters.Parameters = p.SanitizeParameters(i.Parameters.Parameters, i.ID, bun)
+		i.Parameters.Parameters = p.SanitizeParameters(i.Parameters.Parameters, i.SecretOwner(), bun)
 	})
 
 	// do not create a run, simulate that the installation failed before the bundle could execute (or show was called before the bundle was run)
@@ -0,0 +1,107 @@
+package porter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/signing/plugins/keypair"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+const (
+	// DefaultSigningPrivateKey is the default file that porter signing keygen writes the private key to.
+	DefaultSigningPrivateKey = "porter.key"
+
+	// DefaultSigningPublicKey is the default file that porter signing keygen writes the public key to.
+	DefaultSigningPublicKey = "porter.pub"
+
+	// fileModePrivateKey restricts access to a generated private key to the current user.
+	fileModePrivateKey os.FileMode = 0600
+)
+
+// KeygenOptions are the options for porter signing keygen.
+type KeygenOptions struct {
+	// Algorithm of the generated key pair: ed25519 or ecdsa.
+	Algorithm string
+
+	// PrivateKey is the path where the private key is written.
+	PrivateKey string
+
+	// PublicKey is the path where the public key is written.
+	PublicKey string
+
+	// Force overwrites existing key files.
+	Force bool
+}
+
+// Validate the options for porter signing keygen.
+func (o *KeygenOptions) Validate() error {
+	switch o.Algorithm {
+	case "":
+		o.Algorithm = keypair.AlgorithmEd25519
+	case keypair.AlgorithmEd25519, keypair.AlgorithmECDSA:
+	default:
+		return fmt.Errorf("invalid --algorithm %s, allowed values are: %s, %s", o.Algorithm, keypair.AlgorithmEd25519, keypair.AlgorithmECDSA)
+	}
+
+	if o.PrivateKey == "" {
+		o.PrivateKey = DefaultSigningPrivateKey
+	}
+	if o.PublicKey == "" {
+		o.PublicKey = DefaultSigningPublicKey
+	}
+	if o.PrivateKey == o.PublicKey {
+		return fmt.Errorf("--private-key and --public-key must be different files")
+	}
+	return nil
+}
+
+// GenerateSigningKeys generates a key pair for the keypair signer and prints
+// the configuration that uses it.
+func (p *Porter) GenerateSigningKeys(ctx context.Context, opts KeygenOptions) error {
+	_, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if !opts.Force {
+		for _, path := range []string{opts.PrivateKey, opts.PublicKey} {
+			exists, err := p.FileSystem.Exists(path)
+			if err != nil {
+				return log.Errorf("error checking if %s exists: %w", path, err)
+			}
+			if exists {
+				return log.Errorf("%s already exists. To overwrite it, repeat the command with --force specified.", path)
+			}
+		}
+	}
+
+	privateKey, publicKey, err := keypair.GenerateKeyPair(opts.Algorithm)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	if err = p.FileSystem.WriteFile(opts.PrivateKey, privateKey, fileModePrivateKey); err != nil {
+		return log.Errorf("error writing the private key to %s: %w", opts.PrivateKey, err)
+	}
+	// Tighten the permissions when an existing file is overwritten
+	if err = p.FileSystem.Chmod(opts.PrivateKey, fileModePrivateKey); err != nil {
+		return log.Errorf("error setting the permissions of %s: %w", opts.PrivateKey, err)
+	}
+	if err = p.FileSystem.WriteFile(opts.PublicKey, publicKey, pkg.FileModeWritable); err != nil {
+		return log.Errorf("error writing the public key to %s: %w", opts.PublicKey, err)
+	}
+
+	fmt.Fprintf(p.Out, "Wrote the %s private key to %s and the public key to %s\n", opts.Algorithm, opts.PrivateKey, opts.PublicKey)
+	fmt.Fprintf(p.Out, `
+Add a signer to the Porter config file that uses the key pair:
+
+signers:
+  - name: "mysigner"
+    plugin: "keypair"
+    config:
+      privatekey: %s
+      publickey: %s
+`, p.FileSystem.Abs(opts.PrivateKey), p.FileSystem.Abs(opts.PublicKey))
+	return nil
+}
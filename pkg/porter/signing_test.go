@@ -0,0 +1,77 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/signing/plugins/keypair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeygenOptions_Validate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts := KeygenOptions{}
+		require.NoError(t, opts.Validate())
+		assert.Equal(t, keypair.AlgorithmEd25519, opts.Algorithm)
+		assert.Equal(t, DefaultSigningPrivateKey, opts.PrivateKey)
+		assert.Equal(t, DefaultSigningPublicKey, opts.PublicKey)
+	})
+
+	t.Run("invalid algorithm", func(t *testing.T) {
+		opts := KeygenOptions{Algorithm: "rsa"}
+		require.ErrorContains(t, opts.Validate(), "invalid --algorithm rsa")
+	})
+
+	t.Run("same file", func(t *testing.T) {
+		opts := KeygenOptions{PrivateKey: "porter.key", PublicKey: "porter.key"}
+		require.ErrorContains(t, opts.Validate(), "must be different files")
+	})
+}
+
+func TestPorter_GenerateSigningKeys(t *testing.T) {
+	for _, algorithm := range []string{keypair.AlgorithmEd25519, keypair.AlgorithmECDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			opts := KeygenOptions{Algorithm: algorithm}
+			require.NoError(t, opts.Validate())
+			require.NoError(t, p.GenerateSigningKeys(p.RootContext, opts))
+
+			privateKeyB, err := p.FileSystem.ReadFile("porter.key")
+			require.NoError(t, err)
+			privateKey, err := keypair.ParsePrivateKey(privateKeyB)
+			require.NoError(t, err)
+			info, err := p.FileSystem.Stat("porter.key")
+			require.NoError(t, err)
+			assert.Equal(t, fileModePrivateKey, info.Mode().Perm(), "the private key should only be readable by the current user")
+
+			publicKeyB, err := p.FileSystem.ReadFile("porter.pub")
+			require.NoError(t, err)
+			publicKey, err := keypair.ParsePublicKey(publicKeyB)
+			require.NoError(t, err)
+			assert.Equal(t, privateKey.Public(), publicKey, "the public key should match the private key")
+
+			assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `plugin: "keypair"`)
+		})
+	}
+
+	t.Run("existing keys", func(t *testing.T) {
+		p := NewTestPorter(t)
+		defer p.Close()
+
+		require.NoError(t, p.FileSystem.WriteFile("porter.pub", []byte("existing"), 0600))
+
+		opts := KeygenOptions{}
+		require.NoError(t, opts.Validate())
+		err := p.GenerateSigningKeys(p.RootContext, opts)
+		require.ErrorContains(t, err, "porter.pub already exists")
+
+		opts.Force = true
+		require.NoError(t, p.GenerateSigningKeys(p.RootContext, opts))
+		publicKeyB, err := p.FileSystem.ReadFile("porter.pub")
+		require.NoError(t, err)
+		_, err = keypair.ParsePublicKey(publicKeyB)
+		require.NoError(t, err)
+	})
+}
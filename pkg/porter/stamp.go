@@ -111,6 +111,21 @@ func (p *Porter) IsBundleUpToDate(ctx context.Context, opts BundleDefinitionOpti
 				return false, span.Error(err)
 			}
 
+			// Builders that do not use Docker keep track of the images that they built
+			if store, ok := p.GetBuilder(ctx).(build.ImageStore); ok {
+				exists, err := store.HasBundleImage(ctx, invocationImage.Image)
+				if err != nil {
+					err = fmt.Errorf("an error occurred checking for the bundle image: %w", err)
+					span.Debugf("%s: %w", rebuildMessagePrefix, err)
+					return false, span.Error(err)
+				}
+				if !exists {
+					span.Debugf("%s because the bundle image %s has not been built", rebuildMessagePrefix, invocationImage.Image)
+					return false, nil
+				}
+				continue
+			}
+
 			_, err = p.Registry.GetCachedImage(ctx, imgRef)
 			if err != nil {
 				if errors.Is(err, cnabtooci.ErrNotFound{}) {
@@ -7,11 +7,16 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/storage/migrations"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 type MigrateStorageOptions struct {
@@ -41,6 +46,241 @@ func (p *Porter) MigrateStorage(ctx context.Context, opts MigrateStorageOptions)
 	return p.Storage.Migrate(ctx, migrateOpts)
 }
 
+// ExportStorageOptions are the options for porter storage export.
+type ExportStorageOptions struct {
+	// File is the path to the archive that should be created.
+	File string
+
+	// Namespaces limits the export to documents defined in these namespaces.
+	// All namespaces are exported by default.
+	Namespaces []string
+}
+
+func (o *ExportStorageOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("the destination file is required")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the archive file name, but multiple were received: %s", args)
+	}
+	o.File = args[0]
+	return nil
+}
+
+// ImportStorageOptions are the options for porter storage import.
+type ImportStorageOptions struct {
+	// File is the path to an archive created by porter storage export.
+	File string
+
+	// Namespaces limits the import to documents defined in these namespaces.
+	// All namespaces in the archive are imported by default.
+	Namespaces []string
+
+	// SecretsFrom is the name of the secrets plugin in the config file that has
+	// the sensitive values referenced by the archive. The values are resolved with
+	// that plugin and saved with the configured secrets plugin.
+	SecretsFrom string
+}
+
+func (o *ImportStorageOptions) Validate(args []string) error {
+	if len(args) < 1 || args[0] == "" {
+		return errors.New("the archive file is required")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("only one positional argument may be specified, the archive file name, but multiple were received: %s", args)
+	}
+	o.File = args[0]
+	return nil
+}
+
+// ExportStorage writes Porter's installations, runs, results, outputs,
+// credential sets and parameter sets to an archive.
+func (p *Porter) ExportStorage(ctx context.Context, opts ExportStorageOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	backup := storage.Backup{
+		Manifest: storage.BackupManifest{
+			FormatVersion: storage.BackupFormatVersion,
+			PorterVersion: pkg.Version,
+			Created:       time.Now().UTC(),
+			Namespaces:    opts.Namespaces,
+		},
+	}
+
+	if err := p.Storage.Get(ctx, migrations.CollectionConfig, storage.GetOptions{ID: "schema"}, &backup.Schema); err != nil {
+		return span.Error(fmt.Errorf("could not read the storage schema document: %w", err))
+	}
+
+	var filter, setFilter bson.M
+	if len(opts.Namespaces) > 0 {
+		filter = bson.M{"namespace": bson.M{"$in": opts.Namespaces}}
+		// Installations in any namespace may use credential and parameter sets from the global namespace
+		setFilter = bson.M{"namespace": bson.M{"$in": append([]string{""}, opts.Namespaces...)}}
+	}
+	queries := []struct {
+		collection string
+		filter     bson.M
+		sort       []string
+		out        interface{}
+	}{
+		{storage.CollectionInstallations, filter, []string{"namespace", "name"}, &backup.Installations},
+		{storage.CollectionRuns, filter, []string{"_id"}, &backup.Runs},
+		{storage.CollectionResults, filter, []string{"_id"}, &backup.Results},
+		{storage.CollectionOutputs, filter, []string{"resultId", "name"}, &backup.Outputs},
+		{storage.CollectionCredentials, setFilter, []string{"namespace", "name"}, &backup.CredentialSets},
+		{storage.CollectionParameters, setFilter, []string{"namespace", "name"}, &backup.ParameterSets},
+	}
+	for _, q := range queries {
+		if err := p.Storage.Find(ctx, q.collection, storage.FindOptions{Filter: q.filter, Sort: q.sort}, q.out); err != nil {
+			return span.Error(fmt.Errorf("error exporting %s: %w", q.collection, err))
+		}
+	}
+
+	dest, err := p.FileSystem.OpenFile(opts.File, os.O_RDWR|os.O_CREATE|os.O_TRUNC, pkg.FileModeWritable)
+	if err != nil {
+		return span.Error(fmt.Errorf("error creating archive file %s: %w", opts.File, err))
+	}
+
+	err = storage.WriteBackup(dest, backup)
+	if closeErr := dest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return span.Error(fmt.Errorf("error writing archive file %s: %w", opts.File, err))
+	}
+
+	fmt.Fprintf(p.Out, "Exported %s to %s\n", summarizeBackup(backup), opts.File)
+	return nil
+}
+
+// ImportStorage loads an archive created by porter storage export into the configured storage plugin.
+// When SecretsFrom is set, the sensitive values referenced by the archive are
+// resolved with that secrets plugin and saved with the configured secrets plugin.
+func (p *Porter) ImportStorage(ctx context.Context, opts ImportStorageOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	src, err := p.FileSystem.Open(opts.File)
+	if err != nil {
+		return span.Error(fmt.Errorf("error opening archive file %s: %w", opts.File, err))
+	}
+	defer src.Close()
+
+	backup, err := storage.ReadBackup(src)
+	if err != nil {
+		return span.Error(fmt.Errorf("error reading archive file %s: %w", opts.File, err))
+	}
+
+	if backup.Schema != storage.NewSchema() {
+		return span.Error(fmt.Errorf("the archive was exported with Porter %s using the storage schema %#v, which does not match the schema used by Porter %s: %#v. Import the archive using the same version of Porter that exported it, and then run porter storage migrate",
+			backup.Manifest.PorterVersion, backup.Schema, pkg.Version, storage.NewSchema()))
+	}
+
+	backup = backup.FilterNamespaces(opts.Namespaces)
+
+	// Save the secrets first so that the imported documents never reference a secret that doesn't exist yet
+	secretsCopied := 0
+	if opts.SecretsFrom != "" {
+		secretsCopied, err = p.copyBackupSecrets(ctx, backup, opts.SecretsFrom)
+		if err != nil {
+			return span.Error(err)
+		}
+	} else if len(backup.SecretKeys()) > 0 {
+		span.Warnf("The imported installations reference sensitive values that must already exist in the configured secret store. Use --secrets-from to copy them from another secret store.")
+	}
+
+	upsert := func(collection string, doc storage.Document) error {
+		_, err := p.Storage.Update(ctx, collection, storage.UpdateOptions{Document: doc, Upsert: true})
+		if err != nil {
+			return fmt.Errorf("error importing %s %v: %w", collection, doc.DefaultDocumentFilter(), err)
+		}
+		return nil
+	}
+
+	for _, cs := range backup.CredentialSets {
+		if err = upsert(storage.CollectionCredentials, cs); err != nil {
+			return span.Error(err)
+		}
+	}
+	for _, ps := range backup.ParameterSets {
+		if err = upsert(storage.CollectionParameters, ps); err != nil {
+			return span.Error(err)
+		}
+	}
+	for _, inst := range backup.Installations {
+		if err = upsert(storage.CollectionInstallations, inst); err != nil {
+			return span.Error(err)
+		}
+	}
+	for _, run := range backup.Runs {
+		if err = upsert(storage.CollectionRuns, run); err != nil {
+			return span.Error(err)
+		}
+	}
+	for _, result := range backup.Results {
+		if err = upsert(storage.CollectionResults, result); err != nil {
+			return span.Error(err)
+		}
+	}
+	for _, output := range backup.Outputs {
+		if err = upsert(storage.CollectionOutputs, output); err != nil {
+			return span.Error(err)
+		}
+	}
+
+	fmt.Fprintf(p.Out, "Imported %s from %s\n", summarizeBackup(backup), opts.File)
+	if opts.SecretsFrom != "" {
+		fmt.Fprintf(p.Out, "Copied %d secrets from %s\n", secretsCopied, opts.SecretsFrom)
+	}
+	return nil
+}
+
+// copyBackupSecrets resolves the sensitive values referenced by the backup with
+// the named secrets plugin, and saves them with the configured secrets plugin.
+// It returns the number of secrets that were copied.
+func (p *Porter) copyBackupSecrets(ctx context.Context, backup storage.Backup, secretsFrom string) (int, error) {
+	log := tracing.LoggerFromContext(ctx)
+
+	if _, err := p.Config.GetSecretsPlugin(secretsFrom); err != nil {
+		return 0, fmt.Errorf("invalid --secrets-from: %w", err)
+	}
+	source := p.newSecrets(secretsFrom)
+	defer source.Close()
+
+	copied := 0
+	owners := backup.SecretOwners()
+	for _, key := range backup.SecretKeys() {
+		value, err := source.Resolve(ctx, secrets.SourceSecret, key)
+		if err != nil {
+			// The secret may have been removed from the secret store, e.g. when a run was pruned.
+			// The rest of the backup is still useful, so warn instead of failing.
+			log.Warnf("Skipping secret %s because it could not be resolved from %s: %s", key, secretsFrom, err.Error())
+			continue
+		}
+		if err = p.Secrets.Create(ctx, secrets.SourceSecret, key, value); err != nil {
+			return copied, fmt.Errorf("error saving secret %s: %w", key, err)
+		}
+		if err = p.SecretRecords.RecordSecret(ctx, key, owners[key]); err != nil {
+			return copied, fmt.Errorf("error recording the owner of secret %s: %w", key, err)
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+func summarizeBackup(b storage.Backup) string {
+	counts := []string{
+		fmt.Sprintf("%d installations", len(b.Installations)),
+		fmt.Sprintf("%d runs", len(b.Runs)),
+		fmt.Sprintf("%d results", len(b.Results)),
+		fmt.Sprintf("%d outputs", len(b.Outputs)),
+		fmt.Sprintf("%d credential sets", len(b.CredentialSets)),
+		fmt.Sprintf("%d parameter sets", len(b.ParameterSets)),
+	}
+	return strings.Join(counts, ", ")
+}
+
 func (p *Porter) FixPermissions(ctx context.Context) error {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
@@ -74,6 +74,12 @@ func (p *Porter) UninstallBundle(ctx context.Context, opts UninstallOptions) err
 	ctx, log := tracing.StartSpan(ctx)
 	defer log.EndSpan()
 
+	ctx, lock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, storage.DefaultInstallationLockTTL)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	installation, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if err != nil {
 		return fmt.Errorf("could not find installation %s/%s: %w", opts.Namespace, opts.Name, err)
@@ -84,6 +90,10 @@ func (p *Porter) UninstallBundle(ctx context.Context, opts UninstallOptions) err
 		return err
 	}
 
+	if err = p.verifyBundleBeforeExecution(ctx, opts); err != nil {
+		return err
+	}
+
 	deperator := newDependencyExecutioner(p, installation, opts)
 	err = deperator.Prepare(ctx)
 	if err != nil {
@@ -132,7 +142,7 @@ func (p *Porter) UninstallBundle(ctx context.Context, opts UninstallOptions) err
 
 	if opts.shouldDelete() {
 		log.Info("deleting installation records")
-		return p.Installations.RemoveInstallation(ctx, opts.Namespace, opts.Name)
+		return p.removeInstallation(ctx, opts.Namespace, opts.Name)
 	}
 	return nil
 }
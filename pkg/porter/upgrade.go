@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/tracing"
 	"github.com/Masterminds/semver/v3"
 )
@@ -61,6 +62,12 @@ func (p *Porter) UpgradeBundle(ctx context.Context, opts *UpgradeOptions) error
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
+	ctx, lock, err := p.lockInstallation(ctx, opts.Namespace, opts.Name, storage.DefaultInstallationLockTTL)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Sync any changes specified by the user to the installation before running upgrade
 	i, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Name)
 	if err != nil {
@@ -91,7 +98,7 @@ func (p *Porter) UpgradeBundle(ctx context.Context, opts *UpgradeOptions) error
 		return err
 	}
 
-	err = p.Installations.UpdateInstallation(ctx, i)
+	err = p.Installations.UpdateInstallation(ctx, &i)
 	if err != nil {
 		return err
 	}
@@ -102,5 +109,9 @@ func (p *Porter) UpgradeBundle(ctx context.Context, opts *UpgradeOptions) error
 		return err
 	}
 
-	return p.ExecuteAction(ctx, i, opts)
+	if err = p.verifyBundleBeforeExecution(ctx, opts); err != nil {
+		return err
+	}
+
+	return p.ExecuteAction(ctx, lock, i, opts)
 }
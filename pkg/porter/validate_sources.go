@@ -0,0 +1,282 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/hashicorp/go-multierror"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SourceValidation is the result of resolving a credential or parameter from
+// its source. The resolved value is never included.
+type SourceValidation struct {
+	// Namespace of the credential or parameter set.
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Set is the credential or parameter set that defines the source.
+	Set string `json:"set" yaml:"set"`
+
+	// Name of the credential or parameter.
+	Name string `json:"name" yaml:"name"`
+
+	// Source is the strategy used to resolve the value, such as env or secret.
+	Source string `json:"source" yaml:"source"`
+
+	// Hint is where the value is resolved from, such as the name of the environment variable.
+	// It is omitted for hard-coded values.
+	Hint string `json:"hint,omitempty" yaml:"hint,omitempty"`
+
+	// Error explains why the value could not be resolved. Empty when it was resolved.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// CredentialValidateOptions represent options for Porter's credential validate command
+type CredentialValidateOptions struct {
+	printer.PrintOptions
+	Name         string
+	Namespace    string
+	Installation string
+}
+
+// Validate validates the args provided to Porter's credential validate command
+func (o *CredentialValidateOptions) Validate(args []string) error {
+	if o.Installation != "" {
+		if len(args) > 0 {
+			return errors.New("cannot specify both a credential set name and --installation")
+		}
+		return o.ParseFormat()
+	}
+
+	if err := validateCredentialName(args); err != nil {
+		return err
+	}
+	o.Name = args[0]
+	return o.ParseFormat()
+}
+
+// ParameterValidateOptions represent options for Porter's parameter validate command
+type ParameterValidateOptions struct {
+	printer.PrintOptions
+	Name         string
+	Namespace    string
+	Installation string
+}
+
+// Validate validates the args provided to Porter's parameter validate command
+func (o *ParameterValidateOptions) Validate(args []string) error {
+	if o.Installation != "" {
+		if len(args) > 0 {
+			return errors.New("cannot specify both a parameter set name and --installation")
+		}
+		return o.ParseFormat()
+	}
+
+	if err := validateParameterName(args); err != nil {
+		return err
+	}
+	o.Name = args[0]
+	return o.ParseFormat()
+}
+
+// ValidateCredentials resolves every credential in a credential set, or in the
+// credential sets used by an installation, and reports which could not be resolved.
+func (p *Porter) ValidateCredentials(ctx context.Context, opts CredentialValidateOptions) ([]SourceValidation, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var sets []storage.CredentialSet
+	if opts.Installation == "" {
+		cs, err := p.Credentials.GetCredentialSet(ctx, opts.Namespace, opts.Name)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		sets = append(sets, cs)
+	} else {
+		inst, err := p.Installations.GetInstallation(ctx, opts.Namespace, opts.Installation)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		for _, name := range inst.CredentialSets {
+			var cs storage.CredentialSet
+			err := p.findInstallationSet(ctx, p.Credentials.GetDataStore(), storage.CollectionCredentials, inst.Namespace, name, &cs)
+			if err != nil {
+				return nil, span.Errorf("could not find credential set named %s in the %s namespace or global namespace: %w", name, inst.Namespace, err)
+			}
+			sets = append(sets, cs)
+		}
+	}
+
+	var results []SourceValidation
+	for _, cs := range sets {
+		results = append(results, validateSources(cs.Namespace, cs.Name, cs.Credentials, func(name string) error {
+			_, err := p.Credentials.ResolveAll(ctx, cs, []string{name})
+			return err
+		})...)
+	}
+	return results, nil
+}
+
+// PrintCredentialsValidation prints the result of validating credentials,
+// returning an error when any of them could not be resolved.
+func (p *Porter) PrintCredentialsValidation(ctx context.Context, opts CredentialValidateOptions) error {
+	results, err := p.ValidateCredentials(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return p.printSourceValidation(opts.PrintOptions, "credentials", results)
+}
+
+// ValidateParameters resolves every parameter in a parameter set, or in the
+// parameter sets and parameter overrides used by an installation, and reports
+// which could not be resolved.
+func (p *Porter) ValidateParameters(ctx context.Context, opts ParameterValidateOptions) ([]SourceValidation, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	var sets []storage.ParameterSet
+	var inst storage.Installation
+	if opts.Installation == "" {
+		ps, err := p.Parameters.GetParameterSet(ctx, opts.Namespace, opts.Name)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		sets = append(sets, ps)
+	} else {
+		var err error
+		inst, err = p.Installations.GetInstallation(ctx, opts.Namespace, opts.Installation)
+		if err != nil {
+			return nil, span.Error(err)
+		}
+		for _, name := range inst.ParameterSets {
+			var ps storage.ParameterSet
+			err := p.findInstallationSet(ctx, p.Parameters.GetDataStore(), storage.CollectionParameters, inst.Namespace, name, &ps)
+			if err != nil {
+				return nil, span.Errorf("could not find parameter set named %s in the %s namespace or global namespace: %w", name, inst.Namespace, err)
+			}
+			sets = append(sets, ps)
+		}
+	}
+
+	var results []SourceValidation
+	for _, ps := range sets {
+		results = append(results, validateSources(ps.Namespace, ps.Name, ps.Parameters, func(name string) error {
+			_, err := p.Parameters.ResolveAll(ctx, ps, []string{name})
+			return err
+		})...)
+	}
+
+	// The parameters specified directly on the installation, e.g. with --param
+	if opts.Installation != "" {
+		results = append(results, validateSources(inst.Namespace, "installation "+inst.Name, inst.Parameters.Parameters, func(name string) error {
+			_, err := p.Parameters.ResolveAll(ctx, inst.Parameters, []string{name})
+			return err
+		})...)
+	}
+	return results, nil
+}
+
+// PrintParametersValidation prints the result of validating parameters,
+// returning an error when any of them could not be resolved.
+func (p *Porter) PrintParametersValidation(ctx context.Context, opts ParameterValidateOptions) error {
+	results, err := p.ValidateParameters(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return p.printSourceValidation(opts.PrintOptions, "parameters", results)
+}
+
+// findInstallationSet finds a credential or parameter set used by an installation,
+// looking in the installation's namespace first and then in the global namespace.
+func (p *Porter) findInstallationSet(ctx context.Context, store storage.Store, collection string, namespace string, name string, out interface{}) error {
+	query := storage.FindOptions{
+		Sort: []string{"-namespace"},
+		Filter: bson.M{
+			"name": name,
+			"$or": []bson.M{
+				{"namespace": ""},
+				{"namespace": namespace},
+			},
+		},
+	}
+	return store.FindOne(ctx, collection, query, out)
+}
+
+// validateSources resolves each source in a set individually, so that every
+// failure is reported instead of only the first.
+func validateSources(namespace string, set string, sources []secrets.SourceMap, resolve func(name string) error) []SourceValidation {
+	results := make([]SourceValidation, 0, len(sources))
+	for _, source := range sources {
+		result := SourceValidation{Namespace: namespace, Set: set, Name: source.Name, Source: source.Source.Strategy}
+		if source.Source.Strategy != host.SourceValue {
+			result.Hint = source.Source.Hint
+		}
+		if err := resolve(source.Name); err != nil {
+			result.Error = resolveErrorMessage(err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// resolveErrorMessage returns the reason that a single source could not be
+// resolved, without the list formatting of a multierror or the name of the source.
+func resolveErrorMessage(err error) string {
+	var merr *multierror.Error
+	if errors.As(err, &merr) && len(merr.Errors) == 1 {
+		err = merr.Errors[0]
+		if cause := errors.Unwrap(err); cause != nil {
+			err = cause
+		}
+	}
+	return err.Error()
+}
+
+func (p *Porter) printSourceValidation(opts printer.PrintOptions, kind string, results []SourceValidation) error {
+	var failed int
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		if err := printer.PrintJson(p.Out, results); err != nil {
+			return err
+		}
+	case printer.FormatYaml:
+		if err := printer.PrintYaml(p.Out, results); err != nil {
+			return err
+		}
+	case printer.FormatPlaintext:
+		printRow := func(v interface{}) []string {
+			result, ok := v.(SourceValidation)
+			if !ok {
+				return nil
+			}
+			status := "ok"
+			if result.Error != "" {
+				status = result.Error
+			}
+			return []string{result.Namespace, result.Set, result.Name, strings.TrimSpace(result.Source + " " + result.Hint), status}
+		}
+		if err := printer.PrintTable(p.Out, results, printRow, "NAMESPACE", "SET", "NAME", "SOURCE", "STATUS"); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d %s could not be resolved", failed, len(results), kind)
+	}
+	return nil
+}
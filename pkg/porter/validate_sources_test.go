@@ -0,0 +1,134 @@
+package porter
+
+import (
+	"context"
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialValidateOptions_Validate(t *testing.T) {
+	t.Run("name", func(t *testing.T) {
+		opts := CredentialValidateOptions{PrintOptions: printer.PrintOptions{RawFormat: "json"}}
+		require.NoError(t, opts.Validate([]string{"mycreds"}))
+		assert.Equal(t, "mycreds", opts.Name)
+		assert.Equal(t, printer.FormatJson, opts.Format)
+	})
+
+	t.Run("no name", func(t *testing.T) {
+		opts := CredentialValidateOptions{}
+		require.EqualError(t, opts.Validate(nil), "no credential name was specified")
+	})
+
+	t.Run("installation", func(t *testing.T) {
+		opts := CredentialValidateOptions{Installation: "mysql"}
+		require.NoError(t, opts.Validate(nil))
+	})
+
+	t.Run("name and installation", func(t *testing.T) {
+		opts := CredentialValidateOptions{Installation: "mysql"}
+		require.EqualError(t, opts.Validate([]string{"mycreds"}), "cannot specify both a credential set name and --installation")
+	})
+}
+
+func TestPorter_ValidateCredentials(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	t.Setenv("VALIDATE_TOKEN", "topsecret")
+	require.NoError(t, p.Secrets.Create(ctx, secrets.SourceSecret, "password", "topsecret"))
+	cs := storage.NewCredentialSet("dev", "mycreds",
+		secrets.SourceMap{Name: "token", Source: secrets.Source{Strategy: "env", Hint: "VALIDATE_TOKEN"}},
+		secrets.SourceMap{Name: "password", Source: secrets.Source{Strategy: "secret", Hint: "password"}},
+		secrets.SourceMap{Name: "kubeconfig", Source: secrets.Source{Strategy: "secret", Hint: "deleted-kubeconfig"}},
+		secrets.SourceMap{Name: "username", Source: secrets.Source{Strategy: "value", Hint: "admin"}},
+	)
+	require.NoError(t, p.Credentials.InsertCredentialSet(ctx, cs))
+
+	t.Run("credential set", func(t *testing.T) {
+		opts := CredentialValidateOptions{Name: "mycreds", Namespace: "dev"}
+		results, err := p.ValidateCredentials(ctx, opts)
+		require.NoError(t, err)
+
+		wantResults := []SourceValidation{
+			{Namespace: "dev", Set: "mycreds", Name: "token", Source: "env", Hint: "VALIDATE_TOKEN"},
+			{Namespace: "dev", Set: "mycreds", Name: "password", Source: "secret", Hint: "password"},
+			{Namespace: "dev", Set: "mycreds", Name: "kubeconfig", Source: "secret", Hint: "deleted-kubeconfig", Error: "secret not found"},
+			{Namespace: "dev", Set: "mycreds", Name: "username", Source: "value"},
+		}
+		assert.Equal(t, wantResults, results)
+	})
+
+	t.Run("print", func(t *testing.T) {
+		opts := CredentialValidateOptions{Name: "mycreds", Namespace: "dev"}
+		require.NoError(t, opts.Validate([]string{"mycreds"}))
+		err := p.PrintCredentialsValidation(ctx, opts)
+		require.EqualError(t, err, "1 of 4 credentials could not be resolved")
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "secret not found")
+		assert.NotContains(t, output, "topsecret", "resolved values should never be printed")
+		assert.NotContains(t, output, "admin", "hard-coded values should never be printed")
+	})
+
+	t.Run("installation", func(t *testing.T) {
+		inst := storage.NewInstallation("dev", "mysql")
+		inst.CredentialSets = []string{"mycreds"}
+		p.TestInstallations.CreateInstallation(inst)
+
+		opts := CredentialValidateOptions{Installation: "mysql", Namespace: "dev"}
+		results, err := p.ValidateCredentials(ctx, opts)
+		require.NoError(t, err)
+		assert.Len(t, results, 4)
+	})
+
+	t.Run("missing credential set used by installation", func(t *testing.T) {
+		inst := storage.NewInstallation("dev", "wordpress")
+		inst.CredentialSets = []string{"missing"}
+		p.TestInstallations.CreateInstallation(inst)
+
+		opts := CredentialValidateOptions{Installation: "wordpress", Namespace: "dev"}
+		_, err := p.ValidateCredentials(ctx, opts)
+		require.ErrorContains(t, err, "could not find credential set named missing in the dev namespace or global namespace")
+	})
+}
+
+func TestPorter_ValidateParameters(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	// A global parameter set used by an installation in the dev namespace
+	ps := storage.NewParameterSet("", "myparams",
+		secrets.SourceMap{Name: "color", Source: secrets.Source{Strategy: "value", Hint: "blue"}},
+		secrets.SourceMap{Name: "log-level", Source: secrets.Source{Strategy: "env", Hint: "PORTER_TEST_UNDEFINED_LOG_LEVEL"}},
+	)
+	require.NoError(t, p.Parameters.InsertParameterSet(ctx, ps))
+
+	inst := storage.NewInstallation("dev", "mysql")
+	inst.ParameterSets = []string{"myparams"}
+	inst.Parameters = inst.NewInternalParameterSet(
+		secrets.SourceMap{Name: "password", Source: secrets.Source{Strategy: "secret", Hint: "mysql-password"}},
+	)
+	p.TestInstallations.CreateInstallation(inst)
+
+	opts := ParameterValidateOptions{Installation: "mysql", Namespace: "dev"}
+	require.NoError(t, opts.Validate(nil))
+	results, err := p.ValidateParameters(ctx, opts)
+	require.NoError(t, err)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, SourceValidation{Set: "myparams", Name: "color", Source: "value"}, results[0])
+	assert.Equal(t, "log-level", results[1].Name)
+	assert.Equal(t, "PORTER_TEST_UNDEFINED_LOG_LEVEL", results[1].Hint)
+	assert.NotEmpty(t, results[1].Error, "the undefined environment variable should be reported")
+	assert.Equal(t, SourceValidation{Namespace: "dev", Set: "installation mysql", Name: "password", Source: "secret", Hint: "mysql-password", Error: "secret not found"}, results[2])
+
+	err = p.PrintParametersValidation(ctx, opts)
+	require.EqualError(t, err, "2 of 3 parameters could not be resolved")
+}
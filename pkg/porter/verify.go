@@ -0,0 +1,313 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/signing"
+	"get.porter.sh/porter/pkg/tracing"
+)
+
+const (
+	// VerificationStatusVerified indicates that the reference was verified by the signers required by the policy.
+	VerificationStatusVerified = "verified"
+
+	// VerificationStatusUnsignedAllowed indicates that the policy allows the reference to be unsigned.
+	VerificationStatusUnsignedAllowed = "unsigned allowed"
+
+	// VerificationStatusFailed indicates that the reference could not be verified.
+	VerificationStatusFailed = "failed"
+
+	// defaultSignerRule is reported as the rule that was applied when
+	// --verify-bundle is used without a verification policy.
+	defaultSignerRule = "default signer"
+)
+
+// VerifyOptions are the options for porter bundle verify.
+type VerifyOptions struct {
+	BundlePullOptions
+	printer.PrintOptions
+}
+
+// Validate the args provided to porter bundle verify.
+func (o *VerifyOptions) Validate(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New("a bundle reference is required")
+	case 1:
+		o.Reference = args[0]
+	default:
+		return fmt.Errorf("only one bundle reference can be specified, but multiple were received: %s", args)
+	}
+
+	if _, err := cnab.ParseOCIReference(o.Reference); err != nil {
+		return fmt.Errorf("invalid bundle reference %s, it should be of the form REGISTRY/bundle:tag: %w", o.Reference, err)
+	}
+
+	return o.ParseFormat()
+}
+
+// ReferenceVerification is the result of verifying the signature of a
+// bundle or bundle image against the verification policy.
+type ReferenceVerification struct {
+	// Reference that was verified.
+	Reference string `json:"reference" yaml:"reference"`
+
+	// Type of artifact referenced, bundle or bundle image.
+	Type string `json:"type" yaml:"type"`
+
+	// Rule in the verification policy that was applied to the reference.
+	// Empty when no rule matched.
+	Rule string `json:"rule,omitempty" yaml:"rule,omitempty"`
+
+	// Signers that were required to verify the reference.
+	Signers []string `json:"signers,omitempty" yaml:"signers,omitempty"`
+
+	// Status of the verification: verified, unsigned allowed or failed.
+	Status string `json:"status" yaml:"status"`
+
+	// Error explains why the reference could not be verified.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// VerifyBundle verifies the signature of a bundle and its bundle image against
+// the verification policy, reporting which rule was applied to each.
+func (p *Porter) VerifyBundle(ctx context.Context, opts VerifyOptions) ([]ReferenceVerification, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if p.Data.VerificationPolicy.IsEnabled() {
+		if err := validateVerificationPolicy(p.Config); err != nil {
+			return nil, log.Error(err)
+		}
+	} else {
+		log.Info("No verification policy is defined in the config file, verifying with the default signer")
+	}
+
+	pullOpts := &BundleReferenceOptions{BundlePullOptions: opts.BundlePullOptions}
+	bundleRef, err := pullOpts.GetBundleReference(ctx, p)
+	if err != nil {
+		return nil, log.Error(err)
+	}
+
+	return p.verifyBundleReference(ctx, bundleRef)
+}
+
+// PrintVerifyBundle verifies a bundle against the verification policy and
+// prints the result, returning an error when the bundle does not satisfy the policy.
+func (p *Porter) PrintVerifyBundle(ctx context.Context, opts VerifyOptions) error {
+	results, err := p.VerifyBundle(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		err = printer.PrintJson(p.Out, results)
+	case printer.FormatYaml:
+		err = printer.PrintYaml(p.Out, results)
+	case printer.FormatPlaintext:
+		printRow := func(v interface{}) []string {
+			result, ok := v.(ReferenceVerification)
+			if !ok {
+				return nil
+			}
+			rule := result.Rule
+			if rule == "" {
+				rule = "none"
+			}
+			status := result.Status
+			if result.Error != "" {
+				status = fmt.Sprintf("%s: %s", status, result.Error)
+			}
+			return []string{result.Type, result.Reference, rule, strings.Join(result.Signers, ", "), status}
+		}
+		err = printer.PrintTable(p.Out, results, printRow, "TYPE", "REFERENCE", "RULE", "SIGNERS", "STATUS")
+	default:
+		err = fmt.Errorf("invalid format: %s", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return verificationError(opts.Reference, results)
+}
+
+// verifyBundleBeforeExecution enforces the verification policy before a bundle
+// is executed. When no policy is defined, the bundle is only verified with the
+// default signer when --verify-bundle is specified.
+func (p *Porter) verifyBundleBeforeExecution(ctx context.Context, action BundleAction) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	opts := action.GetOptions()
+	if !p.Data.VerificationPolicy.IsEnabled() {
+		if !opts.VerifyBundleBeforeExecution {
+			return nil
+		}
+	} else if err := validateVerificationPolicy(p.Config); err != nil {
+		return log.Error(err)
+	}
+
+	bundleRef, err := opts.GetBundleReference(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if bundleRef.Reference.Named == nil {
+		if opts.VerifyBundleBeforeExecution {
+			return log.Errorf("unable to verify the bundle signature because the bundle was not pulled from a registry")
+		}
+
+		// Local bundles are not signed, so they are only allowed when a rule explicitly allows them
+		rule, ok := p.Data.VerificationPolicy.MatchLocal()
+		if !ok {
+			return log.Errorf("the bundle does not satisfy the verification policy: the bundle was not pulled from a registry and no verification policy rule allows local bundles")
+		}
+		log.Infof("Skipping signature verification because the bundle was not pulled from a registry and the %s verification policy rule allows local bundles", rule.Name)
+		return nil
+	}
+
+	results, err := p.verifyBundleReference(ctx, bundleRef)
+	if err != nil {
+		return err
+	}
+
+	if err = verificationError(bundleRef.Reference.String(), results); err != nil {
+		return log.Error(err)
+	}
+	return nil
+}
+
+// verifyBundleReference verifies the signature of a bundle and its bundle image.
+func (p *Porter) verifyBundleReference(ctx context.Context, bundleRef cnab.BundleReference) ([]ReferenceVerification, error) {
+	if len(bundleRef.Definition.InvocationImages) == 0 {
+		return nil, fmt.Errorf("the bundle %s does not define a bundle image", bundleRef.Reference)
+	}
+
+	invocationImage := bundleRef.Definition.InvocationImages[0].Image
+	if relocInvImage, ok := bundleRef.RelocationMap[invocationImage]; ok {
+		invocationImage = relocInvImage
+	}
+
+	return []ReferenceVerification{
+		p.verifyReference(ctx, "bundle", bundleRef.Reference.String()),
+		p.verifyReference(ctx, "bundle image", invocationImage),
+	}, nil
+}
+
+// verifyReference verifies a single reference using the first rule in the
+// verification policy that matches its repository, or with the default signer
+// when no policy is defined.
+func (p *Porter) verifyReference(ctx context.Context, refType string, ref string) ReferenceVerification {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	result := ReferenceVerification{Reference: ref, Type: refType, Status: VerificationStatusFailed}
+
+	policy := p.Data.VerificationPolicy
+	if !policy.IsEnabled() {
+		result.Rule = defaultSignerRule
+		log.Debugf("verifying %s signature for %s", refType, ref)
+		if err := p.Signer.Verify(ctx, ref); err != nil {
+			result.Error = fmt.Sprintf("unable to verify signature: %s", err)
+			return result
+		}
+		log.Debugf("%s signature verified for %s", refType, ref)
+		result.Status = VerificationStatusVerified
+		return result
+	}
+
+	parsedRef, err := cnab.ParseOCIReference(ref)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Match against the fully qualified repository, e.g. docker.io/getporter/mybuns instead of getporter/mybuns
+	repository := parsedRef.Named.Name()
+	rule, ok := policy.Match(repository)
+	if !ok {
+		result.Error = fmt.Sprintf("no verification policy rule matches the repository %s", repository)
+		return result
+	}
+	result.Rule = rule.Name
+	result.Signers = rule.Signers
+
+	if rule.AllowUnsigned {
+		log.Infof("Skipping signature verification of %s because the %s verification policy rule allows it to be unsigned", ref, rule.Name)
+		result.Status = VerificationStatusUnsignedAllowed
+		return result
+	}
+
+	for _, name := range rule.Signers {
+		signer, err := p.getSigner(rule, name)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+
+		log.Debugf("verifying %s signature for %s with the %s signer", refType, ref, name)
+		if err = signer.Verify(ctx, ref); err != nil {
+			result.Error = fmt.Sprintf("unable to verify signature with the %s signer: %s", name, err)
+			return result
+		}
+	}
+
+	log.Debugf("%s signature verified for %s", refType, ref)
+	log.Infof("Verified the signature of %s using the %s verification policy rule", ref, rule.Name)
+	result.Status = VerificationStatusVerified
+	return result
+}
+
+// getSigner returns the signer defined with the specified name in the config
+// file, configured with the identity that the rule trusts for the signer.
+func (p *Porter) getSigner(rule config.VerificationRule, name string) (signing.Signer, error) {
+	key := name
+	if _, ok := rule.GetIdentity(name); ok {
+		key = rule.Name + "/" + name
+	}
+	if signer, ok := p.signers[key]; ok {
+		return signer, nil
+	}
+
+	def, err := rule.GetSigner(p.Config, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.signers == nil {
+		p.signers = make(map[string]signing.Signer)
+	}
+	signer := p.newSigner(def)
+	p.signers[key] = signer
+	return signer, nil
+}
+
+// verificationError returns an error that describes the references that
+// failed verification, or nil when every reference was verified.
+func verificationError(ref string, results []ReferenceVerification) error {
+	var failures []string
+	for _, result := range results {
+		if result.Status == VerificationStatusFailed {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", result.Type, result.Reference, result.Error))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the bundle %s does not satisfy the verification policy:\n%s", ref, strings.Join(failures, "\n"))
+}
+
+// validateVerificationPolicy checks the verification policy defined in the config file.
+func validateVerificationPolicy(c *config.Config) error {
+	if err := c.Data.VerificationPolicy.Validate(c); err != nil {
+		return fmt.Errorf("invalid verification-policy in the config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package porter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cache"
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyOptions_Validate(t *testing.T) {
+	t.Run("reference", func(t *testing.T) {
+		opts := VerifyOptions{}
+		require.NoError(t, opts.Validate([]string{"ghcr.io/getporter/examples/porter-hello:v0.2.0"}))
+		assert.Equal(t, "ghcr.io/getporter/examples/porter-hello:v0.2.0", opts.Reference)
+	})
+
+	t.Run("missing reference", func(t *testing.T) {
+		opts := VerifyOptions{}
+		require.EqualError(t, opts.Validate(nil), "a bundle reference is required")
+	})
+
+	t.Run("invalid reference", func(t *testing.T) {
+		opts := VerifyOptions{}
+		require.ErrorContains(t, opts.Validate([]string{"ghcr.io/getporter/UPPERCASE:v1"}), "invalid bundle reference")
+	})
+}
+
+func TestPorter_verifyBundleBeforeExecution(t *testing.T) {
+	cxt := portercontext.New()
+	bun, err := cnab.LoadBundle(cxt, filepath.Join("testdata/bundle.json"))
+	require.NoError(t, err)
+
+	const bundleRef = "ghcr.io/myorg/mybuns:v1.0.0"
+	const imageRef = "getporter/porter-hello-installer:0.1.0"
+
+	setup := func(t *testing.T) (*TestPorter, *InstallOptions) {
+		p := NewTestPorter(t)
+		p.Data.SigningPlugin = []config.SigningPlugin{
+			{PluginConfig: config.PluginConfig{Name: "myorg", PluginSubKey: "cosign"}},
+			{PluginConfig: config.PluginConfig{Name: "release", PluginSubKey: "notation"}},
+		}
+		p.Data.VerificationPolicy = config.VerificationPolicy{
+			Rules: []config.VerificationRule{
+				{Name: "myorg", Repositories: []string{"ghcr.io/myorg/*"}, Signers: []string{"myorg", "release"}},
+				{Name: "installers", Repositories: []string{"docker.io/getporter/*"}, AllowUnsigned: true},
+			},
+		}
+
+		opts := NewInstallOptions()
+		opts.bundleRef = &cnab.BundleReference{Reference: cnab.MustParseOCIReference(bundleRef), Definition: bun}
+		return p, &opts
+	}
+
+	sign := func(t *testing.T, p *TestPorter, signerName string, ref string) {
+		signer, err := p.getSigner(config.VerificationRule{}, signerName)
+		require.NoError(t, err)
+		require.NoError(t, signer.Sign(context.Background(), ref))
+	}
+
+	t.Run("signed by every required signer", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		sign(t, p, "myorg", bundleRef)
+		sign(t, p, "release", bundleRef)
+		require.NoError(t, p.verifyBundleBeforeExecution(p.RootContext, opts))
+
+		output := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, output, "Verified the signature of "+bundleRef+" using the myorg verification policy rule")
+		assert.Contains(t, output, "Skipping signature verification of "+imageRef+" because the installers verification policy rule allows it to be unsigned")
+	})
+
+	t.Run("missing a required signature", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		sign(t, p, "myorg", bundleRef)
+		err := p.verifyBundleBeforeExecution(p.RootContext, opts)
+		require.ErrorContains(t, err, "the bundle "+bundleRef+" does not satisfy the verification policy")
+		require.ErrorContains(t, err, "unable to verify signature with the release signer")
+	})
+
+	t.Run("no matching rule", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		p.Data.VerificationPolicy.Rules = p.Data.VerificationPolicy.Rules[:1]
+		sign(t, p, "myorg", bundleRef)
+		sign(t, p, "release", bundleRef)
+		err := p.verifyBundleBeforeExecution(p.RootContext, opts)
+		require.ErrorContains(t, err, "bundle image "+imageRef+": no verification policy rule matches the repository docker.io/getporter/porter-hello-installer")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		p.Data.VerificationPolicy.Rules[0].Signers = []string{"missing"}
+		err := p.verifyBundleBeforeExecution(p.RootContext, opts)
+		require.ErrorContains(t, err, `invalid verification-policy in the config file`)
+		require.ErrorContains(t, err, `signer "missing" not defined`)
+	})
+
+	t.Run("rule identity", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		var created []config.SigningPlugin
+		p.newSigner = func(signer config.SigningPlugin) signing.Signer {
+			created = append(created, signer)
+			return signing.NewTestSigningProvider()
+		}
+		p.Data.VerificationPolicy.Rules[0].Identities = []config.VerificationIdentity{
+			{Signer: "myorg", Config: map[string]interface{}{"publickey": "myorg.pub"}},
+		}
+
+		sign(t, p, "myorg", bundleRef)
+		sign(t, p, "release", bundleRef)
+		err := p.verifyBundleBeforeExecution(p.RootContext, opts)
+		require.ErrorContains(t, err, "unable to verify signature with the myorg signer", "the rule should verify with a signer that trusts its own identity")
+		require.NotContains(t, err.Error(), "release signer", "signers without an identity should be shared with the rest of the policy")
+
+		require.Len(t, created, 3)
+		assert.Equal(t, "myorg", created[2].Name)
+		assert.Equal(t, map[string]interface{}{"publickey": "myorg.pub"}, created[2].Config)
+	})
+
+	t.Run("local bundle", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		opts.bundleRef = nil
+		opts.CNABFile = "/bundle.json"
+		p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "/bundle.json")
+
+		err := p.verifyBundleBeforeExecution(p.RootContext, opts)
+		require.ErrorContains(t, err, "the bundle was not pulled from a registry and no verification policy rule allows local bundles")
+
+		p.Data.VerificationPolicy.Rules = append(p.Data.VerificationPolicy.Rules, config.VerificationRule{Name: "dev", Local: true, AllowUnsigned: true})
+		require.NoError(t, p.verifyBundleBeforeExecution(p.RootContext, opts))
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Skipping signature verification because the bundle was not pulled from a registry and the dev verification policy rule allows local bundles")
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		p, opts := setup(t)
+		defer p.Close()
+
+		p.Data.VerificationPolicy = config.VerificationPolicy{}
+		require.NoError(t, p.verifyBundleBeforeExecution(p.RootContext, opts), "verification should be skipped without a policy or --verify-bundle")
+
+		opts.VerifyBundleBeforeExecution = true
+		err := p.verifyBundleBeforeExecution(p.RootContext, opts)
+		require.ErrorContains(t, err, "bundle "+bundleRef+": unable to verify signature")
+
+		require.NoError(t, p.Signer.Sign(context.Background(), bundleRef))
+		require.NoError(t, p.Signer.Sign(context.Background(), imageRef))
+		require.NoError(t, p.verifyBundleBeforeExecution(p.RootContext, opts))
+	})
+}
+
+func TestPorter_PrintVerifyBundle(t *testing.T) {
+	cxt := portercontext.New()
+	bun, err := cnab.LoadBundle(cxt, filepath.Join("testdata/bundle.json"))
+	require.NoError(t, err)
+
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestCache.FindBundleMock = func(ref cnab.OCIReference) (cache.CachedBundle, bool, error) {
+		return cache.CachedBundle{BundleReference: cnab.BundleReference{Reference: ref, Definition: bun}}, true, nil
+	}
+	p.Data.VerificationPolicy = config.VerificationPolicy{
+		Rules: []config.VerificationRule{
+			{Name: "examples", Repositories: []string{"ghcr.io/getporter/examples/*"}, AllowUnsigned: true},
+		},
+	}
+
+	opts := VerifyOptions{}
+	require.NoError(t, opts.Validate([]string{"ghcr.io/getporter/examples/porter-hello:v0.2.0"}))
+	err = p.PrintVerifyBundle(p.RootContext, opts)
+	require.ErrorContains(t, err, "the bundle ghcr.io/getporter/examples/porter-hello:v0.2.0 does not satisfy the verification policy")
+
+	output := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, output, "ghcr.io/getporter/examples/porter-hello:v0.2.0")
+	assert.Contains(t, output, "unsigned allowed")
+	assert.Contains(t, output, "failed: no verification policy")
+	assert.Contains(t, output, "docker.io/getporter/porter-hello-installer", "the policy should be matched against the fully qualified repository")
+}
@@ -0,0 +1,365 @@
+package porter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+const (
+	// watchPollInterval is how often porter build --watch checks the build context for changes.
+	watchPollInterval = 500 * time.Millisecond
+
+	// watchDebounce is how long porter build --watch waits after the last change before rebuilding,
+	// so that saving several files at once only triggers a single build.
+	watchDebounce = time.Second
+)
+
+// buildStage is the part of the build that must be run again after the bundle changes.
+type buildStage int
+
+const (
+	// buildStageNone means that the changes do not affect the bundle.
+	buildStageNone buildStage = iota
+
+	// buildStageDefinition validates and lints the manifest and regenerates bundle.json, without building the bundle image.
+	buildStageDefinition
+
+	// buildStageImage runs the entire build, including building the bundle image.
+	buildStageImage
+)
+
+// fileState is used to detect when a file in the build context changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// buildWatcher rebuilds the bundle when the files in the build context change.
+type buildWatcher struct {
+	*Porter
+
+	opts         BuildOptions
+	pollInterval time.Duration
+	debounce     time.Duration
+
+	// builtManifest is the contents of the user's manifest the last time that
+	// the bundle image was built successfully.
+	builtManifest []byte
+}
+
+// BuildWatch builds the bundle, and then rebuilds it each time that the
+// manifest, Dockerfile template or files in the build context change, until
+// the context is cancelled.
+func (p *Porter) BuildWatch(ctx context.Context, opts BuildOptions) error {
+	w := &buildWatcher{
+		Porter:       p,
+		opts:         opts,
+		pollInterval: watchPollInterval,
+		debounce:     watchDebounce,
+	}
+	return w.Run(ctx)
+}
+
+func (w *buildWatcher) Run(ctx context.Context) error {
+	files, err := w.snapshot()
+	if err != nil {
+		return err
+	}
+
+	w.rebuild(ctx, buildStageImage)
+	fmt.Fprintf(w.Out, "Watching %s for changes, press Ctrl+C to stop...\n", w.opts.Dir)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	var changed []string
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := w.snapshot()
+		if err != nil {
+			fmt.Fprintf(w.Err, "Unable to check the build context for changes: %s\n", err)
+			continue
+		}
+
+		// Wait until the files stop changing before rebuilding
+		if diff := diffSnapshots(files, current); len(diff) > 0 {
+			files = current
+			changed = mergeChanges(changed, diff)
+			lastChange = time.Now()
+			continue
+		}
+		if len(changed) == 0 || time.Since(lastChange) < w.debounce {
+			continue
+		}
+
+		fmt.Fprintf(w.Out, "\nDetected changes to %s\n", strings.Join(changed, ", "))
+		w.rebuild(ctx, w.determineStage(changed))
+		changed = nil
+	}
+}
+
+// rebuild runs the stage of the build, printing the result instead of
+// returning an error so that the bundle author can fix it and save again.
+func (w *buildWatcher) rebuild(ctx context.Context, stage buildStage) {
+	var err error
+	switch stage {
+	case buildStageNone:
+		fmt.Fprintln(w.Out, "The changed files are not used by the bundle, skipping the build")
+		return
+	case buildStageDefinition:
+		fmt.Fprintln(w.Out, "Only the steps in the manifest changed, regenerating the bundle definition without building the bundle image ===>")
+		err = w.buildBundleDefinition(ctx, w.opts)
+	default:
+		fmt.Fprintln(w.Out, "Building bundle ===>")
+		var manifestData []byte
+		manifestData, err = w.FileSystem.ReadFile(w.opts.File)
+		if err == nil {
+			err = w.Build(ctx, w.opts)
+		}
+		if err == nil {
+			w.builtManifest = manifestData
+		} else {
+			// Build the image again after the next change, since we don't know what it was built with
+			w.builtManifest = nil
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(w.Err, "Build failed: %s\n", err)
+		return
+	}
+	fmt.Fprintln(w.Out, "Build succeeded")
+}
+
+// determineStage decides how much of the build is run again for the changed files.
+func (w *buildWatcher) determineStage(changed []string) buildStage {
+	if w.builtManifest == nil {
+		return buildStageImage
+	}
+
+	manifestData, err := w.FileSystem.ReadFile(w.opts.File)
+	if err != nil {
+		// Let the build report the problem with the manifest
+		return buildStageImage
+	}
+	m, err := manifest.UnmarshalManifest(w.Context, manifestData)
+	if err != nil || len(m.Include) > 0 {
+		// Included files may be changed anywhere, so rebuild everything
+		return buildStageImage
+	}
+
+	ignore, err := w.readDockerignore()
+	if err != nil {
+		return buildStageImage
+	}
+
+	manifestPath, _ := filepath.Rel(w.opts.Dir, w.opts.File)
+	manifestChanged := false
+	for _, path := range changed {
+		if path == manifestPath {
+			manifestChanged = true
+			continue
+		}
+
+		// Files excluded by .dockerignore are not copied into the bundle image, except the Dockerfile template
+		if ignore != nil && path != filepath.Clean(m.Dockerfile) {
+			if ignored, _ := ignore.MatchesOrParentMatches(path); ignored {
+				continue
+			}
+		}
+		return buildStageImage
+	}
+
+	if !manifestChanged {
+		return buildStageNone
+	}
+
+	builtManifest, err := manifest.UnmarshalManifest(w.Context, w.builtManifest)
+	if err != nil || !reflect.DeepEqual(withoutSteps(*builtManifest), withoutSteps(*m)) {
+		return buildStageImage
+	}
+	return buildStageDefinition
+}
+
+// withoutSteps returns a copy of the manifest without the steps of its actions,
+// so that manifests which only have different steps are equal.
+func withoutSteps(m manifest.Manifest) manifest.Manifest {
+	m.Install = nil
+	m.Upgrade = nil
+	m.Uninstall = nil
+	m.CustomActions = nil
+	return m
+}
+
+// readDockerignore reads the patterns in the .dockerignore file of the build context.
+func (w *buildWatcher) readDockerignore() (*patternmatcher.PatternMatcher, error) {
+	f, err := w.FileSystem.Open(filepath.Join(w.opts.Dir, ".dockerignore"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return patternmatcher.New(patterns)
+}
+
+// snapshot records the state of the files in the build context, keyed by their path relative to the build context.
+// The files generated by porter build are not included.
+func (w *buildWatcher) snapshot() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := w.FileSystem.Walk(w.opts.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(w.opts.Dir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if relPath == build.LOCAL_CNAB || relPath == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if relPath == mixin.LockFileName {
+			return nil
+		}
+
+		files[relPath] = fileState{modTime: info.ModTime(), size: info.Size(), mode: info.Mode()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading the build context %s: %w", w.opts.Dir, err)
+	}
+	return files, nil
+}
+
+// diffSnapshots returns the sorted paths of the files that were added, removed or modified.
+func diffSnapshots(before map[string]fileState, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// mergeChanges adds the newly changed paths to the paths that have not been built yet.
+func mergeChanges(changed []string, diff []string) []string {
+	for _, path := range diff {
+		if !stringSliceContains(changed, path) {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// buildBundleDefinition validates and lints the manifest, and regenerates the
+// canonical manifest and bundle.json without building the bundle image. The
+// stamp keeps the digest of the manifest that the bundle image was built with,
+// so the bundle is still out-of-date and its image is rebuilt before the bundle
+// is run or published. Until then, the bundle.json references the bundle image
+// and platforms from the previous build.
+func (p *Porter) buildBundleDefinition(ctx context.Context, opts BuildOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	prevBun, err := cnab.LoadBundle(p.Context, build.LOCAL_BUNDLE)
+	if err != nil {
+		return span.Errorf("the bundle must be built before its definition can be regenerated: %w", err)
+	}
+	prevStamp, err := configadapter.LoadStamp(prevBun)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	m, err := p.loadBuildManifest(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if !opts.NoLint {
+		if err := p.preLint(ctx, opts.File); err != nil {
+			return err
+		}
+	}
+
+	mixins, err := p.getUsedMixins(ctx, m)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	// The bundle image wasn't rebuilt, so keep referencing the image that was built previously
+	var prevImage *bundle.InvocationImage
+	imageDigests := map[string]string{m.Image: ""}
+	for i, img := range prevBun.InvocationImages {
+		if img.Image == m.Image {
+			prevImage = &prevBun.InvocationImages[i]
+			imageDigests[m.Image] = img.Digest
+			break
+		}
+	}
+
+	converter := configadapter.NewManifestConverter(p.Config, m, imageDigests, mixins, opts.PreserveTags)
+	bun, err := converter.ToBundle(ctx)
+	if err != nil {
+		return span.Error(fmt.Errorf("unable to build bundle: %w", err))
+	}
+	if prevImage != nil {
+		for i := range bun.InvocationImages {
+			if bun.InvocationImages[i].Image == prevImage.Image {
+				bun.InvocationImages[i].MediaType = prevImage.MediaType
+			}
+		}
+	}
+
+	stamp, err := configadapter.LoadStamp(bun)
+	if err != nil {
+		return span.Error(err)
+	}
+	stamp.ManifestDigest = prevStamp.ManifestDigest
+	stamp.Platforms = prevStamp.Platforms
+	bun.Custom[config.CustomPorterKey] = stamp
+
+	return p.writeBundle(bun)
+}
@@ -0,0 +1,168 @@
+package porter
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab"
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildWatcher_determineStage(t *testing.T) {
+	editManifest := func(t *testing.T, p *TestPorter, old string, new string) {
+		data, err := p.FileSystem.ReadFile(config.Name)
+		require.NoError(t, err)
+		require.Contains(t, string(data), old)
+		data = []byte(strings.Replace(string(data), old, new, 1))
+		require.NoError(t, p.FileSystem.WriteFile(config.Name, data, pkg.FileModeWritable))
+	}
+
+	testcases := []struct {
+		name      string
+		notBuilt  bool
+		edit      func(t *testing.T, p *TestPorter)
+		changed   []string
+		wantStage buildStage
+	}{
+		{name: "not built", notBuilt: true, changed: []string{"helpers.sh"}, wantStage: buildStageImage},
+		{name: "bundle file", changed: []string{"helpers.sh"}, wantStage: buildStageImage},
+		{name: "dockerfile template", changed: []string{"Dockerfile.tmpl"}, wantStage: buildStageImage},
+		{name: "ignored file", changed: []string{"notes/todo.txt"}, wantStage: buildStageNone},
+		{name: "steps", edit: func(t *testing.T, p *TestPorter) {
+			editManifest(t, p, "Check the docker socket", "Check the docker socket again")
+		}, changed: []string{config.Name}, wantStage: buildStageDefinition},
+		{name: "mixins", edit: func(t *testing.T, p *TestPorter) {
+			editManifest(t, p, "clientVersion: 1.2.3", "clientVersion: 1.2.4")
+		}, changed: []string{config.Name}, wantStage: buildStageImage},
+		{name: "steps and bundle file", edit: func(t *testing.T, p *TestPorter) {
+			editManifest(t, p, "Check the docker socket", "Check the docker socket again")
+		}, changed: []string{config.Name, "helpers.sh"}, wantStage: buildStageImage},
+		{name: "manifest with includes", edit: func(t *testing.T, p *TestPorter) {
+			editManifest(t, p, "install:", "include:\n  - notes/steps.yaml\n\ninstall:")
+		}, changed: []string{"notes/steps.yaml"}, wantStage: buildStageImage},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			defer p.Close()
+
+			p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+			require.NoError(t, p.FileSystem.WriteFile(".dockerignore", []byte("notes/\nDockerfile.tmpl\n"), pkg.FileModeWritable))
+
+			opts := BuildOptions{}
+			require.NoError(t, opts.Validate(p.Porter))
+
+			w := &buildWatcher{Porter: p.Porter, opts: opts}
+			if !tc.notBuilt {
+				data, err := p.FileSystem.ReadFile(config.Name)
+				require.NoError(t, err)
+				w.builtManifest = data
+			}
+			if tc.edit != nil {
+				tc.edit(t, p)
+			}
+
+			assert.Equal(t, tc.wantStage, w.determineStage(tc.changed))
+		})
+	}
+}
+
+func TestBuildWatcher_snapshot(t *testing.T) {
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+	opts := BuildOptions{}
+	require.NoError(t, opts.Validate(p.Porter))
+	w := &buildWatcher{Porter: p.Porter, opts: opts}
+
+	before, err := w.snapshot()
+	require.NoError(t, err)
+	assert.Contains(t, before, "helpers.sh")
+
+	// Files generated by porter build are not watched
+	require.NoError(t, p.FileSystem.WriteFile(filepath.Join(build.LOCAL_CNAB, "bundle.json"), []byte("{}"), pkg.FileModeWritable))
+	require.NoError(t, p.FileSystem.WriteFile("porter.lock", []byte("mixins: []"), pkg.FileModeWritable))
+	// Edit, add and remove files in the build context
+	require.NoError(t, p.FileSystem.Chtimes("helpers.sh", time.Now(), time.Now().Add(time.Minute)))
+	require.NoError(t, p.FileSystem.WriteFile("scripts/setup.sh", []byte("#!/bin/sh"), pkg.FileModeExecutable))
+	require.NoError(t, p.FileSystem.Remove("Dockerfile.tmpl"))
+
+	after, err := w.snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dockerfile.tmpl", "helpers.sh", "scripts/setup.sh"}, diffSnapshots(before, after))
+	assert.Empty(t, diffSnapshots(after, after))
+}
+
+func TestPorter_buildBundleDefinition(t *testing.T) {
+	ctx := context.Background()
+	p := NewTestPorter(t)
+	defer p.Close()
+
+	p.TestConfig.TestContext.AddTestDirectoryFromRoot("tests/testdata/mybuns", p.BundleDir)
+	opts := BuildOptions{}
+	require.NoError(t, opts.Validate(p.Porter))
+	require.NoError(t, p.Build(ctx, opts))
+
+	bun, err := cnab.LoadBundle(p.Context, build.LOCAL_BUNDLE)
+	require.NoError(t, err)
+	// Pretend that the bundle image was built
+	bundleImage := bun.InvocationImages[0].Image
+	p.TestRegistry.MockGetCachedImage = func(ctx context.Context, ref cnab.OCIReference) (cnabtooci.ImageMetadata, error) {
+		if ref.String() == bundleImage {
+			return mockGetCachedImage(ctx, ref)
+		}
+		return cnabtooci.ImageMetadata{}, cnabtooci.ErrNotFound{Reference: ref}
+	}
+	builtStamp, err := configadapter.LoadStamp(bun)
+	require.NoError(t, err)
+
+	// Pretend that the bundle image was built for multiple platforms
+	builtImage := bun.InvocationImages[0]
+	builtImage.Digest = "sha256:276b44be3f478b4c8d1f99c1925386d45a878a853f22436ece5589f32e9df384"
+	builtImage.MediaType = "application/vnd.oci.image.index.v1+json"
+	bun.InvocationImages[0] = builtImage
+	builtStamp.Platforms = []string{"linux/amd64", "linux/arm64"}
+	bun.Custom[config.CustomPorterKey] = builtStamp
+	require.NoError(t, p.writeBundle(bun))
+
+	upToDate, err := p.IsBundleUpToDate(ctx, opts.BundleDefinitionOptions)
+	require.NoError(t, err)
+	require.True(t, upToDate, "the bundle should be up-to-date after it is built")
+
+	// Change a step
+	data, err := p.FileSystem.ReadFile(config.Name)
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "Check the docker socket", "Check the docker socket again", 1))
+	require.NoError(t, p.FileSystem.WriteFile(config.Name, data, pkg.FileModeWritable))
+
+	require.NoError(t, p.buildBundleDefinition(ctx, opts))
+
+	bun, err = cnab.LoadBundle(p.Context, build.LOCAL_BUNDLE)
+	require.NoError(t, err)
+	stamp, err := configadapter.LoadStamp(bun)
+	require.NoError(t, err)
+	embeddedManifest, err := stamp.DecodeManifest()
+	require.NoError(t, err)
+	assert.Contains(t, string(embeddedManifest), "Check the docker socket again", "the bundle definition should be regenerated")
+	assert.Equal(t, builtStamp.ManifestDigest, stamp.ManifestDigest, "the stamp should keep the digest of the manifest that the bundle image was built with")
+	assert.Equal(t, builtStamp.Platforms, stamp.Platforms, "the stamp should keep the platforms that the bundle image was built for")
+	assert.Equal(t, builtImage, bun.InvocationImages[0], "the bundle should reference the bundle image that was built")
+
+	canonicalManifest, err := p.FileSystem.ReadFile(build.LOCAL_MANIFEST)
+	require.NoError(t, err)
+	assert.Contains(t, string(canonicalManifest), "Check the docker socket again", "the canonical manifest should be regenerated")
+
+	upToDate, err = p.IsBundleUpToDate(ctx, opts.BundleDefinitionOptions)
+	require.NoError(t, err)
+	assert.False(t, upToDate, "the bundle image should be rebuilt before the bundle is used")
+}
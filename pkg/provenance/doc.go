@@ -0,0 +1,6 @@
+// Package provenance creates in-toto (https://in-toto.io) statements with a
+// SLSA provenance (https://slsa.dev/provenance/v1) predicate that describe how
+// a bundle was built: the porter.yaml it was built from, the versions of Porter
+// and the mixins that were used, the build arguments, and the digests of the
+// resulting bundle and bundle image.
+package provenance
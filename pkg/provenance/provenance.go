@@ -0,0 +1,193 @@
+package provenance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	// StatementType is the type of an in-toto statement.
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// PredicateType is the type of a SLSA provenance predicate.
+	PredicateType = "https://slsa.dev/provenance/v1"
+
+	// BuildType identifies how Porter builds a bundle, and defines the
+	// meaning of the external and internal parameters.
+	BuildType = "https://porter.sh/provenance/build/v1"
+
+	// BuilderID identifies Porter as the builder.
+	BuilderID = "https://porter.sh/porter"
+
+	// MediaType is the media type of an in-toto statement, and the artifact
+	// type of a provenance statement that is attached to a bundle.
+	MediaType = "application/vnd.in-toto+json"
+
+	// AnnotationPredicateType is the annotation on an attached statement
+	// that identifies the type of its predicate.
+	AnnotationPredicateType = "in-toto.io/predicate-type"
+)
+
+// Statement is an in-toto statement about a set of artifacts, the subjects.
+type Statement struct {
+	Type          string    `json:"_type" yaml:"_type"`
+	Subject       []Subject `json:"subject" yaml:"subject"`
+	PredicateType string    `json:"predicateType" yaml:"predicateType"`
+	Predicate     Predicate `json:"predicate" yaml:"predicate"`
+}
+
+// Subject is an artifact described by a statement, identified by its digest.
+type Subject struct {
+	Name   string            `json:"name" yaml:"name"`
+	Digest map[string]string `json:"digest" yaml:"digest"`
+}
+
+// Predicate is a SLSA provenance predicate.
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition" yaml:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails" yaml:"runDetails"`
+}
+
+// BuildDefinition describes the inputs of the build.
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType" yaml:"buildType"`
+	ExternalParameters   ExternalParameters   `json:"externalParameters" yaml:"externalParameters"`
+	InternalParameters   InternalParameters   `json:"internalParameters" yaml:"internalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty" yaml:"resolvedDependencies,omitempty"`
+}
+
+// ExternalParameters are the inputs of the build that were specified by the user.
+type ExternalParameters struct {
+	// Manifest is the porter.yaml that the bundle was built from.
+	Manifest ResourceDescriptor `json:"manifest" yaml:"manifest"`
+
+	// Reference that the bundle was published to.
+	Reference string `json:"reference" yaml:"reference"`
+
+	// BuildArgs are the build arguments, in the form NAME=VALUE.
+	BuildArgs []string `json:"buildArgs,omitempty" yaml:"buildArgs,omitempty"`
+
+	// BuildContexts are the additional build contexts, in the form NAME=PATH.
+	BuildContexts []string `json:"buildContexts,omitempty" yaml:"buildContexts,omitempty"`
+
+	// Secrets are the ids of the secrets exposed to the build.
+	Secrets []string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+
+	// SSH are the ids of the SSH agent sockets or keys exposed to the build.
+	SSH []string `json:"ssh,omitempty" yaml:"ssh,omitempty"`
+
+	// NoCache indicates that the build did not use the cache.
+	NoCache bool `json:"noCache,omitempty" yaml:"noCache,omitempty"`
+
+	// Platforms are the platforms that the bundle image was built for.
+	Platforms []string `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+}
+
+// InternalParameters are the inputs of the build that were chosen by Porter.
+type InternalParameters struct {
+	// BuildDriver is the driver that built the bundle image.
+	BuildDriver string `json:"buildDriver,omitempty" yaml:"buildDriver,omitempty"`
+
+	// ManifestDigest is the digest of the manifest, including the mixins and
+	// Porter version, that Porter uses to decide if the bundle must be rebuilt.
+	ManifestDigest string `json:"manifestDigest,omitempty" yaml:"manifestDigest,omitempty"`
+}
+
+// ResourceDescriptor identifies an artifact that was used by the build.
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty" yaml:"name,omitempty"`
+	URI    string            `json:"uri,omitempty" yaml:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty" yaml:"digest,omitempty"`
+}
+
+// RunDetails describes the build that was run.
+type RunDetails struct {
+	Builder  Builder       `json:"builder" yaml:"builder"`
+	Metadata BuildMetadata `json:"metadata" yaml:"metadata"`
+}
+
+// Builder identifies the tool that ran the build.
+type Builder struct {
+	ID      string            `json:"id" yaml:"id"`
+	Version map[string]string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// BuildMetadata records when the build ran.
+type BuildMetadata struct {
+	StartedOn  *time.Time `json:"startedOn,omitempty" yaml:"startedOn,omitempty"`
+	FinishedOn *time.Time `json:"finishedOn,omitempty" yaml:"finishedOn,omitempty"`
+}
+
+// NewSubject creates a subject for an artifact with the specified digest.
+func NewSubject(name string, d digest.Digest) Subject {
+	return Subject{Name: name, Digest: DigestSet(d)}
+}
+
+// DigestSet converts a digest to the digest set used by in-toto, which maps
+// the algorithm to the encoded digest, e.g. {"sha256": "abc..."}.
+func DigestSet(d digest.Digest) map[string]string {
+	if d == "" {
+		return nil
+	}
+	return map[string]string{d.Algorithm().String(): d.Encoded()}
+}
+
+// New creates a provenance statement about the subjects.
+func New(subjects []Subject, def BuildDefinition, run RunDetails) Statement {
+	def.BuildType = BuildType
+	run.Builder.ID = BuilderID
+	return Statement{
+		Type:          StatementType,
+		Subject:       subjects,
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: def,
+			RunDetails:      run,
+		},
+	}
+}
+
+// Marshal the statement to JSON.
+func (s Statement) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling the provenance statement: %w", err)
+	}
+	return data, nil
+}
+
+// Parse a provenance statement, checking that it is an in-toto statement
+// with a SLSA provenance predicate.
+func Parse(data []byte) (Statement, error) {
+	var s Statement
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Statement{}, fmt.Errorf("error parsing the provenance statement: %w", err)
+	}
+	if s.Type != StatementType {
+		return Statement{}, fmt.Errorf("unsupported statement type %q, expected %s", s.Type, StatementType)
+	}
+	if s.PredicateType != PredicateType {
+		return Statement{}, fmt.Errorf("unsupported predicate type %q, expected %s", s.PredicateType, PredicateType)
+	}
+	if len(s.Subject) == 0 {
+		return Statement{}, errors.New("the provenance statement does not have a subject")
+	}
+	return s, nil
+}
+
+// HasSubject returns true when one of the subjects of the statement has the specified digest.
+func (s Statement) HasSubject(d digest.Digest) bool {
+	if d == "" {
+		return false
+	}
+	for _, subject := range s.Subject {
+		if subject.Digest[d.Algorithm().String()] == d.Encoded() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package provenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	bundleDigest := digest.FromString("bundle")
+	imageDigest := digest.FromString("image")
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s := New(
+		[]Subject{
+			NewSubject("example.com/mybuns", bundleDigest),
+			NewSubject("example.com/mybuns", imageDigest),
+		},
+		BuildDefinition{
+			ExternalParameters: ExternalParameters{
+				Manifest:  ResourceDescriptor{URI: "porter.yaml", Digest: DigestSet(digest.FromString("porter.yaml"))},
+				Reference: "example.com/mybuns:v0.1.0",
+				BuildArgs: []string{"A=1"},
+			},
+		},
+		RunDetails{
+			Builder:  Builder{Version: map[string]string{"porter": "v1.0.0"}},
+			Metadata: BuildMetadata{StartedOn: &started},
+		})
+
+	assert.Equal(t, StatementType, s.Type)
+	assert.Equal(t, PredicateType, s.PredicateType)
+	assert.Equal(t, BuildType, s.Predicate.BuildDefinition.BuildType)
+	assert.Equal(t, BuilderID, s.Predicate.RunDetails.Builder.ID)
+	assert.Equal(t, bundleDigest.Encoded(), s.Subject[0].Digest["sha256"])
+
+	data, err := s.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"_type": "https://in-toto.io/Statement/v1"`)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, s, parsed)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not json", data: "{", wantErr: "error parsing the provenance statement"},
+		{name: "wrong statement type", data: `{"_type": "oops", "predicateType": "https://slsa.dev/provenance/v1"}`, wantErr: "unsupported statement type"},
+		{name: "wrong predicate type", data: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "oops"}`, wantErr: "unsupported predicate type"},
+		{name: "no subject", data: `{"_type": "https://in-toto.io/Statement/v1", "predicateType": "https://slsa.dev/provenance/v1"}`, wantErr: "does not have a subject"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestStatement_HasSubject(t *testing.T) {
+	s := New([]Subject{NewSubject("mybuns", digest.FromString("bundle"))}, BuildDefinition{}, RunDetails{})
+
+	assert.True(t, s.HasSubject(digest.FromString("bundle")))
+	assert.False(t, s.HasSubject(digest.FromString("other")))
+	assert.False(t, s.HasSubject(""))
+}
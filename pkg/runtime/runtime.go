@@ -92,12 +92,19 @@ func (r *PorterRuntime) executeStep(ctx context.Context, stepIndex int, step *ma
 	if step == nil {
 		return nil
 	}
-	err := r.RuntimeManifest.ResolveStep(ctx, stepIndex, step)
+	run, err := r.RuntimeManifest.ResolveStep(ctx, stepIndex, step)
 	if err != nil {
 		return fmt.Errorf("unable to resolve step: %w", err)
 	}
 
 	description, _ := step.GetDescription()
+	if !run {
+		if len(description) == 0 {
+			description = fmt.Sprintf("%s step %d", step.GetMixinName(), stepIndex+1)
+		}
+		fmt.Fprintf(r.config.Out, "Skipping %s because its if condition is false\n", description)
+		return nil
+	}
 	if len(description) > 0 {
 		fmt.Fprintln(r.config.Out, description)
 	}
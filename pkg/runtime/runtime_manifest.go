@@ -477,13 +477,15 @@ func (m *RuntimeManifest) buildAndResolveMappedDependencyOutputs(sourceData map[
 
 // ResolveStep will walk through the Step's data and resolve any placeholder
 // data using the definitions in the manifest, like parameters or credentials.
-func (m *RuntimeManifest) ResolveStep(ctx context.Context, stepIndex int, step *manifest.Step) error {
+// It returns false, without resolving the step, when the step's if condition
+// is false and the step should be skipped.
+func (m *RuntimeManifest) ResolveStep(ctx context.Context, stepIndex int, step *manifest.Step) (bool, error) {
 	log := tracing.LoggerFromContext(ctx)
 
 	// Refresh our template data
 	sourceData, err := m.buildSourceData()
 	if err != nil {
-		return log.Error(fmt.Errorf("unable to build step template data: %w", err))
+		return false, log.Error(fmt.Errorf("unable to build step template data: %w", err))
 	}
 
 	mustache.AllowMissingVariables = false
@@ -491,15 +493,28 @@ func (m *RuntimeManifest) ResolveStep(ctx context.Context, stepIndex int, step *
 	if m.config.IsFeatureEnabled(experimental.FlagDependenciesV2) {
 		err = m.buildAndResolveMappedDependencyOutputs(sourceData)
 		if err != nil {
-			return log.Errorf("unable to build and resolve mapped dependency outputs: %w", err)
+			return false, log.Errorf("unable to build and resolve mapped dependency outputs: %w", err)
 		}
 	}
 
-	// Get the original yaml for the current step
 	stepPath := fmt.Sprintf("%s[%d]", m.Action, stepIndex)
+	if step.If != "" {
+		run, err := m.evaluateStepCondition(step.If, sourceData)
+		if err != nil {
+			return false, log.Error(fmt.Errorf("unable to evaluate the if condition %q for step %s: %w", step.If, stepPath, err))
+		}
+		if !run {
+			return false, nil
+		}
+
+		// The condition is only used by porter, don't pass it to the mixin
+		step.If = ""
+	}
+
+	// Get the original yaml for the current step
 	stepTemplate, err := m.getStepTemplate(stepPath)
 	if err != nil {
-		return log.Error(fmt.Errorf("unable to retrieve original yaml for step %s: %w", stepPath, err))
+		return false, log.Error(fmt.Errorf("unable to retrieve original yaml for step %s: %w", stepPath, err))
 	}
 
 	// TODO: add back logging step data after we have a solid way to censor it in https://github.com/getporter/porter/issues/2256
@@ -508,7 +523,7 @@ func (m *RuntimeManifest) ResolveStep(ctx context.Context, stepIndex int, step *
 
 	rendered, err := mustache.RenderRaw(stepTemplate, true, sourceData)
 	if err != nil {
-		return log.Errorf("unable to render step template %s: %w", stepTemplate, err)
+		return false, log.Errorf("unable to render step template %s: %w", stepTemplate, err)
 	}
 
 	// TODO: add back logging step data after we have a solid way to censor it in https://github.com/getporter/porter/issues/2256
@@ -517,10 +532,27 @@ func (m *RuntimeManifest) ResolveStep(ctx context.Context, stepIndex int, step *
 	// Update the step parameter with the result of rendering the template
 	err = yaml.Unmarshal([]byte(rendered), step)
 	if err != nil {
-		return log.Error(fmt.Errorf("invalid step yaml after rendering template\n%s: %w", stepTemplate, err))
+		return false, log.Error(fmt.Errorf("invalid step yaml after rendering template\n%s: %w", stepTemplate, err))
 	}
 
-	return nil
+	return true, nil
+}
+
+// evaluateStepCondition renders the templating used in the if condition of a
+// step, and returns if the step should be run.
+func (m *RuntimeManifest) evaluateStepCondition(condition string, sourceData map[string]interface{}) (bool, error) {
+	c, err := manifest.ParseStepCondition(condition)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Evaluate(func(operand string) (string, error) {
+		// Variables that are not set, such as an output from a step that was skipped, are empty
+		mustache.AllowMissingVariables = true
+		defer func() { mustache.AllowMissingVariables = false }()
+
+		return mustache.RenderRaw(m.GetTemplatePrefix()+operand, true, sourceData)
+	})
 }
 
 // Initialize prepares the runtime environment prior to step execution
@@ -894,6 +926,19 @@ func (m *RuntimeManifest) getStepTemplate(stepPath string) (string, error) {
 		return "", fmt.Errorf("unable to retrieve original yaml for step %s: %w", stepPath, err)
 	}
 
+	// The if condition is evaluated before the step is rendered
+	if stepNode.Kind == yaml3.MappingNode {
+		stepWithoutCondition := *stepNode
+		stepWithoutCondition.Content = nil
+		for i := 0; i+1 < len(stepNode.Content); i += 2 {
+			if stepNode.Content[i].Value == "if" {
+				continue
+			}
+			stepWithoutCondition.Content = append(stepWithoutCondition.Content, stepNode.Content[i], stepNode.Content[i+1])
+		}
+		stepNode = &stepWithoutCondition
+	}
+
 	var stepYAML bytes.Buffer
 	enc := yaml3.NewEncoder(&stepYAML)
 	defer enc.Close()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"testing"
 
 	"get.porter.sh/porter/pkg"
@@ -54,7 +55,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -129,7 +130,7 @@ state:
 			require.NoError(t, testConfig.FileSystem.WriteFile("/porter/state.tgz", []byte(test.stateContent), pkg.FileModeWritable))
 			s := rm.Install[0]
 
-			err := rm.ResolveStep(ctx, 0, s)
+			_, err := rm.ResolveStep(ctx, 0, s)
 			require.NoError(t, err)
 
 			err = rm.Initialize(ctx)
@@ -163,7 +164,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -187,7 +188,7 @@ func TestMetadataAvailableForTemplating(t *testing.T) {
 	rm := NewRuntimeManifest(cfg, cnab.ActionInstall, m)
 
 	s := rm.Install[0]
-	err = rm.ResolveStep(ctx, 0, s)
+	_, err = rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	pms, ok := s.Data["exec"].(map[string]interface{})
@@ -214,7 +215,7 @@ func TestDependencyMetadataAvailableForTemplating(t *testing.T) {
 	}
 
 	s := rm.Install[0]
-	err = rm.ResolveStep(ctx, 0, s)
+	_, err = rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	pms, ok := s.Data["exec"].(map[string]interface{})
@@ -236,7 +237,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.Error(t, err)
 	tests.RequireErrorContains(t, err, "missing variable \"person\"")
 }
@@ -257,7 +258,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), `missing variable "person"`)
 }
@@ -279,7 +280,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -319,7 +320,7 @@ install:
 	// Prior to resolving step values, this method should return an empty string array
 	assert.Equal(t, rm.GetSensitiveValues(), []string{})
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -357,7 +358,7 @@ install:
 	// Prior to resolving step values, this method should return an empty string array
 	assert.Equal(t, rm.GetSensitiveValues(), []string{})
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -418,7 +419,7 @@ install:
 	}
 
 	s := rm.Install[0]
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -459,7 +460,7 @@ install:
 	}
 
 	s := rm.Install[0]
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -514,7 +515,7 @@ install:
 	}
 
 	s := rm.Install[0]
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -569,7 +570,7 @@ install:
 	}
 
 	s := rm.Install[0]
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has incorrect type")
@@ -595,7 +596,7 @@ func TestResolveInMainDict(t *testing.T) {
 	installStep := rm.Install[0]
 
 	rm.config.Setenv("COMMAND", "echo hello world")
-	err = rm.ResolveStep(ctx, 0, installStep)
+	_, err = rm.ResolveStep(ctx, 0, installStep)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, installStep.Data["exec"], "Data.exec has the wrong type")
@@ -622,7 +623,7 @@ func TestResolveSliceWithAMap(t *testing.T) {
 	installStep := rm.Install[0]
 
 	rm.config.Setenv("COMMAND", "echo hello world")
-	err = rm.ResolveStep(ctx, 0, installStep)
+	_, err = rm.ResolveStep(ctx, 0, installStep)
 	require.NoError(t, err)
 
 	require.NotNil(t, installStep.Data)
@@ -646,7 +647,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	tests.RequireErrorContains(t, err, `missing variable "database_url"`)
 }
 
@@ -672,7 +673,7 @@ install:
 	}
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, s.Data["mymixin"], map[string]interface{}{}, "Data.mymixin has the wrong type")
@@ -702,7 +703,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, s.Data["mymixin"], map[string]interface{}{}, "Data.mymixin has the wrong type")
@@ -844,7 +845,7 @@ func TestManifest_ResolveImageMap(t *testing.T) {
 	require.True(t, ok, "couldn't get expected image")
 	expectedRef := fmt.Sprintf("%s@%s", expectedImage.Repository, expectedImage.Digest)
 	step := rm.Install[0]
-	err = rm.ResolveStep(ctx, 0, step)
+	_, err = rm.ResolveStep(ctx, 0, step)
 	assert.NoError(t, err, "Should have successfully resolved step")
 	s := step.Data["searcher"].(map[string]interface{})
 	assert.NotNil(t, s)
@@ -887,7 +888,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	tests.RequireErrorContains(t, err, `missing variable "notsomething"`)
 }
 
@@ -1145,7 +1146,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, s.Data["mymixin"], map[string]interface{}{}, "Data.mymixin has the wrong type")
@@ -1171,7 +1172,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has the wrong type")
@@ -1201,13 +1202,13 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has the wrong type")
 	mixin := s.Data["mymixin"].(map[string]interface{})
 
-	err = rm.ResolveStep(ctx, 0, s)
+	_, err = rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err, "ResolveStep failed")
 
 	assert.Equal(t, "foobar", mixin["release"], "custom metadata was not rendered")
@@ -1230,7 +1231,7 @@ install:
 	rm := runtimeManifestFromStepYaml(t, testConfig, mContent)
 	s := rm.Install[0]
 
-	err := rm.ResolveStep(ctx, 0, s)
+	_, err := rm.ResolveStep(ctx, 0, s)
 	require.NoError(t, err)
 
 	require.IsType(t, map[string]interface{}{}, s.Data["mymixin"], "Data.mymixin has the wrong type")
@@ -1292,3 +1293,65 @@ func TestResolveInvocationImage(t *testing.T) {
 	}
 
 }
+
+func TestResolveStep_If(t *testing.T) {
+	ctx := context.Background()
+
+	mContent := `schemaVersion: 1.0.0
+parameters:
+- name: backupUrl
+  type: string
+  default: ""
+- name: environment
+  type: string
+  default: dev
+
+install:
+- if: CONDITION
+  mymixin:
+    description: Restore the backup
+    Arguments:
+    - ${ bundle.parameters.backupUrl }
+    - ${ bundle.outputs.database_url }
+`
+	testcases := []struct {
+		name      string
+		condition string
+		env       map[string]string
+		wantRun   bool
+	}{
+		{name: "set", condition: "${ bundle.parameters.backupUrl }", env: map[string]string{"BACKUPURL": "https://example.com/backup"}, wantRun: true},
+		{name: "empty", condition: "${ bundle.parameters.backupUrl }", wantRun: false},
+		{name: "false", condition: "${ bundle.parameters.backupUrl }", env: map[string]string{"BACKUPURL": "false"}, wantRun: false},
+		{name: "negated", condition: `"!${ bundle.parameters.backupUrl }"`, wantRun: true},
+		{name: "equal", condition: "${ bundle.parameters.environment } == production", env: map[string]string{"ENVIRONMENT": "production"}, wantRun: true},
+		{name: "not equal", condition: "${ bundle.parameters.environment } != 'production'", env: map[string]string{"ENVIRONMENT": "production"}, wantRun: false},
+		{name: "missing output", condition: "${ bundle.outputs.database_url }", wantRun: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			testConfig := config.NewTestConfig(t)
+			for k, v := range tc.env {
+				testConfig.Setenv(k, v)
+			}
+			rm := runtimeManifestFromStepYaml(t, testConfig, strings.Replace(mContent, "CONDITION", tc.condition, 1))
+			rm.outputs = map[string]string{}
+			if tc.wantRun {
+				rm.outputs["database_url"] = "db.example.com"
+			}
+			s := rm.Install[0]
+
+			run, err := rm.ResolveStep(ctx, 0, s)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRun, run)
+			if !tc.wantRun {
+				return
+			}
+
+			assert.Empty(t, s.If, "the condition should not be passed to the mixin")
+			mixin := s.Data["mymixin"].(map[string]interface{})
+			args := mixin["Arguments"].([]interface{})
+			assert.Equal(t, "db.example.com", args[1], "the step should be resolved")
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/manifest"
@@ -61,6 +62,31 @@ BAZ`,
 	assert.Equal(t, wantOutputs, gotOutputs)
 }
 
+func TestPorterRuntime_executeStep_SkippedByIf(t *testing.T) {
+	ctx := context.Background()
+	r := NewTestPorterRuntime(t)
+
+	mContent := `schemaVersion: 1.0.0
+parameters:
+- name: backupUrl
+  type: string
+  default: ""
+
+install:
+- if: ${ bundle.parameters.backupUrl }
+  mymixin:
+    description: Restore the backup
+`
+	require.NoError(t, r.config.FileSystem.WriteFile("/cnab/app/porter.yaml", []byte(mContent), pkg.FileModeWritable))
+	m, err := manifest.ReadManifest(r.config.Context, "/cnab/app/porter.yaml", r.config.Config)
+	require.NoError(t, err, "ReadManifest failed")
+	r.RuntimeManifest = r.NewRuntimeManifest(cnab.ActionInstall, m)
+
+	err = r.executeStep(ctx, 0, m.Install[0])
+	require.NoError(t, err)
+	assert.Contains(t, r.TestContext.GetOutput(), "Skipping Restore the backup because its if condition is false")
+}
+
 func TestPorterRuntime_ApplyStepOutputsToBundle_None(t *testing.T) {
 	r := NewTestPorterRuntime(t)
 	m := &manifest.Manifest{Name: "mybun"}
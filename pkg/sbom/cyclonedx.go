@@ -0,0 +1,114 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components,omitempty"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef      string    `json:"bom-ref,omitempty"`
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version,omitempty"`
+	Description string    `json:"description,omitempty"`
+	PURL        string    `json:"purl,omitempty"`
+	Hashes      []cdxHash `json:"hashes,omitempty"`
+}
+
+type cdxHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+func generateCycloneDX(inv Inventory) ([]byte, error) {
+	newComponent := func(ref string, componentType string, c Component) cdxComponent {
+		result := cdxComponent{
+			BOMRef:      ref,
+			Type:        componentType,
+			Name:        c.Name,
+			Version:     c.Version,
+			Description: c.Description,
+			PURL:        c.PackageURL(),
+		}
+		if algorithm, value, ok := strings.Cut(c.Digest, ":"); ok && algorithm == "sha256" {
+			result.Hashes = []cdxHash{{Algorithm: "SHA-256", Content: value}}
+		}
+		return result
+	}
+
+	const bundleRef = "bundle"
+	const bundleImageRef = "bundle-image"
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{newComponent("", "application", inv.Tool)},
+			},
+			Component: newComponent(bundleRef, "application", inv.Bundle),
+		},
+	}
+
+	bundleDeps := cdxDependency{Ref: bundleRef, DependsOn: []string{bundleImageRef}}
+	imageDeps := cdxDependency{Ref: bundleImageRef}
+	doc.Components = append(doc.Components, newComponent(bundleImageRef, "container", inv.BundleImage))
+
+	for i, c := range inv.BaseImages {
+		ref := fmt.Sprintf("base-image-%d", i)
+		doc.Components = append(doc.Components, newComponent(ref, "container", c))
+		imageDeps.DependsOn = append(imageDeps.DependsOn, ref)
+	}
+
+	if inv.Runtime.Name != "" {
+		const runtimeRef = "runtime"
+		doc.Components = append(doc.Components, newComponent(runtimeRef, "application", inv.Runtime))
+		imageDeps.DependsOn = append(imageDeps.DependsOn, runtimeRef)
+	}
+
+	for i, c := range inv.Mixins {
+		ref := fmt.Sprintf("mixin-%d", i)
+		doc.Components = append(doc.Components, newComponent(ref, "application", c))
+		imageDeps.DependsOn = append(imageDeps.DependsOn, ref)
+	}
+
+	for i, c := range inv.Images {
+		ref := fmt.Sprintf("image-%d", i)
+		doc.Components = append(doc.Components, newComponent(ref, "container", c))
+		bundleDeps.DependsOn = append(bundleDeps.DependsOn, ref)
+	}
+
+	doc.Dependencies = []cdxDependency{bundleDeps, imageDeps}
+	return json.MarshalIndent(doc, "", "  ")
+}
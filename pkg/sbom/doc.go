@@ -0,0 +1,8 @@
+// Package sbom generates a software bill of materials (SBOM) for a bundle, in
+// either SPDX (https://spdx.dev) or CycloneDX (https://cyclonedx.org) JSON
+// format. The SBOM lists the components that Porter builds the bundle image
+// from: the base images, the Porter runtime and the mixins, along with the
+// images referenced by the bundle. It is not an inventory of the contents of
+// the bundle image, the packages installed in the base images or by the mixins
+// are not cataloged.
+package sbom
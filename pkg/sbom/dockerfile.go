@@ -0,0 +1,43 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// ParseBaseImages returns the images referenced by the FROM instructions in a
+// Dockerfile, skipping references to earlier build stages and scratch. Only the
+// image references are returned, the contents of the images are not inspected.
+func ParseBaseImages(dockerfile []byte) []string {
+	var images []string
+	stages := map[string]bool{"scratch": true}
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(dockerfile))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+
+		// Skip flags such as --platform
+		args := fields[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		image := args[0]
+		if !stages[strings.ToLower(image)] && !seen[image] {
+			images = append(images, image)
+			seen[image] = true
+		}
+		if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+			stages[strings.ToLower(args[2])] = true
+		}
+	}
+	return images
+}
@@ -0,0 +1,151 @@
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+)
+
+const (
+	// FormatSPDX generates an SPDX 2.3 JSON document.
+	FormatSPDX = "spdx"
+
+	// FormatCycloneDX generates a CycloneDX 1.5 JSON document.
+	FormatCycloneDX = "cyclonedx"
+
+	// FormatNone disables generating an SBOM.
+	FormatNone = "none"
+
+	// DefaultFormat is used when no format is specified.
+	DefaultFormat = FormatSPDX
+
+	// SPDXMediaType is the media type of an SPDX JSON document.
+	SPDXMediaType = "application/spdx+json"
+
+	// CycloneDXMediaType is the media type of a CycloneDX JSON document.
+	CycloneDXMediaType = "application/vnd.cyclonedx+json"
+)
+
+// Formats are the supported SBOM formats.
+var Formats = []string{FormatSPDX, FormatCycloneDX, FormatNone}
+
+// MediaTypes are the media types of the supported SBOM formats.
+var MediaTypes = []string{SPDXMediaType, CycloneDXMediaType}
+
+// ValidateFormat checks that the format is supported.
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sbom format %s, allowed values are: %s", format, strings.Join(Formats, ", "))
+}
+
+// MediaType returns the media type of an SBOM in the specified format.
+func MediaType(format string) (string, error) {
+	switch format {
+	case FormatSPDX:
+		return SPDXMediaType, nil
+	case FormatCycloneDX:
+		return CycloneDXMediaType, nil
+	default:
+		return "", fmt.Errorf("no media type is defined for the sbom format %s", format)
+	}
+}
+
+// Component is an item that is described by the SBOM.
+type Component struct {
+	// Name of the component.
+	Name string
+
+	// Version of the component.
+	Version string
+
+	// Description of the component.
+	Description string
+
+	// Reference is the OCI reference of an image.
+	Reference string
+
+	// Digest of an image.
+	Digest string
+}
+
+// PackageURL returns the package URL (https://github.com/package-url/purl-spec)
+// that identifies the component. Images are identified with the oci type and
+// other components with the generic type.
+func (c Component) PackageURL() string {
+	if c.Reference == "" {
+		purl := "pkg:generic/" + purlEscape(c.Name)
+		if c.Version != "" {
+			purl += "@" + purlEscape(c.Version)
+		}
+		return purl
+	}
+
+	ref, err := cnab.ParseOCIReference(c.Reference)
+	if err != nil {
+		return ""
+	}
+	repository := ref.Named.Name()
+	name := repository[strings.LastIndex(repository, "/")+1:]
+
+	purl := "pkg:oci/" + purlEscape(name)
+	if c.Digest != "" {
+		purl += "@" + purlEscape(c.Digest)
+	}
+	query := url.Values{}
+	query.Set("repository_url", repository)
+	if ref.HasTag() {
+		query.Set("tag", ref.Tag())
+	}
+	return purl + "?" + query.Encode()
+}
+
+// purlEscape percent-encodes a package URL segment, including the colon in a digest.
+func purlEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), ":", "%3A")
+}
+
+// Inventory is everything that is listed in the SBOM of a bundle.
+type Inventory struct {
+	// Bundle that the SBOM describes.
+	Bundle Component
+
+	// BundleImage is the bundle image, which is built from the base images, the Porter runtime and the mixins.
+	BundleImage Component
+
+	// BaseImages are the images that the bundle image is built from.
+	BaseImages []Component
+
+	// Runtime is the Porter runtime included in the bundle image.
+	Runtime Component
+
+	// Mixins are the mixins that are installed in the bundle image.
+	Mixins []Component
+
+	// Images are the images referenced by the bundle in the images section of porter.yaml.
+	Images []Component
+
+	// Created is when the SBOM was generated.
+	Created time.Time
+
+	// Tool is the name and version of the tool that generated the SBOM.
+	Tool Component
+}
+
+// Generate an SBOM in the specified format.
+func Generate(format string, inv Inventory) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return generateSPDX(inv)
+	case FormatCycloneDX:
+		return generateCycloneDX(inv)
+	default:
+		return nil, fmt.Errorf("cannot generate an sbom in the %s format", format)
+	}
+}
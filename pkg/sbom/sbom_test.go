@@ -0,0 +1,112 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInventory() Inventory {
+	return Inventory{
+		Bundle:      Component{Name: "mybuns", Version: "0.1.0", Description: "My bundle"},
+		BundleImage: Component{Name: "mybuns", Version: "0.1.0", Reference: "localhost:5000/mybuns:porter-abc123", Digest: "sha256:75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041"},
+		BaseImages:  []Component{{Name: "debian", Version: "stable-slim", Reference: "debian:stable-slim"}},
+		Runtime:     Component{Name: "porter-runtime", Version: "v1.2.0"},
+		Mixins:      []Component{{Name: "exec", Version: "v1.2.0"}, {Name: "helm3", Version: "v1.0.1"}},
+		Images:      []Component{{Name: "nginx", Version: "1.25", Reference: "nginx:1.25"}},
+		Created:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tool:        Component{Name: "porter", Version: "v1.2.0"},
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range Formats {
+		require.NoError(t, ValidateFormat(format))
+	}
+	require.EqualError(t, ValidateFormat("syft"), "invalid sbom format syft, allowed values are: spdx, cyclonedx, none")
+}
+
+func TestGenerate_SPDX(t *testing.T) {
+	data, err := Generate(FormatSPDX, testInventory())
+	require.NoError(t, err)
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "mybuns-0.1.0", doc.Name)
+	assert.Contains(t, doc.DocumentNamespace, "https://porter.sh/spdx/mybuns-0.1.0-")
+	assert.Equal(t, "2024-01-02T03:04:05Z", doc.CreationInfo.Created)
+	assert.Equal(t, []string{"Tool: porter-v1.2.0"}, doc.CreationInfo.Creators)
+
+	require.Len(t, doc.Packages, 7)
+	bundleImage := doc.Packages[1]
+	assert.Equal(t, "SPDXRef-BundleImage", bundleImage.SPDXID)
+	assert.Equal(t, []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: "75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041"}}, bundleImage.Checksums)
+	assert.Equal(t, "pkg:oci/mybuns@sha256%3A75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041?repository_url=localhost%3A5000%2Fmybuns&tag=porter-abc123", bundleImage.ExternalRefs[0].ReferenceLocator)
+
+	assert.Contains(t, doc.Relationships, spdxRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Bundle"})
+	assert.Contains(t, doc.Relationships, spdxRelationship{SPDXElementID: "SPDXRef-BundleImage", RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: "SPDXRef-BaseImage-0"})
+	assert.Contains(t, doc.Relationships, spdxRelationship{SPDXElementID: "SPDXRef-BundleImage", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-Mixin-1"})
+	assert.Contains(t, doc.Relationships, spdxRelationship{SPDXElementID: "SPDXRef-Bundle", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Image-0"})
+}
+
+func TestGenerate_CycloneDX(t *testing.T) {
+	data, err := Generate(FormatCycloneDX, testInventory())
+	require.NoError(t, err)
+
+	var doc cdxDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "CycloneDX", doc.BOMFormat)
+	assert.Equal(t, "1.5", doc.SpecVersion)
+	assert.Contains(t, doc.SerialNumber, "urn:uuid:")
+	assert.Equal(t, "mybuns", doc.Metadata.Component.Name)
+	assert.Equal(t, "porter", doc.Metadata.Tools.Components[0].Name)
+
+	require.Len(t, doc.Components, 6)
+	assert.Equal(t, "pkg:generic/exec@v1.2.0", doc.Components[3].PURL)
+	assert.Equal(t, []cdxDependency{
+		{Ref: "bundle", DependsOn: []string{"bundle-image", "image-0"}},
+		{Ref: "bundle-image", DependsOn: []string{"base-image-0", "runtime", "mixin-0", "mixin-1"}},
+	}, doc.Dependencies)
+}
+
+func TestGenerate_UnsupportedFormat(t *testing.T) {
+	_, err := Generate(FormatNone, testInventory())
+	require.EqualError(t, err, "cannot generate an sbom in the none format")
+}
+
+func TestComponent_PackageURL(t *testing.T) {
+	testcases := []struct {
+		name      string
+		component Component
+		want      string
+	}{
+		{name: "generic", component: Component{Name: "exec", Version: "v1.0.0"}, want: "pkg:generic/exec@v1.0.0"},
+		{name: "image with tag", component: Component{Reference: "nginx:1.25"}, want: "pkg:oci/nginx?repository_url=docker.io%2Flibrary%2Fnginx&tag=1.25"},
+		{name: "image with digest", component: Component{Reference: "ghcr.io/getporter/mysql@sha256:75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041", Digest: "sha256:75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041"},
+			want: "pkg:oci/mysql@sha256%3A75c495e5ce9c428d482973d72e3ce9925e1db304a97946c9aa0b540d7537e041?repository_url=ghcr.io%2Fgetporter%2Fmysql"},
+		{name: "build argument", component: Component{Name: "${BASE_IMAGE}", Reference: "${BASE_IMAGE}"}, want: ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.component.PackageURL())
+		})
+	}
+}
+
+func TestParseBaseImages(t *testing.T) {
+	dockerfile := `# syntax=docker/dockerfile-upstream:1.4.0
+FROM --platform=linux/amd64 golang:1.23 AS builder
+RUN go build ./...
+
+FROM debian:stable-slim
+COPY --from=builder /app /app
+from builder as tools
+FROM scratch
+FROM debian:stable-slim
+`
+	assert.Equal(t, []string{"golang:1.23", "debian:stable-slim"}, ParseBaseImages([]byte(dockerfile)))
+}
@@ -0,0 +1,137 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Description           string            `json:"description,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func generateSPDX(inv Inventory) ([]byte, error) {
+	docName := inv.Bundle.Name
+	if inv.Bundle.Version != "" {
+		docName += "-" + inv.Bundle.Version
+	}
+
+	creator := "Tool: " + inv.Tool.Name
+	if inv.Tool.Version != "" {
+		creator += "-" + inv.Tool.Version
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              docName,
+		DocumentNamespace: fmt.Sprintf("https://porter.sh/spdx/%s-%s", url.PathEscape(docName), uuid.NewString()),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.Created.UTC().Format(time.RFC3339),
+			Creators: []string{creator},
+		},
+	}
+
+	addPackage := func(id string, c Component, purpose string) {
+		pkg := spdxPackage{
+			SPDXID:                id,
+			Name:                  c.Name,
+			VersionInfo:           c.Version,
+			Description:           c.Description,
+			DownloadLocation:      spdxNoAssertion,
+			PrimaryPackagePurpose: purpose,
+		}
+		if algorithm, value, ok := strings.Cut(c.Digest, ":"); ok {
+			pkg.Checksums = []spdxChecksum{{Algorithm: strings.ToUpper(algorithm), ChecksumValue: value}}
+		}
+		if purl := c.PackageURL(); purl != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+	relate := func(from string, relationship string, to string) {
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: from, RelationshipType: relationship, RelatedSPDXElement: to})
+	}
+
+	const bundleID = "SPDXRef-Bundle"
+	const bundleImageID = "SPDXRef-BundleImage"
+	addPackage(bundleID, inv.Bundle, "APPLICATION")
+	relate(doc.SPDXID, "DESCRIBES", bundleID)
+
+	addPackage(bundleImageID, inv.BundleImage, "CONTAINER")
+	relate(bundleID, "CONTAINS", bundleImageID)
+
+	for i, c := range inv.BaseImages {
+		id := fmt.Sprintf("SPDXRef-BaseImage-%d", i)
+		addPackage(id, c, "CONTAINER")
+		relate(bundleImageID, "DESCENDANT_OF", id)
+	}
+
+	if inv.Runtime.Name != "" {
+		const runtimeID = "SPDXRef-Runtime"
+		addPackage(runtimeID, inv.Runtime, "APPLICATION")
+		relate(bundleImageID, "CONTAINS", runtimeID)
+	}
+
+	for i, c := range inv.Mixins {
+		id := fmt.Sprintf("SPDXRef-Mixin-%d", i)
+		addPackage(id, c, "APPLICATION")
+		relate(bundleImageID, "CONTAINS", id)
+	}
+
+	for i, c := range inv.Images {
+		id := fmt.Sprintf("SPDXRef-Image-%d", i)
+		addPackage(id, c, "CONTAINER")
+		relate(bundleID, "DEPENDS_ON", id)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,222 @@
+package filesystem
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedHeader is written at the beginning of every encrypted secret file,
+// so that plaintext files written before encryption was enabled can still be read.
+const encryptedHeader = "porter-encrypted-secret:v1\n"
+
+const (
+	keySize = 32
+
+	// kdfScrypt identifies a key derived from a passphrase with scrypt.
+	kdfScrypt = "scrypt"
+
+	// scrypt parameters recommended for interactive logins
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+// envelope is an encrypted secret. The value is encrypted with a random data
+// key, and the data key is encrypted with the key configured for the plugin.
+type envelope struct {
+	// KDF is the key derivation function used to derive the key from a passphrase.
+	// Empty when the key was read from a key file.
+	KDF string `json:"kdf,omitempty"`
+
+	// Salt used to derive the key from a passphrase.
+	Salt []byte `json:"salt,omitempty"`
+
+	// Key is the encrypted data key.
+	Key []byte `json:"key"`
+
+	// Data is the encrypted secret value.
+	Data []byte `json:"data"`
+}
+
+// encryptionKey is a key configured for the plugin, either read from a key
+// file or derived from a passphrase.
+type encryptionKey struct {
+	// source describes where the key came from, for error messages.
+	source string
+
+	// key read from a key file.
+	key []byte
+
+	// passphrase used to derive the key.
+	passphrase []byte
+
+	// salt used when encrypting with a passphrase. It is generated once so that
+	// the key is only derived once per process.
+	salt []byte
+
+	// derived keys, indexed by salt.
+	derived map[string][]byte
+}
+
+// loadEncryptionKey reads the key configured with either a key file or a
+// passphrase. Nil is returned when neither is set. The prefix of the
+// settings, e.g. previous-, is used in error messages.
+func (s *Store) loadEncryptionKey(prefix string, keyFile string, passphrase string) (*encryptionKey, error) {
+	if keyFile != "" && passphrase != "" {
+		return nil, fmt.Errorf("invalid filesystem secrets plugin configuration: only one of %skey-file or %spassphrase may be set", prefix, prefix)
+	}
+
+	if passphrase != "" {
+		return &encryptionKey{
+			source:     prefix + "passphrase",
+			passphrase: []byte(passphrase),
+			derived:    make(map[string][]byte),
+		}, nil
+	}
+
+	if keyFile != "" {
+		contents, err := s.config.FileSystem.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the %skey-file %s: %w", prefix, keyFile, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(contents)))
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("invalid %skey-file %s: it must contain a base64 encoded %d-bit key, for example generated with: openssl rand -base64 %d", prefix, keyFile, keySize*8, keySize)
+		}
+		return &encryptionKey{source: prefix + "key-file " + keyFile, key: key}, nil
+	}
+
+	return nil, nil
+}
+
+// deriveKey returns the key used to encrypt data keys for the specified
+// key derivation function and salt.
+func (k *encryptionKey) deriveKey(kdf string, salt []byte) ([]byte, error) {
+	if k.key != nil {
+		if kdf != "" {
+			return nil, fmt.Errorf("the secret was encrypted with a passphrase, not the %s", k.source)
+		}
+		return k.key, nil
+	}
+
+	if kdf != kdfScrypt {
+		return nil, fmt.Errorf("the secret was not encrypted with a passphrase, unsupported key derivation function %q", kdf)
+	}
+	if key, ok := k.derived[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key(k.passphrase, salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive a key from the %s: %w", k.source, err)
+	}
+	k.derived[string(salt)] = key
+	return key, nil
+}
+
+// encrypt a secret value. The name of the secret is authenticated with the
+// value, so that an encrypted file cannot be swapped for another secret.
+func (k *encryptionKey) encrypt(name string, value []byte) ([]byte, error) {
+	var env envelope
+	if k.key == nil {
+		if k.salt == nil {
+			k.salt = make([]byte, 16)
+			if _, err := rand.Read(k.salt); err != nil {
+				return nil, fmt.Errorf("could not generate a salt: %w", err)
+			}
+		}
+		env.KDF = kdfScrypt
+		env.Salt = k.salt
+	}
+
+	key, err := k.deriveKey(env.KDF, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err = rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("could not generate a data key: %w", err)
+	}
+	if env.Key, err = seal(key, dataKey, nil); err != nil {
+		return nil, err
+	}
+	if env.Data, err = seal(dataKey, value, []byte(name)); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the encrypted secret: %w", err)
+	}
+	return append([]byte(encryptedHeader), data...), nil
+}
+
+// decrypt a secret value that was encrypted by encrypt.
+func (k *encryptionKey) decrypt(name string, data []byte) ([]byte, error) {
+	var env envelope
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte(encryptedHeader)), &env); err != nil {
+		return nil, fmt.Errorf("could not decode the encrypted secret: %w", err)
+	}
+
+	key, err := k.deriveKey(env.KDF, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := open(key, env.Key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("the secret was not encrypted with the %s", k.source)
+	}
+	value, err := open(dataKey, env.Data, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("the secret was not encrypted for %s", name)
+	}
+	return value, nil
+}
+
+// isEncrypted determines if the contents of a secret file were encrypted by the plugin.
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedHeader))
+}
+
+// seal encrypts plaintext with AES-GCM, prepending the random nonce to the ciphertext.
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("could not generate a nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts ciphertext that was encrypted with seal.
+func open(key []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("the ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
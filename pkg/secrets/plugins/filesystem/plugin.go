@@ -1,11 +1,14 @@
 package filesystem
 
 import (
+	"fmt"
+
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/secrets/plugins"
 	"get.porter.sh/porter/pkg/secrets/pluginstore"
 	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
 )
 
 const PluginKey = plugins.PluginInterface + ".porter.filesystem"
@@ -17,7 +20,32 @@ type Plugin struct {
 	secrets.Store
 }
 
-func NewPlugin(c *config.Config, rawCfg interface{}) plugin.Plugin {
-	impl := NewStore(c)
-	return pluginstore.NewPlugin(c.Context, impl)
+// PluginConfig are the configuration settings that can be defined for the
+// filesystem secrets plugin in porter.yaml
+type PluginConfig struct {
+	// KeyFile is the path to a file containing a base64 encoded 256-bit key
+	// used to encrypt secrets.
+	KeyFile string `mapstructure:"key-file,omitempty"`
+
+	// Passphrase used to derive the key that encrypts secrets, when KeyFile is not set.
+	Passphrase string `mapstructure:"passphrase,omitempty"`
+
+	// PreviousKeyFile is the path to a key file that was used before the key was rotated.
+	// It is only used to decrypt secrets that have not been rekeyed yet.
+	PreviousKeyFile string `mapstructure:"previous-key-file,omitempty"`
+
+	// PreviousPassphrase is a passphrase that was used before the key was rotated.
+	// It is only used to decrypt secrets that have not been rekeyed yet.
+	PreviousPassphrase string `mapstructure:"previous-passphrase,omitempty"`
+}
+
+// NewPlugin creates an instance of the secrets.porter.filesystem plugin
+func NewPlugin(c *config.Config, rawCfg interface{}) (plugin.Plugin, error) {
+	cfg := PluginConfig{}
+	if err := mapstructure.Decode(rawCfg, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading plugin configuration: %w", err)
+	}
+
+	impl := NewStore(c, cfg)
+	return pluginstore.NewPlugin(c.Context, impl), nil
 }
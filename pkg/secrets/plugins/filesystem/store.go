@@ -26,14 +26,23 @@ const (
 // development.
 type Store struct {
 	config    *config.Config
+	cfg       PluginConfig
 	secretDir string
 	hostStore plugins.SecretsProtocol
+	connected bool
+
+	// key encrypts new secrets. When nil, secrets are stored as plaintext.
+	key *encryptionKey
+
+	// previousKey decrypts secrets that were encrypted before the key was rotated.
+	previousKey *encryptionKey
 }
 
 // NewStore returns a new instance of the filesystem secret store.
-func NewStore(c *config.Config) *Store {
+func NewStore(c *config.Config, cfg PluginConfig) *Store {
 	s := &Store{
 		config:    c,
+		cfg:       cfg,
 		hostStore: host.NewStore(),
 	}
 
@@ -44,22 +53,35 @@ func NewStore(c *config.Config) *Store {
 // The plugin itself is responsible for ensuring it was called.
 // Close is called automatically when the plugin is used by Porter.
 func (s *Store) Connect(ctx context.Context) error {
-	if s.secretDir != "" {
+	if s.connected {
 		return nil
 	}
 
 	_, log := tracing.StartSpan(ctx)
 	defer log.EndSpan()
 
-	if _, err := s.SetSecretDir(); err != nil {
+	var err error
+	if s.key, err = s.loadEncryptionKey("", s.cfg.KeyFile, s.cfg.Passphrase); err != nil {
+		return log.Error(err)
+	}
+	if s.previousKey, err = s.loadEncryptionKey("previous-", s.cfg.PreviousKeyFile, s.cfg.PreviousPassphrase); err != nil {
+		return log.Error(err)
+	}
+
+	if _, err = s.SetSecretDir(); err != nil {
 		return log.Error(err)
 	}
 
-	if err := s.config.FileSystem.MkdirAll(s.secretDir, FileModeSensitiveDirectory); err != nil && !errors.Is(err, os.ErrExist) {
+	if err = s.config.FileSystem.MkdirAll(s.secretDir, FileModeSensitiveDirectory); err != nil && !errors.Is(err, os.ErrExist) {
 		return log.Error(err)
 	}
 
-	log.Debugf("storing secrets in %s", s.secretDir)
+	if s.key == nil {
+		log.Debugf("storing unencrypted secrets in %s", s.secretDir)
+	} else {
+		log.Debugf("storing secrets in %s encrypted with the %s", s.secretDir, s.key.source)
+	}
+	s.connected = true
 	return nil
 }
 
@@ -99,7 +121,27 @@ func (s *Store) Resolve(ctx context.Context, keyName string, keyValue string) (s
 		return "", log.Error(fmt.Errorf("error reading secret from filesystem: %w", err))
 	}
 
-	return string(data), nil
+	if !isEncrypted(data) {
+		// Secrets saved before encryption was configured are plaintext until they are rekeyed
+		return string(data), nil
+	}
+
+	if s.key == nil && s.previousKey == nil {
+		return "", log.Errorf("secret %s is encrypted but the filesystem secrets plugin is not configured with a key-file or passphrase", keyValue)
+	}
+
+	var decryptErrs error
+	for _, key := range []*encryptionKey{s.key, s.previousKey} {
+		if key == nil {
+			continue
+		}
+		value, err := key.decrypt(keyValue, data)
+		if err == nil {
+			return string(value), nil
+		}
+		decryptErrs = errors.Join(decryptErrs, err)
+	}
+	return "", log.Error(fmt.Errorf("could not decrypt secret %s: %w", keyValue, decryptErrs))
 }
 
 // Create implements the Create method on the secret plugins' interface.
@@ -116,6 +158,15 @@ func (s *Store) Create(ctx context.Context, keyName string, keyValue string, val
 		return log.Error(errors.New("invalid key name: " + keyName))
 	}
 
+	data := []byte(value)
+	if s.key != nil {
+		var err error
+		data, err = s.key.encrypt(keyValue, data)
+		if err != nil {
+			return log.Error(fmt.Errorf("error encrypting secret %s: %w", keyValue, err))
+		}
+	}
+
 	path := filepath.Join(s.secretDir, keyValue)
 	f, err := s.config.FileSystem.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, FileModeSensitiveWritable)
 	if err != nil {
@@ -123,9 +174,60 @@ func (s *Store) Create(ctx context.Context, keyName string, keyValue string, val
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(value)
+	_, err = f.Write(data)
 	if err != nil {
 		return log.Error(fmt.Errorf("error writing secret to filesystem: %w", err))
 	}
 	return nil
 }
+
+// Delete implements the Delete method on the secret plugins' interface.
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	// check if the keyName is secret
+	if keyName != secrets.SourceSecret {
+		return log.Error(errors.New("invalid key name: " + keyName))
+	}
+
+	path := filepath.Join(s.secretDir, keyValue)
+	err := s.config.FileSystem.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return log.Error(fmt.Errorf("error removing secret from filesystem: %w", err))
+	}
+	return nil
+}
+
+// List implements the List method on the secret plugins' interface.
+func (s *Store) List(ctx context.Context, keyName string) ([]string, error) {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	// check if the keyName is secret
+	if keyName != secrets.SourceSecret {
+		return nil, log.Error(errors.New("invalid key name: " + keyName))
+	}
+
+	entries, err := s.config.FileSystem.ReadDir(s.secretDir)
+	if err != nil {
+		return nil, log.Error(fmt.Errorf("error listing secrets in %s: %w", s.secretDir, err))
+	}
+
+	keyValues := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		keyValues = append(keyValues, entry.Name())
+	}
+	return keyValues, nil
+}
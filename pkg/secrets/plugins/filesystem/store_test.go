@@ -8,6 +8,7 @@ import (
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/secrets/plugins/filesystem"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,7 +16,7 @@ func TestFileSystem_Permission(t *testing.T) {
 	c := config.NewTestConfig(t)
 	defer c.Close()
 
-	testStore := filesystem.NewStore(c.Config)
+	testStore := filesystem.NewStore(c.Config, filesystem.PluginConfig{})
 	defer testStore.Close()
 
 	ctx := context.Background()
@@ -39,7 +40,7 @@ func TestFileSystem_Permission(t *testing.T) {
 func TestFileSystem_SetSecretDir(t *testing.T) {
 	c := config.NewTestConfig(t)
 
-	s := filesystem.NewStore(c.Config)
+	s := filesystem.NewStore(c.Config, filesystem.PluginConfig{})
 	secretDir, err := s.SetSecretDir()
 	require.NoError(t, err)
 	require.Equal(t, filepath.FromSlash("/home/myuser/.porter/secrets"), secretDir)
@@ -49,7 +50,7 @@ func TestFileSystem_DataOperation(t *testing.T) {
 	c := config.NewTestConfig(t)
 	defer c.Close()
 
-	testStore := filesystem.NewStore(c.Config)
+	testStore := filesystem.NewStore(c.Config, filesystem.PluginConfig{})
 	defer testStore.Close()
 
 	ctx := context.Background()
@@ -65,3 +66,142 @@ func TestFileSystem_DataOperation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, secretValue, data)
 }
+
+func TestFileSystem_Delete(t *testing.T) {
+	c := config.NewTestConfig(t)
+	defer c.Close()
+
+	testStore := filesystem.NewStore(c.Config, filesystem.PluginConfig{})
+	defer testStore.Close()
+
+	ctx := context.Background()
+	secretKey := "porter-filesystem-plugin-test"
+	err := testStore.Create(ctx, secrets.SourceSecret, secretKey, "supersecret")
+	require.NoError(t, err)
+
+	err = testStore.Delete(ctx, secrets.SourceSecret, secretKey)
+	require.NoError(t, err)
+
+	_, err = testStore.Resolve(ctx, secrets.SourceSecret, secretKey)
+	require.Error(t, err, "expected the secret to be removed")
+
+	err = testStore.Delete(ctx, secrets.SourceSecret, secretKey)
+	require.NoError(t, err, "deleting a secret that does not exist should not fail")
+
+	err = testStore.Delete(ctx, "env", "HOME")
+	require.ErrorContains(t, err, "invalid key name: env")
+}
+
+func TestFileSystem_List(t *testing.T) {
+	c := config.NewTestConfig(t)
+	defer c.Close()
+
+	testStore := filesystem.NewStore(c.Config, filesystem.PluginConfig{})
+	defer testStore.Close()
+
+	ctx := context.Background()
+	keys, err := testStore.List(ctx, secrets.SourceSecret)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	for _, key := range []string{"password", "conn-string"} {
+		err = testStore.Create(ctx, secrets.SourceSecret, key, "supersecret")
+		require.NoError(t, err)
+	}
+
+	keys, err = testStore.List(ctx, secrets.SourceSecret)
+	require.NoError(t, err)
+	require.Equal(t, []string{"conn-string", "password"}, keys)
+}
+
+func TestFileSystem_Encryption(t *testing.T) {
+	ctx := context.Background()
+	secretDir := "/home/myuser/.porter/secrets"
+	oldKeyFile := "/home/myuser/old.key"
+	newKeyFile := "/home/myuser/new.key"
+
+	c := config.NewTestConfig(t)
+	defer c.Close()
+	require.NoError(t, c.FileSystem.WriteFile(oldKeyFile, []byte("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"), filesystem.FileModeSensitiveWritable))
+	require.NoError(t, c.FileSystem.WriteFile(newKeyFile, []byte("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="), filesystem.FileModeSensitiveWritable))
+
+	// Save a plaintext secret before encryption is enabled
+	plaintextStore := filesystem.NewStore(c.Config, filesystem.PluginConfig{})
+	require.NoError(t, plaintextStore.Create(ctx, secrets.SourceSecret, "legacy", "oldsecret"))
+
+	t.Run("key file", func(t *testing.T) {
+		s := filesystem.NewStore(c.Config, filesystem.PluginConfig{KeyFile: oldKeyFile})
+		require.NoError(t, s.Create(ctx, secrets.SourceSecret, "password", "supersecret"))
+
+		contents, err := c.FileSystem.ReadFile(filepath.Join(secretDir, "password"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(contents), "supersecret", "the secret should be encrypted at rest")
+
+		value, err := s.Resolve(ctx, secrets.SourceSecret, "password")
+		require.NoError(t, err)
+		assert.Equal(t, "supersecret", value)
+
+		value, err = s.Resolve(ctx, secrets.SourceSecret, "legacy")
+		require.NoError(t, err)
+		assert.Equal(t, "oldsecret", value, "plaintext secrets should still resolve")
+	})
+
+	t.Run("passphrase", func(t *testing.T) {
+		s := filesystem.NewStore(c.Config, filesystem.PluginConfig{Passphrase: "correct horse battery staple"})
+		require.NoError(t, s.Create(ctx, secrets.SourceSecret, "token", "abc123"))
+
+		// Use a new store so that the key is derived again from the passphrase
+		s = filesystem.NewStore(c.Config, filesystem.PluginConfig{Passphrase: "correct horse battery staple"})
+		value, err := s.Resolve(ctx, secrets.SourceSecret, "token")
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", value)
+
+		s = filesystem.NewStore(c.Config, filesystem.PluginConfig{Passphrase: "wrong"})
+		_, err = s.Resolve(ctx, secrets.SourceSecret, "token")
+		require.ErrorContains(t, err, "could not decrypt secret token: the secret was not encrypted with the passphrase")
+	})
+
+	t.Run("rotate key", func(t *testing.T) {
+		s := filesystem.NewStore(c.Config, filesystem.PluginConfig{KeyFile: newKeyFile})
+		_, err := s.Resolve(ctx, secrets.SourceSecret, "password")
+		require.ErrorContains(t, err, "the secret was not encrypted with the key-file /home/myuser/new.key")
+
+		s = filesystem.NewStore(c.Config, filesystem.PluginConfig{KeyFile: newKeyFile, PreviousKeyFile: oldKeyFile})
+		value, err := s.Resolve(ctx, secrets.SourceSecret, "password")
+		require.NoError(t, err)
+		assert.Equal(t, "supersecret", value, "secrets encrypted with the previous key should resolve")
+
+		// Rewriting the secret encrypts it with the new key
+		require.NoError(t, s.Create(ctx, secrets.SourceSecret, "password", value))
+		s = filesystem.NewStore(c.Config, filesystem.PluginConfig{KeyFile: newKeyFile})
+		value, err = s.Resolve(ctx, secrets.SourceSecret, "password")
+		require.NoError(t, err)
+		assert.Equal(t, "supersecret", value)
+	})
+
+	t.Run("no key configured", func(t *testing.T) {
+		_, err := plaintextStore.Resolve(ctx, secrets.SourceSecret, "password")
+		require.ErrorContains(t, err, "secret password is encrypted but the filesystem secrets plugin is not configured with a key-file or passphrase")
+	})
+
+	t.Run("swapped file", func(t *testing.T) {
+		contents, err := c.FileSystem.ReadFile(filepath.Join(secretDir, "password"))
+		require.NoError(t, err)
+		require.NoError(t, c.FileSystem.WriteFile(filepath.Join(secretDir, "other"), contents, filesystem.FileModeSensitiveWritable))
+
+		s := filesystem.NewStore(c.Config, filesystem.PluginConfig{KeyFile: newKeyFile})
+		_, err = s.Resolve(ctx, secrets.SourceSecret, "other")
+		require.ErrorContains(t, err, "the secret was not encrypted for other")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		s := filesystem.NewStore(c.Config, filesystem.PluginConfig{KeyFile: newKeyFile, Passphrase: "secret"})
+		err := s.Connect(ctx)
+		require.ErrorContains(t, err, "only one of key-file or passphrase may be set")
+
+		require.NoError(t, c.FileSystem.WriteFile("/home/myuser/short.key", []byte("dG9vc2hvcnQ="), filesystem.FileModeSensitiveWritable))
+		s = filesystem.NewStore(c.Config, filesystem.PluginConfig{PreviousKeyFile: "/home/myuser/short.key"})
+		err = s.Connect(ctx)
+		require.ErrorContains(t, err, "invalid previous-key-file /home/myuser/short.key: it must contain a base64 encoded 256-bit key")
+	})
+}
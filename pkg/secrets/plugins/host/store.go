@@ -25,3 +25,11 @@ func (s Store) Resolve(ctx context.Context, keyName string, keyValue string) (st
 func (s Store) Create(ctx context.Context, keyName string, keyValue string, value string) error {
 	return fmt.Errorf("the default secrets plugin, %s, does not support persisting secrets: %w", PluginKey, secretsplugins.ErrNotImplemented)
 }
+
+func (s Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	return fmt.Errorf("the default secrets plugin, %s, does not support deleting secrets: %w", PluginKey, secretsplugins.ErrNotImplemented)
+}
+
+func (s Store) List(ctx context.Context, keyName string) ([]string, error) {
+	return nil, fmt.Errorf("the default secrets plugin, %s, does not support listing secrets: %w", PluginKey, secretsplugins.ErrNotImplemented)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	"get.porter.sh/porter/pkg/secrets/plugins"
 )
@@ -50,3 +51,17 @@ func (s *Store) Create(ctx context.Context, keyName string, keyValue string, val
 	s.Secrets[keyName][keyValue] = value
 	return nil
 }
+
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	delete(s.Secrets[keyName], keyValue)
+	return nil
+}
+
+func (s *Store) List(ctx context.Context, keyName string) ([]string, error) {
+	keyValues := make([]string, 0, len(s.Secrets[keyName]))
+	for keyValue := range s.Secrets[keyName] {
+		keyValues = append(keyValues, keyValue)
+	}
+	sort.Strings(keyValues)
+	return keyValues, nil
+}
@@ -25,6 +25,8 @@ const _ = grpc.SupportPackageIsVersion7
 type SecretsProtocolClient interface {
 	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 }
 
 type secretsProtocolClient struct {
@@ -53,12 +55,32 @@ func (c *secretsProtocolClient) Create(ctx context.Context, in *CreateRequest, o
 	return out, nil
 }
 
+func (c *secretsProtocolClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/plugins.SecretsProtocol/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsProtocolClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/plugins.SecretsProtocol/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SecretsProtocolServer is the server API for SecretsProtocol service.
 // All implementations must embed UnimplementedSecretsProtocolServer
 // for forward compatibility
 type SecretsProtocolServer interface {
 	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
 	mustEmbedUnimplementedSecretsProtocolServer()
 }
 
@@ -72,6 +94,12 @@ func (UnimplementedSecretsProtocolServer) Resolve(context.Context, *ResolveReque
 func (UnimplementedSecretsProtocolServer) Create(context.Context, *CreateRequest) (*CreateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (UnimplementedSecretsProtocolServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSecretsProtocolServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
 func (UnimplementedSecretsProtocolServer) mustEmbedUnimplementedSecretsProtocolServer() {}
 
 // UnsafeSecretsProtocolServer may be embedded to opt out of forward compatibility for this service.
@@ -121,6 +149,42 @@ func _SecretsProtocol_Create_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SecretsProtocol_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsProtocolServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugins.SecretsProtocol/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsProtocolServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SecretsProtocol_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsProtocolServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plugins.SecretsProtocol/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsProtocolServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SecretsProtocol_ServiceDesc is the grpc.ServiceDesc for SecretsProtocol service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -136,6 +200,14 @@ var SecretsProtocol_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Create",
 			Handler:    _SecretsProtocol_Create_Handler,
 		},
+		{
+			MethodName: "Delete",
+			Handler:    _SecretsProtocol_Delete_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _SecretsProtocol_List_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/secrets/plugins/proto/secrets_protocol.proto",
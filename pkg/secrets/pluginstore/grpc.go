@@ -42,6 +42,26 @@ func (m *GClient) Create(ctx context.Context, keyName string, keyValue string, v
 	return err
 }
 
+func (m *GClient) Delete(ctx context.Context, keyName string, keyValue string) error {
+	req := &proto.DeleteRequest{
+		KeyName:  keyName,
+		KeyValue: keyValue,
+	}
+	_, err := m.client.Delete(ctx, req)
+	return err
+}
+
+func (m *GClient) List(ctx context.Context, keyName string) ([]string, error) {
+	req := &proto.ListRequest{
+		KeyName: keyName,
+	}
+	resp, err := m.client.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.KeyValues, nil
+}
+
 // GServer is a gRPC wrapper around a SecretsProtocol plugin
 type GServer struct {
 	c    *portercontext.Context
@@ -68,3 +88,19 @@ func (m *GServer) Create(ctx context.Context, request *proto.CreateRequest) (*pr
 	}
 	return &proto.CreateResponse{}, nil
 }
+
+func (m *GServer) Delete(ctx context.Context, request *proto.DeleteRequest) (*proto.DeleteResponse, error) {
+	err := m.impl.Delete(ctx, request.KeyName, request.KeyValue)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.DeleteResponse{}, nil
+}
+
+func (m *GServer) List(ctx context.Context, request *proto.ListRequest) (*proto.ListResponse, error) {
+	keyValues, err := m.impl.List(ctx, request.KeyName)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.ListResponse{KeyValues: keyValues}, nil
+}
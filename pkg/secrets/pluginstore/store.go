@@ -23,6 +23,9 @@ type Store struct {
 	*config.Config
 	plugin plugins.SecretsProtocol
 	conn   *pluggable.PluginConnection
+
+	// name of the secrets plugin in the config file to use instead of the default secrets plugin.
+	name string
 }
 
 func NewStore(c *config.Config) *Store {
@@ -31,6 +34,15 @@ func NewStore(c *config.Config) *Store {
 	}
 }
 
+// NewNamedStore creates a store that uses the secrets plugin with the specified
+// name in the config file, instead of the default secrets plugin.
+func NewNamedStore(c *config.Config, name string) *Store {
+	return &Store{
+		Config: c,
+		name:   name,
+	}
+}
+
 // NewSecretsPluginConfig for secret sources.
 func NewSecretsPluginConfig() pluggable.PluginTypeConfig {
 	return pluggable.PluginTypeConfig{
@@ -84,6 +96,43 @@ func (s *Store) Create(ctx context.Context, keyName string, keyValue string, val
 	return span.Error(err)
 }
 
+func (s *Store) Delete(ctx context.Context, keyName string, keyValue string) error {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.String("keyName", keyName),
+		attribute.String("keyValue", keyValue))
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	err := s.plugin.Delete(ctx, keyName, keyValue)
+	if errors.Is(err, plugins.ErrNotImplemented) {
+		return span.Error(fmt.Errorf(`the current secrets plugin does not support deleting secrets. You need to edit your porter configuration file and configure a different secrets plugin. See https://porter.sh/end-users/configuration/#change-the-default-secrets-plugin for details: %w`, err))
+	}
+
+	return span.Error(err)
+}
+
+func (s *Store) List(ctx context.Context, keyName string) ([]string, error) {
+	ctx, span := tracing.StartSpan(ctx,
+		attribute.String("keyName", keyName))
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	keyValues, err := s.plugin.List(ctx, keyName)
+	if errors.Is(err, plugins.ErrNotImplemented) {
+		return nil, span.Error(fmt.Errorf(`the current secrets plugin does not support listing secrets. You need to edit your porter configuration file and configure a different secrets plugin. See https://porter.sh/end-users/configuration/#change-the-default-secrets-plugin for details: %w`, err))
+	}
+	if err != nil {
+		return nil, span.Error(err)
+	}
+	return keyValues, nil
+}
+
 // Connect initializes the plugin for use.
 // The plugin itself is responsible for ensuring it was called.
 // Close is called automatically when the plugin is used by Porter.
@@ -96,6 +145,11 @@ func (s *Store) Connect(ctx context.Context) error {
 	defer span.EndSpan()
 
 	pluginType := NewSecretsPluginConfig()
+	if s.name != "" {
+		pluginType.GetDefaultPluggable = func(c *config.Config) string {
+			return s.name
+		}
+	}
 
 	l := pluggable.NewPluginLoader(s.Config)
 	conn, err := l.Load(ctx, pluginType)
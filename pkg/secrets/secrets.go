@@ -6,6 +6,10 @@ import (
 
 const SourceSecret = "secret"
 
+// SourceTemplate is a strategy whose hint is a template that combines the values of
+// other sources, for example postgres://${env.DB_USER}:${secret.db-password}@db
+const SourceTemplate = "template"
+
 // Store is the interface that Porter uses to interact with secrets.
 type Store interface {
 	Close() error
@@ -27,4 +31,19 @@ type Store interface {
 	// - keyName=key, keyValue=conn-string, value=redis://foo
 	// - keyName=path, keyValue=/tmp/connstring.txt, value=redis://foo
 	Create(ctx context.Context, keyName string, keyValue string, value string) error
+
+	// Delete removes a secret value from a secret store. Deleting a secret that
+	// does not exist is not an error.
+	// - keyName is name of the key where the secret can be found.
+	// - keyValue is the value of the key.
+	// Examples:
+	// - keyName=secret, keyValue=conn-string
+	Delete(ctx context.Context, keyName string, keyValue string) error
+
+	// List returns the values of the keys for all secrets in a secret store
+	// with the specified key name.
+	// - keyName is name of the key where the secrets can be found.
+	// Examples:
+	// - keyName=secret returns ["conn-string", "password"]
+	List(ctx context.Context, keyName string) ([]string, error)
 }
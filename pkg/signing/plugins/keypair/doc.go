@@ -0,0 +1,3 @@
+// Package keypair provides an implementation of a signing plugin that signs
+// with a local ed25519 or ECDSA key pair, without requiring any external tools.
+package keypair
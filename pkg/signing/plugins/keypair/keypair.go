@@ -0,0 +1,344 @@
+package keypair
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/signing/plugins"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// SignatureArtifactType is the artifact type of the signatures stored in the registry.
+	// It is used as the media type of the signature manifest's config.
+	SignatureArtifactType = "application/vnd.porter.signature.v1+json"
+
+	// PayloadMediaType is the media type of the signed payload, which identifies the subject.
+	PayloadMediaType = "application/vnd.porter.signature.payload.v1+json"
+
+	// AnnotationSignature is the base64 encoded signature of the payload.
+	AnnotationSignature = "sh.porter.signature"
+
+	// AnnotationAlgorithm is the algorithm used to create the signature.
+	AnnotationAlgorithm = "sh.porter.signature.algorithm"
+
+	// AnnotationKeyID identifies the public key that verifies the signature.
+	AnnotationKeyID = "sh.porter.signature.keyid"
+
+	// payloadType identifies the format of the signed payload.
+	payloadType = "porter.sh/signature/v1"
+)
+
+var _ plugins.SigningProtocol = &Signer{}
+
+// Signer signs bundles and bundle images with a local ed25519 or ECDSA key pair,
+// storing the signature in the registry as an OCI artifact that refers to the signed manifest.
+type Signer struct {
+	*portercontext.Context
+
+	PublicKey        string
+	PrivateKey       string
+	InsecureRegistry bool
+}
+
+func NewSigner(c *portercontext.Context, cfg PluginConfig) *Signer {
+	return &Signer{
+		Context:          c,
+		PublicKey:        cfg.PublicKey,
+		PrivateKey:       cfg.PrivateKey,
+		InsecureRegistry: cfg.InsecureRegistry,
+	}
+}
+
+// payload is the document that is signed. It identifies the signed manifest by its digest.
+type payload struct {
+	Type    string        `json:"type"`
+	Subject v1.Descriptor `json:"subject"`
+}
+
+func (s *Signer) Connect(ctx context.Context) error {
+	_, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	if s.PublicKey == "" && s.PrivateKey == "" {
+		return log.Error(errors.New("the keypair signer requires the publickey or privatekey configuration setting"))
+	}
+	return nil
+}
+
+func (s *Signer) Sign(ctx context.Context, ref string) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	privateKey, err := s.loadPrivateKey()
+	if err != nil {
+		return log.Error(err)
+	}
+
+	subjectRef, subject, err := s.resolveSubject(ctx, ref)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	payloadB, err := json.Marshal(payload{Type: payloadType, Subject: subject})
+	if err != nil {
+		return log.Errorf("error creating the signature payload: %w", err)
+	}
+	signature, err := signPayload(privateKey, payloadB)
+	if err != nil {
+		return log.Errorf("error signing %s: %w", ref, err)
+	}
+	keyID, err := KeyID(privateKey.Public())
+	if err != nil {
+		return log.Error(err)
+	}
+
+	opts := s.remoteOptions(ctx)
+	repo := subjectRef.Context()
+	config := static.NewLayer([]byte("{}"), SignatureArtifactType)
+	payloadLayer := static.NewLayer(payloadB, PayloadMediaType)
+	for _, blob := range []v1.Layer{config, payloadLayer} {
+		if err = remote.WriteLayer(repo, blob, opts...); err != nil {
+			return log.Errorf("error pushing the signature of %s: %w", ref, err)
+		}
+	}
+
+	configDesc, err := descriptorOf(config)
+	if err != nil {
+		return log.Error(err)
+	}
+	payloadDesc, err := descriptorOf(payloadLayer)
+	if err != nil {
+		return log.Error(err)
+	}
+	manifest := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        configDesc,
+		Layers:        []v1.Descriptor{payloadDesc},
+		Annotations: map[string]string{
+			AnnotationSignature: base64.StdEncoding.EncodeToString(signature),
+			AnnotationAlgorithm: algorithmOf(privateKey.Public()),
+			AnnotationKeyID:     keyID,
+		},
+		Subject: &subject,
+	}
+	manifestB, err := json.Marshal(manifest)
+	if err != nil {
+		return log.Errorf("error creating the signature manifest: %w", err)
+	}
+	manifestDigest, _, err := v1.SHA256(bytes.NewReader(manifestB))
+	if err != nil {
+		return log.Error(err)
+	}
+
+	// Pushing a manifest with a subject also updates the referrers tag, sha256-DIGEST,
+	// when the registry does not support the referrers API
+	err = remote.Put(repo.Digest(manifestDigest.String()), rawManifest{data: manifestB, mediaType: types.OCIManifestSchema1}, opts...)
+	if err != nil {
+		return log.Errorf("error pushing the signature of %s: %w", ref, err)
+	}
+
+	log.Infof("Signed %s@%s with key %s", repo.Name(), subject.Digest, keyID)
+	return nil
+}
+
+func (s *Signer) Verify(ctx context.Context, ref string) error {
+	ctx, log := tracing.StartSpan(ctx)
+	defer log.EndSpan()
+
+	publicKey, err := s.loadPublicKey()
+	if err != nil {
+		return log.Error(err)
+	}
+	keyID, err := KeyID(publicKey)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	subjectRef, subject, err := s.resolveSubject(ctx, ref)
+	if err != nil {
+		return log.Error(err)
+	}
+
+	opts := s.remoteOptions(ctx)
+	repo := subjectRef.Context()
+	referrers, err := remote.Referrers(repo.Digest(subject.Digest.String()), append(opts, remote.WithFilter("artifactType", SignatureArtifactType))...)
+	if err != nil {
+		return log.Errorf("error listing the signatures of %s: %w", ref, err)
+	}
+	index, err := referrers.IndexManifest()
+	if err != nil {
+		return log.Errorf("error listing the signatures of %s: %w", ref, err)
+	}
+
+	for _, desc := range index.Manifests {
+		if desc.ArtifactType != "" && desc.ArtifactType != SignatureArtifactType {
+			continue
+		}
+
+		err := s.verifySignature(repo, desc.Digest, subject, publicKey, keyID, opts)
+		if err == nil {
+			log.Infof("Verified the signature of %s@%s with key %s", repo.Name(), subject.Digest, keyID)
+			return nil
+		}
+		log.Debugf("signature %s did not verify %s: %s", desc.Digest, ref, err)
+	}
+
+	return log.Errorf("no signature of %s could be verified with key %s", ref, keyID)
+}
+
+// verifySignature checks if a signature artifact is a valid signature of the subject by the public key.
+func (s *Signer) verifySignature(repo name.Repository, signatureDigest v1.Hash, subject v1.Descriptor, publicKey crypto.PublicKey, keyID string, opts []remote.Option) error {
+	desc, err := remote.Get(repo.Digest(signatureDigest.String()), opts...)
+	if err != nil {
+		return err
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(desc.Manifest, &manifest); err != nil {
+		return fmt.Errorf("invalid signature manifest: %w", err)
+	}
+	if manifest.Config.MediaType != SignatureArtifactType || len(manifest.Layers) != 1 {
+		return errors.New("the manifest is not a signature")
+	}
+	if id := manifest.Annotations[AnnotationKeyID]; id != "" && id != keyID {
+		return fmt.Errorf("the signature was created with a different key, %s", id)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(manifest.Annotations[AnnotationSignature])
+	if err != nil {
+		return fmt.Errorf("invalid signature annotation: %w", err)
+	}
+
+	layer, err := remote.Layer(repo.Digest(manifest.Layers[0].Digest.String()), opts...)
+	if err != nil {
+		return err
+	}
+	r, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	payloadB, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("error reading the signature payload: %w", err)
+	}
+
+	if !verifyPayload(publicKey, payloadB, signature) {
+		return errors.New("the signature is invalid")
+	}
+
+	// Only trust the payload after the signature is verified
+	var p payload
+	if err = json.Unmarshal(payloadB, &p); err != nil {
+		return fmt.Errorf("invalid signature payload: %w", err)
+	}
+	if p.Type != payloadType || p.Subject.Digest != subject.Digest {
+		return fmt.Errorf("the signature is for %s, not %s", p.Subject.Digest, subject.Digest)
+	}
+	return nil
+}
+
+// resolveSubject returns the descriptor of the manifest that is referenced.
+func (s *Signer) resolveSubject(ctx context.Context, ref string) (name.Reference, v1.Descriptor, error) {
+	var nameOpts []name.Option
+	if s.InsecureRegistry {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	parsedRef, err := name.ParseReference(ref, nameOpts...)
+	if err != nil {
+		return nil, v1.Descriptor{}, fmt.Errorf("invalid reference %s: %w", ref, err)
+	}
+
+	desc, err := remote.Head(parsedRef, s.remoteOptions(ctx)...)
+	if err != nil {
+		return nil, v1.Descriptor{}, fmt.Errorf("error resolving the digest of %s: %w", ref, err)
+	}
+	return parsedRef, v1.Descriptor{MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size}, nil
+}
+
+func (s *Signer) remoteOptions(ctx context.Context) []remote.Option {
+	opts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+	}
+	if s.InsecureRegistry {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		opts = append(opts, remote.WithTransport(transport))
+	}
+	return opts
+}
+
+func (s *Signer) loadPrivateKey() (crypto.Signer, error) {
+	if s.PrivateKey == "" {
+		return nil, errors.New("the keypair signer requires the privatekey configuration setting to sign")
+	}
+	data, err := s.FileSystem.ReadFile(s.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the private key %s: %w", s.PrivateKey, err)
+	}
+	return ParsePrivateKey(data)
+}
+
+// loadPublicKey reads the public key, or derives it from the private key when only the private key is configured.
+func (s *Signer) loadPublicKey() (crypto.PublicKey, error) {
+	if s.PublicKey == "" {
+		privateKey, err := s.loadPrivateKey()
+		if err != nil {
+			return nil, errors.New("the keypair signer requires the publickey configuration setting to verify")
+		}
+		return privateKey.Public(), nil
+	}
+
+	data, err := s.FileSystem.ReadFile(s.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the public key %s: %w", s.PublicKey, err)
+	}
+	return ParsePublicKey(data)
+}
+
+func descriptorOf(layer v1.Layer) (v1.Descriptor, error) {
+	mediaType, err := layer.MediaType()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	digest, err := layer.Digest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mediaType, Digest: digest, Size: size}, nil
+}
+
+// rawManifest is a manifest that can be pushed with remote.Put.
+type rawManifest struct {
+	data      []byte
+	mediaType types.MediaType
+}
+
+func (m rawManifest) RawManifest() ([]byte, error) {
+	return m.data, nil
+}
+
+func (m rawManifest) MediaType() (types.MediaType, error) {
+	return m.mediaType, nil
+}
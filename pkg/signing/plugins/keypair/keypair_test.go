@@ -0,0 +1,93 @@
+package keypair
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	testcases := []struct {
+		name      string
+		referrers bool
+	}{
+		{name: "referrers api", referrers: true},
+		{name: "referrers tag fallback", referrers: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			server := httptest.NewServer(registry.New(registry.WithReferrersSupport(tc.referrers)))
+			defer server.Close()
+			reg := strings.TrimPrefix(server.URL, "http://")
+
+			// Push an image to sign
+			ref := reg + "/mybuns:v1.0.0"
+			parsedRef, err := name.ParseReference(ref)
+			require.NoError(t, err)
+			img, err := random.Image(100, 1)
+			require.NoError(t, err)
+			require.NoError(t, remote.Write(parsedRef, img))
+
+			c := portercontext.NewTestContext(t)
+			signer := newTestSigner(t, c, "mykey", AlgorithmEd25519)
+			otherSigner := newTestSigner(t, c, "otherkey", AlgorithmECDSA)
+
+			require.ErrorContains(t, signer.Verify(ctx, ref), "no signature of "+ref+" could be verified")
+
+			require.NoError(t, signer.Sign(ctx, ref))
+			require.NoError(t, signer.Verify(ctx, ref), "the signature should verify with the public key")
+			require.ErrorContains(t, otherSigner.Verify(ctx, ref), "no signature of "+ref+" could be verified", "the signature should not verify with a different key")
+
+			// A signature by another key is stored alongside the first
+			require.NoError(t, otherSigner.Sign(ctx, ref))
+			require.NoError(t, otherSigner.Verify(ctx, ref))
+			require.NoError(t, signer.Verify(ctx, ref))
+
+			// The signature does not apply to a different image pushed to the same tag
+			img, err = random.Image(100, 1)
+			require.NoError(t, err)
+			require.NoError(t, remote.Write(parsedRef, img))
+			require.ErrorContains(t, signer.Verify(ctx, ref), "no signature of "+ref+" could be verified")
+		})
+	}
+}
+
+func TestSigner_Connect(t *testing.T) {
+	s := NewSigner(portercontext.NewTestContext(t).Context, PluginConfig{})
+	require.EqualError(t, s.Connect(context.Background()), "the keypair signer requires the publickey or privatekey configuration setting")
+}
+
+func TestSigner_Verify_DerivePublicKey(t *testing.T) {
+	c := portercontext.NewTestContext(t)
+	signer := newTestSigner(t, c, "mykey", AlgorithmEd25519)
+	signer.PublicKey = ""
+
+	publicKey, err := signer.loadPublicKey()
+	require.NoError(t, err)
+	privateKey, err := signer.loadPrivateKey()
+	require.NoError(t, err)
+	assert.Equal(t, privateKey.Public(), publicKey)
+}
+
+func newTestSigner(t *testing.T, c *portercontext.TestContext, keyName string, algorithm string) *Signer {
+	privatePEM, publicPEM, err := GenerateKeyPair(algorithm)
+	require.NoError(t, err)
+	require.NoError(t, c.FileSystem.WriteFile("/keys/"+keyName+".key", privatePEM, 0600))
+	require.NoError(t, c.FileSystem.WriteFile("/keys/"+keyName+".pub", publicPEM, 0644))
+
+	return NewSigner(c.Context, PluginConfig{
+		PrivateKey:       "/keys/" + keyName + ".key",
+		PublicKey:        "/keys/" + keyName + ".pub",
+		InsecureRegistry: true,
+	})
+}
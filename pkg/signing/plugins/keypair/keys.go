@@ -0,0 +1,144 @@
+package keypair
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AlgorithmEd25519 generates an ed25519 key pair.
+	AlgorithmEd25519 = "ed25519"
+
+	// AlgorithmECDSA generates an ECDSA key pair using the P-256 curve.
+	AlgorithmECDSA = "ecdsa"
+
+	privateKeyPEMType = "PRIVATE KEY"
+	publicKeyPEMType  = "PUBLIC KEY"
+)
+
+// GenerateKeyPair generates a key pair for the specified algorithm, returning
+// the PEM encoded private key in PKCS #8 form and the PEM encoded public key in PKIX form.
+func GenerateKeyPair(algorithm string) (privateKeyPEM []byte, publicKeyPEM []byte, err error) {
+	var privateKey crypto.Signer
+	switch algorithm {
+	case AlgorithmEd25519, "":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmECDSA:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, nil, fmt.Errorf("unsupported key algorithm %s, allowed values are: %s, %s", algorithm, AlgorithmEd25519, AlgorithmECDSA)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating %s key pair: %w", algorithm, err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error encoding the private key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error encoding the public key: %w", err)
+	}
+
+	privateKeyPEM = pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: privateDER})
+	publicKeyPEM = pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: publicDER})
+	return privateKeyPEM, publicKeyPEM, nil
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 ed25519 or ECDSA private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != privateKeyPEMType {
+		return nil, errors.New("the private key is not a PEM encoded PKCS #8 private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, only ed25519 and ECDSA keys are supported", key)
+	}
+}
+
+// ParsePublicKey parses a PEM encoded PKIX ed25519 or ECDSA public key.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != publicKeyPEMType {
+		return nil, errors.New("the public key is not a PEM encoded PKIX public key")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the public key: %w", err)
+	}
+
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T, only ed25519 and ECDSA keys are supported", key)
+	}
+}
+
+// KeyID identifies a public key by the digest of its PKIX encoding.
+func KeyID(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("error encoding the public key: %w", err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(der)), nil
+}
+
+// algorithmOf returns the name of the signature algorithm used with the key.
+func algorithmOf(publicKey crypto.PublicKey) string {
+	switch publicKey.(type) {
+	case ed25519.PublicKey:
+		return AlgorithmEd25519
+	case *ecdsa.PublicKey:
+		return "ecdsa-sha256"
+	default:
+		return ""
+	}
+}
+
+// signPayload signs the payload with an ed25519 key, or the SHA-256 digest of the payload with an ECDSA key.
+func signPayload(key crypto.Signer, payload []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, payload), nil
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(payload)
+		return ecdsa.SignASN1(rand.Reader, k, digest[:])
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// verifyPayload verifies a signature created by signPayload.
+func verifyPayload(publicKey crypto.PublicKey, payload []byte, signature []byte) bool {
+	switch k := publicKey.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	default:
+		return false
+	}
+}
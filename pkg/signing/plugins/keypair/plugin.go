@@ -0,0 +1,42 @@
+package keypair
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/portercontext"
+	"get.porter.sh/porter/pkg/signing"
+	"get.porter.sh/porter/pkg/signing/plugins"
+	"get.porter.sh/porter/pkg/signing/pluginstore"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+)
+
+const PluginKey = plugins.PluginInterface + ".porter.keypair"
+
+var _ plugins.SigningProtocol = &Plugin{}
+
+type PluginConfig struct {
+	// PublicKey is the path to the PEM encoded public key used to verify signatures.
+	PublicKey string `mapstructure:"publickey,omitempty"`
+
+	// PrivateKey is the path to the PEM encoded private key used to sign.
+	PrivateKey string `mapstructure:"privatekey,omitempty"`
+
+	// InsecureRegistry allows signing bundles in registries with expired or self-signed certificates, or over HTTP.
+	InsecureRegistry bool `mapstructure:"insecureregistry,omitempty"`
+}
+
+// Plugin is the plugin wrapper for signing with a local key pair.
+type Plugin struct {
+	signing.Signer
+}
+
+func NewPlugin(c *portercontext.Context, rawCfg interface{}) (plugin.Plugin, error) {
+	cfg := PluginConfig{}
+	if err := mapstructure.Decode(rawCfg, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading plugin configuration: %w", err)
+	}
+
+	impl := NewSigner(c, cfg)
+	return pluginstore.NewPlugin(c, impl), nil
+}
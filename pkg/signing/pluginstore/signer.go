@@ -23,6 +23,9 @@ type Signer struct {
 	*config.Config
 	plugin plugins.SigningProtocol
 	conn   *pluggable.PluginConnection
+
+	// signer in the config file to use instead of the default signer.
+	signer *config.SigningPlugin
 }
 
 func NewSigner(c *config.Config) *Signer {
@@ -31,6 +34,15 @@ func NewSigner(c *config.Config) *Signer {
 	}
 }
 
+// NewConfiguredSigner creates a signer that uses the specified signer
+// definition, instead of the default signer in the config file.
+func NewConfiguredSigner(c *config.Config, signer config.SigningPlugin) *Signer {
+	return &Signer{
+		Config: c,
+		signer: &signer,
+	}
+}
+
 // NewSigningPluginConfig for signing sources.
 func NewSigningPluginConfig() pluggable.PluginTypeConfig {
 	return pluggable.PluginTypeConfig{
@@ -94,6 +106,14 @@ func (s *Signer) Connect(ctx context.Context) error {
 	defer span.EndSpan()
 
 	pluginType := NewSigningPluginConfig()
+	if s.signer != nil {
+		pluginType.GetDefaultPluggable = func(c *config.Config) string {
+			return s.signer.Name
+		}
+		pluginType.GetPluggable = func(c *config.Config, name string) (pluggable.Entry, error) {
+			return *s.signer, nil
+		}
+	}
 
 	l := pluggable.NewPluginLoader(s.Config)
 	conn, err := l.Load(ctx, pluginType)
@@ -0,0 +1,50 @@
+package sops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"filippo.io/age"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/keyservice"
+)
+
+// ParseIdentities parses the contents of an age identity file, which contains
+// one identity per line. Blank lines and comments starting with # are ignored.
+func ParseIdentities(data []byte) ([]age.Identity, error) {
+	identities, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+var _ keyservice.KeyServiceServer = ageKeyService{}
+
+// ageKeyService decrypts the data key of a document with the age identities
+// configured in Porter, instead of the identities that SOPS loads from its
+// environment variables and default key file.
+type ageKeyService struct {
+	identities sopsage.ParsedIdentities
+}
+
+func (s ageKeyService) Encrypt(ctx context.Context, req *keyservice.EncryptRequest) (*keyservice.EncryptResponse, error) {
+	return nil, fmt.Errorf("encrypting with %s is not supported", req.Key)
+}
+
+func (s ageKeyService) Decrypt(ctx context.Context, req *keyservice.DecryptRequest) (*keyservice.DecryptResponse, error) {
+	ageKey := req.Key.GetAgeKey()
+	if ageKey == nil {
+		return nil, fmt.Errorf("only age keys are supported, the data key could not be decrypted with %s", req.Key)
+	}
+
+	key := &sopsage.MasterKey{Recipient: ageKey.Recipient}
+	key.SetEncryptedDataKey(req.Ciphertext)
+	s.identities.ApplyToMasterKey(key)
+	plaintext, err := key.Decrypt()
+	if err != nil {
+		return nil, err
+	}
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
@@ -0,0 +1,7 @@
+// Package sops decrypts documents that were encrypted with SOPS
+// (https://github.com/getsops/sops) using age identities. Only decryption is
+// supported, with the age X25519 recipient type, so that encrypted credential
+// and parameter set files can be committed to source control and decrypted
+// in memory by Porter. Documents are decrypted with the SOPS and age libraries,
+// using the age identities configured for Porter.
+package sops
@@ -0,0 +1,58 @@
+package sops
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/stretchr/testify/require"
+)
+
+// NewTestIdentity generates a random age identity, returning the identity
+// in the format used by age key files.
+func NewTestIdentity(t *testing.T) (string, *age.X25519Identity) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity.String(), identity
+}
+
+// EncryptTestDocument encrypts the values of keys named "value" in a YAML or
+// JSON document, as sops --encrypt --age RECIPIENT --encrypted-regex '^value$' does.
+// Porter only decrypts SOPS documents, this is used to create test data.
+func EncryptTestDocument(t *testing.T, recipient *age.X25519Identity, format string, doc string) []byte {
+	store, err := newStore(format)
+	require.NoError(t, err)
+	branches, err := store.LoadPlainFile([]byte(doc))
+	require.NoError(t, err)
+
+	key, err := sopsage.MasterKeyFromRecipient(recipient.Recipient().String())
+	require.NoError(t, err)
+	tree := sops.Tree{
+		Branches: branches,
+		Metadata: sops.Metadata{
+			KeyGroups:      []sops.KeyGroup{{key}},
+			EncryptedRegex: "^value$",
+			Version:        "3.9.4",
+		},
+	}
+
+	dataKey := make([]byte, 32)
+	_, err = rand.Read(dataKey)
+	require.NoError(t, err)
+	require.NoError(t, key.Encrypt(dataKey))
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Encrypt(dataKey, cipher)
+	require.NoError(t, err)
+	tree.Metadata.LastModified = time.Now().UTC()
+	tree.Metadata.MessageAuthenticationCode, err = cipher.Encrypt(mac, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	require.NoError(t, err)
+
+	data, err := store.EmitEncryptedFile(tree)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,83 @@
+package sops
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"filippo.io/age"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/getsops/sops/v3/stores/json"
+	sopsyaml "github.com/getsops/sops/v3/stores/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+// metadataKey is the key of the SOPS metadata in an encrypted document.
+const metadataKey = "sops"
+
+// IsEncrypted determines if a YAML or JSON document was encrypted with SOPS.
+func IsEncrypted(data []byte) bool {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	md, ok := doc[metadataKey].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = md["mac"]
+	return ok
+}
+
+// Decrypt a YAML or JSON document that was encrypted with SOPS, using the
+// specified age identities. The integrity of the document is verified, and
+// the decrypted document is returned in the same format, without the SOPS metadata.
+func Decrypt(format string, data []byte, identities []age.Identity) ([]byte, error) {
+	store, err := newStore(format)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the encrypted document: %w", err)
+	}
+
+	svc := keyservice.NewCustomLocalClient(ageKeyService{identities: sopsage.ParsedIdentities(identities)})
+	dataKey, err := tree.Metadata.GetDataKeyWithKeyServices([]keyservice.KeyServiceClient{svc}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("none of the configured age identities can decrypt the document: %w", err)
+	}
+
+	// Verify that the document was not modified after it was encrypted
+	cipher := aes.NewCipher()
+	computedMAC, err := tree.Decrypt(dataKey, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt the document: %w", err)
+	}
+	fileMAC, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt the MAC of the document: %w", err)
+	}
+	if fileMAC != computedMAC {
+		return nil, errors.New("the MAC of the document does not match its contents, the document may have been tampered with")
+	}
+
+	return store.EmitPlainFile(tree.Branches)
+}
+
+// newStore returns the SOPS store that reads and writes documents in the specified format.
+func newStore(format string) (sops.Store, error) {
+	switch format {
+	case "yaml", "yml":
+		return sopsyaml.NewStore(&config.YAMLStoreConfig{}), nil
+	case "json":
+		return json.NewStore(&config.JSONStoreConfig{}), nil
+	default:
+		return nil, fmt.Errorf("documents in the %s format cannot be decrypted with SOPS, only yaml and json are supported", format)
+	}
+}
@@ -0,0 +1,146 @@
+package sops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const testCredentialSet = `schemaType: CredentialSet
+schemaVersion: 1.0.1
+name: mycreds
+credentials:
+  - name: password
+    source:
+      value: topsecret
+  - name: port
+    source:
+      value: 5432
+  - name: kubeconfig
+    source:
+      path: /home/me/.kube/config
+`
+
+// The documents in testdata were encrypted with sops 3.9.4 for the age
+// identity in testdata/keys.txt:
+//
+//	sops --encrypt --age RECIPIENT --unencrypted-suffix _unencrypted credentials.yaml
+//	sops --encrypt --age RECIPIENT --encrypted-regex '^value$' parameters.json
+//
+// credentials-tampered.yaml is credentials.yaml with an unencrypted value changed.
+func loadTestIdentities(t *testing.T) []age.Identity {
+	data, err := os.ReadFile("testdata/keys.txt")
+	require.NoError(t, err)
+	identities, err := ParseIdentities(data)
+	require.NoError(t, err)
+	return identities
+}
+
+func readTestFile(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseIdentities(t *testing.T) {
+	identities := loadTestIdentities(t)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "age15e6fllxeag0j7hw47n7g922d8e3ah745h7zmgvm8vhaf0tensg3skm6r97", identities[0].(*age.X25519Identity).Recipient().String())
+
+	_, err := ParseIdentities([]byte("# no keys\n"))
+	require.ErrorContains(t, err, "no secret keys found")
+
+	_, err = ParseIdentities([]byte("age15e6fllxeag0j7hw47n7g922d8e3ah745h7zmgvm8vhaf0tensg3skm6r97"))
+	require.ErrorContains(t, err, "error at line 1")
+}
+
+func TestIsEncrypted(t *testing.T) {
+	_, identity := NewTestIdentity(t)
+
+	assert.False(t, IsEncrypted([]byte(testCredentialSet)))
+	assert.False(t, IsEncrypted([]byte("not: [valid")))
+	assert.True(t, IsEncrypted(EncryptTestDocument(t, identity, "yaml", testCredentialSet)))
+	assert.True(t, IsEncrypted(readTestFile(t, "credentials.yaml")))
+	assert.True(t, IsEncrypted(readTestFile(t, "parameters.json")))
+}
+
+func TestDecrypt_SopsFixtures(t *testing.T) {
+	identities := loadTestIdentities(t)
+	_, other := NewTestIdentity(t)
+
+	t.Run("yaml", func(t *testing.T) {
+		decrypted, err := Decrypt("yaml", readTestFile(t, "credentials.yaml"), append([]age.Identity{other}, identities...))
+		require.NoError(t, err)
+
+		var doc map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(decrypted, &doc))
+		assert.NotContains(t, doc, "sops")
+		assert.Equal(t, "mycreds", doc["name"])
+		assert.Equal(t, map[string]interface{}{"team": "data", "env_unencrypted": "dev"}, doc["labels"], "nested and unencrypted values should be read")
+		assert.Equal(t, []interface{}{"db1.example.com", "db2.example.com"}, doc["hosts"])
+
+		creds := doc["credentials"].([]interface{})
+		require.Len(t, creds, 4)
+		sources := make([]interface{}, len(creds))
+		for i, c := range creds {
+			sources[i] = c.(map[string]interface{})["source"]
+		}
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"value": "topsecret"},
+			map[string]interface{}{"value": 5432},
+			map[string]interface{}{"value": true},
+			map[string]interface{}{"path": "/home/me/.kube/config"},
+		}, sources, "the values should be decrypted with their original types")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		decrypted, err := Decrypt("json", readTestFile(t, "parameters.json"), identities)
+		require.NoError(t, err)
+		assert.Contains(t, string(decrypted), `"value": "topsecret"`)
+		assert.Contains(t, string(decrypted), `"value": 3`)
+		assert.NotContains(t, string(decrypted), "sops")
+	})
+
+	t.Run("wrong identity", func(t *testing.T) {
+		_, err := Decrypt("yaml", readTestFile(t, "credentials.yaml"), []age.Identity{other})
+		require.ErrorContains(t, err, "none of the configured age identities can decrypt the document")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		_, err := Decrypt("yaml", readTestFile(t, "credentials-tampered.yaml"), identities)
+		require.EqualError(t, err, "the MAC of the document does not match its contents, the document may have been tampered with")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := Decrypt("toml", readTestFile(t, "credentials.yaml"), identities)
+		require.EqualError(t, err, "documents in the toml format cannot be decrypted with SOPS, only yaml and json are supported")
+	})
+}
+
+func TestDecrypt_EncryptTestDocument(t *testing.T) {
+	_, identity := NewTestIdentity(t)
+
+	t.Run("yaml", func(t *testing.T) {
+		encrypted := EncryptTestDocument(t, identity, "yaml", testCredentialSet)
+		assert.NotContains(t, string(encrypted), "topsecret")
+
+		decrypted, err := Decrypt("yaml", encrypted, []age.Identity{identity})
+		require.NoError(t, err)
+		assert.Contains(t, string(decrypted), "value: topsecret")
+		assert.Contains(t, string(decrypted), "value: 5432")
+		assert.Contains(t, string(decrypted), "path: /home/me/.kube/config")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		encrypted := EncryptTestDocument(t, identity, "json", `{"name": "mycreds", "credentials": [{"name": "password", "source": {"value": "topsecret"}}]}`)
+
+		decrypted, err := Decrypt("json", encrypted, []age.Identity{identity})
+		require.NoError(t, err)
+		assert.Contains(t, string(decrypted), `"value": "topsecret"`)
+	})
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	// BackupFormatVersion is the version of the archive layout written by
+	// porter storage export. Porter can import archives with the same major
+	// version.
+	BackupFormatVersion = "1.0.0"
+
+	// BackupManifestFile is the name of the file in the archive that describes the backup.
+	BackupManifestFile = "manifest.json"
+
+	// BackupSchemaFile is the name of the file in the archive that contains the
+	// storage schema document of the exported data.
+	BackupSchemaFile = "schema.json"
+)
+
+// BackupManifest describes the contents of a backup archive.
+type BackupManifest struct {
+	// FormatVersion is the version of the archive layout.
+	FormatVersion string `json:"formatVersion"`
+
+	// PorterVersion is the version of Porter that created the backup.
+	PorterVersion string `json:"porterVersion"`
+
+	// Created is the timestamp when the backup was created.
+	Created time.Time `json:"created"`
+
+	// Namespaces that were exported. When empty, all namespaces were exported.
+	// Credential and parameter sets in the global namespace are always exported.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// Backup is the data stored in an archive created by porter storage export.
+// Sensitive values are never stored in the archive, only the keys of the
+// secrets that hold them.
+type Backup struct {
+	Manifest       BackupManifest
+	Schema         Schema
+	Installations  []Installation
+	Runs           []Run
+	Results        []Result
+	Outputs        []Output
+	CredentialSets []CredentialSet
+	ParameterSets  []ParameterSet
+}
+
+// backupEntry is a file in a backup archive.
+type backupEntry struct {
+	name  string
+	value interface{}
+}
+
+func (b *Backup) entries() []backupEntry {
+	return []backupEntry{
+		{BackupManifestFile, &b.Manifest},
+		{BackupSchemaFile, &b.Schema},
+		{CollectionInstallations + ".json", &b.Installations},
+		{CollectionRuns + ".json", &b.Runs},
+		{CollectionResults + ".json", &b.Results},
+		{CollectionOutputs + ".json", &b.Outputs},
+		{CollectionCredentials + ".json", &b.CredentialSets},
+		{CollectionParameters + ".json", &b.ParameterSets},
+	}
+}
+
+// WriteBackup writes the backup to a gzip compressed tarball.
+func WriteBackup(w io.Writer, b Backup) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range b.entries() {
+		data, err := json.MarshalIndent(entry.value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling %s: %w", entry.name, err)
+		}
+
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: b.Manifest.Created,
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing %s to the archive: %w", entry.name, err)
+		}
+		if _, err = tw.Write(data); err != nil {
+			return fmt.Errorf("error writing %s to the archive: %w", entry.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error closing the archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error closing the archive: %w", err)
+	}
+	return nil
+}
+
+// ReadBackup reads a backup from a gzip compressed tarball created by WriteBackup.
+func ReadBackup(r io.Reader) (Backup, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Backup{}, fmt.Errorf("error reading the archive, it is not a backup created by porter storage export: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Backup{}, fmt.Errorf("error reading the archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return Backup{}, fmt.Errorf("error reading %s from the archive: %w", header.Name, err)
+		}
+		files[path.Clean(header.Name)] = data
+	}
+
+	var b Backup
+	manifestData, ok := files[BackupManifestFile]
+	if !ok {
+		return Backup{}, fmt.Errorf("the archive does not contain %s, it is not a backup created by porter storage export", BackupManifestFile)
+	}
+	if err = json.Unmarshal(manifestData, &b.Manifest); err != nil {
+		return Backup{}, fmt.Errorf("error parsing %s from the archive: %w", BackupManifestFile, err)
+	}
+	if err = b.Manifest.Validate(); err != nil {
+		return Backup{}, err
+	}
+
+	for _, entry := range b.entries() {
+		if entry.name == BackupManifestFile {
+			continue
+		}
+
+		data, ok := files[entry.name]
+		if !ok {
+			return Backup{}, fmt.Errorf("the archive is missing %s", entry.name)
+		}
+		if err = json.Unmarshal(data, entry.value); err != nil {
+			return Backup{}, fmt.Errorf("error parsing %s from the archive: %w", entry.name, err)
+		}
+	}
+
+	return b, nil
+}
+
+// Validate checks that the backup was written in a format that this version of Porter can read.
+func (m BackupManifest) Validate() error {
+	supported := semver.MustParse(BackupFormatVersion)
+	v, err := semver.NewVersion(m.FormatVersion)
+	if err != nil {
+		return fmt.Errorf("invalid backup format version %q: %w", m.FormatVersion, err)
+	}
+
+	if v.Major() != supported.Major() {
+		return fmt.Errorf("unsupported backup format version %s, this version of porter supports version %s", m.FormatVersion, BackupFormatVersion)
+	}
+	return nil
+}
+
+// SecretKeys returns the keys of the secrets that Porter created in its secret
+// store for the sensitive parameter and output values referenced by the backup.
+// Secrets referenced by credential and parameter sets are managed by the user
+// and are not included.
+func (b Backup) SecretKeys() []string {
+	owners := b.SecretOwners()
+	result := make([]string, 0, len(owners))
+	for key := range owners {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SecretOwners returns the owner of each secret returned by SecretKeys, keyed by the secret key.
+func (b Backup) SecretOwners() map[string]SecretOwner {
+	owners := make(map[string]SecretOwner)
+	addParameters := func(pset ParameterSet, owner SecretOwner) {
+		for _, key := range SensitiveParameterKeys(pset, owner.ID) {
+			owners[key] = owner
+		}
+	}
+
+	for _, inst := range b.Installations {
+		addParameters(inst.Parameters, inst.SecretOwner())
+	}
+	for _, run := range b.Runs {
+		addParameters(run.Parameters, run.SecretOwner())
+		addParameters(run.ParameterOverrides, run.SecretOwner())
+	}
+	for _, output := range b.Outputs {
+		if output.Key != "" {
+			owners[output.Key] = SecretOwner{Kind: SecretOwnerRun, ID: output.RunID}
+		}
+	}
+	return owners
+}
+
+// FilterNamespaces returns a copy of the backup that only contains documents
+// defined in the specified namespaces. Credential and parameter sets in the
+// global namespace are always kept, because installations in any namespace may
+// use them. When no namespaces are specified, the backup is returned unchanged.
+func (b Backup) FilterNamespaces(namespaces []string) Backup {
+	if len(namespaces) == 0 {
+		return b
+	}
+
+	include := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		include[ns] = true
+	}
+
+	filtered := Backup{Manifest: b.Manifest, Schema: b.Schema}
+	filtered.Manifest.Namespaces = namespaces
+	for _, inst := range b.Installations {
+		if include[inst.Namespace] {
+			filtered.Installations = append(filtered.Installations, inst)
+		}
+	}
+	for _, run := range b.Runs {
+		if include[run.Namespace] {
+			filtered.Runs = append(filtered.Runs, run)
+		}
+	}
+	for _, result := range b.Results {
+		if include[result.Namespace] {
+			filtered.Results = append(filtered.Results, result)
+		}
+	}
+	for _, output := range b.Outputs {
+		if include[output.Namespace] {
+			filtered.Outputs = append(filtered.Outputs, output)
+		}
+	}
+	for _, cs := range b.CredentialSets {
+		if include[cs.Namespace] || cs.Namespace == "" {
+			filtered.CredentialSets = append(filtered.CredentialSets, cs)
+		}
+	}
+	for _, ps := range b.ParameterSets {
+		if include[ps.Namespace] || ps.Namespace == "" {
+			filtered.ParameterSets = append(filtered.ParameterSets, ps)
+		}
+	}
+
+	return filtered
+}
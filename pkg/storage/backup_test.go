@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBackup() Backup {
+	devInst := NewInstallation("dev", "mysql")
+	devInst.Parameters = NewParameterSet("dev", "", secrets.SourceMap{
+		Name:   "password",
+		Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: devInst.ID + "-password"},
+	})
+	globalInst := NewInstallation("", "wordpress")
+
+	run := devInst.NewRun(cnab.ActionInstall, cnab.ExtendedBundle{})
+	run.Parameters = NewParameterSet("dev", "", secrets.SourceMap{
+		Name:   "password",
+		Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: run.ID + "-password"},
+	})
+	result := run.NewResult(cnab.StatusSucceeded)
+	output := result.NewOutput("connstr", nil)
+	output.Key = run.ID + "-connstr"
+
+	return Backup{
+		Manifest: BackupManifest{
+			FormatVersion: BackupFormatVersion,
+			PorterVersion: "v1.2.3",
+			Created:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Schema:        NewSchema(),
+		Installations: []Installation{devInst, globalInst},
+		Runs:          []Run{run},
+		Results:       []Result{result},
+		Outputs:       []Output{output},
+		CredentialSets: []CredentialSet{
+			NewCredentialSet("dev", "azure", secrets.SourceMap{
+				Name:   "token",
+				Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: "azure-token"},
+			}),
+		},
+		ParameterSets: []ParameterSet{NewParameterSet("", "mysql")},
+	}
+}
+
+func TestBackup_RoundTrip(t *testing.T) {
+	backup := newTestBackup()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBackup(&buf, backup))
+
+	got, err := ReadBackup(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, backup.Manifest, got.Manifest)
+	assert.Equal(t, backup.Schema, got.Schema)
+	require.Len(t, got.Installations, 2)
+	assert.Equal(t, backup.Installations[0].ID, got.Installations[0].ID)
+	assert.Equal(t, backup.Installations[0].Parameters.Parameters, got.Installations[0].Parameters.Parameters)
+	require.Len(t, got.Runs, 1)
+	assert.Equal(t, backup.Runs[0].ID, got.Runs[0].ID)
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, backup.Results[0].ID, got.Results[0].ID)
+	require.Len(t, got.Outputs, 1)
+	assert.Equal(t, backup.Outputs[0].Key, got.Outputs[0].Key)
+	require.Len(t, got.CredentialSets, 1)
+	assert.Equal(t, backup.CredentialSets[0].Credentials, got.CredentialSets[0].Credentials)
+	require.Len(t, got.ParameterSets, 1)
+}
+
+func TestReadBackup_UnsupportedFormat(t *testing.T) {
+	backup := newTestBackup()
+	backup.Manifest.FormatVersion = "2.0.0"
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBackup(&buf, backup))
+
+	_, err := ReadBackup(&buf)
+	require.EqualError(t, err, "unsupported backup format version 2.0.0, this version of porter supports version "+BackupFormatVersion)
+
+	_, err = ReadBackup(bytes.NewBufferString("not an archive"))
+	require.ErrorContains(t, err, "it is not a backup created by porter storage export")
+}
+
+func TestBackup_SecretKeys(t *testing.T) {
+	backup := newTestBackup()
+	inst := backup.Installations[0]
+	run := backup.Runs[0]
+
+	// The credential set secret is managed by the user, so it isn't included
+	wantKeys := []string{inst.ID + "-password", run.ID + "-connstr", run.ID + "-password"}
+	assert.ElementsMatch(t, wantKeys, backup.SecretKeys())
+}
+
+func TestBackup_FilterNamespaces(t *testing.T) {
+	backup := newTestBackup()
+
+	t.Run("all namespaces", func(t *testing.T) {
+		assert.Equal(t, backup, backup.FilterNamespaces(nil))
+	})
+
+	t.Run("global namespace", func(t *testing.T) {
+		got := backup.FilterNamespaces([]string{""})
+		require.Len(t, got.Installations, 1)
+		assert.Equal(t, "wordpress", got.Installations[0].Name)
+		assert.Empty(t, got.Runs)
+		assert.Empty(t, got.Results)
+		assert.Empty(t, got.Outputs)
+		assert.Empty(t, got.CredentialSets)
+		assert.Len(t, got.ParameterSets, 1)
+		assert.Empty(t, got.SecretKeys(), "only secrets referenced by the selected documents should be kept")
+	})
+
+	t.Run("dev namespace", func(t *testing.T) {
+		got := backup.FilterNamespaces([]string{"dev"})
+		require.Len(t, got.Installations, 1)
+		assert.Equal(t, "mysql", got.Installations[0].Name)
+		assert.Len(t, got.Runs, 1)
+		assert.Len(t, got.Results, 1)
+		assert.Len(t, got.Outputs, 1)
+		assert.Len(t, got.CredentialSets, 1)
+		assert.Len(t, got.ParameterSets, 1, "parameter sets in the global namespace should always be kept")
+		assert.Len(t, got.SecretKeys(), 3)
+		assert.Equal(t, []string{"dev"}, got.Manifest.Namespaces)
+	})
+}
@@ -66,6 +66,7 @@ func (s CredentialStore) ResolveAll(ctx context.Context, creds CredentialSet, ke
 
 	resolvedCreds := make(secrets.Set)
 	var resolveErrors *multierror.Error
+	resolver := newTemplateResolver(CollectionCredentials, creds.Credentials, s.resolveSource)
 
 	for _, key := range keys {
 		cred, ok := creds.GetCredential(key)
@@ -74,13 +75,7 @@ func (s CredentialStore) ResolveAll(ctx context.Context, creds CredentialSet, ke
 			continue
 		}
 
-		var value string
-		var err error
-		if isHandledByHostPlugin(cred.Source.Strategy) {
-			value, err = s.HostSecrets.Resolve(ctx, cred.Source.Strategy, cred.Source.Hint)
-		} else {
-			value, err = s.Secrets.Resolve(ctx, cred.Source.Strategy, cred.Source.Hint)
-		}
+		value, err := resolver.Resolve(ctx, *cred)
 		if err != nil {
 			resolveErrors = multierror.Append(resolveErrors, span.Errorf("unable to resolve credential %s.%s from %s %s: %w", creds.Name, cred.Name, cred.Source.Strategy, cred.Source.Hint, err))
 		}
@@ -92,7 +87,7 @@ func (s CredentialStore) ResolveAll(ctx context.Context, creds CredentialSet, ke
 }
 
 func (s CredentialStore) Validate(ctx context.Context, creds CredentialSet) error {
-	validSources := []string{secrets.SourceSecret, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand}
+	validSources := []string{secrets.SourceSecret, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand, secrets.SourceTemplate}
 	var errors error
 
 	for _, cs := range creds.Credentials {
@@ -112,9 +107,21 @@ func (s CredentialStore) Validate(ctx context.Context, creds CredentialSet) erro
 		}
 	}
 
+	if err := validateTemplateSources(CollectionCredentials, creds.Credentials); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+
 	return errors
 }
 
+// resolveSource resolves the value of a source that is not a template.
+func (s CredentialStore) resolveSource(ctx context.Context, strategy string, hint string) (string, error) {
+	if isHandledByHostPlugin(strategy) {
+		return s.HostSecrets.Resolve(ctx, strategy, hint)
+	}
+	return s.Secrets.Resolve(ctx, strategy, hint)
+}
+
 /*
   Document Storage
 */
@@ -150,7 +157,8 @@ func (s CredentialStore) UpdateCredentialSet(ctx context.Context, creds Credenti
 	opts := UpdateOptions{
 		Document: creds,
 	}
-	return s.Documents.Update(ctx, CollectionCredentials, opts)
+	_, err := s.Documents.Update(ctx, CollectionCredentials, opts)
+	return err
 }
 
 func (s CredentialStore) UpsertCredentialSet(ctx context.Context, creds CredentialSet) error {
@@ -159,7 +167,8 @@ func (s CredentialStore) UpsertCredentialSet(ctx context.Context, creds Credenti
 		Document: creds,
 		Upsert:   true,
 	}
-	return s.Documents.Update(ctx, CollectionCredentials, opts)
+	_, err := s.Documents.Update(ctx, CollectionCredentials, opts)
+	return err
 }
 
 func (s CredentialStore) RemoveCredentialSet(ctx context.Context, namespace string, name string) error {
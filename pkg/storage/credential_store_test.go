@@ -111,3 +111,22 @@ func TestCredentialStorage_Validate_BadSources(t *testing.T) {
 	err := s.Validate(context.Background(), testCreds)
 	require.Error(t, err, "Validate returned errors")
 }
+
+func TestCredentialStorage_Validate_Templates(t *testing.T) {
+	s := CredentialStore{}
+	testCreds := NewCredentialSet("dev", "mycreds",
+		secrets.SourceMap{
+			Name:   "password",
+			Source: secrets.Source{Strategy: "secret", Hint: "db-password"},
+		},
+		secrets.SourceMap{
+			Name:   "connstr",
+			Source: secrets.Source{Strategy: "template", Hint: "${env.DB_USER}:${credentials.password}"},
+		})
+	require.NoError(t, s.Validate(context.Background(), testCreds))
+
+	testCreds.Credentials[0].Source = secrets.Source{Strategy: "template", Hint: "${credentials.connstr}"}
+	err := s.Validate(context.Background(), testCreds)
+	require.ErrorContains(t, err, "cycle detected in template: password -> connstr -> password")
+	require.ErrorContains(t, err, "cycle detected in template: connstr -> password -> connstr")
+}
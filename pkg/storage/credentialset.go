@@ -104,6 +104,17 @@ func (c CredentialSetStatus) MarshalJSON() ([]byte, error) {
 	return json.Marshal(raw)
 }
 
+// SecretsOwnerID identifies the credential set in the keys of the secrets that
+// Porter saves for it, such as the values from an encrypted credential set file.
+func (s CredentialSet) SecretsOwnerID() string {
+	return fmt.Sprintf("credentialset-%s-%s", s.Namespace, s.Name)
+}
+
+// SecretOwner identifies the credential set as the owner of the secrets that Porter saves for it.
+func (s CredentialSet) SecretOwner() SecretOwner {
+	return SecretOwner{Kind: SecretOwnerCredentialSet, ID: s.SecretsOwnerID()}
+}
+
 func (s CredentialSet) DefaultDocumentFilter() map[string]interface{} {
 	return map[string]interface{}{"namespace": s.Namespace, "name": s.Name}
 }
@@ -24,6 +24,10 @@ type Installation struct {
 	// ID is the unique identifier for an installation record.
 	ID string `json:"id"`
 
+	// DocumentRevision changes each time the installation document is saved,
+	// and is used to detect when another process modified the installation after it was read.
+	DocumentRevision string `json:"documentRevision,omitempty"`
+
 	InstallationSpec
 
 	// Status of the installation.
@@ -76,6 +80,12 @@ func (i Installation) DefaultDocumentFilter() map[string]interface{} {
 	return map[string]interface{}{"namespace": i.Namespace, "name": i.Name}
 }
 
+// SecretOwner identifies the installation as the owner of the secrets that
+// Porter saves for its sensitive parameters.
+func (i Installation) SecretOwner() SecretOwner {
+	return SecretOwner{Kind: SecretOwnerInstallation, ID: i.ID}
+}
+
 func NewInstallation(namespace string, name string) Installation {
 	now := time.Now()
 	return Installation{
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+)
+
+const (
+	// CollectionInstallationLocks is the collection that stores the locks held on installations
+	// while a bundle is executing.
+	CollectionInstallationLocks = "installationLocks"
+
+	// DefaultInstallationLockTTL is how long a lock is valid after it was last renewed.
+	// A lock that isn't renewed, for example because porter crashed, may be taken over once it expires.
+	DefaultInstallationLockTTL = 2 * time.Minute
+)
+
+var _ Document = InstallationLock{}
+
+// InstallationLock is a lease on an installation held by the process that is
+// executing a bundle against it. The holder renews the lock with a heartbeat
+// until it is released.
+type InstallationLock struct {
+	// ID of the lock document. The ID is kept when an expired lock is taken over by another process.
+	ID string `json:"_id"`
+
+	// Revision changes each time the lock is acquired or renewed. The lock is
+	// only renewed, released or taken over when it still has the revision
+	// that was read, so that two processes cannot both hold the lock.
+	Revision string `json:"revision"`
+
+	// Namespace of the locked installation.
+	Namespace string `json:"namespace"`
+
+	// Installation is the name of the locked installation.
+	Installation string `json:"installation"`
+
+	// Owner describes the process holding the lock, for example the user, host and process id.
+	Owner string `json:"owner"`
+
+	// Acquired is when the lock was acquired.
+	Acquired time.Time `json:"acquired"`
+
+	// Heartbeat is when the lock was last renewed by its owner.
+	Heartbeat time.Time `json:"heartbeat"`
+
+	// Expires is when the lock may be taken over by another process if it isn't renewed.
+	Expires time.Time `json:"expires"`
+}
+
+// NewInstallationLock creates a lock on the specified installation, valid for the ttl.
+func NewInstallationLock(namespace string, installation string, owner string, ttl time.Duration) InstallationLock {
+	now := time.Now()
+	return InstallationLock{
+		ID:           cnab.NewULID(),
+		Revision:     cnab.NewULID(),
+		Namespace:    namespace,
+		Installation: installation,
+		Owner:        owner,
+		Acquired:     now,
+		Heartbeat:    now,
+		Expires:      now.Add(ttl),
+	}
+}
+
+func (l InstallationLock) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"_id": l.ID}
+}
+
+// revisionFilter matches the lock only when it has not been modified since it was read.
+func (l InstallationLock) revisionFilter() map[string]interface{} {
+	return map[string]interface{}{"_id": l.ID, "revision": l.Revision}
+}
+
+// IsExpired determines if the lock has not been renewed before its expiration.
+func (l InstallationLock) IsExpired(now time.Time) bool {
+	return now.After(l.Expires)
+}
+
+// String returns the namespace and name of the locked installation.
+func (l InstallationLock) String() string {
+	return fmt.Sprintf("%s/%s", l.Namespace, l.Installation)
+}
+
+// ErrInstallationLocked indicates that another process holds the lock on an installation.
+// You can test for this error using errors.Is(err, storage.ErrInstallationLocked{})
+type ErrInstallationLocked struct {
+	Lock InstallationLock
+}
+
+func (e ErrInstallationLocked) Error() string {
+	unlockCmd := fmt.Sprintf("porter installations unlock %s", e.Lock.Installation)
+	if e.Lock.Namespace != "" {
+		unlockCmd += " --namespace " + e.Lock.Namespace
+	}
+	return fmt.Sprintf("installation %s is locked by %s since %s, another action is in progress. The lock expires at %s unless it is renewed. If the process holding the lock is no longer running, remove the lock with %s",
+		e.Lock, e.Lock.Owner, e.Lock.Acquired.Format(time.RFC3339), e.Lock.Expires.Format(time.RFC3339), unlockCmd)
+}
+
+func (e ErrInstallationLocked) Is(err error) bool {
+	_, ok := err.(ErrInstallationLocked)
+	return ok
+}
+
+// ErrInstallationLockLost indicates that a lock could not be renewed because it
+// was removed, or taken over by another process after it expired.
+// You can test for this error using errors.Is(err, storage.ErrInstallationLockLost{})
+type ErrInstallationLockLost struct {
+	Lock InstallationLock
+}
+
+func (e ErrInstallationLockLost) Error() string {
+	return fmt.Sprintf("the lock on installation %s was removed or taken over by another process", e.Lock)
+}
+
+func (e ErrInstallationLockLost) Is(err error) bool {
+	_, ok := err.(ErrInstallationLockLost)
+	return ok
+}
+
+// ErrConflict indicates that a document was modified by another process after
+// it was read, and the changes were not saved.
+// You can test for this error using errors.Is(err, storage.ErrConflict{})
+type ErrConflict struct {
+	Collection string
+	Item       string
+}
+
+func (e ErrConflict) Error() string {
+	return fmt.Sprintf("the %s document %s was modified by another process after it was read, reload it and try again", e.Collection, e.Item)
+}
+
+func (e ErrConflict) Is(err error) bool {
+	_, ok := err.(ErrConflict)
+	return ok
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 )
 
 // InstallationProvider is an interface for interacting with Porter's claim data.
@@ -19,13 +20,17 @@ type InstallationProvider interface {
 	InsertOutput(ctx context.Context, output Output) error
 
 	// UpdateInstallation saves changes to an existing Installation document.
-	UpdateInstallation(ctx context.Context, installation Installation) error
+	// Returns ErrConflict when the document was modified after it was read,
+	// otherwise the installation is updated with its new revision.
+	UpdateInstallation(ctx context.Context, installation *Installation) error
 
 	// UpsertRun saves changes a Run document, creating it if it doesn't already exist.
 	UpsertRun(ctx context.Context, run Run) error
 
 	// UpsertInstallation saves an Installation document, creating it if it doesn't already exist.
-	UpsertInstallation(ctx context.Context, installation Installation) error
+	// Returns ErrConflict when the document was modified after it was read,
+	// otherwise the installation is updated with its new revision.
+	UpsertInstallation(ctx context.Context, installation *Installation) error
 
 	// FindInstallations applies the find operation against installations collection
 	// using the specified options.
@@ -66,6 +71,26 @@ type InstallationProvider interface {
 	// RemoveInstallation by its name.
 	RemoveInstallation(ctx context.Context, namespace string, name string) error
 
+	// RemoveRun by its ID, along with its results and outputs.
+	RemoveRun(ctx context.Context, id string) error
+
+	// AcquireInstallationLock locks an installation so that only the owner
+	// may execute a bundle against it, until the lock is released or expires.
+	// Returns ErrInstallationLocked when another process holds the lock.
+	AcquireInstallationLock(ctx context.Context, namespace string, installation string, owner string, ttl time.Duration) (InstallationLock, error)
+
+	// RenewInstallationLock extends the expiration of a lock held by the caller.
+	RenewInstallationLock(ctx context.Context, lock InstallationLock, ttl time.Duration) (InstallationLock, error)
+
+	// ReleaseInstallationLock removes a lock held by the caller.
+	ReleaseInstallationLock(ctx context.Context, lock InstallationLock) error
+
+	// GetInstallationLock returns the current lock on an installation.
+	GetInstallationLock(ctx context.Context, namespace string, installation string) (InstallationLock, error)
+
+	// RemoveInstallationLock removes the lock on an installation, regardless of which process holds it.
+	RemoveInstallationLock(ctx context.Context, namespace string, installation string) error
+
 	// GetLogs returns the logs from the specified Run.
 	GetLogs(ctx context.Context, runID string) (logs string, hasLogs bool, err error)
 
@@ -33,6 +33,10 @@ func NewTestInstallationProvider(t *testing.T) *TestInstallationProvider {
 }
 
 func NewTestInstallationProviderFor(t *testing.T, testStore TestStore) *TestInstallationProvider {
+	// Porter relies on the unique indices to detect conflicts, for example when two processes lock an installation
+	err := EnsureInstallationIndices(context.Background(), testStore)
+	require.NoError(t, err, "EnsureInstallationIndices failed")
+
 	return &TestInstallationProvider{
 		t:                 t,
 		TestStore:         testStore,
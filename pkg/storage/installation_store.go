@@ -3,7 +3,10 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"get.porter.sh/porter/pkg/cnab"
 	"get.porter.sh/porter/pkg/tracing"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -57,6 +60,8 @@ func EnsureInstallationIndices(ctx context.Context, store Store) error {
 			{Collection: CollectionOutputs, Keys: []string{"resultId", "name"}, Unique: true},
 			// query most recent outputs by name for an installation
 			{Collection: CollectionOutputs, Keys: []string{"namespace", "installation", "name", "-resultId"}},
+			// only allow a single lock per installation
+			{Collection: CollectionInstallationLocks, Keys: []string{"namespace", "installation"}, Unique: true},
 		},
 	}
 
@@ -307,12 +312,8 @@ func (s InstallationStore) InsertOutput(ctx context.Context, output Output) erro
 	return s.store.Insert(ctx, CollectionOutputs, opts)
 }
 
-func (s InstallationStore) UpdateInstallation(ctx context.Context, installation Installation) error {
-	installation.SchemaVersion = DefaultInstallationSchemaVersion
-	opts := UpdateOptions{
-		Document: installation,
-	}
-	return s.store.Update(ctx, CollectionInstallations, opts)
+func (s InstallationStore) UpdateInstallation(ctx context.Context, installation *Installation) error {
+	return s.saveInstallation(ctx, installation, false)
 }
 
 func (s InstallationStore) UpsertRun(ctx context.Context, run Run) error {
@@ -320,16 +321,53 @@ func (s InstallationStore) UpsertRun(ctx context.Context, run Run) error {
 		Upsert:   true,
 		Document: run,
 	}
-	return s.store.Update(ctx, CollectionRuns, opts)
+	_, err := s.store.Update(ctx, CollectionRuns, opts)
+	return err
 }
 
-func (s InstallationStore) UpsertInstallation(ctx context.Context, installation Installation) error {
-	installation.SchemaVersion = DefaultInstallationSchemaVersion
+func (s InstallationStore) UpsertInstallation(ctx context.Context, installation *Installation) error {
+	return s.saveInstallation(ctx, installation, true)
+}
+
+// saveInstallation replaces the installation document when it has not been
+// modified since it was read, and assigns a new revision to the document.
+func (s InstallationStore) saveInstallation(ctx context.Context, installation *Installation, upsert bool) error {
+	updated := *installation
+	updated.SchemaVersion = DefaultInstallationSchemaVersion
+	updated.DocumentRevision = cnab.NewULID()
+
+	// Only replace the document when it has the revision that we read.
+	// When the revision is empty, the document should not exist yet or was saved before revisions were tracked.
+	filter := updated.DefaultDocumentFilter()
+	if installation.DocumentRevision != "" {
+		filter["documentRevision"] = installation.DocumentRevision
+	} else {
+		filter["documentRevision"] = nil
+	}
+
 	opts := UpdateOptions{
-		Upsert:   true,
-		Document: installation,
+		Filter:   filter,
+		Upsert:   upsert,
+		Document: updated,
 	}
-	return s.store.Update(ctx, CollectionInstallations, opts)
+	matched, err := s.store.Update(ctx, CollectionInstallations, opts)
+	if err != nil {
+		// When the revision filter didn't match, an upsert fails because the installation already exists
+		if current, getErr := s.GetInstallation(ctx, updated.Namespace, updated.Name); getErr == nil && current.DocumentRevision != installation.DocumentRevision {
+			return ErrConflict{Collection: CollectionInstallations, Item: updated.String()}
+		}
+		return err
+	}
+	if matched == 0 && !upsert {
+		// Report that the installation doesn't exist, otherwise it was modified after it was read
+		if _, err := s.GetInstallation(ctx, updated.Namespace, updated.Name); err != nil {
+			return err
+		}
+		return ErrConflict{Collection: CollectionInstallations, Item: updated.String()}
+	}
+
+	*installation = updated
+	return nil
 }
 
 // RemoveInstallation and all associated data.
@@ -382,3 +420,130 @@ type EncryptionHandler func([]byte) ([]byte, error)
 var noOpEncryptionHandler = func(data []byte) ([]byte, error) {
 	return data, nil
 }
+
+// RemoveRun and all associated data.
+func (s InstallationStore) RemoveRun(ctx context.Context, id string) error {
+	err := s.store.Remove(ctx, CollectionRuns, RemoveOptions{ID: id})
+	if err != nil {
+		return err
+	}
+
+	// Find associated documents
+	removeChildDocs := RemoveOptions{
+		Filter: bson.M{
+			"runId": id,
+		},
+		All: true,
+	}
+
+	// Delete results
+	err = s.store.Remove(ctx, CollectionResults, removeChildDocs)
+	if err != nil {
+		return err
+	}
+
+	// Delete outputs
+	err = s.store.Remove(ctx, CollectionOutputs, removeChildDocs)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s InstallationStore) AcquireInstallationLock(ctx context.Context, namespace string, installation string, owner string, ttl time.Duration) (InstallationLock, error) {
+	lock := NewInstallationLock(namespace, installation, owner, ttl)
+
+	// The lock is unique by installation, so the insert fails when the installation is already locked
+	insertErr := s.store.Insert(ctx, CollectionInstallationLocks, InsertOptions{Documents: []interface{}{lock}})
+	if insertErr == nil {
+		return lock, nil
+	}
+
+	existing, err := s.GetInstallationLock(ctx, namespace, installation)
+	if err != nil {
+		if errors.Is(err, ErrNotFound{}) {
+			return InstallationLock{}, insertErr
+		}
+		return InstallationLock{}, err
+	}
+	if !existing.IsExpired(lock.Acquired) {
+		return InstallationLock{}, ErrInstallationLocked{Lock: existing}
+	}
+
+	return s.takeOverInstallationLock(ctx, existing, lock)
+}
+
+// takeOverInstallationLock replaces an expired lock with a new lock, only when
+// the expired lock has not been renewed or taken over by another process since
+// it was read.
+func (s InstallationStore) takeOverInstallationLock(ctx context.Context, expired InstallationLock, lock InstallationLock) (InstallationLock, error) {
+	lock.ID = expired.ID
+	opts := UpdateOptions{
+		Filter:   expired.revisionFilter(),
+		Document: lock,
+	}
+	matched, err := s.store.Update(ctx, CollectionInstallationLocks, opts)
+	if err != nil {
+		return InstallationLock{}, fmt.Errorf("error taking over the expired lock on installation %s: %w", expired, err)
+	}
+	if matched == 0 {
+		current, err := s.GetInstallationLock(ctx, expired.Namespace, expired.Installation)
+		if err != nil {
+			current = expired
+		}
+		return InstallationLock{}, ErrInstallationLocked{Lock: current}
+	}
+
+	return lock, nil
+}
+
+func (s InstallationStore) RenewInstallationLock(ctx context.Context, lock InstallationLock, ttl time.Duration) (InstallationLock, error) {
+	renewed := lock
+	renewed.Revision = cnab.NewULID()
+	renewed.Heartbeat = time.Now()
+	renewed.Expires = renewed.Heartbeat.Add(ttl)
+
+	// Only renew the lock when it wasn't removed, or taken over by another process after it expired
+	opts := UpdateOptions{
+		Filter:   lock.revisionFilter(),
+		Document: renewed,
+	}
+	matched, err := s.store.Update(ctx, CollectionInstallationLocks, opts)
+	if err != nil {
+		return lock, err
+	}
+	if matched == 0 {
+		return lock, ErrInstallationLockLost{Lock: lock}
+	}
+
+	return renewed, nil
+}
+
+func (s InstallationStore) ReleaseInstallationLock(ctx context.Context, lock InstallationLock) error {
+	// Don't remove the lock when it was taken over by another process after it expired
+	return s.store.Remove(ctx, CollectionInstallationLocks, RemoveOptions{Filter: lock.revisionFilter()})
+}
+
+func (s InstallationStore) GetInstallationLock(ctx context.Context, namespace string, installation string) (InstallationLock, error) {
+	var out InstallationLock
+	opts := FindOptions{
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+		},
+	}
+	err := s.store.FindOne(ctx, CollectionInstallationLocks, opts, &out)
+	return out, err
+}
+
+func (s InstallationStore) RemoveInstallationLock(ctx context.Context, namespace string, installation string) error {
+	opts := RemoveOptions{
+		Filter: bson.M{
+			"namespace":    namespace,
+			"installation": installation,
+		},
+		All: true,
+	}
+	return s.store.Remove(ctx, CollectionInstallationLocks, opts)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"testing"
+	"time"
 
 	"get.porter.sh/porter/pkg/cnab"
 	"github.com/cnabio/cnab-go/bundle"
@@ -116,7 +117,7 @@ func generateInstallationData(t *testing.T) *TestInstallationProvider {
 
 	// Record the status of the foo installation
 	foo.ApplyResult(run, result)
-	require.NoError(t, cp.UpdateInstallation(context.Background(), foo))
+	require.NoError(t, cp.UpdateInstallation(context.Background(), &foo))
 
 	// Create the bar installation data
 	bar := cp.CreateInstallation(NewInstallation("dev", "bar"), func(i *Installation) {
@@ -131,7 +132,7 @@ func generateInstallationData(t *testing.T) *TestInstallationProvider {
 
 	// Record the status of the bar installation
 	bar.ApplyResult(run, result)
-	require.NoError(t, cp.UpdateInstallation(context.Background(), bar))
+	require.NoError(t, cp.UpdateInstallation(context.Background(), &bar))
 
 	// Create the baz installation data
 	baz := cp.CreateInstallation(NewInstallation("dev", "baz"))
@@ -142,7 +143,7 @@ func generateInstallationData(t *testing.T) *TestInstallationProvider {
 
 	// Record the status of the baz installation
 	baz.ApplyResult(run, result)
-	require.NoError(t, cp.UpdateInstallation(context.Background(), baz))
+	require.NoError(t, cp.UpdateInstallation(context.Background(), &baz))
 
 	return cp
 }
@@ -268,6 +269,142 @@ func TestInstallationStorageProvider_DeleteInstallation(t *testing.T) {
 	require.ErrorIs(t, err, ErrNotFound{})
 }
 
+func TestInstallationStorageProvider_RemoveRun(t *testing.T) {
+	cp := generateInstallationData(t)
+	defer cp.Close()
+
+	runs, _, err := cp.ListRuns(context.Background(), "dev", "foo")
+	require.NoError(t, err, "ListRuns failed")
+	require.Len(t, runs, 4, "expected 4 runs")
+	upgrade := runs[1]
+
+	err = cp.RemoveRun(context.Background(), upgrade.ID)
+	require.NoError(t, err, "RemoveRun failed")
+
+	runs, resultsMap, err := cp.ListRuns(context.Background(), "dev", "foo")
+	require.NoError(t, err, "ListRuns failed")
+	assert.Len(t, runs, 3, "expected the upgrade run to be deleted")
+	assert.NotContains(t, resultsMap, upgrade.ID, "expected the results of the upgrade run to be deleted")
+
+	_, err = cp.GetRun(context.Background(), upgrade.ID)
+	require.ErrorIs(t, err, ErrNotFound{})
+
+	output, err := cp.GetLastOutput(context.Background(), "dev", "foo", "output1")
+	require.NoError(t, err, "GetLastOutput failed")
+	assert.Equal(t, "install output1", string(output.Value), "expected the outputs of the upgrade run to be deleted")
+}
+
+func TestInstallationStorageProvider_UpdateInstallation_Conflict(t *testing.T) {
+	cp := generateInstallationData(t)
+	defer cp.Close()
+
+	ctx := context.Background()
+	first, err := cp.GetInstallation(ctx, "dev", "foo")
+	require.NoError(t, err)
+	second, err := cp.GetInstallation(ctx, "dev", "foo")
+	require.NoError(t, err)
+
+	first.Labels = map[string]string{"color": "blue"}
+	originalRevision := first.DocumentRevision
+	require.NoError(t, cp.UpdateInstallation(ctx, &first), "the first update should succeed")
+	assert.NotEqual(t, originalRevision, first.DocumentRevision, "expected the revision to change when the installation is saved")
+
+	second.Labels = map[string]string{"color": "red"}
+	err = cp.UpdateInstallation(ctx, &second)
+	require.ErrorIs(t, err, ErrConflict{}, "expected the update of a stale installation to fail")
+	require.ErrorIs(t, cp.UpsertInstallation(ctx, &second), ErrConflict{}, "expected the upsert of a stale installation to fail")
+
+	saved, err := cp.GetInstallation(ctx, "dev", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "blue", saved.Labels["color"], "the stale update should not have been saved")
+	assert.Equal(t, first.DocumentRevision, saved.DocumentRevision)
+
+	// Updating after reloading the installation succeeds
+	saved.Labels["color"] = "red"
+	require.NoError(t, cp.UpdateInstallation(ctx, &saved))
+
+	missing := NewInstallation("dev", "missing")
+	require.ErrorIs(t, cp.UpdateInstallation(ctx, &missing), ErrNotFound{}, "expected updating an installation that doesn't exist to fail")
+}
+
+func TestInstallationStorageProvider_Locks(t *testing.T) {
+	cp := generateInstallationData(t)
+	defer cp.Close()
+
+	ctx := context.Background()
+	_, err := cp.GetInstallationLock(ctx, "dev", "foo")
+	require.ErrorIs(t, err, ErrNotFound{}, "the installation should not be locked yet")
+
+	lock, err := cp.AcquireInstallationLock(ctx, "dev", "foo", "me", time.Minute)
+	require.NoError(t, err, "AcquireInstallationLock failed")
+	assert.Equal(t, "me", lock.Owner)
+
+	t.Run("held lock", func(t *testing.T) {
+		_, err := cp.AcquireInstallationLock(ctx, "dev", "foo", "someone else", time.Minute)
+		require.ErrorIs(t, err, ErrInstallationLocked{})
+		assert.Contains(t, err.Error(), "installation dev/foo is locked by me")
+		assert.Contains(t, err.Error(), "porter installations unlock foo --namespace dev")
+
+		// Other installations are not locked
+		otherLock, err := cp.AcquireInstallationLock(ctx, "dev", "bar", "someone else", time.Minute)
+		require.NoError(t, err, "a different installation should not be locked")
+		require.NoError(t, cp.ReleaseInstallationLock(ctx, otherLock))
+	})
+
+	t.Run("renew lock", func(t *testing.T) {
+		renewed, err := cp.RenewInstallationLock(ctx, lock, time.Hour)
+		require.NoError(t, err, "RenewInstallationLock failed")
+		assert.Equal(t, lock.ID, renewed.ID)
+		assert.NotEqual(t, lock.Revision, renewed.Revision, "expected the lock revision to change when it is renewed")
+		assert.True(t, renewed.Expires.After(lock.Expires), "expected the lock expiration to be extended")
+
+		stored, err := cp.GetInstallationLock(ctx, "dev", "foo")
+		require.NoError(t, err)
+		assert.Equal(t, renewed.Expires.Unix(), stored.Expires.Unix())
+
+		_, err = cp.RenewInstallationLock(ctx, lock, time.Hour)
+		require.ErrorIs(t, err, ErrInstallationLockLost{}, "a stale copy of the lock cannot be renewed")
+		lock = renewed
+	})
+
+	t.Run("release lock", func(t *testing.T) {
+		require.NoError(t, cp.ReleaseInstallationLock(ctx, lock))
+		_, err := cp.GetInstallationLock(ctx, "dev", "foo")
+		require.ErrorIs(t, err, ErrNotFound{})
+
+		_, err = cp.RenewInstallationLock(ctx, lock, time.Minute)
+		require.ErrorIs(t, err, ErrInstallationLockLost{}, "a released lock cannot be renewed")
+	})
+
+	t.Run("expired lock", func(t *testing.T) {
+		expired, err := cp.AcquireInstallationLock(ctx, "dev", "foo", "crashed", -time.Second)
+		require.NoError(t, err)
+
+		taken, err := cp.AcquireInstallationLock(ctx, "dev", "foo", "me", time.Minute)
+		require.NoError(t, err, "an expired lock should be taken over")
+		assert.NotEqual(t, expired.Revision, taken.Revision)
+
+		// Another process that read the same expired lock cannot also take it over
+		_, err = cp.takeOverInstallationLock(ctx, expired, NewInstallationLock("dev", "foo", "someone else", time.Minute))
+		require.ErrorIs(t, err, ErrInstallationLocked{})
+		assert.Contains(t, err.Error(), "installation dev/foo is locked by me")
+
+		// The process that held the expired lock can no longer renew or release it
+		_, err = cp.RenewInstallationLock(ctx, expired, time.Minute)
+		require.ErrorIs(t, err, ErrInstallationLockLost{})
+		require.NoError(t, cp.ReleaseInstallationLock(ctx, expired))
+		stored, err := cp.GetInstallationLock(ctx, "dev", "foo")
+		require.NoError(t, err)
+		assert.Equal(t, taken.Revision, stored.Revision, "releasing the expired lock must not remove the new lock")
+	})
+
+	t.Run("remove lock", func(t *testing.T) {
+		require.NoError(t, cp.RemoveInstallationLock(ctx, "dev", "foo"))
+		_, err := cp.GetInstallationLock(ctx, "dev", "foo")
+		require.ErrorIs(t, err, ErrNotFound{})
+	})
+}
+
 func TestInstallationStorageProvider_Run(t *testing.T) {
 	cp := generateInstallationData(t)
 
@@ -186,9 +186,9 @@ func (m *Manager) Remove(ctx context.Context, collection string, opts storage.Re
 	return m.store.Remove(ctx, collection, opts)
 }
 
-func (m *Manager) Update(ctx context.Context, collection string, opts storage.UpdateOptions) error {
+func (m *Manager) Update(ctx context.Context, collection string, opts storage.UpdateOptions) (int64, error) {
 	if err := m.Connect(ctx); err != nil {
-		return err
+		return 0, err
 	}
 	return m.store.Update(ctx, collection, opts)
 }
@@ -308,7 +308,7 @@ func (m *Manager) WriteSchema(ctx context.Context) error {
 func WriteSchema(ctx context.Context, store storage.Store) (storage.Schema, error) {
 	schema := storage.NewSchema()
 
-	err := store.Update(ctx, CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
+	_, err := store.Update(ctx, CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
 	if err != nil {
 		return storage.Schema{}, fmt.Errorf("Unable to save storage schema file to the database: %w", err)
 	}
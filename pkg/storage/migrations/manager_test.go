@@ -19,7 +19,7 @@ func TestManager_LoadSchema(t *testing.T) {
 		m := NewTestManager(c)
 		defer m.Close()
 
-		err := m.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
+		_, err := m.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
 		require.NoError(t, err, "Save schema failed")
 
 		err = m.loadSchema(context.Background())
@@ -86,7 +86,7 @@ func TestInstallationStorage_HaltOnMigrationRequired(t *testing.T) {
 
 	schema := storage.NewSchema()
 	schema.Installations = "needs-migration"
-	err := mgr.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
+	_, err := mgr.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
 	require.NoError(t, err, "Save schema failed")
 
 	checkMigrationError := func(t *testing.T, err error) {
@@ -141,7 +141,7 @@ func TestCredentialStorage_HaltOnMigrationRequired(t *testing.T) {
 
 	schema := storage.NewSchema()
 	schema.Credentials = "needs-migration"
-	err := mgr.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
+	_, err := mgr.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
 	require.NoError(t, err, "Save schema failed")
 
 	checkMigrationError := func(t *testing.T, err error) {
@@ -200,7 +200,7 @@ func TestParameterStorage_HaltOnMigrationRequired(t *testing.T) {
 
 	schema := storage.NewSchema()
 	schema.Parameters = "needs-migration"
-	err := mgr.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
+	_, err := mgr.store.Update(context.Background(), CollectionConfig, storage.UpdateOptions{Document: schema, Upsert: true})
 	require.NoError(t, err, "Save schema failed")
 
 	checkMigrationError := func(t *testing.T, err error) {
@@ -228,7 +228,7 @@ func (m *Migration) migrateInstallation(ctx context.Context, installationName st
 	inst.ID = claimIDs[0]
 
 	updateOpts := storage.UpdateOptions{Document: inst, Upsert: true}
-	err = m.destStore.Update(ctx, storage.CollectionInstallations, updateOpts)
+	_, err = m.destStore.Update(ctx, storage.CollectionInstallations, updateOpts)
 	if err != nil {
 		return fmt.Errorf("error upserting migrated installation %s: %w", inst.Name, err)
 	}
@@ -279,7 +279,7 @@ func (m *Migration) migrateClaim(ctx context.Context, inst *storage.Installation
 
 	// Sanitize sensitive values on the source claim
 	bun := cnab.ExtendedBundle{Bundle: run.Bundle}
-	cleanParams, err := m.sanitizer.CleanParameters(ctx, run.Parameters.Parameters, bun, run.ID)
+	cleanParams, err := m.sanitizer.CleanParameters(ctx, run.Parameters.Parameters, bun, run.SecretOwner())
 	if err != nil {
 		return span.Error(err)
 	}
@@ -298,7 +298,7 @@ func (m *Migration) migrateClaim(ctx context.Context, inst *storage.Installation
 	}
 
 	updateOpts := storage.UpdateOptions{Document: run, Upsert: true}
-	err = m.destStore.Update(ctx, storage.CollectionRuns, updateOpts)
+	_, err = m.destStore.Update(ctx, storage.CollectionRuns, updateOpts)
 	if err != nil {
 		return span.Error(err)
 	}
@@ -357,7 +357,7 @@ func (m *Migration) migrateResult(ctx context.Context, inst *storage.Installatio
 	}
 
 	updateOpts := storage.UpdateOptions{Document: result, Upsert: true}
-	err = m.destStore.Update(ctx, storage.CollectionResults, updateOpts)
+	_, err = m.destStore.Update(ctx, storage.CollectionResults, updateOpts)
 	if err != nil {
 		return span.Error(err)
 	}
@@ -424,7 +424,7 @@ func (m *Migration) migrateOutput(ctx context.Context, run storage.Run, result s
 	}
 
 	updateOpts := storage.UpdateOptions{Document: output, Upsert: true}
-	err = m.destStore.Update(ctx, storage.CollectionOutputs, updateOpts)
+	_, err = m.destStore.Update(ctx, storage.CollectionOutputs, updateOpts)
 	if err != nil {
 		return span.Error(fmt.Errorf("error upserting migrated output %s: %w", outputKey, err))
 	}
@@ -489,7 +489,7 @@ func (m *Migration) migrateCredentialSet(ctx context.Context, name string) error
 	}
 
 	updateOpts := storage.UpdateOptions{Document: dest, Upsert: true}
-	err = m.destStore.Update(ctx, storage.CollectionCredentials, updateOpts)
+	_, err = m.destStore.Update(ctx, storage.CollectionCredentials, updateOpts)
 	if err != nil {
 		return span.Error(fmt.Errorf("error upserting migrated credential set %s: %w", name, err))
 	}
@@ -567,7 +567,7 @@ func (m *Migration) migrateParameterSet(ctx context.Context, name string) error
 	}
 
 	updateOpts := storage.UpdateOptions{Document: dest, Upsert: true}
-	err = m.destStore.Update(ctx, storage.CollectionParameters, updateOpts)
+	_, err = m.destStore.Update(ctx, storage.CollectionParameters, updateOpts)
 	if err != nil {
 		return span.Error(fmt.Errorf("error upserting migrated credential set %s: %w", name, err))
 	}
@@ -58,19 +58,14 @@ func (s ParameterStore) GetDataStore() Store {
 func (s ParameterStore) ResolveAll(ctx context.Context, params ParameterSet, keys []string) (secrets.Set, error) {
 	resolvedParams := make(secrets.Set)
 	var resolveErrors error
+	resolver := newTemplateResolver(CollectionParameters, params.Parameters, s.resolveSource)
 
 	for _, param := range params.Parameters {
 		if !slices.Contains(keys, param.Name) {
 			continue
 		}
 
-		var value string
-		var err error
-		if isHandledByHostPlugin(param.Source.Strategy) {
-			value, err = s.HostSecrets.Resolve(ctx, param.Source.Strategy, param.Source.Hint)
-		} else {
-			value, err = s.Secrets.Resolve(ctx, param.Source.Strategy, param.Source.Hint)
-		}
+		value, err := resolver.Resolve(ctx, param)
 		if err != nil {
 			resolveErrors = multierror.Append(resolveErrors, fmt.Errorf("unable to resolve parameter %s.%s from %s %s: %w", params.Name, param.Name, param.Source.Strategy, param.Source.Hint, err))
 		}
@@ -82,7 +77,7 @@ func (s ParameterStore) ResolveAll(ctx context.Context, params ParameterSet, key
 }
 
 func (s ParameterStore) Validate(ctx context.Context, params ParameterSet) error {
-	validSources := []string{secrets.SourceSecret, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand}
+	validSources := []string{secrets.SourceSecret, host.SourceValue, host.SourceEnv, host.SourcePath, host.SourceCommand, secrets.SourceTemplate}
 	var errors error
 
 	for _, cs := range params.Parameters {
@@ -102,9 +97,21 @@ func (s ParameterStore) Validate(ctx context.Context, params ParameterSet) error
 		}
 	}
 
+	if err := validateTemplateSources(CollectionParameters, params.Parameters); err != nil {
+		errors = multierror.Append(errors, err)
+	}
+
 	return errors
 }
 
+// resolveSource resolves the value of a source that is not a template.
+func (s ParameterStore) resolveSource(ctx context.Context, strategy string, hint string) (string, error) {
+	if isHandledByHostPlugin(strategy) {
+		return s.HostSecrets.Resolve(ctx, strategy, hint)
+	}
+	return s.Secrets.Resolve(ctx, strategy, hint)
+}
+
 func (s ParameterStore) InsertParameterSet(ctx context.Context, params ParameterSet) error {
 	params.SchemaVersion = DefaultParameterSetSchemaVersion
 	opts := InsertOptions{
@@ -136,7 +143,8 @@ func (s ParameterStore) UpdateParameterSet(ctx context.Context, params Parameter
 	opts := UpdateOptions{
 		Document: params,
 	}
-	return s.Documents.Update(ctx, CollectionParameters, opts)
+	_, err := s.Documents.Update(ctx, CollectionParameters, opts)
+	return err
 }
 
 func (s ParameterStore) UpsertParameterSet(ctx context.Context, params ParameterSet) error {
@@ -145,7 +153,8 @@ func (s ParameterStore) UpsertParameterSet(ctx context.Context, params Parameter
 		Document: params,
 		Upsert:   true,
 	}
-	return s.Documents.Update(ctx, CollectionParameters, opts)
+	_, err := s.Documents.Update(ctx, CollectionParameters, opts)
+	return err
 }
 
 func (s ParameterStore) RemoveParameterSet(ctx context.Context, namespace string, name string) error {
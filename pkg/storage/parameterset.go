@@ -82,6 +82,17 @@ func NewInternalParameterSet(namespace string, name string, params ...secrets.So
 	return NewParameterSet(namespace, INTERNAL_PARAMETERER_SET+"-"+name, params...)
 }
 
+// SecretsOwnerID identifies the parameter set in the keys of the secrets that
+// Porter saves for it, such as the values from an encrypted parameter set file.
+func (s ParameterSet) SecretsOwnerID() string {
+	return fmt.Sprintf("parameterset-%s-%s", s.Namespace, s.Name)
+}
+
+// SecretOwner identifies the parameter set as the owner of the secrets that Porter saves for it.
+func (s ParameterSet) SecretOwner() SecretOwner {
+	return SecretOwner{Kind: SecretOwnerParameterSet, ID: s.SecretsOwnerID()}
+}
+
 func (s ParameterSet) DefaultDocumentFilter() map[string]interface{} {
 	return map[string]interface{}{"namespace": s.Namespace, "name": s.Name}
 }
@@ -163,3 +174,9 @@ func (s ParameterSet) Keys() []string {
 	}
 	return keys
 }
+
+// SensitiveTemplateKeys returns the names of the parameters that are resolved
+// from a template that uses a sensitive value, such as a secret.
+func (s ParameterSet) SensitiveTemplateKeys() []string {
+	return sensitiveTemplateSources(CollectionParameters, s.Parameters)
+}
@@ -155,3 +155,16 @@ func TestParameterSetValidateBundle(t *testing.T) {
 		assert.Contains(t, err.Error(), `parameter "kubeconfig" is required`)
 	})
 }
+
+func TestParameterSet_SensitiveTemplateKeys(t *testing.T) {
+	ps := NewParameterSet("dev", "myparams",
+		secrets.SourceMap{Name: "password", Source: secrets.Source{Strategy: "secret", Hint: "db-password"}},
+		secrets.SourceMap{Name: "user", Source: secrets.Source{Strategy: "env", Hint: "DB_USER"}},
+		secrets.SourceMap{Name: "host", Source: secrets.Source{Strategy: "template", Hint: "${env.DB_HOST}:5432"}},
+		secrets.SourceMap{Name: "login", Source: secrets.Source{Strategy: "template", Hint: "${parameters.user}:${parameters.password}"}},
+		secrets.SourceMap{Name: "connstr", Source: secrets.Source{Strategy: "template", Hint: "postgres://${parameters.login}@${parameters.host}"}},
+		secrets.SourceMap{Name: "token", Source: secrets.Source{Strategy: "template", Hint: "Bearer ${secret.token}"}},
+	)
+
+	assert.Equal(t, []string{"login", "connstr", "token"}, ps.SensitiveTemplateKeys())
+}
@@ -177,13 +177,13 @@ func (a PluginAdapter) Remove(ctx context.Context, collection string, opts Remov
 	return a.handleError(err, collection)
 }
 
-func (a PluginAdapter) Update(ctx context.Context, collection string, opts UpdateOptions) error {
+func (a PluginAdapter) Update(ctx context.Context, collection string, opts UpdateOptions) (int64, error) {
 	pluginOpts, err := opts.ToPluginOptions(collection)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	err = a.plugin.Update(ctx, pluginOpts)
-	return a.handleError(err, collection)
+	matched, err := a.plugin.Update(ctx, pluginOpts)
+	return matched, a.handleError(err, collection)
 }
 
 // handleError unwraps errors returned from a plugin (which due to the round trip
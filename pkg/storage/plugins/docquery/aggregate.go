@@ -0,0 +1,317 @@
+package docquery
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Aggregate runs an aggregation pipeline against the documents.
+// The supported stages are $match, $sort, $skip, $limit, $project, $group,
+// $unwind and $count.
+// See https://docs.mongodb.com/manual/reference/operator/aggregation-pipeline/
+func Aggregate(docs []Document, pipeline []bson.D) ([]Document, error) {
+	results := docs
+	for i, stage := range pipeline {
+		if len(stage) != 1 {
+			return nil, fmt.Errorf("invalid aggregation pipeline stage %d: each stage must have exactly one operator", i)
+		}
+
+		var err error
+		operator := stage[0].Key
+		switch operator {
+		case "$match":
+			var filter Filter
+			filter, err = NewFilter(toMap(stage[0].Value))
+			if err == nil {
+				results, err = FilterDocuments(results, filter)
+			}
+		case "$sort":
+			var spec bson.D
+			spec, err = toOrderedMap(stage[0].Value)
+			if err == nil {
+				// Do not modify the order of the caller's slice
+				results = append([]Document(nil), results...)
+				err = Sort(results, spec)
+			}
+		case "$skip", "$limit":
+			var n interface{}
+			n, err = Normalize(stage[0].Value)
+			count, ok := toFloat(n)
+			if err == nil && !ok {
+				err = fmt.Errorf("%s requires a number", operator)
+			}
+			if err == nil {
+				if operator == "$skip" {
+					results = Page(results, int64(count), 0)
+				} else {
+					results = Page(results, 0, int64(count))
+				}
+			}
+		case "$project":
+			var spec bson.D
+			spec, err = toOrderedMap(stage[0].Value)
+			if err == nil {
+				results, err = Project(results, spec)
+			}
+		case "$group":
+			results, err = group(results, stage[0].Value)
+		case "$unwind":
+			results, err = unwind(results, stage[0].Value)
+		case "$count":
+			field, ok := stage[0].Value.(string)
+			if !ok || field == "" {
+				err = fmt.Errorf("$count requires a field name")
+			} else {
+				results = []Document{{field: float64(len(results))}}
+			}
+		default:
+			err = fmt.Errorf("unsupported aggregation pipeline stage %s", operator)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("error evaluating aggregation pipeline stage %s: %w", operator, err)
+		}
+	}
+	return results, nil
+}
+
+func toMap(value interface{}) map[string]interface{} {
+	normalized, err := Normalize(value)
+	if err != nil {
+		return nil
+	}
+	m, _ := normalized.(map[string]interface{})
+	return m
+}
+
+// toOrderedMap converts a stage value to bson.D, preserving the order of
+// the keys when the value is already ordered.
+func toOrderedMap(value interface{}) (bson.D, error) {
+	switch tv := value.(type) {
+	case bson.D:
+		return tv, nil
+	case map[string]interface{}:
+		if len(tv) > 1 {
+			return nil, fmt.Errorf("the order of the fields must be preserved, use an ordered document (bson.D)")
+		}
+		d := make(bson.D, 0, len(tv))
+		for k, v := range tv {
+			d = append(d, bson.E{Key: k, Value: v})
+		}
+		return d, nil
+	case bson.M:
+		return toOrderedMap(map[string]interface{}(tv))
+	default:
+		return nil, fmt.Errorf("expected a document but got %T", value)
+	}
+}
+
+// evaluateExpression resolves an aggregation expression against a document:
+// "$field" references a field, "$$ROOT" the entire document, embedded
+// documents are evaluated recursively and everything else is a literal.
+func evaluateExpression(doc Document, expr interface{}) interface{} {
+	switch tv := expr.(type) {
+	case string:
+		if tv == "$$ROOT" || tv == "$$CURRENT" {
+			return map[string]interface{}(doc.Copy())
+		}
+		if strings.HasPrefix(tv, "$$ROOT.") {
+			tv = "$" + strings.TrimPrefix(tv, "$$ROOT.")
+		}
+		if strings.HasPrefix(tv, "$") && !strings.HasPrefix(tv, "$$") {
+			value, ok := getValue(doc, strings.TrimPrefix(tv, "$"))
+			if !ok {
+				return nil
+			}
+			return copyValue(value)
+		}
+		return tv
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(tv))
+		for k, v := range tv {
+			result[k] = evaluateExpression(doc, v)
+		}
+		return result
+	default:
+		return expr
+	}
+}
+
+type accumulator struct {
+	field    string
+	operator string
+	expr     interface{}
+}
+
+func group(docs []Document, rawSpec interface{}) ([]Document, error) {
+	spec, err := toOrderedGroup(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var idExpr interface{}
+	var accumulators []accumulator
+	hasID := false
+	for _, e := range spec {
+		value, err := Normalize(e.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Key == "_id" {
+			idExpr = value
+			hasID = true
+			continue
+		}
+
+		acc, ok := value.(map[string]interface{})
+		if !ok || len(acc) != 1 {
+			return nil, fmt.Errorf("the field %s must be an accumulator object", e.Key)
+		}
+		for op, expr := range acc {
+			accumulators = append(accumulators, accumulator{field: e.Key, operator: op, expr: expr})
+		}
+	}
+	if !hasID {
+		return nil, fmt.Errorf("a group specification must include an _id")
+	}
+
+	// Preserve the order that groups were first seen so that results are stable
+	var groups []Document
+	for _, doc := range docs {
+		id := evaluateExpression(doc, idExpr)
+
+		var g Document
+		for _, existing := range groups {
+			if equal(existing["_id"], id) {
+				g = existing
+				break
+			}
+		}
+		isNew := g == nil
+		if isNew {
+			g = Document{"_id": id}
+			groups = append(groups, g)
+		}
+
+		for _, acc := range accumulators {
+			value := evaluateExpression(doc, acc.expr)
+			if err := accumulate(g, acc, value, isNew); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// toOrderedGroup reads a $group specification. The order of its fields isn't
+// significant, so it may be sent as either bson.D or bson.M.
+func toOrderedGroup(value interface{}) (bson.D, error) {
+	switch tv := value.(type) {
+	case bson.D:
+		return tv, nil
+	case bson.M:
+		return toOrderedGroup(map[string]interface{}(tv))
+	case map[string]interface{}:
+		d := make(bson.D, 0, len(tv))
+		for _, k := range sortedKeys(tv) {
+			d = append(d, bson.E{Key: k, Value: tv[k]})
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("expected a document but got %T", value)
+	}
+}
+
+func accumulate(g Document, acc accumulator, value interface{}, isNew bool) error {
+	switch acc.operator {
+	case "$first":
+		if isNew {
+			g[acc.field] = value
+		}
+	case "$last":
+		g[acc.field] = value
+	case "$sum":
+		n, ok := toFloat(value)
+		if !ok {
+			n = 0
+		}
+		current, _ := toFloat(g[acc.field])
+		g[acc.field] = current + n
+	case "$min", "$max":
+		if value == nil {
+			if isNew {
+				g[acc.field] = nil
+			}
+			return nil
+		}
+		current, ok := g[acc.field]
+		if !ok || current == nil {
+			g[acc.field] = value
+			return nil
+		}
+		c := compare(value, current)
+		if (acc.operator == "$min" && c < 0) || (acc.operator == "$max" && c > 0) {
+			g[acc.field] = value
+		}
+	case "$push", "$addToSet":
+		items, _ := g[acc.field].([]interface{})
+		if items == nil {
+			items = []interface{}{}
+		}
+		if acc.operator == "$push" || !containsValue(items, value) {
+			items = append(items, value)
+		}
+		g[acc.field] = items
+	default:
+		return fmt.Errorf("unsupported group accumulator %s", acc.operator)
+	}
+	return nil
+}
+
+func unwind(docs []Document, rawSpec interface{}) ([]Document, error) {
+	var path string
+	preserveEmpty := false
+
+	spec, err := Normalize(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+	switch tv := spec.(type) {
+	case string:
+		path = tv
+	case map[string]interface{}:
+		path, _ = tv["path"].(string)
+		preserveEmpty = isTruthy(tv["preserveNullAndEmptyArrays"])
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("$unwind requires a field path prefixed with $")
+	}
+	path = strings.TrimPrefix(path, "$")
+
+	var results []Document
+	for _, doc := range docs {
+		value, ok := getValue(doc, path)
+		items, isArray := value.([]interface{})
+		switch {
+		case !ok || value == nil || (isArray && len(items) == 0):
+			if preserveEmpty {
+				results = append(results, doc)
+			}
+		case !isArray:
+			results = append(results, doc)
+		default:
+			for _, item := range items {
+				unwound := doc.Copy()
+				if err := setValue(unwound, path, copyValue(item)); err != nil {
+					return nil, err
+				}
+				results = append(results, unwound)
+			}
+		}
+	}
+	return results, nil
+}
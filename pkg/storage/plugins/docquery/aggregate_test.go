@@ -0,0 +1,72 @@
+package docquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAggregate_LastOutputs(t *testing.T) {
+	// This is the pipeline used by InstallationStore.GetLastOutputs
+	docs := []Document{
+		{"_id": "1", "namespace": "dev", "installation": "mysql", "name": "connstr", "resultId": "01", "value": "first"},
+		{"_id": "2", "namespace": "dev", "installation": "mysql", "name": "connstr", "resultId": "02", "value": "second"},
+		{"_id": "3", "namespace": "dev", "installation": "mysql", "name": "port", "resultId": "01", "value": "3306"},
+		{"_id": "4", "namespace": "dev", "installation": "redis", "name": "port", "resultId": "03", "value": "6379"},
+	}
+
+	pipeline := []bson.D{
+		{{Key: "$match", Value: bson.M{"namespace": "dev", "installation": "mysql"}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "namespace", Value: 1},
+			{Key: "installation", Value: 1},
+			{Key: "name", Value: 1},
+			{Key: "resultId", Value: -1},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$name"},
+			{Key: "lastOutput", Value: bson.M{"$first": "$$ROOT"}},
+		}}},
+	}
+
+	results, err := Aggregate(docs, pipeline)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "connstr", results[0]["_id"])
+	assert.Equal(t, "second", results[0]["lastOutput"].(map[string]interface{})["value"])
+	assert.Equal(t, "port", results[1]["_id"])
+	assert.Equal(t, "3306", results[1]["lastOutput"].(map[string]interface{})["value"])
+}
+
+func TestAggregate_Accumulators(t *testing.T) {
+	docs := []Document{
+		{"installation": "mysql", "action": "install", "tags": []interface{}{"a", "b"}},
+		{"installation": "mysql", "action": "upgrade", "tags": []interface{}{"c"}},
+		{"installation": "redis", "action": "install"},
+	}
+
+	results, err := Aggregate(docs, []bson.D{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$installation"},
+			{Key: "runs", Value: bson.M{"$sum": 1}},
+			{Key: "lastAction", Value: bson.M{"$last": "$action"}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "runs", Value: -1}}}},
+		{{Key: "$limit", Value: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Document{{"_id": "mysql", "runs": float64(2), "lastAction": "upgrade"}}, results)
+
+	results, err = Aggregate(docs, []bson.D{
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$count", Value: "total"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Document{{"total": float64(3)}}, results)
+
+	_, err = Aggregate(docs, []bson.D{{{Key: "$lookup", Value: bson.M{}}}})
+	require.EqualError(t, err, "error evaluating aggregation pipeline stage $lookup: unsupported aggregation pipeline stage $lookup")
+}
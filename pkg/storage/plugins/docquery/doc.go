@@ -0,0 +1,6 @@
+// Package docquery evaluates the subset of MongoDB query, projection, sort,
+// update and aggregation documents used by Porter against documents held in
+// memory. It lets storage plugins without a native document query engine,
+// such as the sqlite plugin, implement plugins.StorageProtocol with the same
+// semantics as the mongodb plugin.
+package docquery
@@ -0,0 +1,397 @@
+package docquery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Filter is a parsed query filter document.
+// See https://docs.mongodb.com/manual/core/document/#std-label-document-query-filter
+type Filter struct {
+	query map[string]interface{}
+}
+
+// NewFilter validates and normalizes a query filter document. An empty filter
+// matches all documents.
+func NewFilter(filter map[string]interface{}) (Filter, error) {
+	if filter == nil {
+		return Filter{query: map[string]interface{}{}}, nil
+	}
+
+	normalized, err := Normalize(filter)
+	if err != nil {
+		return Filter{}, fmt.Errorf("invalid query filter: %w", err)
+	}
+
+	return Filter{query: normalized.(map[string]interface{})}, nil
+}
+
+// Matches determines if the document satisfies the filter.
+func (f Filter) Matches(doc Document) (bool, error) {
+	return matchQuery(map[string]interface{}(doc), f.query)
+}
+
+// Equalities returns the fields in the filter that are compared with a
+// literal value, e.g. {"name": "mysql"}. These are used to seed the _id of
+// documents inserted by an upsert.
+func (f Filter) Equalities() map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range f.query {
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		if isOperatorDocument(value) {
+			if m := value.(map[string]interface{}); len(m) == 1 {
+				if eq, ok := m["$eq"]; ok {
+					result[key] = eq
+				}
+			}
+			continue
+		}
+		if _, isRegex := value.(primitive.Regex); isRegex {
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+func matchQuery(doc map[string]interface{}, query map[string]interface{}) (bool, error) {
+	for key, condition := range query {
+		var matched bool
+		var err error
+
+		switch key {
+		case "$and", "$or", "$nor":
+			matched, err = matchLogical(doc, key, condition)
+		case "$comment":
+			matched = true
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("unsupported query operator %s", key)
+			}
+			matched, err = matchField(doc, key, condition)
+		}
+
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchLogical(doc map[string]interface{}, operator string, condition interface{}) (bool, error) {
+	clauses, ok := condition.([]interface{})
+	if !ok || len(clauses) == 0 {
+		return false, fmt.Errorf("%s must be a non-empty array of query documents", operator)
+	}
+
+	for _, rawClause := range clauses {
+		clause, ok := rawClause.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s must be a non-empty array of query documents", operator)
+		}
+
+		matched, err := matchQuery(doc, clause)
+		if err != nil {
+			return false, err
+		}
+
+		switch operator {
+		case "$and":
+			if !matched {
+				return false, nil
+			}
+		case "$or":
+			if matched {
+				return true, nil
+			}
+		case "$nor":
+			if matched {
+				return false, nil
+			}
+		}
+	}
+
+	return operator != "$or", nil
+}
+
+// isOperatorDocument determines if a filter value is a set of operators,
+// e.g. {"$gt": 1}, instead of an embedded document to compare against.
+func isOperatorDocument(value interface{}) bool {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+func matchField(doc map[string]interface{}, path string, condition interface{}) (bool, error) {
+	values, exists := lookup(doc, path)
+
+	if !isOperatorDocument(condition) {
+		return matchEquality(values, exists, condition), nil
+	}
+
+	operators := condition.(map[string]interface{})
+	for operator, operand := range operators {
+		matched, err := matchOperator(values, exists, operator, operand, operators)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchEquality implements implicit equality, where an array field matches
+// when either the entire array or any of its elements equals the value.
+func matchEquality(values []interface{}, exists bool, want interface{}) bool {
+	if regex, ok := want.(primitive.Regex); ok {
+		re, err := compileRegex(regex.Pattern, regex.Options)
+		if err != nil {
+			return false
+		}
+		return anyCandidate(values, func(v interface{}) bool {
+			s, ok := v.(string)
+			return ok && re.MatchString(s)
+		})
+	}
+
+	if want == nil {
+		// null matches documents where the field is null or missing
+		if !exists {
+			return true
+		}
+		return anyCandidate(values, func(v interface{}) bool { return v == nil })
+	}
+
+	return anyCandidate(values, func(v interface{}) bool { return equal(v, want) })
+}
+
+// anyCandidate checks the values found at a path, and the elements of any
+// arrays found, against the predicate.
+func anyCandidate(values []interface{}, predicate func(v interface{}) bool) bool {
+	for _, v := range values {
+		if predicate(v) {
+			return true
+		}
+		if items, ok := v.([]interface{}); ok {
+			for _, item := range items {
+				if predicate(item) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func matchOperator(values []interface{}, exists bool, operator string, operand interface{}, siblings map[string]interface{}) (bool, error) {
+	switch operator {
+	case "$eq":
+		return matchEquality(values, exists, operand), nil
+	case "$ne":
+		return !matchEquality(values, exists, operand), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		return anyCandidate(values, func(v interface{}) bool {
+			if typeOrder(v) != typeOrder(operand) {
+				return false
+			}
+			c := compare(v, operand)
+			switch operator {
+			case "$gt":
+				return c > 0
+			case "$gte":
+				return c >= 0
+			case "$lt":
+				return c < 0
+			default:
+				return c <= 0
+			}
+		}), nil
+	case "$in", "$nin":
+		items, ok := operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s requires an array", operator)
+		}
+		found := false
+		for _, item := range items {
+			if matchEquality(values, exists, item) {
+				found = true
+				break
+			}
+		}
+		if operator == "$in" {
+			return found, nil
+		}
+		return !found, nil
+	case "$exists":
+		return exists == isTruthy(operand), nil
+	case "$regex":
+		pattern, options, err := regexOperand(operand, siblings)
+		if err != nil {
+			return false, err
+		}
+		re, err := compileRegex(pattern, options)
+		if err != nil {
+			return false, err
+		}
+		return anyCandidate(values, func(v interface{}) bool {
+			s, ok := v.(string)
+			return ok && re.MatchString(s)
+		}), nil
+	case "$options":
+		if _, ok := siblings["$regex"]; !ok {
+			return false, fmt.Errorf("$options requires $regex")
+		}
+		return true, nil
+	case "$not":
+		if regex, ok := operand.(primitive.Regex); ok {
+			return !matchEquality(values, exists, regex), nil
+		}
+		operators, ok := operand.(map[string]interface{})
+		if !ok || !isOperatorDocument(operators) {
+			return false, fmt.Errorf("$not requires an operator document or a regular expression")
+		}
+		for op, arg := range operators {
+			matched, err := matchOperator(values, exists, op, arg, operators)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$size":
+		size, ok := toFloat(operand)
+		if !ok {
+			return false, fmt.Errorf("$size requires a number")
+		}
+		for _, v := range values {
+			if items, ok := v.([]interface{}); ok && float64(len(items)) == size {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$all":
+		items, ok := operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("$all requires an array")
+		}
+		if len(items) == 0 {
+			return false, nil
+		}
+		for _, item := range items {
+			if !matchEquality(values, exists, item) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$elemMatch":
+		query, ok := operand.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("$elemMatch requires a query document")
+		}
+		for _, v := range values {
+			items, ok := v.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, item := range items {
+				matched, err := matchElement(item, query)
+				if err != nil {
+					return false, err
+				}
+				if matched {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unsupported query operator %s", operator)
+	}
+}
+
+// matchElement evaluates an $elemMatch query against a single array element,
+// which is either an embedded document or a value compared with operators.
+func matchElement(item interface{}, query map[string]interface{}) (bool, error) {
+	if isOperatorDocument(query) {
+		for op, arg := range query {
+			matched, err := matchOperator([]interface{}{item}, true, op, arg, query)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	doc, ok := item.(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	return matchQuery(doc, query)
+}
+
+func regexOperand(operand interface{}, siblings map[string]interface{}) (string, string, error) {
+	var pattern, options string
+	switch tv := operand.(type) {
+	case string:
+		pattern = tv
+	case primitive.Regex:
+		pattern = tv.Pattern
+		options = tv.Options
+	default:
+		return "", "", fmt.Errorf("$regex requires a string or regular expression")
+	}
+
+	if rawOptions, ok := siblings["$options"]; ok {
+		s, ok := rawOptions.(string)
+		if !ok {
+			return "", "", fmt.Errorf("$options requires a string")
+		}
+		options = s
+	}
+	return pattern, options, nil
+}
+
+func compileRegex(pattern string, options string) (*regexp.Regexp, error) {
+	var flags string
+	for _, opt := range options {
+		switch opt {
+		case 'i', 'm', 's':
+			flags += string(opt)
+		case 'x':
+			// extended mode isn't supported by go, ignore it rather than fail the query
+		default:
+			return nil, fmt.Errorf("unsupported regular expression option %q", opt)
+		}
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+	}
+	return re, nil
+}
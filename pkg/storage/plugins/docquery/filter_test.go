@@ -0,0 +1,115 @@
+package docquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	doc, err := NewDocument(map[string]interface{}{
+		"_id":       "1",
+		"namespace": "dev",
+		"name":      "mysql",
+		"revision":  3,
+		"labels":    map[string]interface{}{"team": "data"},
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "port", "source": map[string]interface{}{"value": "3306"}},
+			map[string]interface{}{"name": "password", "source": map[string]interface{}{"secret": "mysql-pwd"}},
+		},
+		"credentialSets": []interface{}{"azure", "aws"},
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name   string
+		filter bson.M
+		want   bool
+	}{
+		{name: "empty", filter: bson.M{}, want: true},
+		{name: "equality", filter: bson.M{"namespace": "dev", "name": "mysql"}, want: true},
+		{name: "equality mismatch", filter: bson.M{"namespace": "dev", "name": "redis"}, want: false},
+		{name: "embedded field", filter: bson.M{"labels.team": "data"}, want: true},
+		{name: "array element", filter: bson.M{"credentialSets": "aws"}, want: true},
+		{name: "array of documents", filter: bson.M{"parameters.name": "password"}, want: true},
+		{name: "missing equals null", filter: bson.M{"uninstalled": nil}, want: true},
+		{name: "numbers of different types", filter: bson.M{"revision": int64(3)}, want: true},
+		{name: "$ne", filter: bson.M{"name": bson.M{"$ne": "mysql"}}, want: false},
+		{name: "$gt", filter: bson.M{"revision": bson.M{"$gt": 2}}, want: true},
+		{name: "$lte", filter: bson.M{"revision": bson.M{"$lte": 2}}, want: false},
+		{name: "$gt different type", filter: bson.M{"revision": bson.M{"$gt": "2"}}, want: false},
+		{name: "$in", filter: bson.M{"namespace": bson.M{"$in": []string{"test", "dev"}}}, want: true},
+		{name: "$nin", filter: bson.M{"credentialSets": bson.M{"$nin": []string{"aws"}}}, want: false},
+		{name: "$exists", filter: bson.M{"labels.team": bson.M{"$exists": true}}, want: true},
+		{name: "$exists false", filter: bson.M{"status": bson.M{"$exists": false}}, want: true},
+		{name: "$regex", filter: bson.M{"name": bson.M{"$regex": "^MY", "$options": "i"}}, want: true},
+		{name: "regex value", filter: bson.M{"name": primitive.Regex{Pattern: "sql$"}}, want: true},
+		{name: "$not", filter: bson.M{"revision": bson.M{"$not": bson.M{"$gt": 5}}}, want: true},
+		{name: "$size", filter: bson.M{"credentialSets": bson.M{"$size": 2}}, want: true},
+		{name: "$all", filter: bson.M{"credentialSets": bson.M{"$all": []string{"aws", "gcp"}}}, want: false},
+		{name: "$elemMatch", filter: bson.M{"parameters": bson.M{"$elemMatch": bson.M{"name": "password", "source.secret": bson.M{"$exists": true}}}}, want: true},
+		{name: "$or", filter: bson.M{"$or": []bson.M{{"namespace": ""}, {"namespace": "dev"}}}, want: true},
+		{name: "$and", filter: bson.M{"$and": []bson.M{{"namespace": "dev"}, {"name": "redis"}}}, want: false},
+		{name: "$nor", filter: bson.M{"$nor": []bson.M{{"namespace": "test"}}}, want: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := NewFilter(tc.filter)
+			require.NoError(t, err)
+
+			got, err := filter.Matches(doc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFilter_UnsupportedOperator(t *testing.T) {
+	filter, err := NewFilter(bson.M{"name": bson.M{"$where": "this.name"}})
+	require.NoError(t, err)
+
+	_, err = filter.Matches(Document{"name": "mysql"})
+	require.EqualError(t, err, "unsupported query operator $where")
+}
+
+func TestFind(t *testing.T) {
+	docs := []Document{
+		{"_id": "1", "namespace": "dev", "name": "b"},
+		{"_id": "2", "namespace": "", "name": "z"},
+		{"_id": "3", "namespace": "dev", "name": "a"},
+	}
+
+	results, err := Find(docs, FindOptions{
+		Filter: bson.M{"namespace": "dev"},
+		Sort:   bson.D{{Key: "name", Value: 1}},
+		Limit:  1,
+		Select: bson.D{{Key: "name", Value: 1}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Document{{"_id": "3", "name": "a"}}, results)
+	assert.Equal(t, "b", docs[0]["name"], "the original documents should not be modified")
+}
+
+func TestPatch(t *testing.T) {
+	doc := Document{"_id": "1", "status": map[string]interface{}{"runId": "a"}, "revision": float64(1), "tags": []interface{}{"x"}}
+
+	got, err := Patch(doc, bson.D{
+		{Key: "$set", Value: bson.M{"status.runId": "b"}},
+		{Key: "$inc", Value: bson.M{"revision": 1}},
+		{Key: "$addToSet", Value: bson.M{"tags": "x"}},
+		{Key: "$push", Value: bson.M{"tags": "y"}},
+		{Key: "$unset", Value: bson.M{"missing": ""}},
+	})
+	require.NoError(t, err)
+
+	want := Document{"_id": "1", "status": map[string]interface{}{"runId": "b"}, "revision": float64(2), "tags": []interface{}{"x", "y"}}
+	assert.Equal(t, want, got)
+	assert.Equal(t, "a", doc["status"].(map[string]interface{})["runId"], "the original document should not be modified")
+
+	_, err = Patch(doc, bson.D{{Key: "$set", Value: bson.M{"_id": "2"}}})
+	require.EqualError(t, err, "the _id field cannot be modified")
+}
@@ -0,0 +1,242 @@
+package docquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FindOptions mirrors plugins.FindOptions without the collection, so that it
+// can be applied to documents that were already loaded from a collection.
+type FindOptions struct {
+	// Filter specifies how to filter the results.
+	Filter map[string]interface{}
+
+	// Sort is a list of field names and their sort order, 1 or -1.
+	Sort bson.D
+
+	// Skip is the number of results to skip past and exclude from the results.
+	Skip int64
+
+	// Limit is the number of results to return.
+	Limit int64
+
+	// Select is a projection document.
+	Select bson.D
+}
+
+// Find returns the documents that match the filter, sorted, paged and
+// projected as specified. The documents are not modified.
+func Find(docs []Document, opts FindOptions) ([]Document, error) {
+	filter, err := NewFilter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := FilterDocuments(docs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = Sort(results, opts.Sort); err != nil {
+		return nil, err
+	}
+
+	results = Page(results, opts.Skip, opts.Limit)
+
+	return Project(results, opts.Select)
+}
+
+// FilterDocuments returns the documents that match the filter, preserving their order.
+func FilterDocuments(docs []Document, filter Filter) ([]Document, error) {
+	results := make([]Document, 0, len(docs))
+	for _, doc := range docs {
+		matched, err := filter.Matches(doc)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			results = append(results, doc)
+		}
+	}
+	return results, nil
+}
+
+// Page skips and limits the documents. A limit of zero means no limit.
+func Page(docs []Document, skip int64, limit int64) []Document {
+	if skip > 0 {
+		if skip >= int64(len(docs)) {
+			return []Document{}
+		}
+		docs = docs[skip:]
+	}
+	if limit > 0 && limit < int64(len(docs)) {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+// sortKey is a single field of a sort document.
+type sortKey struct {
+	path       string
+	descending bool
+}
+
+func parseSort(spec bson.D) ([]sortKey, error) {
+	keys := make([]sortKey, 0, len(spec))
+	for _, e := range spec {
+		value, err := Normalize(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		order, ok := toFloat(value)
+		if !ok || (order != 1 && order != -1) {
+			return nil, fmt.Errorf("invalid sort order for %s: %v, must be 1 or -1", e.Key, e.Value)
+		}
+		keys = append(keys, sortKey{path: e.Key, descending: order < 0})
+	}
+	return keys, nil
+}
+
+// Sort orders the documents in place by the specified sort document, e.g.
+// {"namespace": 1, "name": 1}. Documents with equal keys keep their relative order.
+func Sort(docs []Document, spec bson.D) error {
+	if len(spec) == 0 {
+		return nil
+	}
+
+	keys, err := parseSort(spec)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, key := range keys {
+			a := sortValue(docs[i], key)
+			b := sortValue(docs[j], key)
+			c := compare(a, b)
+			if c == 0 {
+				continue
+			}
+			if key.descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	return nil
+}
+
+// sortValue resolves the value used to sort a document. Missing fields sort
+// as null, and arrays sort by their smallest element when ascending or
+// their largest element when descending.
+func sortValue(doc Document, key sortKey) interface{} {
+	value, ok := getValue(doc, key.path)
+	if !ok {
+		return nil
+	}
+
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return value
+	}
+
+	result := items[0]
+	for _, item := range items[1:] {
+		c := compare(item, result)
+		if (key.descending && c > 0) || (!key.descending && c < 0) {
+			result = item
+		}
+	}
+	return result
+}
+
+// Project applies a projection document to each document, returning copies
+// that contain only the selected fields. Projections either include fields,
+// e.g. {"name": 1}, or exclude them, e.g. {"outputs": 0}. The _id field is
+// included unless it is explicitly excluded.
+func Project(docs []Document, selection bson.D) ([]Document, error) {
+	if len(selection) == 0 {
+		return docs, nil
+	}
+
+	include, fields, excludeID, err := parseProjection(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Document, len(docs))
+	for i, doc := range docs {
+		var projected Document
+		if include {
+			projected = Document{}
+			if !excludeID {
+				if id, ok := doc["_id"]; ok {
+					projected["_id"] = copyValue(id)
+				}
+			}
+			for _, field := range fields {
+				if value, ok := getValue(doc, field); ok {
+					if err = setValue(projected, field, copyValue(value)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		} else {
+			projected = doc.Copy()
+			for _, field := range fields {
+				unsetValue(projected, field)
+			}
+			if excludeID {
+				delete(projected, "_id")
+			}
+		}
+		results[i] = projected
+	}
+	return results, nil
+}
+
+func parseProjection(selection bson.D) (include bool, fields []string, excludeID bool, err error) {
+	var includes, excludes []string
+	for _, e := range selection {
+		value, err := Normalize(e.Value)
+		if err != nil {
+			return false, nil, false, err
+		}
+
+		if _, isExpr := value.(map[string]interface{}); isExpr {
+			return false, nil, false, fmt.Errorf("unsupported projection for %s, only inclusion and exclusion of fields is supported", e.Key)
+		}
+		if strings.HasPrefix(e.Key, "$") {
+			return false, nil, false, fmt.Errorf("unsupported projection operator %s", e.Key)
+		}
+
+		if e.Key == "_id" {
+			excludeID = !isTruthy(value)
+			continue
+		}
+
+		if isTruthy(value) {
+			includes = append(includes, e.Key)
+		} else {
+			excludes = append(excludes, e.Key)
+		}
+	}
+
+	if len(includes) > 0 && len(excludes) > 0 {
+		return false, nil, false, fmt.Errorf("cannot mix inclusion and exclusion in a projection")
+	}
+
+	if len(includes) > 0 {
+		return true, includes, excludeID, nil
+	}
+
+	// Only {_id: 1} was specified
+	if len(excludes) == 0 && !excludeID {
+		return true, nil, false, nil
+	}
+	return false, excludes, excludeID, nil
+}
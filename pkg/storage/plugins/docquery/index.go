@@ -0,0 +1,15 @@
+package docquery
+
+// HasSameKeys determines if two documents have the same values for the
+// specified fields, which is how unique indices are enforced. Like MongoDB, a
+// missing field is treated as null.
+func HasSameKeys(a Document, b Document, fields []string) bool {
+	for _, field := range fields {
+		aValue, _ := getValue(a, field)
+		bValue, _ := getValue(b, field)
+		if !equal(aValue, bValue) {
+			return false
+		}
+	}
+	return true
+}
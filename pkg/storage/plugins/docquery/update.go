@@ -0,0 +1,177 @@
+package docquery
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// EnsureID sets a generated _id on the document when one isn't defined,
+// which is what MongoDB does on insert. The document's id is returned.
+func EnsureID(doc Document) string {
+	if id, ok := doc.ID(); ok {
+		return id
+	}
+
+	id := cnab.NewULID()
+	doc["_id"] = id
+	return id
+}
+
+// Replace builds the document that replaces an existing document, keeping
+// the existing document's _id.
+func Replace(existing Document, replacement Document) (Document, error) {
+	result := replacement.Copy()
+	existingID, _ := existing.ID()
+	if newID, ok := result.ID(); ok && newID != existingID {
+		return nil, fmt.Errorf("the replacement document cannot change the _id of the document from %s to %s", existingID, newID)
+	}
+	result["_id"] = existing["_id"]
+	return result, nil
+}
+
+// NewUpsertDocument builds the document inserted by an upsert when no
+// document matched the filter. Fields compared by equality in the filter,
+// such as _id, are used when the replacement document doesn't define them.
+func NewUpsertDocument(filter Filter, replacement Document) Document {
+	result := replacement.Copy()
+	if _, ok := result["_id"]; !ok {
+		if id, ok := filter.Equalities()["_id"]; ok {
+			result["_id"] = id
+		}
+	}
+	EnsureID(result)
+	return result
+}
+
+// Patch applies a set of update operators, e.g. {"$set": {"status": "done"}},
+// to a copy of the document and returns the updated document.
+// See https://docs.mongodb.com/manual/reference/operator/update/
+func Patch(doc Document, transformation bson.D) (Document, error) {
+	result := doc.Copy()
+	for _, e := range transformation {
+		rawFields, err := Normalize(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		fields, ok := rawFields.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s requires a document of fields to update", e.Key)
+		}
+
+		for _, path := range sortedKeys(fields) {
+			if path == "_id" && e.Key != "$setOnInsert" {
+				return nil, fmt.Errorf("the _id field cannot be modified")
+			}
+			if err = applyUpdateOperator(result, e.Key, path, fields[path]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func applyUpdateOperator(doc Document, operator string, path string, value interface{}) error {
+	switch operator {
+	case "$set":
+		return setValue(doc, path, copyValue(value))
+	case "$setOnInsert":
+		// Patch only updates existing documents so there is never an insert
+		return nil
+	case "$unset":
+		unsetValue(doc, path)
+		return nil
+	case "$inc":
+		amount, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("$inc requires a number for %s", path)
+		}
+		current, exists := getValue(doc, path)
+		if !exists || current == nil {
+			return setValue(doc, path, amount)
+		}
+		currentNumber, ok := toFloat(current)
+		if !ok {
+			return fmt.Errorf("cannot apply $inc to %s because it is not a number", path)
+		}
+		return setValue(doc, path, currentNumber+amount)
+	case "$rename":
+		newPath, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("$rename requires a string for %s", path)
+		}
+		current, exists := getValue(doc, path)
+		if !exists {
+			return nil
+		}
+		unsetValue(doc, path)
+		return setValue(doc, newPath, current)
+	case "$push", "$addToSet":
+		current, exists := getValue(doc, path)
+		var items []interface{}
+		if exists && current != nil {
+			var ok bool
+			items, ok = current.([]interface{})
+			if !ok {
+				return fmt.Errorf("cannot apply %s to %s because it is not an array", operator, path)
+			}
+		}
+
+		newItems := []interface{}{value}
+		if each, ok := value.(map[string]interface{}); ok {
+			if eachItems, ok := each["$each"].([]interface{}); ok {
+				newItems = eachItems
+			}
+		}
+
+		for _, item := range newItems {
+			if operator == "$addToSet" && containsValue(items, item) {
+				continue
+			}
+			items = append(items, copyValue(item))
+		}
+		return setValue(doc, path, items)
+	case "$pull":
+		current, exists := getValue(doc, path)
+		if !exists || current == nil {
+			return nil
+		}
+		items, ok := current.([]interface{})
+		if !ok {
+			return fmt.Errorf("cannot apply $pull to %s because it is not an array", path)
+		}
+
+		var query map[string]interface{}
+		if m, ok := value.(map[string]interface{}); ok {
+			query = m
+		}
+
+		kept := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			remove := equal(item, value)
+			if query != nil && !remove {
+				matched, err := matchElement(item, query)
+				if err != nil {
+					return err
+				}
+				remove = matched
+			}
+			if !remove {
+				kept = append(kept, item)
+			}
+		}
+		return setValue(doc, path, kept)
+	default:
+		return fmt.Errorf("unsupported update operator %s", operator)
+	}
+}
+
+func containsValue(items []interface{}, value interface{}) bool {
+	for _, item := range items {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,470 @@
+package docquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Document is the in-memory representation of a stored document. Values are
+// normalized to plain Go types: map[string]interface{} for embedded
+// documents, []interface{} for arrays, float64 for numbers, and string, bool,
+// time.Time or nil for everything else.
+type Document map[string]interface{}
+
+// NewDocument normalizes a document received by a storage plugin so that it
+// can be evaluated against queries and persisted as json.
+func NewDocument(src map[string]interface{}) (Document, error) {
+	normalized, err := Normalize(src)
+	if err != nil {
+		return nil, err
+	}
+	return normalized.(map[string]interface{}), nil
+}
+
+// UnmarshalDocument reads a document that was persisted with Document.MarshalJSON.
+func UnmarshalDocument(data []byte) (Document, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error parsing stored document: %w", err)
+	}
+	return Document(raw), nil
+}
+
+// ID returns the document's _id as a string, and if it was set.
+func (d Document) ID() (string, bool) {
+	id, ok := d["_id"]
+	if !ok || id == nil {
+		return "", false
+	}
+	return fmt.Sprint(id), true
+}
+
+// MarshalJSON returns the json representation of the document suitable for persisting.
+func (d Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(d))
+}
+
+// ToBson converts the document to the raw bson representation returned by
+// plugins.StorageProtocol.
+func (d Document) ToBson() (bson.Raw, error) {
+	data, err := bson.Marshal(map[string]interface{}(d))
+	if err != nil {
+		return nil, fmt.Errorf("error converting document to bson: %w", err)
+	}
+	return data, nil
+}
+
+// ToBsonList converts a set of documents to raw bson.
+func ToBsonList(docs []Document) ([]bson.Raw, error) {
+	results := make([]bson.Raw, len(docs))
+	for i, doc := range docs {
+		raw, err := doc.ToBson()
+		if err != nil {
+			return nil, err
+		}
+		results[i] = raw
+	}
+	return results, nil
+}
+
+// Copy returns a deep copy of the document.
+func (d Document) Copy() Document {
+	return copyValue(map[string]interface{}(d)).(map[string]interface{})
+}
+
+func copyValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(tv))
+		for k, item := range tv {
+			result[k] = copyValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(tv))
+		for i, item := range tv {
+			result[i] = copyValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// Normalize converts bson and Go values into the plain representation
+// used by Document so that values from documents and queries can be compared.
+func Normalize(v interface{}) (interface{}, error) {
+	switch tv := v.(type) {
+	case nil:
+		return nil, nil
+	case string, bool, time.Time, primitive.Regex:
+		return tv, nil
+	case float64:
+		return tv, nil
+	case float32:
+		return float64(tv), nil
+	case int:
+		return float64(tv), nil
+	case int8:
+		return float64(tv), nil
+	case int16:
+		return float64(tv), nil
+	case int32:
+		return float64(tv), nil
+	case int64:
+		return float64(tv), nil
+	case uint:
+		return float64(tv), nil
+	case uint8:
+		return float64(tv), nil
+	case uint16:
+		return float64(tv), nil
+	case uint32:
+		return float64(tv), nil
+	case uint64:
+		return float64(tv), nil
+	case json.Number:
+		return tv.Float64()
+	case primitive.DateTime:
+		return tv.Time().UTC(), nil
+	case primitive.ObjectID:
+		return tv.Hex(), nil
+	case primitive.Null, primitive.Undefined:
+		return nil, nil
+	case bson.E:
+		value, err := Normalize(tv.Value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{tv.Key: value}, nil
+	case bson.D:
+		result := make(map[string]interface{}, len(tv))
+		for _, e := range tv {
+			value, err := Normalize(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			result[e.Key] = value
+		}
+		return result, nil
+	case bson.Raw:
+		var m bson.M
+		if err := bson.Unmarshal(tv, &m); err != nil {
+			return nil, err
+		}
+		return Normalize(m)
+	case []byte:
+		// Match how json would represent the value, which is how porter sends documents to plugins
+		data, err := json.Marshal(tv)
+		if err != nil {
+			return nil, err
+		}
+		var s string
+		err = json.Unmarshal(data, &s)
+		return s, err
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported document key type %s", rv.Type().Key())
+		}
+		result := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			value, err := Normalize(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			result[iter.Key().String()] = value
+		}
+		return result, nil
+	case reflect.Slice, reflect.Array:
+		result := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			value, err := Normalize(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return Normalize(rv.Elem().Interface())
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	}
+
+	// Fallback to the json representation of the value
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported document value type %T: %w", v, err)
+	}
+	var raw interface{}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("unsupported document value type %T: %w", v, err)
+	}
+	return raw, nil
+}
+
+// lookup finds the values identified by a dotted path, e.g. labels.env.
+// When the path traverses an array, the remaining path is evaluated against
+// each element, so a single path may resolve to multiple values. The second
+// return value indicates if the path resolved to at least one value.
+func lookup(doc interface{}, path string) ([]interface{}, bool) {
+	return lookupParts(doc, strings.Split(path, "."))
+}
+
+func lookupParts(current interface{}, parts []string) ([]interface{}, bool) {
+	if len(parts) == 0 {
+		return []interface{}{current}, true
+	}
+
+	switch tv := current.(type) {
+	case Document:
+		return lookupParts(map[string]interface{}(tv), parts)
+	case map[string]interface{}:
+		next, ok := tv[parts[0]]
+		if !ok {
+			return nil, false
+		}
+		return lookupParts(next, parts[1:])
+	case []interface{}:
+		// Numeric path segments index into the array
+		if i, err := strconv.Atoi(parts[0]); err == nil {
+			if i < 0 || i >= len(tv) {
+				return nil, false
+			}
+			return lookupParts(tv[i], parts[1:])
+		}
+
+		var results []interface{}
+		found := false
+		for _, item := range tv {
+			if _, isDoc := item.(map[string]interface{}); !isDoc {
+				continue
+			}
+			values, ok := lookupParts(item, parts)
+			if ok {
+				found = true
+				results = append(results, values...)
+			}
+		}
+		return results, found
+	default:
+		return nil, false
+	}
+}
+
+// getValue returns the single value stored at the specified dotted path
+// without traversing arrays, which is how sort keys and expressions are resolved.
+func getValue(doc map[string]interface{}, path string) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+	var current interface{} = doc
+	for _, part := range parts {
+		switch tv := current.(type) {
+		case map[string]interface{}:
+			next, ok := tv[part]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(tv) {
+				return nil, false
+			}
+			current = tv[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setValue sets the value at the specified dotted path, creating embedded
+// documents as necessary.
+func setValue(doc map[string]interface{}, path string, value interface{}) error {
+	parts := strings.Split(path, ".")
+	current := doc
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := current[part]
+		if !ok || next == nil {
+			child := map[string]interface{}{}
+			current[part] = child
+			current = child
+			continue
+		}
+
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("cannot set %s because %s is not an embedded document", path, strings.Join(parts[:i+1], "."))
+		}
+		current = child
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// unsetValue removes the value at the specified dotted path, if present.
+func unsetValue(doc map[string]interface{}, path string) {
+	parts := strings.Split(path, ".")
+	current := doc
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = child
+	}
+	delete(current, parts[len(parts)-1])
+}
+
+// typeOrder returns the rank of a value's type when comparing values of
+// different types, following the MongoDB comparison order.
+func typeOrder(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case map[string]interface{}:
+		return 4
+	case []interface{}:
+		return 5
+	case bool:
+		return 7
+	case time.Time:
+		return 8
+	case primitive.Regex:
+		return 10
+	default:
+		return 11
+	}
+}
+
+// compare returns -1, 0 or 1 when a is less than, equal to or greater than b.
+// Both values must be normalized.
+func compare(a, b interface{}) int {
+	ta, tb := typeOrder(a), typeOrder(b)
+	if ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+
+	switch av := a.(type) {
+	case nil:
+		return 0
+	case float64:
+		bv := b.(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		default:
+			return 0
+		}
+	case string:
+		return strings.Compare(av, b.(string))
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		default:
+			return 1
+		}
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compare(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(av), len(bv))
+	case map[string]interface{}:
+		bv := b.(map[string]interface{})
+		aKeys, bKeys := sortedKeys(av), sortedKeys(bv)
+		for i := 0; i < len(aKeys) && i < len(bKeys); i++ {
+			if c := strings.Compare(aKeys[i], bKeys[i]); c != 0 {
+				return c
+			}
+			if c := compare(av[aKeys[i]], bv[bKeys[i]]); c != 0 {
+				return c
+			}
+		}
+		return compareInt(len(aKeys), len(bKeys))
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// equal determines if two normalized values are equivalent.
+func equal(a, b interface{}) bool {
+	return typeOrder(a) == typeOrder(b) && compare(a, b) == 0
+}
+
+// toFloat converts a normalized number to a float, returning false if it is not a number.
+func toFloat(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+// isTruthy interprets projection and index values such as 1, -1, true and false.
+func isTruthy(v interface{}) bool {
+	switch tv := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return tv
+	case float64:
+		return tv != 0
+	default:
+		return true
+	}
+}
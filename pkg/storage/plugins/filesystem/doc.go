@@ -0,0 +1,5 @@
+// Package filesystem provides a storage plugin that saves each of Porter's
+// documents as a json file in a directory tree, with a folder per collection.
+// The files are formatted consistently so that the directory can be committed
+// to source control and changes reviewed, for example in a GitOps workflow.
+package filesystem
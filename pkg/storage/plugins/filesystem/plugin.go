@@ -0,0 +1,43 @@
+package filesystem
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/pluginstore"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+)
+
+// PluginKey is the identifier of the internal filesystem storage plugin.
+const PluginKey = plugins.PluginInterface + ".porter.filesystem"
+
+var _ plugins.StorageProtocol = Plugin{}
+
+type Plugin struct {
+	*Store
+}
+
+// PluginConfig are the configuration settings that can be defined for the
+// filesystem storage plugin in porter.yaml
+type PluginConfig struct {
+	// Path to the directory where documents are stored. Defaults to PORTER_HOME/storage
+	Path string `mapstructure:"path,omitempty"`
+
+	// Timeout in seconds to wait for another porter process to release its lock on the directory.
+	Timeout int `mapstructure:"timeout,omitempty"`
+}
+
+// NewPlugin creates an instance of the storage.porter.filesystem plugin
+func NewPlugin(c *config.Config, rawCfg interface{}) (plugin.Plugin, error) {
+	cfg := PluginConfig{
+		Timeout: 10,
+	}
+	if err := mapstructure.Decode(rawCfg, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading plugin configuration: %w", err)
+	}
+
+	impl := NewStore(c, cfg)
+	return pluginstore.NewPlugin(c.Context, impl), nil
+}
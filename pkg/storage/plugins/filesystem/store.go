@@ -0,0 +1,663 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/docquery"
+	"get.porter.sh/porter/pkg/tracing"
+	"github.com/gofrs/flock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var _ plugins.StorageProtocol = &Store{}
+
+const (
+	// DefaultStorageDir is the name of the directory created in PORTER_HOME
+	// when a path is not configured.
+	DefaultStorageDir = "storage"
+
+	// lockFile is locked while reading or writing to the directory so that
+	// concurrent porter processes don't corrupt the documents.
+	lockFile = ".lock"
+
+	// indicesFile records the indices requested with EnsureIndex.
+	indicesFile = ".indices.json"
+
+	documentExt = ".json"
+
+	FileModeDirectory os.FileMode = 0750
+	FileModeWritable  os.FileMode = 0640
+)
+
+// Store implements the Porter plugin.StoragePlugin interface by saving each
+// document to a json file named after its _id, in a directory per collection.
+//
+// Queries are evaluated by the docquery package so that the plugin supports
+// the same filters, sorting and projections as the mongodb plugin. Unique
+// indices requested by Porter are enforced when documents are written.
+//
+// The filesystem is accessed directly, instead of through the context's
+// filesystem abstraction, because file locks require a real file.
+type Store struct {
+	config  *config.Config
+	dir     string
+	timeout time.Duration
+
+	// lock coordinates access between porter processes
+	lock *flock.Flock
+	// mu coordinates access within the current process, since file locks
+	// are held by the process and not per goroutine
+	mu sync.Mutex
+}
+
+// NewStore creates a new storage engine that saves documents to the filesystem.
+func NewStore(c *config.Config, cfg PluginConfig) *Store {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 // default to 10 seconds
+	}
+	return &Store{
+		config:  c,
+		dir:     cfg.Path,
+		timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+// Connect initializes the plugin for use.
+// The plugin itself is responsible for ensuring it was called.
+// Close is called automatically when the plugin is used by Porter.
+func (s *Store) Connect(ctx context.Context) error {
+	if s.lock != nil {
+		return nil
+	}
+
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if s.dir == "" {
+		home, err := s.config.GetHomeDir()
+		if err != nil {
+			return span.Error(fmt.Errorf("could not determine the default storage directory: %w", err))
+		}
+		s.dir = filepath.Join(home, DefaultStorageDir)
+	}
+	span.SetAttributes(attribute.String("path", s.dir))
+
+	if err := os.MkdirAll(s.dir, FileModeDirectory); err != nil {
+		return span.Error(fmt.Errorf("error creating the storage directory %s: %w", s.dir, err))
+	}
+
+	s.lock = flock.New(filepath.Join(s.dir, lockFile))
+	return nil
+}
+
+func (s *Store) Close() error {
+	if s.lock != nil {
+		if err := s.lock.Close(); err != nil {
+			return err
+		}
+		s.lock = nil
+	}
+	return nil
+}
+
+// withLock runs the specified function while holding a lock on the storage
+// directory. Writers hold an exclusive lock, while readers share the lock.
+func (s *Store) withLock(ctx context.Context, exclusive bool, fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var locked bool
+	var err error
+	if exclusive {
+		locked, err = s.lock.TryLockContext(cxt, 50*time.Millisecond)
+	} else {
+		locked, err = s.lock.TryRLockContext(cxt, 50*time.Millisecond)
+	}
+	if err != nil || !locked {
+		return fmt.Errorf("timed out waiting for the lock on the storage directory %s, another porter process may be using it: %w", s.dir, err)
+	}
+	defer s.lock.Unlock()
+
+	return fn()
+}
+
+// storedDocument is a document loaded from a collection, along with the
+// file where it is stored.
+type storedDocument struct {
+	path string
+	doc  docquery.Document
+}
+
+func (s *Store) collectionDir(collection string) (string, error) {
+	if collection == "" || strings.ContainsAny(collection, `/\`) || strings.HasPrefix(collection, ".") {
+		return "", fmt.Errorf("invalid collection name %q", collection)
+	}
+	return filepath.Join(s.dir, collection), nil
+}
+
+// documentPath returns the file where the document with the specified id is stored.
+func (s *Store) documentPath(collection string, id string) (string, error) {
+	dir, err := s.collectionDir(collection)
+	if err != nil {
+		return "", err
+	}
+
+	// Escape the id so that it is always a valid file name
+	name := url.PathEscape(id)
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+	return filepath.Join(dir, name+documentExt), nil
+}
+
+// load reads every document in a collection, sorted by file name.
+func (s *Store) load(collection string) ([]storedDocument, error) {
+	dir, err := s.collectionDir(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing the %s collection: %w", collection, err)
+	}
+
+	var results []storedDocument
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != documentExt || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading document %s: %w", path, err)
+		}
+
+		doc, err := docquery.UnmarshalDocument(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading document %s: %w", path, err)
+		}
+		results = append(results, storedDocument{path: path, doc: doc})
+	}
+	return results, nil
+}
+
+func documents(stored []storedDocument) []docquery.Document {
+	docs := make([]docquery.Document, len(stored))
+	for i, item := range stored {
+		docs[i] = item.doc
+	}
+	return docs
+}
+
+// match returns the stored documents that match the filter.
+func match(stored []storedDocument, filter docquery.Filter) ([]storedDocument, error) {
+	var results []storedDocument
+	for _, item := range stored {
+		matched, err := filter.Matches(item.doc)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+// write saves the document to the specified file, replacing it atomically
+// so that readers never see a partially written document.
+func write(path string, doc interface{}) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling document %s: %w", path, err)
+	}
+	data = append(data, '\n')
+
+	if err = os.MkdirAll(filepath.Dir(path), FileModeDirectory); err != nil {
+		return fmt.Errorf("error creating directory for document %s: %w", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for document %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing document %s: %w", path, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("error writing document %s: %w", path, err)
+	}
+	if err = os.Chmod(tmp.Name(), FileModeWritable); err != nil {
+		return fmt.Errorf("error setting permissions on document %s: %w", path, err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error saving document %s: %w", path, err)
+	}
+	return nil
+}
+
+// index is the representation of a plugins.Index saved to the indices file.
+type index struct {
+	Collection string   `json:"collection"`
+	Keys       []string `json:"keys"`
+	Unique     bool     `json:"unique"`
+}
+
+func newIndex(src plugins.Index) (index, error) {
+	i := index{Collection: src.Collection, Unique: src.Unique, Keys: make([]string, len(src.Keys))}
+	for j, key := range src.Keys {
+		order, err := docquery.Normalize(key.Value)
+		if err != nil {
+			return index{}, err
+		}
+		if n, ok := order.(float64); ok && n < 0 {
+			i.Keys[j] = "-" + key.Key
+		} else {
+			i.Keys[j] = key.Key
+		}
+	}
+	return i, nil
+}
+
+// fields returns the indexed fields, without their sort order.
+func (i index) fields() []string {
+	fields := make([]string, len(i.Keys))
+	for j, key := range i.Keys {
+		fields[j] = strings.TrimPrefix(key, "-")
+	}
+	return fields
+}
+
+func (i index) sameFields(other index) bool {
+	return i.Collection == other.Collection && strings.Join(i.fields(), ",") == strings.Join(other.fields(), ",")
+}
+
+func (s *Store) loadIndices() ([]index, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, indicesFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading the storage indices: %w", err)
+	}
+
+	var indices []index
+	if err = json.Unmarshal(data, &indices); err != nil {
+		return nil, fmt.Errorf("error parsing the storage indices: %w", err)
+	}
+	return indices, nil
+}
+
+// checkUnique validates that writing the document does not violate a
+// unique index on the collection. The document being replaced, if any,
+// is identified by its path and excluded from the check.
+func (s *Store) checkUnique(collection string, stored []storedDocument, path string, doc docquery.Document) error {
+	indices, err := s.loadIndices()
+	if err != nil {
+		return err
+	}
+
+	for _, i := range indices {
+		if i.Collection != collection || !i.Unique {
+			continue
+		}
+
+		fields := i.fields()
+		for _, existing := range stored {
+			if existing.path == path {
+				continue
+			}
+			if docquery.HasSameKeys(existing.doc, doc, fields) {
+				return fmt.Errorf("duplicate key error: a document in the %s collection already exists with the same values for %s", collection, strings.Join(fields, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Store) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	var results []docquery.Document
+	err := s.withLock(ctx, false, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		results, err = docquery.Aggregate(documents(stored), opts.Pipeline)
+		return err
+	})
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	raw, err := docquery.ToBsonList(results)
+	return raw, span.Error(err)
+}
+
+// EnsureIndex makes sure that the specified indexes exist and are
+// defined appropriately.
+func (s *Store) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	err := s.withLock(ctx, true, func() error {
+		indices, err := s.loadIndices()
+		if err != nil {
+			return err
+		}
+
+		for _, src := range opts.Indices {
+			if len(src.Keys) == 0 {
+				return fmt.Errorf("invalid index specified for the %s collection: no keys were defined", src.Collection)
+			}
+
+			newIndex, err := newIndex(src)
+			if err != nil {
+				return err
+			}
+
+			// Replace an existing index on the same fields, when its definition has changed
+			found := false
+			for i, existing := range indices {
+				if existing.sameFields(newIndex) {
+					indices[i] = newIndex
+					found = true
+					break
+				}
+			}
+			if !found {
+				indices = append(indices, newIndex)
+			}
+		}
+
+		sort.SliceStable(indices, func(i, j int) bool {
+			return indices[i].Collection < indices[j].Collection
+		})
+		return write(filepath.Join(s.dir, indicesFile), indices)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return 0, err
+	}
+
+	filter, err := docquery.NewFilter(opts.Filter)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	var count int64
+	err = s.withLock(ctx, false, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		matches, err := match(stored, filter)
+		count = int64(len(matches))
+		return err
+	})
+	return count, span.Error(err)
+}
+
+func (s *Store) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	var results []docquery.Document
+	err := s.withLock(ctx, false, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		results, err = docquery.Find(documents(stored), docquery.FindOptions{
+			Filter: opts.Filter,
+			Sort:   opts.Sort,
+			Skip:   opts.Skip,
+			Limit:  opts.Limit,
+			Select: opts.Select,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	raw, err := docquery.ToBsonList(results)
+	return raw, span.Error(err)
+}
+
+func (s *Store) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	err := s.withLock(ctx, true, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		// Validate every document before writing any of them
+		pending := make([]storedDocument, 0, len(opts.Documents))
+		for _, rawDoc := range opts.Documents {
+			doc, err := docquery.NewDocument(rawDoc)
+			if err != nil {
+				return err
+			}
+
+			id := docquery.EnsureID(doc)
+			path, err := s.documentPath(opts.Collection, id)
+			if err != nil {
+				return err
+			}
+
+			for _, existing := range append(stored, pending...) {
+				if existing.path == path {
+					return fmt.Errorf("duplicate key error: a document with _id %s already exists in the %s collection", id, opts.Collection)
+				}
+			}
+			if err = s.checkUnique(opts.Collection, append(stored, pending...), path, doc); err != nil {
+				return err
+			}
+
+			pending = append(pending, storedDocument{path: path, doc: doc})
+		}
+
+		for _, item := range pending {
+			if err = write(item.path, item.doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Patch(ctx context.Context, opts plugins.PatchOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	filter, err := docquery.NewFilter(opts.QueryDocument)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	err = s.withLock(ctx, true, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		matches, err := match(stored, filter)
+		if err != nil || len(matches) == 0 {
+			return err
+		}
+
+		// Only the first matching document is patched
+		patched, err := docquery.Patch(matches[0].doc, opts.Transformation)
+		if err != nil {
+			return err
+		}
+		if err = s.checkUnique(opts.Collection, stored, matches[0].path, patched); err != nil {
+			return err
+		}
+		return write(matches[0].path, patched)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	filter, err := docquery.NewFilter(opts.Filter)
+	if err != nil {
+		return span.Error(err)
+	}
+
+	err = s.withLock(ctx, true, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		matches, err := match(stored, filter)
+		if err != nil {
+			return err
+		}
+		if !opts.All && len(matches) > 1 {
+			matches = matches[:1]
+		}
+
+		for _, item := range matches {
+			if err = os.Remove(item.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("error removing document %s: %w", item.path, err)
+			}
+		}
+		return nil
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return 0, err
+	}
+
+	filter, err := docquery.NewFilter(opts.Filter)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	replacement, err := docquery.NewDocument(opts.Document)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	var matched int64
+	err = s.withLock(ctx, true, func() error {
+		stored, err := s.load(opts.Collection)
+		if err != nil {
+			return err
+		}
+
+		matches, err := match(stored, filter)
+		if err != nil {
+			return err
+		}
+
+		if len(matches) == 0 {
+			if !opts.Upsert {
+				return nil
+			}
+
+			doc := docquery.NewUpsertDocument(filter, replacement)
+			id, _ := doc.ID()
+			path, err := s.documentPath(opts.Collection, id)
+			if err != nil {
+				return err
+			}
+			for _, existing := range stored {
+				if existing.path == path {
+					return fmt.Errorf("duplicate key error: a document with _id %s already exists in the %s collection", id, opts.Collection)
+				}
+			}
+			if err = s.checkUnique(opts.Collection, stored, path, doc); err != nil {
+				return err
+			}
+			return write(path, doc)
+		}
+
+		// Only the first matching document is replaced
+		matched = 1
+		doc, err := docquery.Replace(matches[0].doc, replacement)
+		if err != nil {
+			return err
+		}
+		if err = s.checkUnique(opts.Collection, stored, matches[0].path, doc); err != nil {
+			return err
+		}
+		return write(matches[0].path, doc)
+	})
+	if err != nil {
+		return 0, span.Error(err)
+	}
+	return matched, nil
+}
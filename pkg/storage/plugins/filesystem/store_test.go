@@ -0,0 +1,164 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newTestStore(t *testing.T) *Store {
+	c := config.NewTestConfig(t)
+	s := NewStore(c.Config, PluginConfig{Path: t.TempDir()})
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+	return s
+}
+
+func TestStore_DefaultPath(t *testing.T) {
+	c := config.NewTestConfig(t)
+	home := t.TempDir()
+	c.SetHomeDir(home)
+
+	s := NewStore(c.Config, PluginConfig{})
+	defer s.Close()
+
+	require.NoError(t, s.Connect(context.Background()))
+	assert.Equal(t, filepath.Join(home, DefaultStorageDir), s.dir)
+	assert.DirExists(t, s.dir)
+}
+
+func TestStore_DocumentFiles(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: "credentials", Documents: []bson.M{
+		{"_id": "dev/azure", "namespace": "dev", "name": "azure"},
+	}})
+	require.NoError(t, err)
+
+	// Documents are stored as formatted json named after their id, so they can be reviewed in source control
+	data, err := os.ReadFile(filepath.Join(s.dir, "credentials", "dev%2Fazure.json"))
+	require.NoError(t, err)
+	wantData := `{
+  "_id": "dev/azure",
+  "name": "azure",
+  "namespace": "dev"
+}
+`
+	assert.Equal(t, wantData, string(data))
+
+	err = s.Insert(ctx, plugins.InsertOptions{Collection: "credentials", Documents: []bson.M{{"_id": "dev/azure"}}})
+	require.ErrorContains(t, err, "duplicate key error")
+
+	err = s.Remove(ctx, plugins.RemoveOptions{Collection: "credentials", Filter: bson.M{"name": "azure"}})
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(s.dir, "credentials", "dev%2Fazure.json"))
+}
+
+func TestStore_EnsureIndex_Unique(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	opts := plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: "installations", Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}}, Unique: true},
+	}}
+	require.NoError(t, s.EnsureIndex(ctx, opts))
+	require.NoError(t, s.EnsureIndex(ctx, opts), "EnsureIndex should be idempotent")
+
+	indices, err := s.loadIndices()
+	require.NoError(t, err)
+	assert.Len(t, indices, 1)
+
+	require.NoError(t, s.Insert(ctx, plugins.InsertOptions{Collection: "installations", Documents: []bson.M{{"namespace": "dev", "name": "mysql"}}}))
+
+	err = s.Insert(ctx, plugins.InsertOptions{Collection: "installations", Documents: []bson.M{{"namespace": "dev", "name": "mysql"}}})
+	require.ErrorContains(t, err, "duplicate key error", "the unique index should reject a duplicate document")
+
+	matched, err := s.Update(ctx, plugins.UpdateOptions{Collection: "installations", Filter: bson.M{"name": "mysql"}, Document: bson.M{"namespace": "dev", "name": "mysql", "uninstalled": true}})
+	require.NoError(t, err, "replacing a document should not conflict with itself")
+	assert.Equal(t, int64(1), matched)
+}
+
+func TestStore_Lock(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	s.timeout = 100 * time.Millisecond
+	require.NoError(t, s.Connect(ctx))
+
+	// Simulate another porter process holding the lock
+	other := NewStore(s.config, PluginConfig{Path: s.dir})
+	require.NoError(t, other.Connect(ctx))
+	defer other.Close()
+	locked, err := other.lock.TryLock()
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	err = s.Insert(ctx, plugins.InsertOptions{Collection: "runs", Documents: []bson.M{{"_id": "1"}}})
+	require.ErrorContains(t, err, "timed out waiting for the lock on the storage directory")
+
+	require.NoError(t, other.lock.Unlock())
+	err = s.Insert(ctx, plugins.InsertOptions{Collection: "runs", Documents: []bson.M{{"_id": "1"}}})
+	require.NoError(t, err)
+}
+
+func TestStore_PorterStores(t *testing.T) {
+	// Validate that the plugin supports the queries made by porter's stores
+	ctx := context.Background()
+	s := newTestStore(t)
+	store := storage.NewPluginAdapter(s)
+	require.NoError(t, storage.EnsureInstallationIndices(ctx, store))
+
+	installations := storage.NewInstallationStore(store)
+	inst := storage.NewInstallation("dev", "mysql")
+	inst.Labels = map[string]string{"team": "data"}
+	require.NoError(t, installations.InsertInstallation(ctx, inst))
+	require.NoError(t, installations.InsertInstallation(ctx, storage.NewInstallation("", "wordpress")))
+	require.Error(t, installations.InsertInstallation(ctx, storage.NewInstallation("dev", "mysql")), "installations should be unique by namespace and name")
+
+	list, err := installations.ListInstallations(ctx, storage.ListOptions{Namespace: "*", Labels: map[string]string{"team": "data"}})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, inst.ID, list[0].ID)
+
+	run := inst.NewRun("install", cnab.ExtendedBundle{})
+	require.NoError(t, installations.InsertRun(ctx, run))
+	result := run.NewResult("succeeded")
+	require.NoError(t, installations.InsertResult(ctx, result))
+	require.NoError(t, installations.InsertOutput(ctx, result.NewOutput("port", []byte("3306"))))
+
+	outputs, err := installations.GetLastOutputs(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	port, ok := outputs.GetByName("port")
+	require.True(t, ok)
+	assert.Equal(t, "3306", string(port.Value))
+
+	// Credential sets are looked up in the current namespace, falling back to the global namespace
+	credentials := storage.NewCredentialStore(store, secrets.NewTestSecretsProvider())
+	require.NoError(t, credentials.InsertCredentialSet(ctx, storage.NewCredentialSet("", "azure")))
+	var cs storage.CredentialSet
+	err = store.FindOne(ctx, storage.CollectionCredentials, storage.FindOptions{
+		Sort: []string{"-namespace"},
+		Filter: bson.M{
+			"name": "azure",
+			"$or":  []bson.M{{"namespace": ""}, {"namespace": "dev"}},
+		},
+	}, &cs)
+	require.NoError(t, err)
+	assert.Equal(t, "azure", cs.Name)
+
+	require.NoError(t, installations.RemoveInstallation(ctx, "dev", "mysql"))
+	_, err = installations.GetInstallation(ctx, "dev", "mysql")
+	require.ErrorIs(t, err, storage.ErrNotFound{})
+}
@@ -292,12 +292,12 @@ func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
 	return span.Error(err)
 }
 
-func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.EndSpan()
 
 	if err := s.Connect(ctx); err != nil {
-		return err
+		return 0, err
 	}
 
 	c := s.getCollection(opts.Collection)
@@ -305,8 +305,11 @@ func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) error {
 	cxt, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	_, err := c.ReplaceOne(cxt, opts.Filter, opts.Document, &options.ReplaceOptions{Upsert: &opts.Upsert})
-	return span.Error(err)
+	result, err := c.ReplaceOne(cxt, opts.Filter, opts.Document, &options.ReplaceOptions{Upsert: &opts.Upsert})
+	if err != nil {
+		return 0, span.Error(err)
+	}
+	return result.MatchedCount, nil
 }
 
 func (s *Store) getCollection(collection string) *mongo.Collection {
@@ -131,9 +131,9 @@ func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
 	return s.Store.Remove(ctx, opts)
 }
 
-func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
 	if err := s.Connect(ctx); err != nil {
-		return err
+		return 0, err
 	}
 
 	return s.Store.Update(ctx, opts)
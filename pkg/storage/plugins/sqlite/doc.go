@@ -0,0 +1,5 @@
+// Package sqlite provides a storage plugin that saves Porter's data in an
+// embedded SQLite database file, so that Porter can be used without running
+// MongoDB or Docker. It is intended for a single user or CI runner, and is not
+// suitable for sharing data between multiple machines.
+package sqlite
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/pluginstore"
+	"github.com/hashicorp/go-plugin"
+	"github.com/mitchellh/mapstructure"
+)
+
+// PluginKey is the identifier of the internal sqlite plugin.
+const PluginKey = plugins.PluginInterface + ".porter.sqlite"
+
+var _ plugins.StorageProtocol = Plugin{}
+
+type Plugin struct {
+	*Store
+}
+
+// PluginConfig are the configuration settings that can be defined for the
+// sqlite plugin in porter.yaml
+type PluginConfig struct {
+	// Path to the database file. Defaults to PORTER_HOME/porter.db
+	Path string `mapstructure:"path,omitempty"`
+
+	// Timeout in seconds to wait for the database to be unlocked by another porter process.
+	Timeout int `mapstructure:"timeout,omitempty"`
+}
+
+// NewPlugin creates an instance of the storage.porter.sqlite plugin
+func NewPlugin(c *config.Config, rawCfg interface{}) (plugin.Plugin, error) {
+	cfg := PluginConfig{
+		Timeout: 10,
+	}
+	if err := mapstructure.Decode(rawCfg, &cfg); err != nil {
+		return nil, fmt.Errorf("error reading plugin configuration: %w", err)
+	}
+
+	impl := NewStore(c, cfg)
+	return pluginstore.NewPlugin(c.Context, impl), nil
+}
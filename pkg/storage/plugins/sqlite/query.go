@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/storage/plugins/docquery"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sqlFields are the fields that are evaluated by SQLite instead of docquery.
+// They are the fields that Porter indexes, and always hold a single string in
+// Porter's documents, so comparing them in SQL matches the MongoDB semantics.
+// Other fields may hold arrays or values of mixed types, which SQLite compares
+// differently than MongoDB.
+var sqlFields = map[string]bool{
+	"_id":          true,
+	"namespace":    true,
+	"installation": true,
+	"name":         true,
+	"runId":        true,
+	"resultId":     true,
+}
+
+// query is a find query split into the parts that are evaluated by SQLite,
+// and the remainder that is evaluated by docquery after the documents are loaded.
+type query struct {
+	// where is the WHERE clause selecting the documents in the collection.
+	where string
+
+	// args are the arguments for the placeholders in the WHERE clause.
+	args []interface{}
+
+	// orderBy is the ORDER BY clause. Documents are returned in insertion
+	// order when the sort is not evaluated by SQLite.
+	orderBy string
+
+	// limit is the LIMIT clause, when the results are paged by SQLite.
+	limit string
+
+	// remaining are the options that must still be applied by docquery.
+	remaining docquery.FindOptions
+}
+
+// filtered reports if the filter is evaluated entirely by SQLite.
+func (q query) filtered() bool {
+	return len(q.remaining.Filter) == 0
+}
+
+// buildQuery converts equality filters on the indexed fields, and sorts by those
+// fields, to SQL so that SQLite can use the indices created by EnsureIndex.
+// Paging is done by SQLite when both the filter and the sort were converted.
+func buildQuery(collection string, opts docquery.FindOptions) (query, error) {
+	// Compare the collection to a literal, so that SQLite can use the partial indices for the collection
+	q := query{
+		where:   "collection = " + quoteString(collection),
+		orderBy: "rowid",
+		remaining: docquery.FindOptions{
+			Filter: map[string]interface{}{},
+			Select: opts.Select,
+		},
+	}
+
+	if len(opts.Filter) > 0 {
+		normalized, err := docquery.Normalize(opts.Filter)
+		if err != nil {
+			return query{}, fmt.Errorf("invalid query filter: %w", err)
+		}
+
+		filter := normalized.(map[string]interface{})
+		fields := make([]string, 0, len(filter))
+		for field := range filter {
+			fields = append(fields, field)
+		}
+		// Generate the conditions in a stable order
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			condition, args, ok := fieldCondition(field, filter[field])
+			if !ok {
+				q.remaining.Filter[field] = filter[field]
+				continue
+			}
+			q.where += " AND " + condition
+			q.args = append(q.args, args...)
+		}
+	}
+
+	orderBy, ok, err := sortClause(opts.Sort)
+	if err != nil {
+		return query{}, err
+	}
+	if !ok {
+		q.remaining.Sort = opts.Sort
+	} else if orderBy != "" {
+		// Break ties by insertion order, as docquery.Sort does
+		q.orderBy = orderBy + ", rowid"
+	}
+
+	if q.filtered() && ok {
+		if opts.Limit > 0 || opts.Skip > 0 {
+			limit := opts.Limit
+			if limit <= 0 {
+				limit = -1
+			}
+			q.limit = fmt.Sprintf("%d OFFSET %d", limit, opts.Skip)
+		}
+	} else {
+		q.remaining.Skip = opts.Skip
+		q.remaining.Limit = opts.Limit
+	}
+
+	return q, nil
+}
+
+// fieldCondition converts a filter on a single field to SQL, returning false
+// when the filter must be evaluated by docquery. Only string equality, {"$eq": value},
+// and {"$in": [values]} filters on sqlFields are converted.
+func fieldCondition(field string, value interface{}) (string, []interface{}, bool) {
+	if !sqlFields[field] {
+		return "", nil, false
+	}
+
+	column := fieldExpression(field)
+	switch v := value.(type) {
+	case string:
+		return column + " = ?", []interface{}{v}, true
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return "", nil, false
+		}
+		if eq, ok := v["$eq"].(string); ok {
+			return column + " = ?", []interface{}{eq}, true
+		}
+		items, ok := v["$in"].([]interface{})
+		if !ok || len(items) == 0 {
+			return "", nil, false
+		}
+		placeholders := make([]string, len(items))
+		for i, item := range items {
+			if _, ok := item.(string); !ok {
+				return "", nil, false
+			}
+			placeholders[i] = "?"
+		}
+		return column + " IN (" + strings.Join(placeholders, ", ") + ")", items, true
+	}
+	return "", nil, false
+}
+
+// sortClause converts a sort document to an ORDER BY clause, returning false
+// when it sorts by a field that is not in sqlFields.
+func sortClause(spec bson.D) (string, bool, error) {
+	columns := make([]string, 0, len(spec))
+	for _, e := range spec {
+		if !sqlFields[e.Key] {
+			return "", false, nil
+		}
+
+		order, err := docquery.Normalize(e.Value)
+		if err != nil {
+			return "", false, err
+		}
+		switch order {
+		case float64(1):
+			columns = append(columns, fieldExpression(e.Key)+" ASC")
+		case float64(-1):
+			columns = append(columns, fieldExpression(e.Key)+" DESC")
+		default:
+			return "", false, fmt.Errorf("invalid sort order for %s: %v, must be 1 or -1", e.Key, e.Value)
+		}
+	}
+	return strings.Join(columns, ", "), true, nil
+}
+
+// fieldExpression returns the SQL expression for a field of a document. The
+// expression must match the one used by buildIndex for SQLite to use the index.
+func fieldExpression(field string) string {
+	if field == "_id" {
+		return "id"
+	}
+	return fmt.Sprintf("json_extract(doc, %s)", quoteString(jsonPath(field)))
+}
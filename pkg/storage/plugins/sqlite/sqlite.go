@@ -0,0 +1,611 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/docquery"
+	"get.porter.sh/porter/pkg/tracing"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+
+	// Register the pure-go sqlite driver, so that porter can still be built with CGO_ENABLED=0
+	_ "modernc.org/sqlite"
+)
+
+var _ plugins.StorageProtocol = &Store{}
+
+const (
+	// DefaultDatabaseFile is the name of the database file created in PORTER_HOME
+	// when a path is not configured.
+	DefaultDatabaseFile = "porter.db"
+
+	// documentsTable stores every document, for all collections, as json.
+	documentsTable = "documents"
+)
+
+// Store implements the Porter plugin.StoragePlugin interface for an embedded
+// SQLite database.
+//
+// Documents are stored as json in a single table, keyed by their collection
+// and _id. Queries are evaluated by the docquery package so that the plugin
+// supports the same filters, sorting and projections as the mongodb plugin.
+// Equality filters and sorts on the fields that Porter indexes are evaluated
+// by SQLite, so that only the documents selected by the query are decoded.
+// Indices requested by Porter are created as SQLite expression indices so
+// that unique constraints are enforced by the database.
+type Store struct {
+	config  *config.Config
+	path    string
+	timeout time.Duration
+	db      *sql.DB
+}
+
+// NewStore creates a new storage engine that uses SQLite.
+func NewStore(c *config.Config, cfg PluginConfig) *Store {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 // default to 10 seconds
+	}
+	return &Store{
+		config:  c,
+		path:    cfg.Path,
+		timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+// Connect initializes the plugin for use.
+// The plugin itself is responsible for ensuring it was called.
+// Close is called automatically when the plugin is used by Porter.
+func (s *Store) Connect(ctx context.Context) error {
+	if s.db != nil {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if s.path == "" {
+		home, err := s.config.GetHomeDir()
+		if err != nil {
+			return span.Error(fmt.Errorf("could not determine the default database path: %w", err))
+		}
+		s.path = filepath.Join(home, DefaultDatabaseFile)
+	}
+	span.SetAttributes(attribute.String("path", s.path))
+
+	// SQLite works directly with the filesystem, so we can't use the
+	// context's filesystem abstraction here.
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return span.Error(fmt.Errorf("error creating the directory for the sqlite database %s: %w", s.path, err))
+	}
+
+	// Wait for other porter processes to release their locks, and take the write lock at the start of
+	// a transaction so that concurrent read-modify-write operations are serialized instead of deadlocking.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		filepath.ToSlash(s.path), s.timeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return span.Error(fmt.Errorf("error opening the sqlite database %s: %w", s.path, err))
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	createTable := `CREATE TABLE IF NOT EXISTS ` + documentsTable + ` (
+	collection TEXT NOT NULL,
+	id TEXT NOT NULL,
+	doc TEXT NOT NULL,
+	PRIMARY KEY (collection, id)
+)`
+	if _, err = db.ExecContext(cxt, createTable); err != nil {
+		db.Close()
+		return span.Error(fmt.Errorf("error initializing the sqlite database %s: %w", s.path, err))
+	}
+
+	s.db = db
+	return nil
+}
+
+func (s *Store) Close() error {
+	if s.db != nil {
+		if err := s.db.Close(); err != nil {
+			return err
+		}
+		s.db = nil
+	}
+	return nil
+}
+
+// storedDocument is a document loaded from the database, along with the
+// rowid used to update it.
+type storedDocument struct {
+	rowid int64
+	doc   docquery.Document
+}
+
+// queryer is implemented by both sql.DB and sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// load reads the documents in a collection that are selected by the SQL
+// portion of a query. Documents are returned in insertion order unless the
+// query is sorted by SQLite.
+func (s *Store) load(ctx context.Context, db queryer, collection string, q query) ([]storedDocument, error) {
+	stmt := `SELECT rowid, doc FROM ` + documentsTable + ` WHERE ` + q.where + ` ORDER BY ` + q.orderBy
+	if q.limit != "" {
+		stmt += ` LIMIT ` + q.limit
+	}
+	rows, err := db.QueryContext(ctx, stmt, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying the %s collection: %w", collection, err)
+	}
+	defer rows.Close()
+
+	var results []storedDocument
+	for rows.Next() {
+		var rowid int64
+		var data []byte
+		if err = rows.Scan(&rowid, &data); err != nil {
+			return nil, fmt.Errorf("error reading a document from the %s collection: %w", collection, err)
+		}
+
+		doc, err := docquery.UnmarshalDocument(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading document %d from the %s collection: %w", rowid, collection, err)
+		}
+		results = append(results, storedDocument{rowid: rowid, doc: doc})
+	}
+	return results, rows.Err()
+}
+
+// loadDocuments reads the documents selected by a query, without their rowids.
+func (s *Store) loadDocuments(ctx context.Context, collection string, q query) ([]docquery.Document, error) {
+	stored, err := s.load(ctx, s.db, collection, q)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]docquery.Document, len(stored))
+	for i, item := range stored {
+		docs[i] = item.doc
+	}
+	return docs, nil
+}
+
+// loadMatches reads the documents in a collection that match a filter, using
+// SQL for the parts of the filter that SQLite can evaluate.
+func (s *Store) loadMatches(ctx context.Context, db queryer, collection string, filter map[string]interface{}) ([]storedDocument, error) {
+	q, err := buildQuery(collection, docquery.FindOptions{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.load(ctx, db, collection, q)
+	if err != nil || q.filtered() {
+		return stored, err
+	}
+
+	remaining, err := docquery.NewFilter(q.remaining.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return match(stored, remaining)
+}
+
+// match returns the stored documents that match the filter.
+func match(stored []storedDocument, filter docquery.Filter) ([]storedDocument, error) {
+	var results []storedDocument
+	for _, item := range stored {
+		matched, err := filter.Matches(item.doc)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			results = append(results, item)
+		}
+	}
+	return results, nil
+}
+
+// inTransaction runs the specified function in a transaction, committing
+// when it succeeds and rolling back otherwise.
+func (s *Store) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting a sqlite transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing the sqlite transaction: %w", err)
+	}
+	return nil
+}
+
+func insertDocument(ctx context.Context, tx *sql.Tx, collection string, doc docquery.Document) error {
+	id := docquery.EnsureID(doc)
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("error marshaling document %s: %w", id, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO `+documentsTable+` (collection, id, doc) VALUES (?, ?, ?)`, collection, id, string(data))
+	if err != nil {
+		return fmt.Errorf("error inserting document %s into the %s collection: %w", id, collection, err)
+	}
+	return nil
+}
+
+func replaceDocument(ctx context.Context, tx *sql.Tx, collection string, rowid int64, doc docquery.Document) error {
+	id, _ := doc.ID()
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("error marshaling document %s: %w", id, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE `+documentsTable+` SET doc = ? WHERE rowid = ?`, string(data), rowid)
+	if err != nil {
+		return fmt.Errorf("error updating document %s in the %s collection: %w", id, collection, err)
+	}
+	return nil
+}
+
+func (s *Store) Aggregate(ctx context.Context, opts plugins.AggregateOptions) ([]bson.Raw, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	// The pipeline is evaluated by docquery, so every document in the collection is loaded
+	q, err := buildQuery(opts.Collection, docquery.FindOptions{})
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	docs, err := s.loadDocuments(cxt, opts.Collection, q)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	results, err := docquery.Aggregate(docs, opts.Pipeline)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	raw, err := docquery.ToBsonList(results)
+	return raw, span.Error(err)
+}
+
+// EnsureIndex makes sure that the specified indexes exist and are
+// defined appropriately.
+func (s *Store) EnsureIndex(ctx context.Context, opts plugins.EnsureIndexOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := s.inTransaction(cxt, func(tx *sql.Tx) error {
+		for _, index := range opts.Indices {
+			name, definition, err := buildIndex(index)
+			if err != nil {
+				return err
+			}
+
+			// Recreate the index when its definition has changed
+			var existing string
+			err = tx.QueryRowContext(cxt, `SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?`, name).Scan(&existing)
+			if err == nil && existing == definition {
+				continue
+			}
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("error checking for index %s: %w", name, err)
+			}
+			if err == nil {
+				if _, err = tx.ExecContext(cxt, `DROP INDEX `+quoteIdentifier(name)); err != nil {
+					return fmt.Errorf("error removing outdated index %s: %w", name, err)
+				}
+			}
+
+			if _, err = tx.ExecContext(cxt, definition); err != nil {
+				return fmt.Errorf("invalid index specified: %s: %w", definition, err)
+			}
+		}
+		return nil
+	})
+	return span.Error(err)
+}
+
+// buildIndex generates a partial expression index for a collection, so
+// that SQLite can enforce unique constraints on fields inside the json documents.
+func buildIndex(index plugins.Index) (string, string, error) {
+	if len(index.Keys) == 0 {
+		return "", "", fmt.Errorf("invalid index specified for the %s collection: no keys were defined", index.Collection)
+	}
+
+	nameParts := []string{"idx", index.Collection}
+	columns := make([]string, len(index.Keys))
+	for i, key := range index.Keys {
+		order, err := docquery.Normalize(key.Value)
+		if err != nil {
+			return "", "", err
+		}
+		direction := "ASC"
+		if n, ok := order.(float64); ok && n < 0 {
+			direction = "DESC"
+			nameParts = append(nameParts, "-"+key.Key)
+		} else {
+			nameParts = append(nameParts, key.Key)
+		}
+		columns[i] = fmt.Sprintf("json_extract(doc, %s) %s", quoteString(jsonPath(key.Key)), direction)
+	}
+	if index.Unique {
+		nameParts = append(nameParts, "unique")
+	}
+
+	name := strings.Join(nameParts, "_")
+	unique := ""
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	definition := fmt.Sprintf("CREATE %sINDEX %s ON %s (%s) WHERE collection = %s",
+		unique, quoteIdentifier(name), documentsTable, strings.Join(columns, ", "), quoteString(index.Collection))
+	return name, definition, nil
+}
+
+// jsonPath converts a dotted field path to a SQLite json path, e.g. $."labels"."env"
+func jsonPath(field string) string {
+	parts := strings.Split(field, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `\"`) + `"`
+	}
+	return "$." + strings.Join(parts, ".")
+}
+
+func quoteIdentifier(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+func quoteString(value string) string {
+	return `'` + strings.ReplaceAll(value, `'`, `''`) + `'`
+}
+
+func (s *Store) Count(ctx context.Context, opts plugins.CountOptions) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return 0, err
+	}
+
+	q, err := buildQuery(opts.Collection, docquery.FindOptions{Filter: opts.Filter})
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if q.filtered() {
+		var count int64
+		err = s.db.QueryRowContext(cxt, `SELECT COUNT(*) FROM `+documentsTable+` WHERE `+q.where, q.args...).Scan(&count)
+		if err != nil {
+			return 0, span.Error(fmt.Errorf("error counting the documents in the %s collection: %w", opts.Collection, err))
+		}
+		return count, nil
+	}
+
+	stored, err := s.loadMatches(cxt, s.db, opts.Collection, opts.Filter)
+	return int64(len(stored)), span.Error(err)
+}
+
+func (s *Store) Find(ctx context.Context, opts plugins.FindOptions) ([]bson.Raw, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+	if err := s.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	q, err := buildQuery(opts.Collection, docquery.FindOptions{
+		Filter: opts.Filter,
+		Sort:   opts.Sort,
+		Skip:   opts.Skip,
+		Limit:  opts.Limit,
+		Select: opts.Select,
+	})
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	docs, err := s.loadDocuments(cxt, opts.Collection, q)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	// Apply the parts of the query that SQLite could not evaluate
+	results, err := docquery.Find(docs, q.remaining)
+	if err != nil {
+		return nil, span.Error(err)
+	}
+
+	raw, err := docquery.ToBsonList(results)
+	return raw, span.Error(err)
+}
+
+func (s *Store) Insert(ctx context.Context, opts plugins.InsertOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	docs := make([]docquery.Document, len(opts.Documents))
+	for i, rawDoc := range opts.Documents {
+		doc, err := docquery.NewDocument(rawDoc)
+		if err != nil {
+			return span.Error(err)
+		}
+		docs[i] = doc
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := s.inTransaction(cxt, func(tx *sql.Tx) error {
+		for _, doc := range docs {
+			if err := insertDocument(cxt, tx, opts.Collection, doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Patch(ctx context.Context, opts plugins.PatchOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := s.inTransaction(cxt, func(tx *sql.Tx) error {
+		matches, err := s.loadMatches(cxt, tx, opts.Collection, opts.QueryDocument)
+		if err != nil || len(matches) == 0 {
+			return err
+		}
+
+		// Only the first matching document is patched
+		patched, err := docquery.Patch(matches[0].doc, opts.Transformation)
+		if err != nil {
+			return err
+		}
+		return replaceDocument(cxt, tx, opts.Collection, matches[0].rowid, patched)
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return err
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	err := s.inTransaction(cxt, func(tx *sql.Tx) error {
+		matches, err := s.loadMatches(cxt, tx, opts.Collection, opts.Filter)
+		if err != nil {
+			return err
+		}
+		if !opts.All && len(matches) > 1 {
+			matches = matches[:1]
+		}
+
+		for _, item := range matches {
+			if _, err = tx.ExecContext(cxt, `DELETE FROM `+documentsTable+` WHERE rowid = ?`, item.rowid); err != nil {
+				return fmt.Errorf("error removing a document from the %s collection: %w", opts.Collection, err)
+			}
+		}
+		return nil
+	})
+	return span.Error(err)
+}
+
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Connect(ctx); err != nil {
+		return 0, err
+	}
+
+	filter, err := docquery.NewFilter(opts.Filter)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	replacement, err := docquery.NewDocument(opts.Document)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+
+	cxt, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var matched int64
+	err = s.inTransaction(cxt, func(tx *sql.Tx) error {
+		matches, err := s.loadMatches(cxt, tx, opts.Collection, opts.Filter)
+		if err != nil {
+			return err
+		}
+
+		if len(matches) == 0 {
+			if !opts.Upsert {
+				return nil
+			}
+			return insertDocument(cxt, tx, opts.Collection, docquery.NewUpsertDocument(filter, replacement))
+		}
+
+		// Only the first matching document is replaced
+		matched = 1
+		doc, err := docquery.Replace(matches[0].doc, replacement)
+		if err != nil {
+			return err
+		}
+		return replaceDocument(cxt, tx, opts.Collection, matches[0].rowid, doc)
+	})
+	if err != nil {
+		return 0, span.Error(err)
+	}
+	return matched, nil
+}
+
+// RemoveDatabase deletes the database file.
+func (s *Store) RemoveDatabase(ctx context.Context) error {
+	_, span := tracing.StartSpan(ctx)
+	defer span.EndSpan()
+
+	if err := s.Close(); err != nil {
+		return span.Error(err)
+	}
+
+	span.Info("Removing database", attribute.String("path", s.path))
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Remove(s.path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return span.Error(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/storage/plugins"
+	"get.porter.sh/porter/pkg/storage/plugins/docquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func newTestStore(t *testing.T) *Store {
+	c := config.NewTestConfig(t)
+	s := NewStore(c.Config, PluginConfig{Path: filepath.Join(t.TempDir(), "porter.db")})
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+	return s
+}
+
+func unmarshalNames(t *testing.T, results []bson.Raw) []string {
+	names := make([]string, len(results))
+	for i, result := range results {
+		var doc struct {
+			Name string `bson:"name"`
+		}
+		require.NoError(t, bson.Unmarshal(result, &doc))
+		names[i] = doc.Name
+	}
+	return names
+}
+
+func TestStore_DefaultPath(t *testing.T) {
+	c := config.NewTestConfig(t)
+	home := t.TempDir()
+	c.SetHomeDir(home)
+
+	s := NewStore(c.Config, PluginConfig{})
+	defer s.Close()
+
+	require.NoError(t, s.Connect(context.Background()))
+	assert.Equal(t, filepath.Join(home, DefaultDatabaseFile), s.path)
+	assert.FileExists(t, s.path)
+}
+
+func TestStore_Find(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	err := s.Insert(ctx, plugins.InsertOptions{
+		Collection: "installations",
+		Documents: []bson.M{
+			{"namespace": "dev", "name": "mysql", "labels": map[string]interface{}{"team": "data"}},
+			{"namespace": "dev", "name": "wordpress", "labels": map[string]interface{}{"team": "web"}},
+			{"namespace": "", "name": "mysql-global"},
+			{"namespace": "test", "name": "mysql", "credentialSets": []interface{}{"azure", "aws"}},
+		},
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name      string
+		opts      plugins.FindOptions
+		wantNames []string
+	}{
+		{name: "all documents", opts: plugins.FindOptions{}, wantNames: []string{"mysql", "wordpress", "mysql-global", "mysql"}},
+		{name: "equality", opts: plugins.FindOptions{Filter: bson.M{"namespace": "dev"}}, wantNames: []string{"mysql", "wordpress"}},
+		{name: "regex", opts: plugins.FindOptions{Filter: bson.M{"name": bson.M{"$regex": "sql"}}, Sort: bson.D{{Key: "name", Value: 1}}}, wantNames: []string{"mysql", "mysql", "mysql-global"}},
+		{name: "labels", opts: plugins.FindOptions{Filter: bson.M{"labels.team": "web"}}, wantNames: []string{"wordpress"}},
+		{name: "array contains", opts: plugins.FindOptions{Filter: bson.M{"credentialSets": "aws"}}, wantNames: []string{"mysql"}},
+		{name: "or", opts: plugins.FindOptions{
+			Filter: bson.M{"$or": []bson.M{{"namespace": ""}, {"namespace": "test"}}},
+			Sort:   bson.D{{Key: "namespace", Value: -1}},
+		}, wantNames: []string{"mysql", "mysql-global"}},
+		{name: "sort skip limit", opts: plugins.FindOptions{
+			Sort:  bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}},
+			Skip:  1,
+			Limit: 2,
+		}, wantNames: []string{"mysql", "wordpress"}},
+		{name: "in", opts: plugins.FindOptions{
+			Filter: bson.M{"namespace": bson.M{"$in": []string{"", "test"}}},
+			Sort:   bson.D{{Key: "namespace", Value: -1}},
+		}, wantNames: []string{"mysql", "mysql-global"}},
+		{name: "equality with an operator", opts: plugins.FindOptions{
+			Filter: bson.M{"name": "mysql", "credentialSets": "aws"},
+		}, wantNames: []string{"mysql"}},
+		{name: "limit with an operator", opts: plugins.FindOptions{
+			Filter: bson.M{"namespace": "dev", "labels.team": "web"},
+			Sort:   bson.D{{Key: "name", Value: 1}},
+			Limit:  1,
+		}, wantNames: []string{"wordpress"}},
+		{name: "limit with an unindexed sort", opts: plugins.FindOptions{
+			Filter: bson.M{"namespace": "dev"},
+			Sort:   bson.D{{Key: "labels.team", Value: -1}},
+			Limit:  1,
+		}, wantNames: []string{"wordpress"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := tc.opts
+			opts.Collection = "installations"
+			results, err := s.Find(ctx, opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantNames, unmarshalNames(t, results))
+		})
+	}
+
+	t.Run("projection", func(t *testing.T) {
+		results, err := s.Find(ctx, plugins.FindOptions{
+			Collection: "installations",
+			Filter:     bson.M{"namespace": "dev", "name": "mysql"},
+			Select:     bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 0}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		var doc bson.M
+		require.NoError(t, bson.Unmarshal(results[0], &doc))
+		assert.Equal(t, bson.M{"name": "mysql"}, doc)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		count, err := s.Count(ctx, plugins.CountOptions{Collection: "installations", Filter: bson.M{"name": "mysql"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = s.Count(ctx, plugins.CountOptions{Collection: "installations", Filter: bson.M{"name": "mysql", "credentialSets": "aws"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
+
+func TestBuildQuery(t *testing.T) {
+	t.Run("indexed fields", func(t *testing.T) {
+		q, err := buildQuery("runs", docquery.FindOptions{
+			Filter: map[string]interface{}{"namespace": "dev", "installation": bson.M{"$eq": "mysql"}, "_id": bson.M{"$in": []string{"1", "2"}}},
+			Sort:   bson.D{{Key: "_id", Value: -1}},
+			Skip:   2,
+			Limit:  1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `collection = 'runs' AND id IN (?, ?) AND json_extract(doc, '$."installation"') = ? AND json_extract(doc, '$."namespace"') = ?`, q.where)
+		assert.Equal(t, []interface{}{"1", "2", "mysql", "dev"}, q.args)
+		assert.Equal(t, "id DESC, rowid", q.orderBy)
+		assert.Equal(t, "1 OFFSET 2", q.limit)
+		assert.True(t, q.filtered())
+		assert.Empty(t, q.remaining.Sort)
+		assert.Zero(t, q.remaining.Limit)
+	})
+
+	t.Run("other filters", func(t *testing.T) {
+		q, err := buildQuery("installations", docquery.FindOptions{
+			Filter: map[string]interface{}{"namespace": "dev", "labels.env": "prod", "name": bson.M{"$regex": "sql"}},
+			Sort:   bson.D{{Key: "name", Value: 1}},
+			Limit:  1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `collection = 'installations' AND json_extract(doc, '$."namespace"') = ?`, q.where)
+		assert.Equal(t, map[string]interface{}{"labels.env": "prod", "name": map[string]interface{}{"$regex": "sql"}}, q.remaining.Filter)
+		assert.Equal(t, `json_extract(doc, '$."name"') ASC, rowid`, q.orderBy)
+		assert.Empty(t, q.limit, "results cannot be limited until docquery filters them")
+		assert.Equal(t, int64(1), q.remaining.Limit)
+	})
+
+	t.Run("unindexed sort", func(t *testing.T) {
+		q, err := buildQuery("installations", docquery.FindOptions{Sort: bson.D{{Key: "status.created", Value: -1}}, Skip: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "rowid", q.orderBy)
+		assert.Empty(t, q.limit)
+		assert.Equal(t, bson.D{{Key: "status.created", Value: -1}}, q.remaining.Sort)
+		assert.Equal(t, int64(1), q.remaining.Skip)
+	})
+}
+
+func TestStore_Find_UsesIndex(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	require.NoError(t, storage.EnsureInstallationIndices(ctx, storage.NewPluginAdapter(s)))
+
+	q, err := buildQuery("installations", docquery.FindOptions{Filter: map[string]interface{}{"namespace": "dev", "name": "mysql"}})
+	require.NoError(t, err)
+
+	rows, err := s.db.QueryContext(ctx, `EXPLAIN QUERY PLAN SELECT rowid, doc FROM `+documentsTable+` WHERE `+q.where+` ORDER BY `+q.orderBy, q.args...)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		require.NoError(t, rows.Scan(&id, &parent, &notused, &detail))
+		plan = append(plan, detail)
+	}
+	require.NoError(t, rows.Err())
+	assert.Contains(t, strings.Join(plan, "\n"), "USING INDEX idx_installations_namespace_name_unique")
+}
+
+func TestStore_EnsureIndex_Unique(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	opts := plugins.EnsureIndexOptions{Indices: []plugins.Index{
+		{Collection: "installations", Keys: bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}}, Unique: true},
+	}}
+	require.NoError(t, s.EnsureIndex(ctx, opts))
+	require.NoError(t, s.EnsureIndex(ctx, opts), "EnsureIndex should be idempotent")
+
+	doc := bson.M{"namespace": "dev", "name": "mysql"}
+	require.NoError(t, s.Insert(ctx, plugins.InsertOptions{Collection: "installations", Documents: []bson.M{doc}}))
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: "installations", Documents: []bson.M{{"namespace": "dev", "name": "mysql"}}})
+	require.Error(t, err, "the unique index should reject a duplicate document")
+	assert.Contains(t, err.Error(), "UNIQUE constraint failed")
+
+	// The index only applies to the installations collection
+	err = s.Insert(ctx, plugins.InsertOptions{Collection: "credentials", Documents: []bson.M{{"namespace": "dev", "name": "mysql"}}})
+	require.NoError(t, err)
+}
+
+func TestStore_Update(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	filter := bson.M{"namespace": "dev", "name": "mysql"}
+	matched, err := s.Update(ctx, plugins.UpdateOptions{Collection: "installations", Filter: filter, Document: bson.M{"namespace": "dev", "name": "mysql", "uninstalled": false}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), matched)
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: "installations"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count, "Update without Upsert should not insert a document")
+
+	matched, err = s.Update(ctx, plugins.UpdateOptions{Collection: "installations", Filter: filter, Upsert: true, Document: bson.M{"namespace": "dev", "name": "mysql", "uninstalled": false}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), matched, "an upserted document should not be counted as matched")
+
+	results, err := s.Find(ctx, plugins.FindOptions{Collection: "installations", Filter: filter})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	originalID := results[0].Lookup("_id").StringValue()
+
+	matched, err = s.Update(ctx, plugins.UpdateOptions{Collection: "installations", Filter: filter, Upsert: true, Document: bson.M{"namespace": "dev", "name": "mysql", "uninstalled": true}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), matched)
+
+	results, err = s.Find(ctx, plugins.FindOptions{Collection: "installations", Filter: filter})
+	require.NoError(t, err)
+	require.Len(t, results, 1, "the existing document should have been replaced")
+	assert.Equal(t, originalID, results[0].Lookup("_id").StringValue(), "the _id should be preserved when a document is replaced")
+	assert.True(t, results[0].Lookup("uninstalled").Boolean())
+}
+
+func TestStore_Patch(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: "runs", Documents: []bson.M{
+		{"_id": "1", "installation": "mysql", "action": "install"},
+		{"_id": "2", "installation": "mysql", "action": "upgrade"},
+	}})
+	require.NoError(t, err)
+
+	err = s.Patch(ctx, plugins.PatchOptions{
+		Collection:     "runs",
+		QueryDocument:  bson.M{"installation": "mysql"},
+		Transformation: bson.D{{Key: "$set", Value: bson.M{"action": "uninstall"}}},
+	})
+	require.NoError(t, err)
+
+	results, err := s.Find(ctx, plugins.FindOptions{Collection: "runs", Filter: bson.M{"action": "uninstall"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1, "only the first matching document should be patched")
+	assert.Equal(t, "1", results[0].Lookup("_id").StringValue())
+}
+
+func TestStore_Remove(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+
+	err := s.Insert(ctx, plugins.InsertOptions{Collection: "outputs", Documents: []bson.M{
+		{"installation": "mysql", "name": "a"},
+		{"installation": "mysql", "name": "b"},
+		{"installation": "wordpress", "name": "a"},
+	}})
+	require.NoError(t, err)
+
+	err = s.Remove(ctx, plugins.RemoveOptions{Collection: "outputs", Filter: bson.M{"installation": "mysql"}})
+	require.NoError(t, err)
+	count, err := s.Count(ctx, plugins.CountOptions{Collection: "outputs"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count, "only the first matching document should be removed")
+
+	err = s.Remove(ctx, plugins.RemoveOptions{Collection: "outputs", Filter: bson.M{"name": "a"}, All: true})
+	require.NoError(t, err)
+	results, err := s.Find(ctx, plugins.FindOptions{Collection: "outputs"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, unmarshalNames(t, results))
+}
+
+func TestStore_InstallationStore(t *testing.T) {
+	// Validate that the plugin supports the queries made by porter's stores
+	ctx := context.Background()
+	s := newTestStore(t)
+	store := storage.NewPluginAdapter(s)
+	require.NoError(t, storage.EnsureInstallationIndices(ctx, store))
+	installations := storage.NewInstallationStore(store)
+
+	inst := storage.NewInstallation("dev", "mysql")
+	require.NoError(t, installations.InsertInstallation(ctx, inst))
+	require.NoError(t, installations.InsertInstallation(ctx, storage.NewInstallation("", "wordpress")))
+
+	err := installations.InsertInstallation(ctx, storage.NewInstallation("dev", "mysql"))
+	require.Error(t, err, "installations should be unique by namespace and name")
+
+	list, err := installations.ListInstallations(ctx, storage.ListOptions{Namespace: "*"})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "wordpress", list[0].Name, "installations should be sorted by namespace and then name")
+
+	got, err := installations.GetInstallation(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, inst.ID, got.ID)
+
+	_, err = installations.GetInstallation(ctx, "dev", "missing")
+	require.ErrorIs(t, err, storage.ErrNotFound{})
+
+	run := inst.NewRun("install", cnab.ExtendedBundle{})
+	require.NoError(t, installations.InsertRun(ctx, run))
+	result := run.NewResult("succeeded")
+	require.NoError(t, installations.InsertResult(ctx, result))
+	require.NoError(t, installations.InsertOutput(ctx, result.NewOutput("connstr", []byte("first"))))
+
+	run2 := inst.NewRun("upgrade", cnab.ExtendedBundle{})
+	require.NoError(t, installations.InsertRun(ctx, run2))
+	result2 := run2.NewResult("succeeded")
+	require.NoError(t, installations.InsertResult(ctx, result2))
+	require.NoError(t, installations.InsertOutput(ctx, result2.NewOutput("connstr", []byte("second"))))
+	require.NoError(t, installations.InsertOutput(ctx, result2.NewOutput("port", []byte("3306"))))
+
+	lastRun, err := installations.GetLastRun(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, run2.ID, lastRun.ID)
+
+	outputs, err := installations.GetLastOutputs(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	require.Equal(t, 2, outputs.Len())
+	connstr, ok := outputs.GetByName("connstr")
+	require.True(t, ok)
+	assert.Equal(t, "second", string(connstr.Value), "the most recent output value should be returned")
+
+	require.NoError(t, installations.RemoveInstallation(ctx, "dev", "mysql"))
+	runs, _, err := installations.ListRuns(ctx, "dev", "mysql")
+	require.NoError(t, err)
+	assert.Empty(t, runs, "the installation's runs should be removed with it")
+}
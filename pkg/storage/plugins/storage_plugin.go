@@ -7,5 +7,7 @@ const (
 	PluginInterface = "storage"
 
 	// PluginProtocolVersion is the currently supported plugin protocol version for storage.
-	PluginProtocolVersion = 3
+	// Version 4 requires Update to return the number of documents matched by the filter,
+	// which Porter uses to detect conflicting changes to an installation.
+	PluginProtocolVersion = 4
 )
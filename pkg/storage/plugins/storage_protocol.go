@@ -33,8 +33,10 @@ type StorageProtocol interface {
 	// Remove matching documents from a collection.
 	Remove(ctx context.Context, opts RemoveOptions) error
 
-	// Update matching documents with the specified replacement document.
-	Update(ctx context.Context, opts UpdateOptions) error
+	// Update matching documents with the specified replacement document,
+	// returning the number of documents that matched the filter. A document
+	// inserted by an upsert is not counted.
+	Update(ctx context.Context, opts UpdateOptions) (int64, error)
 }
 
 // EnsureIndexOptions is the set of options available to the
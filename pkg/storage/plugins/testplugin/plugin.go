@@ -139,9 +139,9 @@ func (s *TestStoragePlugin) Remove(ctx context.Context, opts plugins.RemoveOptio
 	return s.store.Remove(ctx, opts)
 }
 
-func (s *TestStoragePlugin) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+func (s *TestStoragePlugin) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
 	if err := s.Connect(ctx); err != nil {
-		return err
+		return 0, err
 	}
 	return s.store.Update(ctx, opts)
 }
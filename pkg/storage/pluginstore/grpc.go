@@ -110,15 +110,18 @@ func (m *GClient) Remove(ctx context.Context, opts plugins.RemoveOptions) error
 	return err
 }
 
-func (m *GClient) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+func (m *GClient) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
 	req := &proto.UpdateRequest{
 		Collection: opts.Collection,
 		Filter:     FromMap(opts.Filter),
 		Upsert:     opts.Upsert,
 		Document:   FromMap(opts.Document),
 	}
-	_, err := m.client.Update(ctx, req)
-	return err
+	resp, err := m.client.Update(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return resp.MatchedCount, nil
 }
 
 // GServer is a gRPC wrapper around a StorageProtocol plugin
@@ -231,8 +234,8 @@ func (m *GServer) Update(ctx context.Context, request *proto.UpdateRequest) (*pr
 		Document:   AsMap(request.Document),
 	}
 
-	err := m.impl.Update(ctx, opts)
-	return &proto.UpdateResponse{}, err
+	matched, err := m.impl.Update(ctx, opts)
+	return &proto.UpdateResponse{MatchedCount: matched}, err
 }
 
 func NewPipeline(src []bson.D) []*proto.Stage {
@@ -190,15 +190,19 @@ func (s *Store) Remove(ctx context.Context, opts plugins.RemoveOptions) error {
 	return span.Error(err)
 }
 
-func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) error {
+func (s *Store) Update(ctx context.Context, opts plugins.UpdateOptions) (int64, error) {
 	ctx, span := tracing.StartSpan(ctx,
 		tracing.ObjectAttribute("options", opts))
 	defer span.EndSpan()
 
 	if err := s.Connect(ctx); err != nil {
-		return err
+		return 0, err
 	}
 
-	err := s.plugin.Update(ctx, opts)
-	return span.Error(err)
+	matched, err := s.plugin.Update(ctx, opts)
+	if err != nil {
+		return 0, span.Error(err)
+	}
+	span.SetAttributes(attribute.Int64("matched", matched))
+	return matched, nil
 }
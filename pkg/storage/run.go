@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -43,6 +45,14 @@ type Run struct {
 	// Action executed against the installation.
 	Action string `json:"action"`
 
+	// RollbackTo is the ID of the previous run whose bundle and inputs were used
+	// to roll back the installation. Empty when the run was not a rollback.
+	RollbackTo string `json:"rollbackTo,omitempty"`
+
+	// Audit records who started the run and from where.
+	// This is a status/audit field and is not used when executing the bundle.
+	Audit RunAudit `json:"audit"`
+
 	// Bundle is the definition of the bundle.
 	// Bundle has custom marshal logic in MarshalJson.
 	Bundle bundle.Bundle `json:"-"`
@@ -97,6 +107,12 @@ type Run struct {
 	// are re-resolved. The value should contain the hash type, e.g. sha256:abc123...
 	// This is a status/audit field and is not used to resolve credentials for a Run.
 	CredentialsDigest string `json:"credentialsDigest,omitempty"`
+
+	// CredentialDigests is the digest of the resolved value of each credential, keyed by
+	// the credential name, which allows us to determine which credentials changed
+	// between runs, such as when a secret is rotated, without storing their values.
+	// This is a status/audit field and is not used to resolve credentials for a Run.
+	CredentialDigests map[string]string `json:"credentialDigests,omitempty"`
 }
 
 // rawRun is an alias for Run that does not have a json marshal functions defined,
@@ -141,6 +157,12 @@ func (r Run) DefaultDocumentFilter() map[string]interface{} {
 	return map[string]interface{}{"_id": r.ID}
 }
 
+// SecretOwner identifies the run as the owner of the secrets that Porter saves
+// for its sensitive parameters and outputs.
+func (r Run) SecretOwner() SecretOwner {
+	return SecretOwner{Kind: SecretOwnerRun, ID: r.ID}
+}
+
 // NewRun creates a run with default values initialized.
 func NewRun(namespace string, installation string) Run {
 	return Run{
@@ -242,20 +264,46 @@ func (r *Run) SetParametersDigest() error {
 }
 
 // SetCredentialsDigest records the hash of the resolved credentials, so we can
-// quickly tell if the parameters between runs were different without
-// re-resolving them.
-func (r *Run) SetCredentialsDigest() error {
-	// Calculate a hash of the resolved credentials
-	credB, err := json.Marshal(r.Credentials.Credentials)
+// quickly tell if the credentials between runs were different without
+// re-resolving them. The key is the installation's credentials digest key,
+// see Sanitizer.CredentialsDigestKey.
+func (r *Run) SetCredentialsDigest(key []byte) error {
+	digests, digest, err := DigestCredentials(key, r.Credentials)
 	if err != nil {
 		r.CredentialsDigest = ""
-		return fmt.Errorf("error calculating the digest of the run credentials: %w", err)
+		r.CredentialDigests = nil
+		return err
 	}
 
-	r.CredentialsDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(credB))
+	r.CredentialsDigest = digest
+	r.CredentialDigests = digests
 	return nil
 }
 
+// DigestCredentials calculates the digest of the resolved value of each
+// credential in a set, and the digest of the entire set. The values are hashed
+// with a secret key, so that the digests cannot be used to guess the value of
+// a credential.
+func DigestCredentials(key []byte, creds CredentialSet) (map[string]string, string, error) {
+	if len(key) == 0 {
+		return nil, "", errors.New("a key is required to calculate the digest of the run credentials")
+	}
+
+	digests := make(map[string]string, len(creds.Credentials))
+	for _, cred := range creds.Credentials {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(cred.ResolvedValue))
+		digests[cred.Name] = fmt.Sprintf("hmac-sha256:%x", h.Sum(nil))
+	}
+
+	// Calculate a hash of the digests of the resolved credentials, which are sorted by name when marshaled
+	credB, err := json.Marshal(digests)
+	if err != nil {
+		return nil, "", fmt.Errorf("error calculating the digest of the run credentials: %w", err)
+	}
+	return digests, fmt.Sprintf("sha256:%x", sha256.Sum256(credB)), nil
+}
+
 // NewRun creates a result for the current Run.
 func (r Run) NewResult(status string) Result {
 	result := NewResult()
@@ -0,0 +1,46 @@
+package storage
+
+const (
+	// RunClientCLI indicates that the run was started with the porter CLI.
+	RunClientCLI = "cli"
+
+	// RunClientGRPC indicates that the run was started through the porter gRPC server.
+	RunClientGRPC = "grpc"
+
+	// RunClientOperator indicates that the run was started by the Porter Operator.
+	RunClientOperator = "operator"
+)
+
+// RunAudit identifies who started a run and from where.
+type RunAudit struct {
+	// User that started the run. When the run was requested through the gRPC
+	// server, this is the authenticated caller.
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+
+	// Host from which the run was started.
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+
+	// PorterVersion is the version of porter that executed the run.
+	PorterVersion string `json:"porterVersion,omitempty" yaml:"porterVersion,omitempty"`
+
+	// Client used to start the run, for example cli, grpc or operator.
+	Client string `json:"client,omitempty" yaml:"client,omitempty"`
+}
+
+// Matches determines if the audit record matches the filter. Empty fields on
+// the filter match any value.
+func (a RunAudit) Matches(filter RunAudit) bool {
+	if filter.User != "" && filter.User != a.User {
+		return false
+	}
+	if filter.Host != "" && filter.Host != a.Host {
+		return false
+	}
+	if filter.PorterVersion != "" && filter.PorterVersion != a.PorterVersion {
+		return false
+	}
+	if filter.Client != "" && filter.Client != a.Client {
+		return false
+	}
+	return true
+}
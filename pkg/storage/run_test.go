@@ -210,3 +210,46 @@ func TestRun_MarshalJSON(t *testing.T) {
 
 	assert.Equal(t, r1, r2, "The run did not survive the round trip")
 }
+
+func TestDigestCredentials(t *testing.T) {
+	creds := func(value string) CredentialSet {
+		return NewInternalCredentialSet(
+			secrets.SourceMap{Name: "password", Source: secrets.Source{Strategy: secrets.SourceSecret, Hint: "password"}, ResolvedValue: value},
+			secrets.SourceMap{Name: "username", Source: secrets.Source{Strategy: "value", Hint: "admin"}, ResolvedValue: "admin"},
+		)
+	}
+
+	key := []byte("mysql-digest-key")
+	digests, digest, err := DigestCredentials(key, creds("oldpassword"))
+	require.NoError(t, err)
+	require.Len(t, digests, 2)
+	assert.NotContains(t, digests["password"], "oldpassword", "the resolved value should not be stored in the digest")
+	assert.Contains(t, digests["password"], "hmac-sha256:")
+	assert.Contains(t, digest, "sha256:")
+
+	t.Run("same value", func(t *testing.T) {
+		gotDigests, gotDigest, err := DigestCredentials(key, creds("oldpassword"))
+		require.NoError(t, err)
+		assert.Equal(t, digests, gotDigests)
+		assert.Equal(t, digest, gotDigest)
+	})
+
+	t.Run("rotated value", func(t *testing.T) {
+		gotDigests, gotDigest, err := DigestCredentials(key, creds("newpassword"))
+		require.NoError(t, err)
+		assert.NotEqual(t, digests["password"], gotDigests["password"])
+		assert.Equal(t, digests["username"], gotDigests["username"])
+		assert.NotEqual(t, digest, gotDigest)
+	})
+
+	t.Run("different key", func(t *testing.T) {
+		gotDigests, _, err := DigestCredentials([]byte("wordpress-digest-key"), creds("oldpassword"))
+		require.NoError(t, err)
+		assert.NotEqual(t, digests["password"], gotDigests["password"], "the same value should have a different digest for each key")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, _, err := DigestCredentials(nil, creds("oldpassword"))
+		require.EqualError(t, err, "a key is required to calculate the digest of the run credentials")
+	})
+}
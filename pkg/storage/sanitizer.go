@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 
 	"get.porter.sh/porter/pkg/cnab"
@@ -14,22 +16,81 @@ import (
 type Sanitizer struct {
 	parameter ParameterSetProvider
 	secrets   secrets.Store
+	records   *SecretRecordStore
 }
 
 // NewSanitizer creates a new service for sanitizing sensitive data and save them
-// to a secret store.
+// to a secret store. The secrets that it creates are recorded in the same
+// database as the parameter sets.
 func NewSanitizer(parameterstore ParameterSetProvider, secretstore secrets.Store) *Sanitizer {
 	return &Sanitizer{
 		parameter: parameterstore,
 		secrets:   secretstore,
+		records:   NewSecretRecordStore(parameterstore.GetDataStore()),
 	}
 }
 
+// createSecret saves a sensitive value in the secret store, and records the
+// owner of the secret so that it can be removed once the owner is deleted.
+func (s *Sanitizer) createSecret(ctx context.Context, key string, value string, owner SecretOwner) error {
+	if err := s.secrets.Create(ctx, secrets.SourceSecret, key, value); err != nil {
+		return err
+	}
+	if err := s.records.RecordSecret(ctx, key, owner); err != nil {
+		return fmt.Errorf("failed to record the owner of secret %s: %w", key, err)
+	}
+	return nil
+}
+
+// CredentialsDigestKeyName returns the key of the secret that holds the key
+// used to calculate the digest of the credentials of an installation.
+func CredentialsDigestKeyName(installationID string) string {
+	return installationID + "-credentials-digest-key"
+}
+
+// CredentialsDigestKey returns the key used to calculate the digest of the
+// resolved credentials of an installation. The key is a random value that is
+// saved in the secret store the first time that it is needed, so that the
+// digests saved on the runs cannot be used to guess the value of a credential
+// without access to the secret store. When create is false, and the key was
+// not created yet, nil is returned.
+func (s *Sanitizer) CredentialsDigestKey(ctx context.Context, inst Installation, create bool) ([]byte, error) {
+	keyName := CredentialsDigestKeyName(inst.ID)
+	exists, err := s.records.HasSecretRecord(ctx, keyName)
+	if err != nil {
+		return nil, fmt.Errorf("could not check for the credentials digest key of installation %s: %w", inst, err)
+	}
+
+	if exists {
+		value, err := s.secrets.Resolve(ctx, secrets.SourceSecret, keyName)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the credentials digest key of installation %s: %w", inst, err)
+		}
+		key, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials digest key for installation %s: %w", inst, err)
+		}
+		return key, nil
+	}
+
+	if !create {
+		return nil, nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("could not generate the credentials digest key of installation %s: %w", inst, err)
+	}
+	if err = s.createSecret(ctx, keyName, hex.EncodeToString(key), inst.SecretOwner()); err != nil {
+		return nil, fmt.Errorf("could not save the credentials digest key of installation %s: %w", inst, err)
+	}
+	return key, nil
+}
+
 // CleanRawParameters clears out sensitive data in raw parameter values (resolved parameter values stored on a Run) before
 // transform the raw value into secret strategies.
-// The id argument is used to associate the reference key with the corresponding
+// The owner argument is used to associate the reference key with the corresponding
 // run or installation record in porter's database.
-func (s *Sanitizer) CleanRawParameters(ctx context.Context, params map[string]interface{}, bun cnab.ExtendedBundle, id string) ([]secrets.SourceMap, error) {
+func (s *Sanitizer) CleanRawParameters(ctx context.Context, params map[string]interface{}, bun cnab.ExtendedBundle, owner SecretOwner) ([]secrets.SourceMap, error) {
 	strategies := make([]secrets.SourceMap, 0, len(params))
 	for name, value := range params {
 		stringVal, err := bun.WriteParameterToString(name, value)
@@ -40,7 +101,7 @@ func (s *Sanitizer) CleanRawParameters(ctx context.Context, params map[string]in
 		strategies = append(strategies, strategy)
 	}
 
-	strategies, err := s.CleanParameters(ctx, strategies, bun, id)
+	strategies, err := s.CleanParameters(ctx, strategies, bun, owner)
 	if err != nil {
 		return nil, err
 	}
@@ -51,15 +112,15 @@ func (s *Sanitizer) CleanRawParameters(ctx context.Context, params map[string]in
 
 // CleanParameters clears out sensitive data in strategized parameter data (overrides provided by the user on an Installation record) and return
 // Sanitized value after saving sensitive data to secrets store.
-// The id argument is used to associate the reference key with the corresponding
+// The owner argument is used to associate the reference key with the corresponding
 // run or installation record in porter's database.
-func (s *Sanitizer) CleanParameters(ctx context.Context, dirtyParams []secrets.SourceMap, bun cnab.ExtendedBundle, id string) ([]secrets.SourceMap, error) {
+func (s *Sanitizer) CleanParameters(ctx context.Context, dirtyParams []secrets.SourceMap, bun cnab.ExtendedBundle, owner SecretOwner) ([]secrets.SourceMap, error) {
 	cleanedParams := make([]secrets.SourceMap, 0, len(dirtyParams))
 	for _, param := range dirtyParams {
 		// Store sensitive hard-coded values in a secret store
 		if param.Source.Strategy == host.SourceValue && bun.IsSensitiveParameter(param.Name) {
-			cleaned := sanitizedParam(param, id)
-			err := s.secrets.Create(ctx, cleaned.Source.Strategy, cleaned.Source.Hint, cleaned.ResolvedValue)
+			cleaned := sanitizedParam(param, owner.ID)
+			err := s.createSecret(ctx, cleaned.Source.Hint, cleaned.ResolvedValue, owner)
 			if err != nil {
 				return nil, fmt.Errorf("failed to save sensitive param to secrete store: %w", err)
 			}
@@ -78,6 +139,28 @@ func (s *Sanitizer) CleanParameters(ctx context.Context, dirtyParams []secrets.S
 
 }
 
+// CleanValueSources stores every hard-coded value in a secret store and replaces
+// it with a reference to the secret, regardless of whether the bundle defines it
+// as sensitive. It is used for documents that were encrypted by the user.
+// The owner argument is used to associate the reference key with the corresponding
+// record in porter's database.
+func (s *Sanitizer) CleanValueSources(ctx context.Context, sources []secrets.SourceMap, owner SecretOwner) ([]secrets.SourceMap, error) {
+	cleaned := make([]secrets.SourceMap, len(sources))
+	for i, source := range sources {
+		cleaned[i] = source
+		if source.Source.Strategy != host.SourceValue {
+			continue
+		}
+
+		cleaned[i] = sanitizedParam(source, owner.ID)
+		err := s.createSecret(ctx, cleaned[i].Source.Hint, source.Source.Hint, owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save the value of %s to the secret store: %w", source.Name, err)
+		}
+	}
+	return cleaned, nil
+}
+
 // LinkSensitiveParametersToSecrets creates a reference key for sensitive data
 // and replace the sensitive value with the reference key.
 // The id argument is used to associate the reference key with the corresponding
@@ -93,6 +176,26 @@ func LinkSensitiveParametersToSecrets(pset ParameterSet, bun cnab.ExtendedBundle
 	return pset
 }
 
+// SensitiveParameterKeys returns the keys of the secrets that were created by
+// the Sanitizer for the sensitive values in a parameter set.
+// The id argument is the run or installation record that owns the parameter set.
+func SensitiveParameterKeys(pset ParameterSet, id string) []string {
+	return SanitizedSourceKeys(pset.Parameters, id)
+}
+
+// SanitizedSourceKeys returns the keys of the secrets that were created by the
+// Sanitizer for a list of sources.
+// The id argument is the record that owns the sources.
+func SanitizedSourceKeys(sources []secrets.SourceMap, id string) []string {
+	var keys []string
+	for _, source := range sources {
+		if source.Source.Strategy == secrets.SourceSecret && source.Source.Hint == sanitizedParam(source, id).Source.Hint {
+			keys = append(keys, source.Source.Hint)
+		}
+	}
+	return keys
+}
+
 func sanitizedParam(param secrets.SourceMap, id string) secrets.SourceMap {
 	param.Source.Strategy = secrets.SourceSecret
 	param.Source.Hint = id + "-" + param.Name
@@ -143,7 +246,7 @@ func (s *Sanitizer) CleanOutput(ctx context.Context, output Output, bun cnab.Ext
 
 	secretOt := sanitizedOutput(output)
 
-	err = s.secrets.Create(ctx, secrets.SourceSecret, secretOt.Key, string(output.Value))
+	err = s.createSecret(ctx, secretOt.Key, string(output.Value), SecretOwner{Kind: SecretOwnerRun, ID: output.RunID})
 	if err != nil {
 		return secretOt, err
 	}
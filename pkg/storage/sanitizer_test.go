@@ -41,7 +41,7 @@ func TestSanitizer_Parameters(t *testing.T) {
 		"my-first-param":   1,
 		sensitiveParamName: "2",
 	}
-	result, err := r.TestSanitizer.CleanRawParameters(ctx, rawParams, bun, recordID)
+	result, err := r.TestSanitizer.CleanRawParameters(ctx, rawParams, bun, storage.SecretOwner{Kind: storage.SecretOwnerRun, ID: recordID})
 	require.NoError(t, err)
 	require.Equal(t, len(expected), len(result))
 	sort.SliceStable(result, func(i, j int) bool {
@@ -110,7 +110,7 @@ func TestSanitizer_CleanParameters(t *testing.T) {
 			inst.Parameters.Parameters = []secrets.SourceMap{
 				{Name: tc.paramName, Source: secrets.Source{Strategy: tc.sourceKey, Hint: "myvalue"}},
 			}
-			gotParams, err := r.Sanitizer.CleanParameters(ctx, inst.Parameters.Parameters, bun, inst.ID)
+			gotParams, err := r.Sanitizer.CleanParameters(ctx, inst.Parameters.Parameters, bun, inst.SecretOwner())
 			require.NoError(t, err, "CleanParameters failed")
 
 			wantParms := []secrets.SourceMap{{Name: tc.paramName, Source: tc.wantSource}}
@@ -170,3 +170,35 @@ func TestSanitizer_Output(t *testing.T) {
 	require.Truef(t, reflect.DeepEqual(expectedOutputs, resolved), "expected outputs: %v, got outputs: %v", expectedOutputs, resolved)
 
 }
+
+func TestSanitizer_CredentialsDigestKey(t *testing.T) {
+	ctx := context.Background()
+	r := porter.NewTestPorter(t)
+	defer r.Close()
+
+	inst := storage.NewInstallation("dev", "mybuns")
+
+	key, err := r.TestSanitizer.CredentialsDigestKey(ctx, inst, false)
+	require.NoError(t, err)
+	require.Nil(t, key, "the key should not be created unless requested")
+
+	key, err = r.TestSanitizer.CredentialsDigestKey(ctx, inst, true)
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+
+	gotKey, err := r.TestSanitizer.CredentialsDigestKey(ctx, inst, false)
+	require.NoError(t, err)
+	require.Equal(t, key, gotKey, "the key saved in the secret store should be reused")
+
+	otherKey, err := r.TestSanitizer.CredentialsDigestKey(ctx, storage.NewInstallation("dev", "wordpress"), true)
+	require.NoError(t, err)
+	require.NotEqual(t, key, otherKey, "each installation should have a different key")
+
+	owners := map[string]storage.SecretOwner{}
+	records, err := r.SecretRecords.ListSecretRecords(ctx)
+	require.NoError(t, err)
+	for _, record := range records {
+		owners[record.Key] = record.Owner
+	}
+	require.Equal(t, inst.SecretOwner(), owners[storage.CredentialsDigestKeyName(inst.ID)], "the key should be owned by the installation")
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"time"
+)
+
+const (
+	// SecretOwnerInstallation is the kind of owner for the secrets created for the parameters of an installation.
+	SecretOwnerInstallation = "installation"
+
+	// SecretOwnerRun is the kind of owner for the secrets created for the parameters and outputs of a run.
+	SecretOwnerRun = "run"
+
+	// SecretOwnerCredentialSet is the kind of owner for the secrets created for the values in a credential set.
+	SecretOwnerCredentialSet = "credentialset"
+
+	// SecretOwnerParameterSet is the kind of owner for the secrets created for the values in a parameter set.
+	SecretOwnerParameterSet = "parameterset"
+)
+
+// SecretOwner identifies the record in Porter's database that a secret created
+// by Porter belongs to.
+type SecretOwner struct {
+	// Kind of record that owns the secret, for example installation or run.
+	Kind string `json:"kind"`
+
+	// ID of the record that owns the secret. The keys of the secrets created
+	// for the record are prefixed with the ID.
+	ID string `json:"id"`
+}
+
+var _ Document = SecretRecord{}
+
+// SecretRecord records a secret that Porter created in the secret store for a
+// sensitive value, so that the secret can be removed once its owner is deleted.
+// Secrets without a record were not created by Porter and are never removed by it.
+type SecretRecord struct {
+	// Key of the secret in the secret store.
+	Key string `json:"_id"`
+
+	// Owner of the secret.
+	Owner SecretOwner `json:"owner"`
+
+	// Created is when the secret was saved by Porter.
+	Created time.Time `json:"created"`
+}
+
+// NewSecretRecord records that Porter created a secret for the specified owner.
+func NewSecretRecord(key string, owner SecretOwner) SecretRecord {
+	return SecretRecord{
+		Key:     key,
+		Owner:   owner,
+		Created: time.Now(),
+	}
+}
+
+func (r SecretRecord) DefaultDocumentFilter() map[string]interface{} {
+	return map[string]interface{}{"_id": r.Key}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// CollectionSecrets is the collection that records the secrets that Porter
+	// created in the secret store, and which record owns each of them.
+	CollectionSecrets = "secrets"
+)
+
+// SecretRecordStore records the secrets that Porter creates in the secret store.
+type SecretRecordStore struct {
+	Documents Store
+}
+
+func NewSecretRecordStore(store Store) *SecretRecordStore {
+	return &SecretRecordStore{Documents: store}
+}
+
+// RecordSecret records that Porter created the secret with the specified key
+// for an owner, replacing any previous record of the secret.
+func (s SecretRecordStore) RecordSecret(ctx context.Context, key string, owner SecretOwner) error {
+	opts := UpdateOptions{
+		Document: NewSecretRecord(key, owner),
+		Upsert:   true,
+	}
+	_, err := s.Documents.Update(ctx, CollectionSecrets, opts)
+	return err
+}
+
+// ListSecretRecords returns the records of every secret that Porter created.
+func (s SecretRecordStore) ListSecretRecords(ctx context.Context) ([]SecretRecord, error) {
+	var out []SecretRecord
+	opts := FindOptions{Sort: []string{"_id"}}
+	err := s.Documents.Find(ctx, CollectionSecrets, opts, &out)
+	return out, err
+}
+
+// HasSecretRecord checks if Porter recorded that it created the secret with the specified key.
+func (s SecretRecordStore) HasSecretRecord(ctx context.Context, key string) (bool, error) {
+	var out SecretRecord
+	opts := FindOptions{Filter: map[string]interface{}{"_id": key}}
+	err := s.Documents.FindOne(ctx, CollectionSecrets, opts, &out)
+	if errors.Is(err, ErrNotFound{}) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RemoveSecretRecord removes the record of a secret after it was removed from
+// the secret store. A secret that was not recorded is ignored.
+func (s SecretRecordStore) RemoveSecretRecord(ctx context.Context, key string) error {
+	err := s.Documents.Remove(ctx, CollectionSecrets, RemoveOptions{ID: key})
+	if errors.Is(err, ErrNotFound{}) {
+		return nil
+	}
+	return err
+}
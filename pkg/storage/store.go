@@ -43,6 +43,8 @@ type Store interface {
 	// Remove matching documents from a collection.
 	Remove(ctx context.Context, collection string, opts RemoveOptions) error
 
-	// Update matching documents with the specified replacement document.
-	Update(ctx context.Context, collection string, opts UpdateOptions) error
+	// Update matching documents with the specified replacement document,
+	// returning the number of documents that matched the filter. A document
+	// inserted by an upsert is not counted.
+	Update(ctx context.Context, collection string, opts UpdateOptions) (int64, error)
 }
@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/hashicorp/go-multierror"
+)
+
+// templateReference matches a reference to another source in the hint of a
+// template source, ${STRATEGY.HINT}, for example ${secret.db-password}.
+var templateReference = regexp.MustCompile(`\$\{\s*([a-z]+)\.([^}]*?)\s*\}`)
+
+// templateStrategies are the strategies that a template may reference directly.
+var templateStrategies = []string{secrets.SourceSecret, host.SourceEnv, host.SourcePath, host.SourceCommand}
+
+// resolveFunc resolves the value of a source that is not a template.
+type resolveFunc func(ctx context.Context, strategy string, hint string) (string, error)
+
+// sourceReference is a reference to another source in a template.
+type sourceReference struct {
+	// match is the text of the reference in the template, e.g. ${env.USER}
+	match string
+
+	// Strategy of the referenced source, or the collection name, e.g.
+	// parameters, when the reference is to another entry in the same set.
+	Strategy string
+
+	// Hint of the referenced source, or the name of the entry in the same set.
+	Hint string
+}
+
+// parseTemplate returns the references to other sources in the hint of a template source.
+func parseTemplate(hint string) []sourceReference {
+	matches := templateReference.FindAllStringSubmatch(hint, -1)
+	refs := make([]sourceReference, len(matches))
+	for i, match := range matches {
+		refs[i] = sourceReference{match: match[0], Strategy: match[1], Hint: match[2]}
+	}
+	return refs
+}
+
+// templateResolver resolves the sources in a credential or parameter set,
+// substituting the references in template sources with the value of the
+// referenced source.
+type templateResolver struct {
+	// collection is how entries in the same set are referenced, credentials or parameters.
+	collection string
+
+	sources []secrets.SourceMap
+	resolve resolveFunc
+
+	// resolved values of the entries in the set, cached so that each source is only resolved once.
+	resolved map[string]string
+
+	// resolving is the chain of entries that are being resolved, used to detect cycles.
+	resolving []string
+}
+
+func newTemplateResolver(collection string, sources []secrets.SourceMap, resolve resolveFunc) *templateResolver {
+	return &templateResolver{
+		collection: collection,
+		sources:    sources,
+		resolve:    resolve,
+		resolved:   make(map[string]string),
+	}
+}
+
+// Resolve the value of an entry in the set.
+func (r *templateResolver) Resolve(ctx context.Context, source secrets.SourceMap) (string, error) {
+	if value, ok := r.resolved[source.Name]; ok {
+		return value, nil
+	}
+
+	if source.Source.Strategy != secrets.SourceTemplate {
+		value, err := r.resolve(ctx, source.Source.Strategy, source.Source.Hint)
+		if err != nil {
+			return "", err
+		}
+		r.resolved[source.Name] = value
+		return value, nil
+	}
+
+	for i, name := range r.resolving {
+		if name == source.Name {
+			return "", fmt.Errorf("cycle detected in template: %s", strings.Join(append(r.resolving[i:], name), " -> "))
+		}
+	}
+	r.resolving = append(r.resolving, source.Name)
+	defer func() { r.resolving = r.resolving[:len(r.resolving)-1] }()
+
+	var resolveErr error
+	value := templateReference.ReplaceAllStringFunc(source.Source.Hint, func(match string) string {
+		if resolveErr != nil {
+			return ""
+		}
+		ref := parseTemplate(match)[0]
+
+		var refValue string
+		var err error
+		if ref.Strategy == r.collection {
+			entry, ok := findSource(r.sources, ref.Hint)
+			if !ok {
+				err = fmt.Errorf("%s.%s is not defined in the set", ref.Strategy, ref.Hint)
+			} else {
+				refValue, err = r.Resolve(ctx, entry)
+			}
+		} else if isTemplateStrategy(ref.Strategy) {
+			refValue, err = r.resolve(ctx, ref.Strategy, ref.Hint)
+		} else {
+			err = fmt.Errorf("%s is not a valid source for a template", ref.Strategy)
+		}
+		if err != nil {
+			resolveErr = fmt.Errorf("could not resolve %s: %w", ref.match, err)
+		}
+		return refValue
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	r.resolved[source.Name] = value
+	return value, nil
+}
+
+// validateTemplateSources checks that the template sources in a set only
+// reference valid sources, entries that are defined in the set, and do not
+// reference themselves.
+func validateTemplateSources(collection string, sources []secrets.SourceMap) error {
+	var errs error
+	for _, source := range sources {
+		if source.Source.Strategy != secrets.SourceTemplate {
+			continue
+		}
+
+		refs := parseTemplate(source.Source.Hint)
+		if len(refs) == 0 {
+			errs = multierror.Append(errs, fmt.Errorf("the template for %s does not reference any sources, use the value source instead", source.Name))
+		}
+		for _, ref := range refs {
+			if ref.Strategy == collection {
+				if _, ok := findSource(sources, ref.Hint); !ok {
+					errs = multierror.Append(errs, fmt.Errorf("the template for %s references %s.%s which is not defined in the set", source.Name, ref.Strategy, ref.Hint))
+				}
+			} else if !isTemplateStrategy(ref.Strategy) {
+				errs = multierror.Append(errs, fmt.Errorf("the template for %s references %s, which is not a valid source. Valid sources are: %s",
+					source.Name, ref.match, strings.Join(append(templateStrategies, collection), ", ")))
+			}
+		}
+
+		if cycle := findTemplateCycle(collection, sources, source.Name, nil); cycle != nil {
+			errs = multierror.Append(errs, fmt.Errorf("cycle detected in template: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+	return errs
+}
+
+// findTemplateCycle returns the chain of references that leads from the first
+// entry in the chain back to itself, or nil when there is no cycle.
+func findTemplateCycle(collection string, sources []secrets.SourceMap, name string, chain []string) []string {
+	if len(chain) > 0 && chain[0] == name {
+		return append(chain, name)
+	}
+	for _, visited := range chain {
+		if visited == name {
+			// A cycle that is reported for another entry
+			return nil
+		}
+	}
+	source, ok := findSource(sources, name)
+	if !ok || source.Source.Strategy != secrets.SourceTemplate {
+		return nil
+	}
+
+	chain = append(chain, name)
+	for _, ref := range parseTemplate(source.Source.Hint) {
+		if ref.Strategy != collection {
+			continue
+		}
+		if cycle := findTemplateCycle(collection, sources, ref.Hint, chain); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// InlineTemplateSources returns a copy of the sources in a set, where the
+// references in template sources to other entries in the set are replaced with
+// the source of the referenced entry, so that each entry can be resolved
+// without the rest of the set. A template that only references values is
+// replaced with a value source.
+func InlineTemplateSources(collection string, sources []secrets.SourceMap) ([]secrets.SourceMap, error) {
+	inlined := make([]secrets.SourceMap, len(sources))
+	for i, source := range sources {
+		inlined[i] = source
+		if source.Source.Strategy != secrets.SourceTemplate {
+			continue
+		}
+
+		hint, err := inlineTemplate(collection, sources, source, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(parseTemplate(hint)) == 0 {
+			inlined[i].Source = secrets.Source{Strategy: host.SourceValue, Hint: hint}
+		} else {
+			inlined[i].Source.Hint = hint
+		}
+	}
+	return inlined, nil
+}
+
+// inlineTemplate returns the hint of a template source with the references to
+// other entries in the set replaced with the source of the referenced entry.
+func inlineTemplate(collection string, sources []secrets.SourceMap, source secrets.SourceMap, chain []string) (string, error) {
+	for i, name := range chain {
+		if name == source.Name {
+			return "", fmt.Errorf("cycle detected in template: %s", strings.Join(append(chain[i:], name), " -> "))
+		}
+	}
+	chain = append(chain, source.Name)
+
+	var inlineErr error
+	hint := templateReference.ReplaceAllStringFunc(source.Source.Hint, func(match string) string {
+		if inlineErr != nil {
+			return ""
+		}
+		ref := parseTemplate(match)[0]
+		if ref.Strategy != collection {
+			return match
+		}
+
+		entry, ok := findSource(sources, ref.Hint)
+		if !ok {
+			inlineErr = fmt.Errorf("the template for %s references %s.%s which is not defined in the set", source.Name, ref.Strategy, ref.Hint)
+			return ""
+		}
+		switch {
+		case entry.Source.Strategy == secrets.SourceTemplate:
+			value, err := inlineTemplate(collection, sources, entry, chain)
+			if err != nil {
+				inlineErr = err
+			}
+			return value
+		case entry.Source.Strategy == host.SourceValue:
+			if templateReference.MatchString(entry.Source.Hint) {
+				inlineErr = fmt.Errorf("the template for %s references %s.%s, whose value cannot be used in a template because it contains ${...}", source.Name, ref.Strategy, ref.Hint)
+				return ""
+			}
+			return entry.Source.Hint
+		case isTemplateStrategy(entry.Source.Strategy):
+			return fmt.Sprintf("${%s.%s}", entry.Source.Strategy, entry.Source.Hint)
+		default:
+			inlineErr = fmt.Errorf("the template for %s references %s.%s, which uses the %s source that is not valid for a template", source.Name, ref.Strategy, ref.Hint, entry.Source.Strategy)
+			return ""
+		}
+	})
+	return hint, inlineErr
+}
+
+// sensitiveTemplateSources returns the names of the template sources in a set
+// that use a sensitive value, either a secret or another template that uses a secret.
+func sensitiveTemplateSources(collection string, sources []secrets.SourceMap) []string {
+	var names []string
+	for _, source := range sources {
+		if source.Source.Strategy == secrets.SourceTemplate && isSensitiveSource(collection, sources, source, nil) {
+			names = append(names, source.Name)
+		}
+	}
+	return names
+}
+
+func isSensitiveSource(collection string, sources []secrets.SourceMap, source secrets.SourceMap, visited []string) bool {
+	switch source.Source.Strategy {
+	case secrets.SourceSecret:
+		return true
+	case secrets.SourceTemplate:
+		for _, name := range visited {
+			if name == source.Name {
+				return false
+			}
+		}
+		visited = append(visited, source.Name)
+
+		for _, ref := range parseTemplate(source.Source.Hint) {
+			if ref.Strategy == secrets.SourceSecret {
+				return true
+			}
+			if ref.Strategy != collection {
+				continue
+			}
+			if entry, ok := findSource(sources, ref.Hint); ok && isSensitiveSource(collection, sources, entry, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isTemplateStrategy(strategy string) bool {
+	for _, s := range templateStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+func findSource(sources []secrets.SourceMap, name string) (secrets.SourceMap, bool) {
+	for _, source := range sources {
+		if source.Name == name {
+			return source, true
+		}
+	}
+	return secrets.SourceMap{}, false
+}
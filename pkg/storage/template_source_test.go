@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/secrets"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineTemplateSources(t *testing.T) {
+	source := func(name string, strategy string, hint string) secrets.SourceMap {
+		return secrets.SourceMap{Name: name, Source: secrets.Source{Strategy: strategy, Hint: hint}}
+	}
+
+	t.Run("inline references", func(t *testing.T) {
+		creds := []secrets.SourceMap{
+			source("password", secrets.SourceSecret, "db-password"),
+			source("user", host.SourceValue, "admin"),
+			source("login", secrets.SourceTemplate, "${credentials.user}:${credentials.password}"),
+			source("connstr", secrets.SourceTemplate, "postgres://${credentials.login}@${env.DB_HOST}"),
+			source("greeting", secrets.SourceTemplate, "hello ${credentials.user}"),
+		}
+
+		got, err := InlineTemplateSources(CollectionCredentials, creds)
+		require.NoError(t, err)
+		assert.Equal(t, []secrets.SourceMap{
+			source("password", secrets.SourceSecret, "db-password"),
+			source("user", host.SourceValue, "admin"),
+			source("login", secrets.SourceTemplate, "admin:${secret.db-password}"),
+			source("connstr", secrets.SourceTemplate, "postgres://admin:${secret.db-password}@${env.DB_HOST}"),
+			source("greeting", host.SourceValue, "hello admin"),
+		}, got, "references to entries in the set should be replaced with their source")
+		assert.Equal(t, "${credentials.user}:${credentials.password}", creds[2].Source.Hint, "the sources should not be modified")
+	})
+
+	t.Run("undefined reference", func(t *testing.T) {
+		_, err := InlineTemplateSources(CollectionParameters, []secrets.SourceMap{
+			source("url", secrets.SourceTemplate, "${parameters.host}"),
+		})
+		require.EqualError(t, err, "the template for url references parameters.host which is not defined in the set")
+	})
+
+	t.Run("value that looks like a template", func(t *testing.T) {
+		_, err := InlineTemplateSources(CollectionParameters, []secrets.SourceMap{
+			source("host", host.SourceValue, "${env.HOST}"),
+			source("url", secrets.SourceTemplate, "https://${parameters.host}"),
+		})
+		require.ErrorContains(t, err, "whose value cannot be used in a template")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := InlineTemplateSources(CollectionParameters, []secrets.SourceMap{
+			source("a", secrets.SourceTemplate, "${parameters.b}"),
+			source("b", secrets.SourceTemplate, "${parameters.a}"),
+		})
+		require.EqualError(t, err, "cycle detected in template: a -> b -> a")
+	})
+}
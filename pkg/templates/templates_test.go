@@ -34,27 +34,57 @@ func TestTemplates_GetRunScript(t *testing.T) {
 }
 
 func TestTemplates_GetDockerfile(t *testing.T) {
-	c := config.NewTestConfig(t)
-	tmpl := NewTemplates(c.Config)
+	t.Run("buildkit", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		tmpl := NewTemplates(c.Config)
 
-	gotTmpl, err := tmpl.GetDockerfile()
-	require.NoError(t, err)
+		gotTmpl, err := tmpl.GetDockerfile()
+		require.NoError(t, err)
+
+		strTmpl := string(gotTmpl)
+		require.NotContains(t, strTmpl, "FROM --platform", "the base image should not be pinned to a platform, porter build sets the platforms to build")
+		test.CompareGoldenFile(t, "./templates/build/buildkit.Dockerfile", strTmpl)
+	})
+
+	t.Run("oci", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		c.Data.BuildDriver = config.BuildDriverOCI
+		tmpl := NewTemplates(c.Config)
 
-	strTmpl := string(gotTmpl)
-	require.Contains(t, strTmpl, "--platform=linux/amd64", "missing default platform flag")
-	test.CompareGoldenFile(t, "./templates/build/buildkit.Dockerfile", strTmpl)
+		gotTmpl, err := tmpl.GetDockerfile()
+		require.NoError(t, err)
+
+		strTmpl := string(gotTmpl)
+		require.NotContains(t, strTmpl, "\nRUN ", "the oci build driver cannot run commands")
+		test.CompareGoldenFile(t, "./templates/build/oci.Dockerfile", strTmpl)
+	})
 }
 
 func TestTemplates_GetDockerfileTemplate(t *testing.T) {
-	c := config.NewTestConfig(t)
-	tmpl := NewTemplates(c.Config)
+	t.Run("buildkit", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		tmpl := NewTemplates(c.Config)
 
-	gotTmpl, err := tmpl.GetDockerfileTemplate()
-	require.NoError(t, err)
+		gotTmpl, err := tmpl.GetDockerfileTemplate()
+		require.NoError(t, err)
+
+		strTmpl := string(gotTmpl)
+		require.NotContains(t, strTmpl, "FROM --platform", "the base image should not be pinned to a platform, porter build sets the platforms to build")
+		test.CompareGoldenFile(t, "./templates/create/template.buildkit.Dockerfile", strTmpl)
+	})
+
+	t.Run("oci", func(t *testing.T) {
+		c := config.NewTestConfig(t)
+		c.Data.BuildDriver = config.BuildDriverOCI
+		tmpl := NewTemplates(c.Config)
 
-	strTmpl := string(gotTmpl)
-	require.Contains(t, strTmpl, "--platform=linux/amd64", "missing default platform flag")
-	test.CompareGoldenFile(t, "./templates/create/template.buildkit.Dockerfile", strTmpl)
+		gotTmpl, err := tmpl.GetDockerfileTemplate()
+		require.NoError(t, err)
+
+		strTmpl := string(gotTmpl)
+		require.NotContains(t, strTmpl, "\nRUN ", "the oci build driver cannot run commands")
+		test.CompareGoldenFile(t, "./templates/create/template.oci.Dockerfile", strTmpl)
+	})
 }
 
 func TestTemplates_GetCredentialSetJSON(t *testing.T) {
@@ -66,7 +66,7 @@ func setupMysql(ctx context.Context, p *porter.TestPorter, namespace string, bun
 
 	//Set the label on the installaiton so Porter knows to grab it
 	mysqlinst.SetLabel("sh.porter.SharingGroup", "myapp")
-	err = p.Installations.UpdateInstallation(ctx, mysqlinst)
+	err = p.Installations.UpdateInstallation(ctx, &mysqlinst)
 	require.NoError(p.T(), err, "could not add label to mysql inst")
 
 }